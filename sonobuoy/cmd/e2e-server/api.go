@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// api serves the control endpoints for a runner
+type api struct {
+	runner *runner
+	token  string
+}
+
+func (a *api) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/api/v1/runs", a.authenticated(http.MethodPost, a.startRun))
+	mux.Handle("/api/v1/runs/current/cancel", a.authenticated(http.MethodPost, a.cancelRun))
+	mux.Handle("/api/v1/runs/last/report", a.authenticated(http.MethodGet, a.lastReport))
+	mux.Handle("/api/v1/status", a.authenticated(http.MethodGet, a.status))
+	return mux
+}
+
+// authenticated only passes requests with the given method and the API bearer token on to handler
+func (a *api) authenticated(method string, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// startRun triggers a run, with optional RunOptions as the body
+func (a *api) startRun(w http.ResponseWriter, r *http.Request) {
+	var options RunOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	run, err := a.runner.Start(options)
+	switch {
+	case errors.Is(err, errRunInProgress):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusAccepted, run)
+	}
+}
+
+func (a *api) cancelRun(w http.ResponseWriter, _ *http.Request) {
+	run, err := a.runner.Cancel()
+	switch {
+	case errors.Is(err, errNoRun):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusAccepted, run)
+	}
+}
+
+func (a *api) status(w http.ResponseWriter, _ *http.Request) {
+	current, last := a.runner.Status()
+	writeJSON(w, http.StatusOK, struct {
+		Current *Run `json:"current"`
+		Last    *Run `json:"last"`
+	}{current, last})
+}
+
+// lastReport serves the JUnit report of the last finished run, or the Ginkgo JSON report with ?format=json
+func (a *api) lastReport(w http.ResponseWriter, r *http.Request) {
+	name, contentType := "junit.xml", "application/xml"
+	if r.URL.Query().Get("format") == "json" {
+		name, contentType = "report.json", "application/json"
+	}
+	path, err := a.runner.LastReport(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, errors.New("no report is available"))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeFile(w, r, path)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testToken = "test-token"
+
+// request sends a request with the bearer token to an API serving r
+func request(t *testing.T, r *runner, method, path, token string) *http.Response {
+	t.Helper()
+	server := httptest.NewServer((&api{runner: r, token: testToken}).routes())
+	t.Cleanup(server.Close)
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{name: "missing", token: "", want: http.StatusUnauthorized},
+		{name: "wrong", token: "not-" + testToken, want: http.StatusUnauthorized},
+		{name: "valid", token: testToken, want: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := request(t, &runner{}, http.MethodGet, "/api/v1/status", test.token)
+			if resp.StatusCode != test.want {
+				t.Errorf("GET /api/v1/status answered %s, want %d", resp.Status, test.want)
+			}
+			if test.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("401 without a WWW-Authenticate: Bearer challenge")
+			}
+		})
+	}
+
+	if resp := request(t, &runner{}, http.MethodGet, "/healthz", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz without a token answered %s, want 200", resp.Status)
+	}
+}
+
+func TestAPIRejectsWrongMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		{method: http.MethodGet, path: "/api/v1/runs", allow: http.MethodPost},
+		{method: http.MethodGet, path: "/api/v1/runs/current/cancel", allow: http.MethodPost},
+		{method: http.MethodPost, path: "/api/v1/status", allow: http.MethodGet},
+		{method: http.MethodDelete, path: "/api/v1/runs/last/report", allow: http.MethodGet},
+	}
+	for _, test := range tests {
+		resp := request(t, &runner{}, test.method, test.path, testToken)
+		if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != test.allow {
+			t.Errorf("%s %s answered %s with Allow %q, want 405 with Allow %q",
+				test.method, test.path, resp.Status, resp.Header.Get("Allow"), test.allow)
+		}
+	}
+	// The token is checked first, so the methods an endpoint takes are not disclosed without it
+	if resp := request(t, &runner{}, http.MethodGet, "/api/v1/runs", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/v1/runs without a token answered %s, want 401", resp.Status)
+	}
+}
+
+func TestAPIConflicts(t *testing.T) {
+	running := &runner{current: &Run{ID: "running", State: RunStateRunning}}
+	if resp := request(t, running, http.MethodPost, "/api/v1/runs", testToken); resp.StatusCode != http.StatusConflict {
+		t.Errorf("starting a run during another answered %s, want 409", resp.Status)
+	}
+	if resp := request(t, &runner{}, http.MethodPost, "/api/v1/runs/current/cancel", testToken); resp.StatusCode != http.StatusConflict {
+		t.Errorf("cancelling without a run answered %s, want 409", resp.Status)
+	}
+}
+
+func TestAPILastReport(t *testing.T) {
+	resp := request(t, &runner{}, http.MethodGet, "/api/v1/runs/last/report", testToken)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("report before any run answered %s, want 404", resp.Status)
+	}
+
+	resultsDir := t.TempDir()
+	for name, content := range map[string]string{"junit.xml": "<testsuites/>", "report.json": "[]"} {
+		if err := os.WriteFile(filepath.Join(resultsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	finished := &runner{last: &Run{ID: "finished", State: RunStateSucceeded, resultsDir: resultsDir}}
+	tests := []struct {
+		query       string
+		contentType string
+		body        string
+	}{
+		{query: "", contentType: "application/xml", body: "<testsuites/>"},
+		{query: "?format=json", contentType: "application/json", body: "[]"},
+	}
+	for _, test := range tests {
+		resp := request(t, finished, http.MethodGet, "/api/v1/runs/last/report"+test.query, testToken)
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), test.contentType) || string(body) != test.body {
+			t.Errorf("report%s answered %s, %s %q; want 200, %s %q",
+				test.query, resp.Status, resp.Header.Get("Content-Type"), body, test.contentType, test.body)
+		}
+	}
+
+	// A run that ended before writing its report has none to serve
+	os.Remove(filepath.Join(resultsDir, "junit.xml"))
+	if resp := request(t, finished, http.MethodGet, "/api/v1/runs/last/report", testToken); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing report answered %s, want 404", resp.Status)
+	}
+}
+
+func TestAPIStatus(t *testing.T) {
+	r := &runner{last: &Run{ID: "finished", State: RunStateFailed}}
+	resp := request(t, r, http.MethodGet, "/api/v1/status", testToken)
+	var status struct {
+		Current *Run `json:"current"`
+		Last    *Run `json:"last"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Current != nil || status.Last == nil || status.Last.ID != "finished" || status.Last.State != RunStateFailed {
+		t.Errorf("status = %+v, %+v; want no current run and the failed last run", status.Current, status.Last)
+	}
+}
@@ -0,0 +1,76 @@
+// Command e2e-server keeps the suites available in a long-running, self-hosted deployment and exposes a
+// small control API so platform portals can trigger runs, check on them, fetch the last report and cancel
+// a run without shelling into the pod.
+//
+// Every request except /healthz needs the bearer token from E2E_API_TOKEN, so the API is served over TLS
+// unless --insecure says a proxy in front of it terminates TLS:
+//
+//	POST /api/v1/runs                  start a run; optional body {"focus": "...", "labelFilter": "..."}
+//	GET  /api/v1/status                the current and last finished run
+//	GET  /api/v1/runs/last/report      JUnit report of the last run, ?format=json for the Ginkgo report
+//	POST /api/v1/runs/current/cancel   interrupt the current run
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+func main() {
+	listen := flag.String("listen", ":8080", "Address to serve the control API on")
+	testsDir := flag.String("tests", "/workspace/tests", "Directory of the suites to run")
+	resultsDir := flag.String("results-dir", envOrDefault("RESULTS_DIR", "/tmp/results"), "Directory each run writes its results under")
+	tlsCert := flag.String("tls-cert-file", os.Getenv("E2E_API_TLS_CERT_FILE"), "Serve over TLS with this certificate")
+	tlsKey := flag.String("tls-private-key-file", os.Getenv("E2E_API_TLS_KEY_FILE"), "Private key of --tls-cert-file")
+	insecure := flag.Bool("insecure", os.Getenv("E2E_API_INSECURE") == "true", "Serve over plain HTTP, e.g. behind a proxy terminating TLS")
+	cancelGrace := flag.Duration("cancel-grace-period", 2*time.Minute, "How long a cancelled run may clean up before it is killed")
+	procs := flag.String("procs", envOrDefault("E2E_PARALLELISM", "0"), "Ginkgo processes per run; 0 starts one per CPU")
+	flag.Parse()
+
+	parallelism, err := strconv.Atoi(*procs)
+	if err != nil || parallelism < 0 {
+		log.Fatalf("Invalid --procs %q: must be a non-negative number", *procs)
+	}
+
+	token := os.Getenv("E2E_API_TOKEN")
+	if token == "" {
+		log.Fatal("E2E_API_TOKEN must be set to protect the control API")
+	}
+	switch {
+	case *tlsCert != "" && *tlsKey == "":
+		log.Fatal("--tls-private-key-file must be set with --tls-cert-file")
+	case *tlsCert == "" && !*insecure:
+		log.Fatal("--tls-cert-file must be set, or --insecure given, since the bearer token would cross the network in clear text")
+	case *tlsCert == "":
+		log.Printf("Warning: serving the control API over plain HTTP; the bearer token is only protected by whatever is in front of it")
+	}
+
+	a := &api{
+		runner: &runner{testsDir: *testsDir, resultsDir: *resultsDir, cancelGrace: *cancelGrace, procs: parallelism},
+		token:  token,
+	}
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           a.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Serving control API on %s", *listen)
+	if *tlsCert != "" {
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		err = server.ListenAndServe()
+	}
+	log.Fatal(err)
+}
+
+func envOrDefault(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunState is the lifecycle state of a run
+type RunState string
+
+const (
+	RunStateRunning   RunState = "Running"
+	RunStateSucceeded RunState = "Succeeded"
+	RunStateFailed    RunState = "Failed"
+	RunStateCancelled RunState = "Cancelled"
+)
+
+// RunOptions narrow down which specs a run executes
+type RunOptions struct {
+	Focus       string `json:"focus,omitempty"`
+	LabelFilter string `json:"labelFilter,omitempty"`
+	// FlakeAttempts retries failed specs up to this many attempts in total, reporting the ones passing on a
+	// retry as flaky
+	FlakeAttempts int `json:"flakeAttempts,omitempty"`
+}
+
+// Run is one execution of the suites
+type Run struct {
+	ID        string     `json:"id"`
+	State     RunState   `json:"state"`
+	Options   RunOptions `json:"options"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	ExitCode  *int       `json:"exitCode,omitempty"`
+	Error     string     `json:"error,omitempty"`
+
+	resultsDir string
+}
+
+var (
+	errRunInProgress = errors.New("a run is already in progress")
+	errNoRun         = errors.New("no run is in progress")
+)
+
+// runner starts the suites with ginkgo, one run at a time
+type runner struct {
+	testsDir    string
+	resultsDir  string
+	cancelGrace time.Duration
+	// procs is how many Ginkgo processes a run uses; zero lets ginkgo start one per CPU
+	procs int
+
+	mu        sync.Mutex
+	current   *Run
+	process   *os.Process
+	cancelled bool
+	last      *Run
+}
+
+// Start launches a run unless one is already in progress
+func (r *runner) Start(options RunOptions) (Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		return Run{}, errRunInProgress
+	}
+
+	hostname, _ := os.Hostname()
+	run := &Run{
+		ID:        fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102150405"), hostname),
+		State:     RunStateRunning,
+		Options:   options,
+		StartTime: time.Now().UTC(),
+	}
+	run.resultsDir = filepath.Join(r.resultsDir, run.ID)
+	if err := os.MkdirAll(run.resultsDir, 0755); err != nil {
+		return Run{}, err
+	}
+	out, err := os.Create(filepath.Join(run.resultsDir, "out"))
+	if err != nil {
+		return Run{}, err
+	}
+
+	cmd := exec.Command("ginkgo", r.ginkgoArgs(run.resultsDir, options)...)
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.Env = append(childEnv(os.Environ()), "E2E_RUN_ID="+run.ID, "RESULTS_DIR="+run.resultsDir)
+	if err := cmd.Start(); err != nil {
+		out.Close()
+		return Run{}, err
+	}
+
+	r.current, r.process, r.cancelled = run, cmd.Process, false
+	go r.wait(cmd, out)
+	return *run, nil
+}
+
+// childEnv returns environ without the control API's token, which the specs, and the pods and webhooks the
+// specs run, have no need to see
+func childEnv(environ []string) []string {
+	return slices.DeleteFunc(slices.Clone(environ), func(variable string) bool {
+		return strings.HasPrefix(variable, "E2E_API_TOKEN=")
+	})
+}
+
+// ginkgoArgs returns the arguments of the ginkgo invocation of a run writing its results to resultsDir: the
+// same as run.sh's, plus a JSON report for clients
+func (r *runner) ginkgoArgs(resultsDir string, options RunOptions) []string {
+	args := []string{"run", "--keep-going", "--output-dir=" + resultsDir, "--json-report=report.json"}
+	// With a baseline the suite writes junit.xml itself, with known issues skipped, and ginkgo's own would
+	// overwrite it after the suite's report nodes ran
+	if os.Getenv("E2E_BASELINE") == "" {
+		args = append(args, "--junit-report=junit.xml")
+	}
+	if r.procs > 0 {
+		args = append(args, fmt.Sprintf("--procs=%d", r.procs))
+	} else {
+		args = append(args, "-p")
+	}
+	if options.Focus != "" {
+		args = append(args, "--focus="+options.Focus)
+	}
+	if options.LabelFilter != "" {
+		args = append(args, "--label-filter="+options.LabelFilter)
+	}
+	if options.FlakeAttempts > 1 {
+		args = append(args, fmt.Sprintf("--flake-attempts=%d", options.FlakeAttempts))
+	}
+	return append(args, r.testsDir)
+}
+
+// wait records the outcome of a run once ginkgo exits
+func (r *runner) wait(cmd *exec.Cmd, out *os.File) {
+	err := cmd.Wait()
+	out.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := r.current
+	end := time.Now().UTC()
+	code := cmd.ProcessState.ExitCode()
+	run.EndTime, run.ExitCode = &end, &code
+	switch {
+	case r.cancelled:
+		run.State = RunStateCancelled
+	case err == nil:
+		run.State = RunStateSucceeded
+	default:
+		run.State = RunStateFailed
+		run.Error = err.Error()
+	}
+	r.last, r.current, r.process = run, nil, nil
+}
+
+// Cancel interrupts the current run, giving ginkgo time to run cleanup nodes before it is killed
+func (r *runner) Cancel() (Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return Run{}, errNoRun
+	}
+	if err := r.process.Signal(os.Interrupt); err != nil {
+		return Run{}, err
+	}
+	r.cancelled = true
+
+	process, run := r.process, r.current
+	time.AfterFunc(r.cancelGrace, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.current == run {
+			process.Kill()
+		}
+	})
+	return *run, nil
+}
+
+// Status returns copies of the current and last finished runs, either of which may be nil
+func (r *runner) Status() (current, last *Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		run := *r.current
+		current = &run
+	}
+	if r.last != nil {
+		run := *r.last
+		last = &run
+	}
+	return current, last
+}
+
+// LastReport returns the path of a report file of the last finished run
+func (r *runner) LastReport(name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil {
+		return "", os.ErrNotExist
+	}
+	path := filepath.Join(r.last.resultsDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
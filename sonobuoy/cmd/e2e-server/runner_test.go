@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGinkgoArgsLeaveJUnitToBaseline(t *testing.T) {
+	r := &runner{testsDir: "/workspace/tests", procs: 2}
+
+	t.Setenv("E2E_BASELINE", "")
+	args := r.ginkgoArgs("/results/run", RunOptions{})
+	if !slices.Contains(args, "--junit-report=junit.xml") {
+		t.Errorf("ginkgo args %q do not write junit.xml without a baseline", args)
+	}
+
+	t.Setenv("E2E_BASELINE", "/etc/e2e/baseline.yaml")
+	args = r.ginkgoArgs("/results/run", RunOptions{})
+	if slices.Contains(args, "--junit-report=junit.xml") {
+		t.Errorf("ginkgo args %q overwrite the baseline-filtered junit.xml", args)
+	}
+	if !slices.Contains(args, "--json-report=report.json") {
+		t.Errorf("ginkgo args %q do not write the report the baseline is applied to", args)
+	}
+	if args[len(args)-1] != r.testsDir {
+		t.Errorf("ginkgo args %q do not end with the tests directory", args)
+	}
+}
+
+func TestChildEnvDropsAPIToken(t *testing.T) {
+	environ := []string{"E2E_API_TOKEN=secret", "E2E_API_TOKEN_FILE=/keep", "KUBECONFIG=/kubeconfig", "E2E_API_INSECURE=true"}
+	env := childEnv(environ)
+	want := []string{"E2E_API_TOKEN_FILE=/keep", "KUBECONFIG=/kubeconfig", "E2E_API_INSECURE=true"}
+	if !slices.Equal(env, want) {
+		t.Errorf("childEnv(%q) = %q, want %q", environ, env, want)
+	}
+	if environ[0] != "E2E_API_TOKEN=secret" {
+		t.Errorf("childEnv modified the environment it was given: %q", environ)
+	}
+}
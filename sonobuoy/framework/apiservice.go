@@ -0,0 +1,28 @@
+package framework
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// APIServiceResource is the aggregation layer's registration of an API group version with the service serving it.
+// The typed client lives in k8s.io/kube-aggregator, so suites read APIServices through DynamicClient.
+var APIServiceResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// APIServiceAvailable reports whether the APIService's Available condition is True, and otherwise why not
+func APIServiceAvailable(apiService *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Available" {
+			continue
+		}
+		if condition["status"] == "True" {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%v: %v", condition["reason"], condition["message"])
+	}
+	return false, "no Available condition"
+}
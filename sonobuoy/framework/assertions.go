@@ -0,0 +1,139 @@
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ObjectAssertion checks an object created by the suites, as stored by the API server, against a cluster
+// policy. A non-nil error is a policy violation.
+type ObjectAssertion func(obj *unstructured.Unstructured) error
+
+type namedAssertion struct {
+	name      string
+	assertion ObjectAssertion
+}
+
+var (
+	objectAssertionsMu sync.RWMutex
+	objectAssertions   []namedAssertion
+
+	violationsMu sync.Mutex
+	violations   []string
+)
+
+// RegisterObjectAssertion adds a policy assertion run against every object created through clients built
+// from LoadConfig. Violations fail the spec that created the object once it finishes, provided the entry
+// point registers VerifyObjectAssertions with AfterEach.
+func RegisterObjectAssertion(name string, assertion ObjectAssertion) {
+	objectAssertionsMu.Lock()
+	defer objectAssertionsMu.Unlock()
+	objectAssertions = append(objectAssertions, namedAssertion{name: name, assertion: assertion})
+}
+
+// VerifyObjectAssertions fails the current spec if any object created during it violated a registered
+// assertion, and records each violation in the report
+func VerifyObjectAssertions() {
+	violationsMu.Lock()
+	found := violations
+	violations = nil
+	violationsMu.Unlock()
+
+	if len(found) == 0 {
+		return
+	}
+	for _, violation := range found {
+		ginkgo.AddReportEntry("Policy violation", violation)
+	}
+	ginkgo.Fail(fmt.Sprintf("%d object(s) violated cluster policy:\n%s", len(found), strings.Join(found, "\n")))
+}
+
+// RequireLabel returns an assertion that objects of the given kind carry a label with key
+func RequireLabel(kind, key string) ObjectAssertion {
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() != kind {
+			return nil
+		}
+		if _, ok := obj.GetLabels()[key]; !ok {
+			return fmt.Errorf("missing label %q", key)
+		}
+		return nil
+	}
+}
+
+// Paths of the container lists in the pod specs of built-in workload kinds
+var containerPaths = [][]string{
+	{"spec", "containers"},
+	{"spec", "initContainers"},
+	{"spec", "template", "spec", "containers"},
+	{"spec", "template", "spec", "initContainers"},
+	{"spec", "jobTemplate", "spec", "template", "spec", "containers"},
+	{"spec", "jobTemplate", "spec", "template", "spec", "initContainers"},
+}
+
+// ForbidLatestTag returns an assertion that no container image is untagged or tagged latest, unless pinned by digest
+func ForbidLatestTag() ObjectAssertion {
+	return func(obj *unstructured.Unstructured) error {
+		for _, path := range containerPaths {
+			containers, _, _ := unstructured.NestedSlice(obj.Object, path...)
+			for _, container := range containers {
+				container, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				image, _, _ := unstructured.NestedString(container, "image")
+				if strings.Contains(image, "@") {
+					continue
+				}
+				name := image[strings.LastIndex(image, "/")+1:]
+				if !strings.Contains(name, ":") || strings.HasSuffix(name, ":latest") {
+					return fmt.Errorf("image %q is not pinned to a tag other than latest", image)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// assertCreated runs the registered assertions on the object returned by a successful create
+func assertCreated(req *http.Request, resp *http.Response) error {
+	objectAssertionsMu.RLock()
+	assertions := objectAssertions
+	objectAssertionsMu.RUnlock()
+
+	// Dry-run creates are not persisted, so they are not checked
+	if len(assertions) == 0 || resp.StatusCode/100 != 2 || req.URL.Query().Has("dryRun") {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(body); err != nil {
+		return nil
+	}
+
+	for _, a := range assertions {
+		if err := a.assertion(obj); err != nil {
+			violationsMu.Lock()
+			violations = append(violations, fmt.Sprintf("%s %s: %s: %v", obj.GetKind(), objectName(obj), a.name, err))
+			violationsMu.Unlock()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package framework
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestForbidLatestTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"alpine:3.20", true},
+		{"alpine", false},
+		{"alpine:latest", false},
+		{"docker.io/library/alpine:3.20", true},
+		{"docker.io/library/alpine", false},
+		{"registry.example.com:5000/team/app:1.2.3", true},
+		{"registry.example.com:5000/team/app", false},
+		{"registry.example.com:5000/team/app:latest", false},
+		{"localhost:5000/app", false},
+		{"alpine@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"alpine:latest@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"registry.example.com:5000/team/app@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"nginxinc/nginx-unprivileged:1.25-alpine", true},
+		{"example.com/app:latest-stable", true},
+	}
+	assertion := ForbidLatestTag()
+	for _, test := range tests {
+		pod := &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": "Pod",
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "app", "image": test.image}},
+			},
+		}}
+		if err := assertion(pod); (err == nil) != test.want {
+			t.Errorf("ForbidLatestTag() on image %q = %v, want allowed %v", test.image, err, test.want)
+		}
+	}
+}
+
+func TestForbidLatestTagContainerPaths(t *testing.T) {
+	containers := []interface{}{
+		map[string]interface{}{"name": "pinned", "image": "alpine:3.20"},
+		map[string]interface{}{"name": "untagged", "image": "alpine"},
+	}
+	objects := map[string]map[string]interface{}{
+		"Pod init containers": {"spec": map[string]interface{}{"initContainers": containers}},
+		"Deployment": {"spec": map[string]interface{}{"template": map[string]interface{}{
+			"spec": map[string]interface{}{"containers": containers},
+		}}},
+		"CronJob": {"spec": map[string]interface{}{"jobTemplate": map[string]interface{}{
+			"spec": map[string]interface{}{"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			}},
+		}}},
+	}
+	for name, object := range objects {
+		if err := ForbidLatestTag()(&unstructured.Unstructured{Object: object}); err == nil {
+			t.Errorf("ForbidLatestTag() allowed the untagged image in the %s", name)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package framework
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// AuditDir is the directory under RESULTS_DIR holding the audit events of failed specs
+const AuditDir = "audit"
+
+// Longest audit log line read; request and response bodies can make events large
+const maxAuditEventSize = 4 << 20
+
+// Denied or failed requests listed in the report entry of a failed spec
+const maxAuditEntries = 20
+
+// AuditEvent is the part of an audit.k8s.io/v1 Event the suites correlate failures with
+type AuditEvent struct {
+	AuditID    string `json:"auditID"`
+	Stage      string `json:"stage"`
+	Verb       string `json:"verb"`
+	RequestURI string `json:"requestURI"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	UserAgent string `json:"userAgent"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	} `json:"responseStatus"`
+	RequestReceivedTimestamp time.Time         `json:"requestReceivedTimestamp"`
+	Annotations              map[string]string `json:"annotations"`
+}
+
+// UserAgent returns the user agent of the clients LoadConfig builds. It names the run and the parallel
+// process, so audit events of the requests a spec sent can be told apart from the rest.
+func UserAgent() string {
+	return fmt.Sprintf("sonobuoy-e2e/%s (%s)", Version, auditMarker())
+}
+
+// auditMarker is the part of UserAgent identifying this process of the run
+func auditMarker() string {
+	return fmt.Sprintf("run %s; process %d", RunID(), ginkgo.GinkgoParallelProcess())
+}
+
+// auditWebhookLog is where the audit webhook receiver keeps the events of the run, for every parallel
+// process to read
+func auditWebhookLog() string {
+	return filepath.Join(os.TempDir(), "e2e-audit-webhook.jsonl")
+}
+
+// startAuditWebhook serves the audit webhook backend over TLS on the address E2E_AUDIT_WEBHOOK_ADDR names, on
+// the first parallel process only, keeping the events of the suites' requests in auditWebhookLog
+func startAuditWebhook() {
+	config, err := LoadRunConfig()
+	if err != nil || config.Audit.WebhookAddr == "" || ginkgo.GinkgoParallelProcess() != 1 {
+		return
+	}
+	file, err := os.OpenFile(auditWebhookLog(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		Logger().Warn("Not receiving audit events", "error", err)
+		return
+	}
+	tlsConfig, err := auditWebhookTLS(config.Audit)
+	if err != nil {
+		file.Close()
+		Logger().Warn("Not receiving audit events", "error", err)
+		return
+	}
+	listener, err := net.Listen("tcp", config.Audit.WebhookAddr)
+	if err != nil {
+		file.Close()
+		Logger().Warn("Not receiving audit events", "error", err)
+		return
+	}
+
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler:           auditWebhookHandler(config.Audit.WebhookToken, file),
+		TLSConfig:         tlsConfig,
+	}
+	go server.ServeTLS(listener, config.Audit.WebhookCertFile, config.Audit.WebhookKeyFile)
+	Logger().Info("Receiving audit events", "address", listener.Addr().String())
+}
+
+// auditWebhookTLS returns the TLS configuration of the receiver, asking the backend for a client certificate
+// when E2E_AUDIT_WEBHOOK_CLIENT_CA names the authorities to verify it with
+func auditWebhookTLS(audit AuditConfig) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if audit.WebhookClientCA == "" {
+		return config, nil
+	}
+	bundle, err := os.ReadFile(audit.WebhookClientCA)
+	if err != nil {
+		return nil, err
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", audit.WebhookClientCA)
+	}
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+// auditWebhookHandler receives the event lists of an audit webhook backend that authenticates with a verified
+// client certificate or, when token is set, the bearer token, and writes the events of the suites' requests
+// to out as JSON lines
+func auditWebhookHandler(token string, out io.Writer) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verified := r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); !verified &&
+			(token == "" || !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1) {
+			http.Error(w, "missing or invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, item := range list.Items {
+			// Other clients' events only matter when they touch a test namespace
+			if bytes.Contains(item, []byte(`sonobuoy-e2e/`)) || bytes.Contains(item, []byte(`"namespace":"`+baseNamespace())) {
+				out.Write(append(item, '\n'))
+			}
+		}
+	})
+}
+
+// CollectAuditEvents attaches the audit events of the current spec's requests, and of requests touching
+// its namespace, to the spec when it fails: all of them as a file under RESULTS_DIR and the denied or
+// failed ones as a report entry, which pins down admission and RBAC failures. Meant to be registered with
+// BeforeEach; it does nothing unless E2E_AUDIT_LOG or E2E_AUDIT_WEBHOOK_ADDR is set.
+func CollectAuditEvents() {
+	config, err := LoadRunConfig()
+	if err != nil || config.Audit.LogPath == "" && config.Audit.WebhookAddr == "" {
+		return
+	}
+	start := time.Now()
+	namespace := TestNamespace()
+	ginkgo.DeferCleanup(func() {
+		report := ginkgo.CurrentSpecReport()
+		if !report.Failed() {
+			return
+		}
+		// Audit backends batch their events, so give them time to arrive
+		time.Sleep(config.Audit.Delay)
+
+		path := config.Audit.LogPath
+		if path == "" {
+			path = auditWebhookLog()
+		}
+		// Timestamps are truncated to microseconds and clocks of the API servers may drift a little
+		events, err := ReadAuditEvents(path, start.Add(-time.Second), time.Now(), func(event *AuditEvent) bool {
+			return strings.Contains(event.UserAgent, auditMarker()) ||
+				event.ObjectRef != nil && event.ObjectRef.Namespace == namespace
+		})
+		if err != nil {
+			Logger().Warn("Failed to read audit events", "path", path, "error", err)
+			return
+		}
+		if len(events) > config.Audit.MaxEvents {
+			events = events[len(events)-config.Audit.MaxEvents:]
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		var denied []string
+		for _, event := range events {
+			if event.ResponseStatus != nil && event.ResponseStatus.Code >= 400 && len(denied) < maxAuditEntries {
+				denied = append(denied, describeAuditEvent(event))
+			}
+		}
+		if len(denied) > 0 {
+			ginkgo.AddReportEntry("Denied API requests", strings.Join(denied, "\n"))
+		}
+		if err := writeAuditEvents(report.FullText(), events); err != nil {
+			Logger().Warn("Failed to write audit events", "error", err)
+		}
+	})
+}
+
+// ReadAuditEvents returns the events at the ResponseComplete or Panic stage of the JSON lines audit log at
+// path received between from and to that match keeps
+func ReadAuditEvents(path string, from, to time.Time, keep func(*AuditEvent) bool) ([]AuditEvent, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []AuditEvent
+	reader := bufio.NewReaderSize(file, 64<<10)
+	for {
+		line, err := readAuditLine(reader)
+		if err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		var event AuditEvent
+		// Log rotation or a partially written line must not hide the rest
+		if json.Unmarshal(line, &event) != nil || event.Stage != "ResponseComplete" && event.Stage != "Panic" {
+			continue
+		}
+		if event.RequestReceivedTimestamp.Before(from) || event.RequestReceivedTimestamp.After(to) || !keep(&event) {
+			continue
+		}
+		events = append(events, event)
+	}
+}
+
+// readAuditLine returns the next line of reader, skipping over lines longer than maxAuditEventSize
+func readAuditLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line)+len(chunk) <= maxAuditEventSize {
+			line = append(line, chunk...)
+		}
+		if err != bufio.ErrBufferFull {
+			if err == io.EOF && len(line) > 0 {
+				return line, nil
+			}
+			return line, err
+		}
+	}
+}
+
+// describeAuditEvent summarizes a denied or failed request in a line
+func describeAuditEvent(event AuditEvent) string {
+	line := fmt.Sprintf("%s %s by %s: %d", event.Verb, event.RequestURI, event.User.Username, event.ResponseStatus.Code)
+	if event.ResponseStatus.Message != "" {
+		line += " " + event.ResponseStatus.Message
+	}
+	// The authorizer and admission plugins explain their decisions in annotations
+	for _, key := range []string{"authorization.k8s.io/reason", "pod-security.kubernetes.io/enforce-policy"} {
+		if value := event.Annotations[key]; value != "" {
+			line += fmt.Sprintf(" (%s: %s)", key, value)
+		}
+	}
+	return line
+}
+
+// writeAuditEvents writes the audit events of a failed spec as JSON lines next to its log under RESULTS_DIR
+func writeAuditEvents(spec string, events []AuditEvent) error {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return nil
+	}
+	dir := filepath.Join(resultsDir, AuditDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	name := strings.TrimSuffix(specLogName(spec), ".log") + ".jsonl"
+	return os.WriteFile(filepath.Join(dir, name), out.Bytes(), 0644)
+}
@@ -0,0 +1,211 @@
+package framework
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientCertificate creates a throwaway CA and a client certificate it signs, returning the CA as PEM
+func clientCertificate(t *testing.T) (tls.Certificate, []byte) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "audit-client-ca"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "kube-apiserver"},
+		NotBefore:    caTemplate.NotBefore,
+		NotAfter:     caTemplate.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caTemplate, key.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{certDER}, PrivateKey: key},
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+}
+
+func TestAuditWebhookHandlerAuthenticates(t *testing.T) {
+	t.Setenv("TEST_NAMESPACE", "e2e")
+	events := `{"items":[{"userAgent":"sonobuoy-e2e/0.2.0"},{"userAgent":"kubelet","objectRef":{"namespace":"kube-system"}}]}`
+	tests := []struct {
+		name     string
+		token    string
+		header   string
+		verified bool
+		want     int
+	}{
+		{name: "no credentials", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: "Bearer not-secret", want: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "secret", header: "Basic secret", want: http.StatusUnauthorized},
+		{name: "empty token configured", header: "Bearer ", want: http.StatusUnauthorized},
+		{name: "token", token: "secret", header: "Bearer secret", want: http.StatusOK},
+		{name: "client certificate", verified: true, want: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(events))
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			if test.verified {
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+			}
+			rec := httptest.NewRecorder()
+			auditWebhookHandler(test.token, &out).ServeHTTP(rec, req)
+			if rec.Code != test.want {
+				t.Fatalf("handler answered %d, want %d", rec.Code, test.want)
+			}
+			want := ""
+			if test.want == http.StatusOK {
+				// Only the event of the suites' own request is kept
+				want = `{"userAgent":"sonobuoy-e2e/0.2.0"}` + "\n"
+			}
+			if out.String() != want {
+				t.Errorf("handler wrote %q, want %q", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestAuditWebhookServesTLS(t *testing.T) {
+	certificate, err := GenerateServingCertificate(time.Hour, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPair, err := tls.X509KeyPair(certificate.Cert, certificate.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, clientCAPEM := clientCertificate(t)
+	clientCA := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(clientCA, clientCAPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := auditWebhookTLS(AuditConfig{WebhookClientCA: clientCA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MinVersion != tls.VersionTLS12 || config.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("TLS config has minimum version %#x and client auth %v", config.MinVersion, config.ClientAuth)
+	}
+
+	server := httptest.NewUnstartedServer(auditWebhookHandler("", &bytes.Buffer{}))
+	server.TLS = config
+	server.TLS.Certificates = []tls.Certificate{keyPair}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certificate.CACert)
+	tests := []struct {
+		name         string
+		certificates []tls.Certificate
+		want         int
+	}{
+		{name: "without a client certificate", want: http.StatusUnauthorized},
+		{name: "with a client certificate", certificates: []tls.Certificate{clientCert}, want: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				ServerName:   "localhost",
+				Certificates: test.certificates,
+			}}}
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"items":[]}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.want {
+				t.Errorf("receiver answered %s, want %d", resp.Status, test.want)
+			}
+		})
+	}
+
+	if _, err := auditWebhookTLS(AuditConfig{WebhookClientCA: filepath.Join(t.TempDir(), "missing.crt")}); err == nil {
+		t.Error("auditWebhookTLS accepted a missing client CA file")
+	}
+}
+
+func TestParseRunConfigAuditWebhook(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		err  string
+	}{
+		{name: "disabled", env: map[string]string{}},
+		{
+			name: "plain HTTP",
+			env:  map[string]string{"E2E_AUDIT_WEBHOOK_ADDR": ":8443", "E2E_AUDIT_WEBHOOK_TOKEN": "secret"},
+			err:  "E2E_AUDIT_WEBHOOK_TLS_CERT_FILE and E2E_AUDIT_WEBHOOK_TLS_KEY_FILE are required",
+		},
+		{
+			name: "unauthenticated",
+			env: map[string]string{"E2E_AUDIT_WEBHOOK_ADDR": ":8443",
+				"E2E_AUDIT_WEBHOOK_TLS_CERT_FILE": "tls.crt", "E2E_AUDIT_WEBHOOK_TLS_KEY_FILE": "tls.key"},
+			err: "E2E_AUDIT_WEBHOOK_TOKEN or E2E_AUDIT_WEBHOOK_CLIENT_CA is required",
+		},
+		{
+			name: "token",
+			env: map[string]string{"E2E_AUDIT_WEBHOOK_ADDR": ":8443", "E2E_AUDIT_WEBHOOK_TOKEN": "secret",
+				"E2E_AUDIT_WEBHOOK_TLS_CERT_FILE": "tls.crt", "E2E_AUDIT_WEBHOOK_TLS_KEY_FILE": "tls.key"},
+		},
+		{
+			name: "client CA",
+			env: map[string]string{"E2E_AUDIT_WEBHOOK_ADDR": ":8443", "E2E_AUDIT_WEBHOOK_CLIENT_CA": "ca.crt",
+				"E2E_AUDIT_WEBHOOK_TLS_CERT_FILE": "tls.crt", "E2E_AUDIT_WEBHOOK_TLS_KEY_FILE": "tls.key"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"E2E_AUDIT_WEBHOOK_ADDR", "E2E_AUDIT_WEBHOOK_TOKEN", "E2E_AUDIT_WEBHOOK_CLIENT_CA",
+				"E2E_AUDIT_WEBHOOK_TLS_CERT_FILE", "E2E_AUDIT_WEBHOOK_TLS_KEY_FILE"} {
+				t.Setenv(name, test.env[name])
+			}
+			_, err := parseRunConfig()
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("parseRunConfig() = %v, want no error", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("parseRunConfig() = %v, want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
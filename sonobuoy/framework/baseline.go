@@ -0,0 +1,157 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// BaselineReportFile is written to RESULTS_DIR by ApplyBaseline
+const BaselineReportFile = "baseline.json"
+
+// Baseline lists the specs known to fail, on every platform or only some, so their failures do not fail
+// the plugin while the issue is tracked
+type Baseline struct {
+	KnownIssues []KnownIssue `json:"knownIssues"`
+}
+
+// KnownIssue quarantines the specs matching Spec
+type KnownIssue struct {
+	// Spec is a regular expression matched against the full text of a spec
+	Spec string `json:"spec"`
+	// Platforms the issue occurs on, matched against E2E_PLATFORM; empty for every platform
+	Platforms []string `json:"platforms,omitempty"`
+	// Reason explains the issue, e.g. with a link to where it is tracked
+	Reason string `json:"reason"`
+
+	pattern *regexp.Regexp
+}
+
+// BaselineReport records how a run compared to the baseline
+type BaselineReport struct {
+	Platform string `json:"platform"`
+	// KnownIssues are the quarantined specs that failed as expected
+	KnownIssues []BaselineSpec `json:"knownIssues"`
+	// UnexpectedlyPassing are the quarantined specs that passed, whose entry can likely be removed
+	UnexpectedlyPassing []BaselineSpec `json:"unexpectedlyPassing"`
+}
+
+// BaselineSpec is a quarantined spec and the issue it matched
+type BaselineSpec struct {
+	Spec    string `json:"spec"`
+	Reason  string `json:"reason"`
+	Failure string `json:"failure,omitempty"`
+}
+
+// LoadBaseline reads a baseline from a YAML file
+func LoadBaseline(path string) (*Baseline, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := yaml.ToJSON(source)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	baseline := &Baseline{}
+	if err := decoder.Decode(baseline); err != nil {
+		return nil, err
+	}
+	for i := range baseline.KnownIssues {
+		issue := &baseline.KnownIssues[i]
+		if issue.Spec == "" {
+			return nil, fmt.Errorf("known issue %d has no spec", i+1)
+		}
+		if issue.pattern, err = regexp.Compile(issue.Spec); err != nil {
+			return nil, fmt.Errorf("known issue %d: %v", i+1, err)
+		}
+	}
+	return baseline, nil
+}
+
+// Match returns the known issue quarantining the spec on platform, or nil
+func (b *Baseline) Match(spec, platform string) *KnownIssue {
+	for i := range b.KnownIssues {
+		issue := &b.KnownIssues[i]
+		if !issue.pattern.MatchString(spec) {
+			continue
+		}
+		if len(issue.Platforms) == 0 {
+			return issue
+		}
+		for _, p := range issue.Platforms {
+			if p == platform {
+				return issue
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyBaseline writes junit.xml to RESULTS_DIR with the failures of quarantined specs reported as skipped
+// known issues, so they do not fail the plugin, and records them, as well as the quarantined specs that
+// passed, in baseline.json. Meant to be registered with ReportAfterSuite; run.sh then has ginkgo write its
+// unfiltered report as JSON only. Nothing happens without E2E_BASELINE or RESULTS_DIR.
+func ApplyBaseline(report ginkgo.Report) {
+	config, err := LoadRunConfig()
+	if err != nil || config.Baseline == nil {
+		return
+	}
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+
+	filtered, baselineReport := applyBaseline(report, config.Baseline, config.Platform)
+	if err := reporters.GenerateJUnitReport(filtered, filepath.Join(resultsDir, "junit.xml")); err != nil {
+		Logger().Error("Failed to write junit.xml", "error", err)
+	}
+	data, err := json.MarshalIndent(baselineReport, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(resultsDir, BaselineReportFile), data, 0644)
+	}
+	if err != nil {
+		Logger().Error("Failed to write baseline report", "file", BaselineReportFile, "error", err)
+	}
+}
+
+// applyBaseline returns a copy of report with the failures of the specs baseline quarantines on platform
+// turned into skipped known issues, and how the run compared to the baseline
+func applyBaseline(report ginkgo.Report, baseline *Baseline, platform string) (ginkgo.Report, BaselineReport) {
+	baselineReport := BaselineReport{Platform: platform, KnownIssues: []BaselineSpec{}, UnexpectedlyPassing: []BaselineSpec{}}
+	filtered := report
+	filtered.SpecReports = make(types.SpecReports, len(report.SpecReports))
+	copy(filtered.SpecReports, report.SpecReports)
+	succeeded := true
+	for i, spec := range filtered.SpecReports {
+		var issue *KnownIssue
+		if spec.LeafNodeType == types.NodeTypeIt {
+			issue = baseline.Match(spec.FullText(), platform)
+		}
+		switch {
+		case issue != nil && spec.State.Is(types.SpecStateFailureStates):
+			baselineReport.KnownIssues = append(baselineReport.KnownIssues,
+				BaselineSpec{Spec: spec.FullText(), Reason: issue.Reason, Failure: spec.Failure.Message})
+			spec.State = types.SpecStateSkipped
+			spec.Failure.Message = fmt.Sprintf("known issue: %s: %s", issue.Reason, spec.Failure.Message)
+			filtered.SpecReports[i] = spec
+		case issue != nil && spec.State == types.SpecStatePassed:
+			baselineReport.UnexpectedlyPassing = append(baselineReport.UnexpectedlyPassing,
+				BaselineSpec{Spec: spec.FullText(), Reason: issue.Reason})
+		case spec.State.Is(types.SpecStateFailureStates):
+			succeeded = false
+		}
+	}
+	filtered.SuiteSucceeded = succeeded
+	return filtered, baselineReport
+}
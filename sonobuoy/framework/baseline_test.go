@@ -0,0 +1,185 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// writeBaseline writes source to a baseline file and loads it
+func writeBaseline(t *testing.T, source string) (*Baseline, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	if err := os.WriteFile(path, []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadBaseline(path)
+}
+
+// itReport returns the report of an It named text in the container "[sig-storage] Snapshots"
+func itReport(text string, state types.SpecState) types.SpecReport {
+	report := types.SpecReport{
+		ContainerHierarchyTexts: []string{"[sig-storage] Snapshots"},
+		LeafNodeType:            types.NodeTypeIt,
+		LeafNodeText:            text,
+		State:                   state,
+	}
+	if state.Is(types.SpecStateFailureStates) {
+		report.Failure.Message = "timed out"
+	}
+	return report
+}
+
+func TestLoadBaseline(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name:   "valid",
+			source: "knownIssues:\n- spec: 'Snapshots should restore'\n  platforms: [kind]\n  reason: tracked upstream\n",
+		},
+		{
+			name:   "unknown field",
+			source: "knownIssues:\n- spec: 'Snapshots should restore'\n  platform: kind\n",
+			err:    `unknown field "platform"`,
+		},
+		{
+			name:   "no spec",
+			source: "knownIssues:\n- reason: tracked upstream\n",
+			err:    "known issue 1 has no spec",
+		},
+		{
+			name:   "bad regex",
+			source: "knownIssues:\n- spec: 'Snapshots should restore'\n- spec: 'Snapshots (should'\n",
+			err:    "known issue 2: error parsing regexp",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := writeBaseline(t, test.source)
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("LoadBaseline() = %v, want no error", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("LoadBaseline() = %v, want an error containing %q", err, test.err)
+			}
+		})
+	}
+
+	if _, err := LoadBaseline(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadBaseline() accepted a missing file")
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	baseline, err := writeBaseline(t, `knownIssues:
+- spec: 'should restore a snapshot$'
+  reason: tracked upstream
+- spec: 'should resize a volume$'
+  platforms: [kind]
+  reason: kind has no resizer
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		spec      types.SpecReport
+		platform  string
+		state     types.SpecState
+		succeeded bool
+		known     bool
+		passing   bool
+	}{
+		{
+			name:      "quarantined failure",
+			spec:      itReport("should restore a snapshot", types.SpecStateFailed),
+			platform:  "eks",
+			state:     types.SpecStateSkipped,
+			succeeded: true,
+			known:     true,
+		},
+		{
+			name:      "quarantined timeout on its platform",
+			spec:      itReport("should resize a volume", types.SpecStateTimedout),
+			platform:  "kind",
+			state:     types.SpecStateSkipped,
+			succeeded: true,
+			known:     true,
+		},
+		{
+			name:     "platform-scoped entry on another platform",
+			spec:     itReport("should resize a volume", types.SpecStateFailed),
+			platform: "eks",
+			state:    types.SpecStateFailed,
+		},
+		{
+			name:      "unexpectedly passing",
+			spec:      itReport("should restore a snapshot", types.SpecStatePassed),
+			platform:  "eks",
+			state:     types.SpecStatePassed,
+			succeeded: true,
+			passing:   true,
+		},
+		{
+			name:     "unquarantined failure",
+			spec:     itReport("should delete a snapshot", types.SpecStateFailed),
+			platform: "eks",
+			state:    types.SpecStateFailed,
+		},
+		{
+			name: "failed setup node",
+			spec: types.SpecReport{
+				LeafNodeType: types.NodeTypeBeforeSuite,
+				LeafNodeText: "should restore a snapshot",
+				State:        types.SpecStateFailed,
+			},
+			platform: "eks",
+			state:    types.SpecStateFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := ginkgo.Report{SpecReports: types.SpecReports{test.spec}}
+			filtered, baselineReport := applyBaseline(report, baseline, test.platform)
+			if state := filtered.SpecReports[0].State; state != test.state {
+				t.Errorf("spec state = %v, want %v", state, test.state)
+			}
+			if filtered.SuiteSucceeded != test.succeeded {
+				t.Errorf("SuiteSucceeded = %t, want %t", filtered.SuiteSucceeded, test.succeeded)
+			}
+			if known := len(baselineReport.KnownIssues) == 1; known != test.known {
+				t.Errorf("known issues = %v, want one: %t", baselineReport.KnownIssues, test.known)
+			}
+			if passing := len(baselineReport.UnexpectedlyPassing) == 1; passing != test.passing {
+				t.Errorf("unexpectedly passing = %v, want one: %t", baselineReport.UnexpectedlyPassing, test.passing)
+			}
+			if report.SpecReports[0].State != test.spec.State {
+				t.Error("applyBaseline() changed the report it was given")
+			}
+		})
+	}
+}
+
+func TestApplyBaselineRecordsKnownIssue(t *testing.T) {
+	baseline, err := writeBaseline(t, "knownIssues:\n- spec: 'should restore a snapshot$'\n  reason: tracked upstream\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := ginkgo.Report{SpecReports: types.SpecReports{itReport("should restore a snapshot", types.SpecStateFailed)}}
+	filtered, baselineReport := applyBaseline(report, baseline, "")
+
+	want := BaselineSpec{Spec: "[sig-storage] Snapshots should restore a snapshot", Reason: "tracked upstream", Failure: "timed out"}
+	if len(baselineReport.KnownIssues) != 1 || baselineReport.KnownIssues[0] != want {
+		t.Errorf("known issues = %v, want %v", baselineReport.KnownIssues, want)
+	}
+	if message := filtered.SpecReports[0].Failure.Message; message != "known issue: tracked upstream: timed out" {
+		t.Errorf("failure message = %q", message)
+	}
+}
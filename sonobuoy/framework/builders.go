@@ -0,0 +1,166 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestrictedUID is the user and group restricted pods run as. Images are expected to work as an arbitrary
+// non-root user, which is what clusters enforcing the restricted Pod Security Standard require.
+const RestrictedUID = int64(65534)
+
+// NewPod returns a single-container pod labeled app=<name> running command in image.
+// The pod is not restarted, so a command that exits leaves it Succeeded or Failed.
+// It complies with the restricted Pod Security Standard; see Unrestricted for suites that need more.
+func NewPod(namespace, name, image string, command ...string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": name},
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers:    []v1.Container{newContainer(image, command)},
+		},
+	}
+	Restrict(&pod.Spec)
+	return pod
+}
+
+// NewDeployment returns a Deployment of replicas pods labeled app=<name> running command in image.
+// Like NewPod, its pods comply with the restricted Pod Security Standard.
+func NewDeployment(namespace, name, image string, replicas int32, command ...string) *appsv1.Deployment {
+	labels := map[string]string{"app": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{newContainer(image, command)},
+				},
+			},
+		},
+	}
+	Restrict(&deployment.Spec.Template.Spec)
+	return deployment
+}
+
+// Restrict makes a pod spec comply with the restricted Pod Security Standard: it runs as RestrictedUID
+// with the RuntimeDefault seccomp profile, and its containers drop all capabilities and cannot escalate
+// privileges. Settings the spec already makes are kept. Suites building pod templates by hand call it
+// before creating them.
+func Restrict(spec *v1.PodSpec) {
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &v1.PodSecurityContext{}
+	}
+	sc := spec.SecurityContext
+	if sc.RunAsNonRoot == nil {
+		nonRoot := true
+		sc.RunAsNonRoot = &nonRoot
+	}
+	if sc.RunAsUser == nil {
+		uid := RestrictedUID
+		sc.RunAsUser = &uid
+	}
+	if sc.RunAsGroup == nil {
+		gid := RestrictedUID
+		sc.RunAsGroup = &gid
+	}
+	// Lets the non-root user write to the volumes it mounts
+	if sc.FSGroup == nil {
+		gid := RestrictedUID
+		sc.FSGroup = &gid
+	}
+	if sc.SeccompProfile == nil {
+		sc.SeccompProfile = &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil {
+				containers[i].SecurityContext = &v1.SecurityContext{}
+			}
+			csc := containers[i].SecurityContext
+			if csc.AllowPrivilegeEscalation == nil {
+				escalation := false
+				csc.AllowPrivilegeEscalation = &escalation
+			}
+			if csc.Capabilities == nil {
+				csc.Capabilities = &v1.Capabilities{Drop: []v1.Capability{"ALL"}}
+			}
+		}
+	}
+}
+
+// Labels a namespace opts into a Pod Security Standard level with, per Pod Security Admission mode
+const (
+	LabelPodSecurityEnforce = "pod-security.kubernetes.io/enforce"
+	LabelPodSecurityWarn    = "pod-security.kubernetes.io/warn"
+	LabelPodSecurityAudit   = "pod-security.kubernetes.io/audit"
+)
+
+// Pod Security Standard levels
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// CreateNamespace creates a namespace named prefix-<unique suffix> enforcing the given Pod Security level,
+// deletes it when the node it was created in ends, and returns its name. Suites whose pods Restrict does
+// not cover, e.g. Unrestricted ones, create their own namespace with the level they need.
+func CreateNamespace(ctx context.Context, prefix, level string) string {
+	ginkgo.GinkgoHelper()
+	name := fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+	_, err := Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{LabelPodSecurityEnforce: level}},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to create namespace %s: %v", name, err))
+	}
+	ginkgo.DeferCleanup(func(ctx ginkgo.SpecContext) {
+		if err := Cleanup(ctx, Clientset.CoreV1().Namespaces(), name); err != nil {
+			ginkgo.Fail(fmt.Sprintf("Failed to delete namespace %s: %v", name, err))
+		}
+	})
+	return name
+}
+
+// Unrestricted removes the security contexts from a pod spec. It is the escape hatch for suites that
+// need root, host access or privileges, which only run in namespaces that allow them.
+func Unrestricted(spec *v1.PodSpec) {
+	spec.SecurityContext = nil
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].SecurityContext = nil
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].SecurityContext = nil
+	}
+}
+
+// newContainer names the container after its image, e.g. "alpine" for alpine:3.19
+func newContainer(image string, command []string) v1.Container {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return v1.Container{
+		Name:    name,
+		Image:   image,
+		Command: command,
+	}
+}
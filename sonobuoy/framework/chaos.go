@@ -0,0 +1,200 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ChaosEngine is the chaos engineering tool installed in the cluster
+type ChaosEngine string
+
+const (
+	ChaosEngineChaosMesh ChaosEngine = "chaos-mesh"
+	ChaosEngineLitmus    ChaosEngine = "litmus"
+)
+
+// ChaosAction is the kind of fault injected into the test workloads
+type ChaosAction string
+
+const (
+	// ChaosActionPodKill deletes one of the target pods
+	ChaosActionPodKill ChaosAction = "pod-kill"
+	// ChaosActionNetworkDelay adds latency to the network traffic of the target pods
+	ChaosActionNetworkDelay ChaosAction = "network-delay"
+)
+
+// ChaosConfig is the chaos section of the RunConfig. Chaos is disabled unless Engine is set.
+type ChaosConfig struct {
+	Engine ChaosEngine
+	// Action defaults to pod-kill
+	Action ChaosAction
+	// Duration is how long the fault is kept up, default 30s
+	Duration time.Duration
+	// RecoverySLO is how long the workloads may take to recover once the fault is removed, default 2m
+	RecoverySLO time.Duration
+	// LitmusServiceAccount runs Litmus experiments, default litmus-admin
+	LitmusServiceAccount string
+}
+
+// Injected network latency for network-delay experiments
+const chaosNetworkLatency = 100 * time.Millisecond
+
+var (
+	chaosMeshGroupVersion = schema.GroupVersion{Group: "chaos-mesh.org", Version: "v1alpha1"}
+	litmusGroupVersion    = schema.GroupVersion{Group: "litmuschaos.io", Version: "v1alpha1"}
+)
+
+// ChaosExperiment is a fault injected through the configured chaos engine
+type ChaosExperiment struct {
+	Engine    ChaosEngine
+	Action    ChaosAction
+	Namespace string
+	Name      string
+
+	resource schema.GroupVersionResource
+}
+
+// RequireChaos skips the spec unless a chaos engine is configured and its API is served by the cluster
+func RequireChaos() *ChaosConfig {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	if config.Chaos.Engine == "" {
+		ginkgo.Skip("Chaos experiments are disabled, set E2E_CHAOS_ENGINE to enable them")
+	}
+	groupVersion := chaosMeshGroupVersion
+	if config.Chaos.Engine == ChaosEngineLitmus {
+		groupVersion = litmusGroupVersion
+	}
+	RequireAPIGroupVersion(groupVersion.String())
+	return &config.Chaos
+}
+
+// StartChaos injects the configured fault into the pods in namespace matching podLabels, and records the
+// experiment in the report. Stop the experiment to remove the fault.
+func StartChaos(ctx context.Context, namespace string, podLabels map[string]string) (*ChaosExperiment, error) {
+	config, err := LoadRunConfig()
+	if err != nil {
+		return nil, err
+	}
+	chaos := config.Chaos
+	if chaos.Engine == "" {
+		return nil, fmt.Errorf("no chaos engine is configured")
+	}
+
+	experiment := &ChaosExperiment{
+		Engine:    chaos.Engine,
+		Action:    chaos.Action,
+		Namespace: namespace,
+		Name:      fmt.Sprintf("e2e-chaos-%d", time.Now().UnixNano()),
+	}
+	var obj *unstructured.Unstructured
+	switch chaos.Engine {
+	case ChaosEngineChaosMesh:
+		obj, experiment.resource = chaosMeshObject(experiment, &chaos, podLabels)
+	case ChaosEngineLitmus:
+		obj, experiment.resource = litmusObject(experiment, &chaos, podLabels)
+	}
+
+	_, err = DynamicClient.Resource(experiment.resource).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s %s experiment: %w", chaos.Engine, chaos.Action, err)
+	}
+	ginkgo.AddReportEntry("Chaos experiment", fmt.Sprintf("%s %s against %s in %s for %s",
+		chaos.Engine, chaos.Action, labels.SelectorFromSet(podLabels).String(), namespace, chaos.Duration))
+	return experiment, nil
+}
+
+// Stop deletes the experiment, which makes the engine remove the fault
+func (e *ChaosExperiment) Stop(ctx context.Context) error {
+	return Cleanup(ctx, Dynamic(DynamicClient.Resource(e.resource).Namespace(e.Namespace)), e.Name)
+}
+
+func chaosMeshObject(experiment *ChaosExperiment, chaos *ChaosConfig, podLabels map[string]string) (*unstructured.Unstructured, schema.GroupVersionResource) {
+	selector := map[string]interface{}{
+		"namespaces":     []interface{}{experiment.Namespace},
+		"labelSelectors": stringMap(podLabels),
+	}
+	spec := map[string]interface{}{"selector": selector}
+	kind, resource := "PodChaos", "podchaos"
+	switch chaos.Action {
+	case ChaosActionPodKill:
+		spec["action"] = "pod-kill"
+		spec["mode"] = "one"
+	case ChaosActionNetworkDelay:
+		kind, resource = "NetworkChaos", "networkchaos"
+		spec["action"] = "delay"
+		spec["mode"] = "all"
+		spec["delay"] = map[string]interface{}{"latency": chaosNetworkLatency.String()}
+		spec["duration"] = chaos.Duration.String()
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": chaosMeshGroupVersion.String(),
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      experiment.Name,
+			"namespace": experiment.Namespace,
+		},
+		"spec": spec,
+	}}
+	return obj, chaosMeshGroupVersion.WithResource(resource)
+}
+
+// litmusObject builds a ChaosEngine running the matching Litmus experiment, which must be installed in the namespace
+func litmusObject(experiment *ChaosExperiment, chaos *ChaosConfig, podLabels map[string]string) (*unstructured.Unstructured, schema.GroupVersionResource) {
+	env := []interface{}{
+		map[string]interface{}{"name": "TOTAL_CHAOS_DURATION", "value": strconv.Itoa(int(chaos.Duration.Seconds()))},
+	}
+	name := "pod-delete"
+	if chaos.Action == ChaosActionNetworkDelay {
+		name = "pod-network-latency"
+		env = append(env, map[string]interface{}{"name": "NETWORK_LATENCY", "value": strconv.FormatInt(chaosNetworkLatency.Milliseconds(), 10)})
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": litmusGroupVersion.String(),
+		"kind":       "ChaosEngine",
+		"metadata": map[string]interface{}{
+			"name":      experiment.Name,
+			"namespace": experiment.Namespace,
+		},
+		"spec": map[string]interface{}{
+			"engineState":         "active",
+			"annotationCheck":     "false",
+			"chaosServiceAccount": chaos.LitmusServiceAccount,
+			"appinfo": map[string]interface{}{
+				"appns":    experiment.Namespace,
+				"applabel": labels.SelectorFromSet(podLabels).String(),
+				"appkind":  "deployment",
+			},
+			"experiments": []interface{}{
+				map[string]interface{}{
+					"name": name,
+					"spec": map[string]interface{}{
+						"components": map[string]interface{}{"env": env},
+					},
+				},
+			},
+		},
+	}}
+	return obj, litmusGroupVersion.WithResource("chaosengines")
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
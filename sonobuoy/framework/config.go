@@ -0,0 +1,128 @@
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+// LoadConfig returns the kubeconfig KUBECONFIG names, or the merge of the files it lists as kubectl merges
+// them, when it is set, otherwise the in-cluster config when running as a Sonobuoy plugin, falling back to
+// ~/.kube/config. Kubeconfigs of managed clusters may authenticate through an exec credential plugin such as
+// aws, gke-gcloud-auth-plugin or kubelogin, which must then be on the PATH; requests go through the proxy
+// HTTPS_PROXY and NO_PROXY select.
+// Clients built from it are rate limited as the E2E_CLIENT_* variables say, trust E2E_CA_BUNDLE, log every
+// request, identify the run in their user agent and run the registered object hooks on every create. They
+// retry requests the API server throttles with 429 Too Many Requests as client-go does, up to 10 times after
+// the delay the response's Retry-After header asks for.
+func LoadConfig() (*rest.Config, error) {
+	runConfig, err := LoadRunConfig()
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadRawConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := addCABundle(config, runConfig.Client.CABundle); err != nil {
+		return nil, fmt.Errorf("invalid E2E_CA_BUNDLE %q: %v", runConfig.Client.CABundle, err)
+	}
+	config.QPS = runConfig.Client.QPS
+	config.Burst = runConfig.Client.Burst
+	config.Timeout = runConfig.Client.Timeout
+	config.UserAgent = UserAgent()
+	config.Wrap(newLoggingTransport)
+	config.Wrap(newObjectHookTransport)
+	return config, nil
+}
+
+func loadRawConfig() (*rest.Config, error) {
+	// An explicit kubeconfig or context wins over the in-cluster config, so a plugin pod can check other clusters
+	kubeconfig := os.Getenv("KUBECONFIG")
+	context := ClusterContext()
+	if kubeconfig == "" && context == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, nil
+		}
+	}
+	// The default rules read every file KUBECONFIG lists; only the fallback is a single explicit file
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig == "" {
+		if home := homedir.HomeDir(); home != "" {
+			kubeconfig = filepath.Join(home, ".kube", "config")
+		} else {
+			kubeconfig = "/root/.kube/config"
+		}
+		rules.ExplicitPath = kubeconfig
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Without its credential plugin, every request would fail with a far less telling error
+	if plugin := config.ExecProvider; plugin != nil {
+		if _, err := exec.LookPath(plugin.Command); err != nil {
+			return nil, fmt.Errorf("kubeconfig %s authenticates with the exec credential plugin %q, which is not installed: %v",
+				kubeconfig, plugin.Command, err)
+		}
+	}
+	return config, nil
+}
+
+// addCABundle makes config trust the certificate authorities in the PEM file at path as well as its own.
+// A config without certificate authorities of its own then trusts the bundle instead of the system's.
+func addCABundle(config *rest.Config, path string) error {
+	if path == "" {
+		return nil
+	}
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(bundle, []byte("-----BEGIN CERTIFICATE-----")) {
+		return fmt.Errorf("no PEM certificates found")
+	}
+
+	caData := config.CAData
+	if len(caData) == 0 && config.CAFile != "" {
+		if caData, err = os.ReadFile(config.CAFile); err != nil {
+			return err
+		}
+	}
+	config.CAData = append(append(bytes.TrimSpace(caData), '\n'), bundle...)
+	config.CAFile = ""
+	return nil
+}
+
+// ClusterContext returns the kubeconfig context named by E2E_CONTEXT, which selects the cluster the suites
+// check in runs against several clusters, or an empty string for the kubeconfig's current context
+func ClusterContext() string {
+	return os.Getenv("E2E_CONTEXT")
+}
+
+// TestNamespace returns the namespace the suites create their resources in. In parallel runs, once
+// SetupSuite has run, each Ginkgo process gets a namespace of its own; see setupProcessNamespace.
+func TestNamespace() string {
+	if processNamespace != "" {
+		return processNamespace
+	}
+	return baseNamespace()
+}
+
+// baseNamespace returns the namespace named by TEST_NAMESPACE, shared by every process of a run
+func baseNamespace() string {
+	if namespace := os.Getenv("TEST_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
@@ -0,0 +1,86 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKubeconfig writes a kubeconfig with a single cluster, user and context, all called name, to dir
+func writeKubeconfig(t *testing.T, dir, name, server string, current bool) string {
+	t.Helper()
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- name: ` + name + `
+  cluster:
+    server: ` + server + `
+users:
+- name: ` + name + `
+  user:
+    token: ` + name + `-token
+contexts:
+- name: ` + name + `
+  context:
+    cluster: ` + name + `
+    user: ` + name + `
+`
+	if current {
+		content += "current-context: " + name + "\n"
+	}
+	path := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadRawConfigMergesKubeconfigList(t *testing.T) {
+	dir := t.TempDir()
+	first := writeKubeconfig(t, dir, "first", "https://first.example.com", true)
+	second := writeKubeconfig(t, dir, "second", "https://second.example.com", false)
+	t.Setenv("KUBECONFIG", strings.Join([]string{first, second}, string(os.PathListSeparator)))
+
+	tests := []struct {
+		context string
+		host    string
+	}{
+		{context: "", host: "https://first.example.com"},
+		{context: "second", host: "https://second.example.com"},
+	}
+	for _, test := range tests {
+		t.Setenv("E2E_CONTEXT", test.context)
+		config, err := loadRawConfig()
+		if err != nil {
+			t.Errorf("context %q: %v", test.context, err)
+			continue
+		}
+		if config.Host != test.host || config.BearerToken == "" {
+			t.Errorf("context %q: got host %s, want %s with its user's token", test.context, config.Host, test.host)
+		}
+	}
+}
+
+func TestLoadRawConfigFallsBackToHome(t *testing.T) {
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, ".kube"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := writeKubeconfig(t, filepath.Join(home, ".kube"), "home", "https://home.example.com", true)
+	if err := os.Rename(path, filepath.Join(home, ".kube", "config")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("KUBECONFIG", "")
+	// A context keeps the in-cluster config out of the way when the test runs in a pod
+	t.Setenv("E2E_CONTEXT", "home")
+
+	config, err := loadRawConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "https://home.example.com" {
+		t.Errorf("got host %s, want the one in ~/.kube/config", config.Host)
+	}
+}
@@ -0,0 +1,129 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+// ObjectClient is the subset of a typed client, e.g. clientset.CoreV1().Pods(namespace), needed for cleanup
+type ObjectClient[T metav1.Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// CollectionClient is the subset of a typed client needed to clean up objects by selector
+type CollectionClient[L runtime.Object] interface {
+	List(ctx context.Context, opts metav1.ListOptions) (L, error)
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+}
+
+// Cleanup deletes the named object according to the run's DeletionPolicy. Under the default
+// Foreground policy it waits until the object is gone; an object recreated under the same name
+// is told apart by its UID. An object that does not exist is not an error.
+func Cleanup[T metav1.Object](ctx context.Context, client ObjectClient[T], name string) error {
+	config, err := LoadRunConfig()
+	if err != nil {
+		return err
+	}
+
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	uid := obj.GetUID()
+
+	err = client.Delete(ctx, name, deleteOptions(config, metav1.NewUIDPreconditions(string(uid))))
+	// A UID precondition conflict means the object was already replaced
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if config.DeletionPolicy != DeletionPolicyForeground {
+		return nil
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, config.DeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return obj.GetUID() != uid, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s was not deleted within %s: %w", name, config.DeletionTimeout, err)
+	}
+	return nil
+}
+
+// CleanupCollection deletes every object matching listOpts according to the run's DeletionPolicy,
+// waiting under the Foreground policy until none remain
+func CleanupCollection[L runtime.Object](ctx context.Context, client CollectionClient[L], listOpts metav1.ListOptions) error {
+	config, err := LoadRunConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteCollection(ctx, deleteOptions(config, nil), listOpts); err != nil {
+		return err
+	}
+	if config.DeletionPolicy != DeletionPolicyForeground {
+		return nil
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, config.DeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		list, err := client.List(ctx, listOpts)
+		if err != nil {
+			return false, err
+		}
+		return meta.LenList(list) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("objects matching %q were not deleted within %s: %w", listOpts.LabelSelector, config.DeletionTimeout, err)
+	}
+	return nil
+}
+
+// Dynamic adapts a dynamic client resource to ObjectClient for use with Cleanup
+func Dynamic(resource dynamic.ResourceInterface) ObjectClient[*unstructured.Unstructured] {
+	return dynamicObjectClient{resource}
+}
+
+type dynamicObjectClient struct {
+	resource dynamic.ResourceInterface
+}
+
+func (c dynamicObjectClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
+	return c.resource.Get(ctx, name, opts)
+}
+
+func (c dynamicObjectClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.resource.Delete(ctx, name, opts)
+}
+
+func deleteOptions(config *RunConfig, preconditions *metav1.Preconditions) metav1.DeleteOptions {
+	propagation := metav1.DeletePropagationBackground
+	if config.DeletionPolicy == DeletionPolicyForeground {
+		propagation = metav1.DeletePropagationForeground
+	}
+	return metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+		Preconditions:     preconditions,
+	}
+}
@@ -0,0 +1,25 @@
+// Package framework contains the infrastructure the e2e suites are built on, for use by other teams
+// building their own Sonobuoy plugins and suites on top of it:
+//
+//   - Framework and SetupSuite build the clients a suite needs from the plugin's environment
+//   - Impersonate and ImpersonatingFramework build clients acting as a restricted Identity
+//   - RunConfig holds the run-wide settings read from E2E_* environment variables
+//   - NewPod and NewDeployment build restricted-compliant fixtures; Restrict and Unrestricted adjust others
+//     and CreateNamespace makes a namespace enforcing the Pod Security level they need
+//   - Cleanup, CreateOrUpdate and the WaitFor helpers create, wait on and remove resources
+//   - ExecInPod runs commands in containers, classifying why they failed
+//   - DialChannelStream and PortForward stream pod subresources over WebSocket
+//   - GenerateServingCertificate issues throwaway TLS certificates for servers the specs deploy
+//   - RequireChaos and StartChaos inject faults through Chaos Mesh or Litmus for resilience specs
+//   - The Require helpers skip specs the cluster cannot run and WriteRequirementsManifest records them
+//   - EnforceMaintenanceWindows keeps disruptive and privileged specs within maintenance windows
+//   - DescribeScenarios and RunScenario run end-to-end workflows written in YAML
+//   - RecordSuiteStarted and RecordSuiteFinished report suite progress as Kubernetes Events
+//   - RegisterObjectHook and RegisterObjectAssertion mutate and check every object the suites create
+//   - RegisterProgressUI shows a live view of the run to humans running the suites locally
+//
+// The package follows semantic versioning. The module is tagged as sonobuoy/vX.Y.Z and Version
+// reports the release compiled in. Until 1.0.0 a minor release may change exported identifiers
+// incompatibly, keeping the replaced ones as deprecated wrappers where the signatures allow it; patch
+// releases never do. CHANGELOG.md lists the exported changes of every release.
+package framework
@@ -0,0 +1,61 @@
+package framework
+
+import (
+	"github.com/onsi/ginkgo/v2"
+)
+
+// RegisterEntryPoint registers the suite, spec and report nodes every entry point runs the specs with: the
+// aggregated run in the tests package, each standalone suite and downstream plugins' own suites. The run
+// configuration then means the same whichever of them runs the specs. Call it at package level next to
+// RunSpecs.
+func RegisterEntryPoint() bool {
+	// Setup Kubernetes clients before the tests
+	ginkgo.BeforeSuite(SetupSuite)
+
+	// Log each spec to its own file in the results, tagged with the spec and run ID
+	ginkgo.BeforeEach(StartSpecLog)
+
+	// Attach the audit events of failed specs' requests when an audit source is configured
+	ginkgo.BeforeEach(CollectAuditEvents)
+
+	// Flag specs that took longer than their E2E_SPEC_BUDGETS budget as slow
+	ginkgo.BeforeEach(EnforceSpecBudgets)
+
+	// Only run disruptive and privileged specs within the configured maintenance windows
+	ginkgo.BeforeEach(EnforceMaintenanceWindows)
+
+	// Only run node-level specs, on the plugin's node, when running as a daemonset plugin
+	ginkgo.BeforeEach(EnforceNodeScope)
+
+	// Fail specs whose objects violate a registered cluster policy assertion
+	ginkgo.AfterEach(VerifyObjectAssertions)
+
+	// Record suite lifecycle events on the test namespace
+	ginkgo.ReportBeforeSuite(RecordSuiteStarted)
+	ginkgo.ReportAfterSuite("Record suite lifecycle event", RecordSuiteFinished)
+
+	// Persist what the specs required of the cluster next to what it provides
+	ginkgo.ReportAfterSuite("Write requirements manifest", WriteRequirementsManifest)
+
+	// Classify specs that passed on a retry as flaky rather than letting them pass silently
+	ginkgo.ReportAfterSuite("Write flake report", WriteFlakeReport)
+
+	// List the slow specs with what they waited for, to track the cluster's performance between runs
+	ginkgo.ReportAfterSuite("Write slow spec report", WriteSlowSpecReport)
+
+	// Collect the latencies the benchmarks measured
+	ginkgo.ReportAfterSuite("Write perf report", WritePerfReport)
+
+	// Track failure rates and leaked objects across the iterations of an E2E_SOAK run
+	ginkgo.ReportAfterSuite("Write soak report", WriteSoakReport)
+
+	// Report failures of quarantined specs as known issues when a baseline is configured
+	ginkgo.ReportAfterSuite("Apply known-issue baseline", ApplyBaseline)
+
+	// Feed the built-in reporters E2E_REPORTERS selects and those registered by downstream entry points
+	RegisterReporters()
+
+	// Show a live progress view when E2E_TUI is set and the run is interactive
+	RegisterProgressUI()
+	return true
+}
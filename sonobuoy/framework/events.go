@@ -0,0 +1,96 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Event reasons recorded on the test namespace so operators watching events can see verification runs
+const (
+	ReasonSuiteStarted   = "E2ESuiteStarted"
+	ReasonSuiteCompleted = "E2ESuiteCompleted"
+	ReasonSuiteFailed    = "E2ESuiteFailed"
+)
+
+// EventSource is the component name events are reported under
+const EventSource = "sonobuoy-e2e"
+
+// reportTimeout bounds the API calls of report nodes, which Ginkgo runs without a SpecContext
+const reportTimeout = 30 * time.Second
+
+// RecordSuiteStarted emits an E2ESuiteStarted event, meant to be registered with ReportBeforeSuite
+func RecordSuiteStarted(report ginkgo.Report) {
+	message := fmt.Sprintf("Suite %q started with %d specs", report.SuiteDescription, report.PreRunStats.SpecsThatWillRun)
+	recordSuiteEvent(v1.EventTypeNormal, ReasonSuiteStarted, message)
+}
+
+// RecordSuiteFinished emits an E2ESuiteCompleted or E2ESuiteFailed event, meant to be registered with ReportAfterSuite
+func RecordSuiteFinished(report ginkgo.Report) {
+	specs := report.SpecReports.WithLeafNodeType(types.NodeTypeIt)
+	message := fmt.Sprintf("Suite %q finished in %s: %d passed (%d flaky), %d failed, %d skipped",
+		report.SuiteDescription, report.RunTime.Round(time.Second),
+		specs.CountWithState(types.SpecStatePassed), len(flakySpecs(report)),
+		specs.CountWithState(types.SpecStateFailureStates),
+		specs.CountWithState(types.SpecStateSkipped|types.SpecStatePending))
+
+	if report.SuiteSucceeded {
+		recordSuiteEvent(v1.EventTypeNormal, ReasonSuiteCompleted, message)
+	} else {
+		recordSuiteEvent(v1.EventTypeWarning, ReasonSuiteFailed, message)
+	}
+}
+
+// recordSuiteEvent creates an Event involving the test namespace. Failures are logged rather than
+// failing the suite, since the plugin may lack permission to create events.
+func recordSuiteEvent(eventType, reason, message string) {
+	config, err := LoadConfig()
+	if err != nil {
+		Logger().Warn("Skipping suite event", "reason", reason, "error", err)
+		return
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		Logger().Warn("Skipping suite event", "reason", reason, "error", err)
+		return
+	}
+
+	namespace := TestNamespace()
+	instance, _ := os.Hostname()
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "e2e-suite-",
+			Namespace:    namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       namespace,
+		},
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Source:              v1.EventSource{Component: EventSource},
+		ReportingController: EventSource,
+		ReportingInstance:   instance,
+		Action:              "Verify",
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	_, err = clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		Logger().Warn("Failed to record suite event", "reason", reason, "error", err)
+	}
+}
@@ -0,0 +1,133 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Errors ExecInPod classifies failures as, to be checked with errors.Is
+var (
+	// ErrExecTimeout means the command did not finish within the exec timeout or before the context was done
+	ErrExecTimeout = errors.New("exec timed out")
+	// ErrExecUnavailable means the command never started, e.g. because the pod or container does not exist,
+	// is not running, exec is forbidden or the executable was not found
+	ErrExecUnavailable = errors.New("exec unavailable")
+	// ErrExecStreamBroken means the connection was lost before the command reported how it ended
+	ErrExecStreamBroken = errors.New("exec stream broken")
+)
+
+// ExecExitError reports a command that ran and exited with a non-zero code, to be checked with errors.As
+type ExecExitError struct {
+	Command  []string
+	ExitCode int
+	Stderr   string
+}
+
+func (e *ExecExitError) Error() string {
+	message := fmt.Sprintf("command terminated with exit code %d", e.ExitCode)
+	if e.Command != nil {
+		message = fmt.Sprintf("command %q terminated with exit code %d", e.Command, e.ExitCode)
+	}
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		message += ": " + stderr
+	}
+	return message
+}
+
+// ExecResult holds what a command run with ExecInPod wrote
+type ExecResult struct {
+	Stdout string
+	Stderr string
+}
+
+// ExecInPod runs command in a container of a running pod, without stdin or a TTY, and waits up to
+// ExecTimeout, or until ctx is done, for it to finish. An empty container selects the pod's default container.
+// The result is never nil and holds whatever the command wrote even when it fails.
+func ExecInPod(ctx context.Context, namespace, pod, container string, command ...string) (*ExecResult, error) {
+	result := &ExecResult{}
+	config, err := LoadRunConfig()
+	if err != nil {
+		return result, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.ExecTimeout)
+	defer cancel()
+
+	request := Clientset.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("pods").
+		Name(pod).
+		SubResource("exec").
+		Param("stdout", "true").
+		Param("stderr", "true")
+	if container != "" {
+		request = request.Param("container", container)
+	}
+	for _, arg := range command {
+		request = request.Param("command", arg)
+	}
+
+	fail := func(class, err error) (*ExecResult, error) {
+		if ctx.Err() != nil {
+			class, err = ErrExecTimeout, ctx.Err()
+		}
+		return result, fmt.Errorf("exec %q in pod %s/%s: %w: %w", command, namespace, pod, class, err)
+	}
+
+	stream, err := DialChannelStream(ctx, request)
+	if err != nil {
+		var status apierrors.APIStatus
+		if errors.As(err, &status) {
+			return fail(ErrExecUnavailable, err)
+		}
+		return fail(ErrExecStreamBroken, err)
+	}
+	defer stream.Close()
+
+	var stdout, stderr, status bytes.Buffer
+	for {
+		channel, data, err := stream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			result.Stdout, result.Stderr = stdout.String(), stderr.String()
+			return fail(ErrExecStreamBroken, err)
+		}
+		switch channel {
+		case ChannelStdout:
+			stdout.Write(data)
+		case ChannelStderr:
+			stderr.Write(data)
+		case ChannelError:
+			status.Write(data)
+		}
+	}
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+
+	if status.Len() == 0 {
+		return result, nil
+	}
+	err = ParseStreamStatus(status.Bytes())
+	var exit *ExecExitError
+	if errors.As(err, &exit) {
+		exit.Command, exit.Stderr = command, result.Stderr
+		return result, fmt.Errorf("exec in pod %s/%s: %w", namespace, pod, exit)
+	}
+	if err != nil {
+		return fail(ErrExecUnavailable, err)
+	}
+	return result, nil
+}
+
+// ExecInPodWithContext is ExecInPod.
+//
+// Deprecated: ExecInPod takes a context since 0.2.0; call it instead.
+func ExecInPodWithContext(ctx context.Context, namespace, pod, container string, command ...string) (*ExecResult, error) {
+	return ExecInPod(ctx, namespace, pod, container, command...)
+}
@@ -0,0 +1,93 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// FixtureClient is the subset of a typed client, e.g. clientset.CoreV1().Services(namespace), needed to set up fixtures
+type FixtureClient[T metav1.Object] interface {
+	Create(ctx context.Context, obj T, opts metav1.CreateOptions) (T, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Update(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+}
+
+// CreateIfNotExists creates obj, or returns the existing object of the same name when it was left behind
+// by an earlier run, e.g. one that crashed before cleaning up. Objects the suites did not create are
+// never adopted and result in an error.
+func CreateIfNotExists[T metav1.Object](ctx context.Context, client FixtureClient[T], obj T) (T, error) {
+	result, _, err := createOrAdopt(ctx, client, obj)
+	return result, err
+}
+
+// CreateOrUpdate creates obj, or replaces a leftover object of the same name with it, so the fixture
+// matches obj either way. The same ownership rules as CreateIfNotExists apply. The resourceVersion and
+// annotations of obj are modified when it is used to update an existing object.
+func CreateOrUpdate[T metav1.Object](ctx context.Context, client FixtureClient[T], obj T) (T, error) {
+	result, created, err := createOrAdopt(ctx, client, obj)
+	if err != nil || created {
+		return result, err
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		// Updates bypass the object hooks, so stamp this run's metadata over the previous run's
+		setRunMetadata(obj)
+		result, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	return result, err
+}
+
+// createOrAdopt creates obj or returns the owned object already in its place, reporting which happened.
+// A leftover that is still terminating is waited for and obj created once it is gone.
+func createOrAdopt[T metav1.Object](ctx context.Context, client FixtureClient[T], obj T) (T, bool, error) {
+	result, err := client.Create(ctx, obj, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return result, err == nil, err
+	}
+
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return existing, false, err
+	}
+	if _, owned := existing.GetAnnotations()[AnnotationRunID]; !owned {
+		return existing, false, fmt.Errorf("%s already exists and was not created by the e2e suites", objectName(existing))
+	}
+	if existing.GetDeletionTimestamp() == nil {
+		return existing, false, nil
+	}
+
+	config, err := LoadRunConfig()
+	if err != nil {
+		return existing, false, err
+	}
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, config.DeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		result, err = client.Create(ctx, obj, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		return result, false, fmt.Errorf("leftover %s was not deleted within %s: %v", objectName(existing), config.DeletionTimeout, err)
+	}
+	return result, true, nil
+}
+
+func objectName(obj metav1.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,98 @@
+package framework
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fixtureConfigMap returns a ConfigMap named "fixture" in namespace "test", annotated as created by a run
+// when owned is set
+func fixtureConfigMap(value string, owned bool) *v1.ConfigMap {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "fixture", Namespace: "test"},
+		Data:       map[string]string{"value": value},
+	}
+	if owned {
+		configMap.Annotations = map[string]string{AnnotationRunID: "earlier-run"}
+	}
+	return configMap
+}
+
+func TestCreateIfNotExists(t *testing.T) {
+	ctx := context.Background()
+
+	clientset := fake.NewSimpleClientset()
+	result, err := CreateIfNotExists(ctx, clientset.CoreV1().ConfigMaps("test"), fixtureConfigMap("new", false))
+	if err != nil || result.Data["value"] != "new" {
+		t.Errorf("CreateIfNotExists() without a leftover = %v, %v; want the new object", result.Data, err)
+	}
+
+	clientset = fake.NewSimpleClientset(fixtureConfigMap("leftover", true))
+	result, err = CreateIfNotExists(ctx, clientset.CoreV1().ConfigMaps("test"), fixtureConfigMap("new", false))
+	if err != nil || result.Data["value"] != "leftover" {
+		t.Errorf("CreateIfNotExists() with an owned leftover = %v, %v; want the leftover", result.Data, err)
+	}
+
+	clientset = fake.NewSimpleClientset(fixtureConfigMap("foreign", false))
+	_, err = CreateIfNotExists(ctx, clientset.CoreV1().ConfigMaps("test"), fixtureConfigMap("new", false))
+	if err == nil || !strings.Contains(err.Error(), "test/fixture already exists and was not created by the e2e suites") {
+		t.Errorf("CreateIfNotExists() with a foreign object = %v, want an ownership error", err)
+	}
+}
+
+func TestCreateOrUpdate(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(fixtureConfigMap("leftover", true))
+	configMaps := clientset.CoreV1().ConfigMaps("test")
+
+	result, err := CreateOrUpdate(ctx, configMaps, fixtureConfigMap("new", false))
+	if err != nil {
+		t.Fatalf("CreateOrUpdate() = %v", err)
+	}
+	stored, err := configMaps.Get(ctx, "fixture", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Data["value"] != "new" || stored.Data["value"] != "new" {
+		t.Errorf("CreateOrUpdate() returned %v and stored %v, want the new data", result.Data, stored.Data)
+	}
+	if stored.Annotations[AnnotationRunID] != RunID() {
+		t.Errorf("updated object has run ID %q, want this run's %q", stored.Annotations[AnnotationRunID], RunID())
+	}
+
+	clientset = fake.NewSimpleClientset(fixtureConfigMap("foreign", false))
+	if _, err := CreateOrUpdate(ctx, clientset.CoreV1().ConfigMaps("test"), fixtureConfigMap("new", false)); err == nil {
+		t.Error("CreateOrUpdate() replaced an object the suites did not create")
+	}
+}
+
+func TestCreateOrAdoptWaitsForTerminatingLeftover(t *testing.T) {
+	ctx := context.Background()
+	leftover := fixtureConfigMap("leftover", true)
+	leftover.DeletionTimestamp = &metav1.Time{}
+	leftover.Finalizers = []string{"test/finalizer"}
+	clientset := fake.NewSimpleClientset(leftover)
+
+	// The leftover goes away once the first create has been refused
+	creates := 0
+	clientset.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if creates++; creates == 2 {
+			if err := clientset.Tracker().Delete(action.GetResource(), "test", "fixture"); err != nil {
+				t.Error(err)
+			}
+		}
+		return false, nil, nil
+	})
+
+	result, created, err := createOrAdopt(ctx, clientset.CoreV1().ConfigMaps("test"), fixtureConfigMap("new", false))
+	if err != nil || !created || result.Data["value"] != "new" {
+		t.Errorf("createOrAdopt() = %v, %t, %v; want the new object created", result.Data, created, err)
+	}
+}
@@ -0,0 +1,73 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// FlakesFile is written to RESULTS_DIR by WriteFlakeReport
+const FlakesFile = "flakes.json"
+
+// FlakySpec is a spec that failed and then passed when Ginkgo retried it
+type FlakySpec struct {
+	Name     string `json:"name"`
+	Attempts int    `json:"attempts"`
+}
+
+// FlakeReport classifies the specs of a run retried with --flake-attempts
+type FlakeReport struct {
+	RunID  string      `json:"runID"`
+	Flaky  []FlakySpec `json:"flaky"`
+	Failed []string    `json:"failed"`
+}
+
+// flakySpecs returns the specs that passed only after a retry, sorted by name
+func flakySpecs(report ginkgo.Report) []FlakySpec {
+	var flaky []FlakySpec
+	for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+		if spec.State == types.SpecStatePassed && spec.NumAttempts > 1 {
+			flaky = append(flaky, FlakySpec{Name: spec.FullText(), Attempts: spec.NumAttempts})
+		}
+	}
+	sort.Slice(flaky, func(i, j int) bool { return flaky[i].Name < flaky[j].Name })
+	return flaky
+}
+
+// WriteFlakeReport writes the specs that passed on a retry, as flaky, apart from those that failed every
+// attempt, meant to be registered with ReportAfterSuite. Ginkgo counts a spec passing on a retry as passed,
+// so it does not fail the run, and this report keeps it from going unnoticed. Nothing is written when
+// RESULTS_DIR is unset, as for local runs.
+func WriteFlakeReport(report ginkgo.Report) {
+	flake := FlakeReport{RunID: RunID(), Flaky: flakySpecs(report), Failed: []string{}}
+	for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+		if spec.State.Is(types.SpecStateFailureStates) {
+			flake.Failed = append(flake.Failed, spec.FullText())
+		}
+	}
+	sort.Strings(flake.Failed)
+	if len(flake.Flaky) > 0 {
+		var names []string
+		for _, spec := range flake.Flaky {
+			names = append(names, fmt.Sprintf("%s (%d attempts)", spec.Name, spec.Attempts))
+		}
+		Logger().Warn("Specs passed only on a retry", "specs", names)
+	}
+
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(flake, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(resultsDir, FlakesFile), data, 0644)
+	}
+	if err != nil {
+		Logger().Error("Failed to write flake report", "file", FlakesFile, "error", err)
+	}
+}
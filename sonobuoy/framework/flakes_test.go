@@ -0,0 +1,52 @@
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func TestFlakySpecs(t *testing.T) {
+	spec := func(text string, nodeType types.NodeType, state types.SpecState, attempts int) types.SpecReport {
+		return types.SpecReport{
+			ContainerHierarchyTexts: []string{"[sig-apps] Deployments"},
+			LeafNodeType:            nodeType,
+			LeafNodeText:            text,
+			State:                   state,
+			NumAttempts:             attempts,
+		}
+	}
+	tests := []struct {
+		name string
+		spec types.SpecReport
+		want []FlakySpec
+	}{
+		{
+			name: "passed on a retry",
+			spec: spec("should roll out", types.NodeTypeIt, types.SpecStatePassed, 3),
+			want: []FlakySpec{{Name: "[sig-apps] Deployments should roll out", Attempts: 3}},
+		},
+		{name: "passed first time", spec: spec("should roll out", types.NodeTypeIt, types.SpecStatePassed, 1)},
+		{name: "failed every attempt", spec: spec("should roll out", types.NodeTypeIt, types.SpecStateFailed, 3)},
+		{name: "not an It", spec: spec("", types.NodeTypeBeforeSuite, types.SpecStatePassed, 2)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := flakySpecs(ginkgo.Report{SpecReports: types.SpecReports{test.spec}})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("flakySpecs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	report := ginkgo.Report{SpecReports: types.SpecReports{
+		spec("should scale", types.NodeTypeIt, types.SpecStatePassed, 2),
+		spec("should roll back", types.NodeTypeIt, types.SpecStatePassed, 2),
+	}}
+	got := flakySpecs(report)
+	if len(got) != 2 || got[0].Name != "[sig-apps] Deployments should roll back" {
+		t.Errorf("flakySpecs() = %v, want them sorted by name", got)
+	}
+}
@@ -0,0 +1,65 @@
+package framework
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Framework bundles the clients a suite talks to the cluster with
+type Framework struct {
+	RestConfig    *rest.Config
+	Clientset     *kubernetes.Clientset
+	DynamicClient dynamic.Interface
+}
+
+// NewFramework builds the clients for config. Use LoadConfig for a config that runs the object hooks.
+func NewFramework(config *rest.Config) (*Framework, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Framework{
+		RestConfig:    config,
+		Clientset:     clientset,
+		DynamicClient: dynamicClient,
+	}, nil
+}
+
+// Namespace returns the namespace specs create their resources in
+func (f *Framework) Namespace() string {
+	return TestNamespace()
+}
+
+// Clients shared by every suite, built once per process by SetupSuite
+var (
+	RestConfig    *rest.Config
+	Clientset     *kubernetes.Clientset
+	DynamicClient dynamic.Interface
+)
+
+// SetupSuite loads the kubeconfig, builds the shared clients, detects the API server's version and
+// resources, runs the preflight checks, in parallel runs creates the process's own test namespace and,
+// when configured, starts receiving audit events.
+// RegisterEntryPoint registers it with BeforeSuite for every entry point.
+func SetupSuite(ctx ginkgo.SpecContext) {
+	config, err := LoadConfig()
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to load kubeconfig")
+
+	f, err := NewFramework(config)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to create Kubernetes clients")
+	RestConfig, Clientset, DynamicClient = f.RestConfig, f.Clientset, f.DynamicClient
+
+	serverInfo, err = DetectServer(Clientset)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to detect API server")
+
+	checkPreflight(ctx)
+	setupProcessNamespace(ctx)
+	startAuditWebhook()
+}
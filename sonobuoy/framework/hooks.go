@@ -0,0 +1,127 @@
+package framework
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ObjectHook is called with every object the suites create or server-side apply, right before the request
+// is sent
+type ObjectHook func(obj *unstructured.Unstructured)
+
+var (
+	objectHooksMu sync.RWMutex
+	objectHooks   = []ObjectHook{stampRunMetadata, pinToPluginNode}
+)
+
+// RegisterObjectHook adds a hook applied to every object created through clients built from LoadConfig
+func RegisterObjectHook(hook ObjectHook) {
+	objectHooksMu.Lock()
+	defer objectHooksMu.Unlock()
+	objectHooks = append(objectHooks, hook)
+}
+
+// objectHookTransport runs the registered hooks on the body of create and server-side apply requests, shrinks
+// workloads that could never fit the namespace's ResourceQuotas and runs the registered assertions on the objects they return
+type objectHookTransport struct {
+	next http.RoundTripper
+}
+
+func newObjectHookTransport(next http.RoundTripper) http.RoundTripper {
+	return &objectHookTransport{next: next}
+}
+
+func (t *objectHookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !writesObject(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(body); err == nil {
+		objectHooksMu.RLock()
+		for _, hook := range objectHooks {
+			hook(obj)
+		}
+		objectHooksMu.RUnlock()
+		fitToQuota(req.Context(), pathNamespace(req.URL.Path), obj)
+		if mutated, err := obj.MarshalJSON(); err == nil {
+			body = mutated
+		}
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := assertCreated(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writesObject reports whether req creates an object from a JSON body or server-side applies one. The
+// dynamic client sends applied objects as JSON, which is valid apply YAML.
+func writesObject(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodPost:
+		return mediaType == "application/json" && isCreatePath(req.URL.Path)
+	case http.MethodPatch:
+		return mediaType == string(types.ApplyPatchType) && isObjectPath(req.URL.Path)
+	}
+	return false
+}
+
+// isCreatePath reports whether a POST to path creates an object in a collection,
+// as opposed to acting on a subresource such as pods/eviction or serviceaccounts/token
+func isCreatePath(path string) bool {
+	segments, ok := resourceSegments(path)
+	if ok && len(segments) == 3 && segments[0] == "namespaces" {
+		return true
+	}
+	return ok && len(segments) == 1
+}
+
+// isObjectPath reports whether path names an object, as opposed to a collection or a subresource
+func isObjectPath(path string) bool {
+	segments, ok := resourceSegments(path)
+	if ok && len(segments) == 4 && segments[0] == "namespaces" {
+		return true
+	}
+	return ok && len(segments) == 2
+}
+
+// resourceSegments returns the segments of an API path following the group and version
+func resourceSegments(path string) ([]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		return segments[2:], true
+	case len(segments) >= 3 && segments[0] == "apis":
+		return segments[3:], true
+	}
+	return nil, false
+}
@@ -0,0 +1,39 @@
+package framework
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWritesObject(t *testing.T) {
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		want        bool
+	}{
+		{http.MethodPost, "/api/v1/namespaces/e2e/pods", "application/json", true},
+		{http.MethodPost, "/apis/apps/v1/namespaces/e2e/deployments", "application/json", true},
+		{http.MethodPost, "/api/v1/namespaces", "application/json", true},
+		{http.MethodPost, "/api/v1/namespaces/e2e/pods", "application/vnd.kubernetes.protobuf", false},
+		{http.MethodPost, "/api/v1/namespaces/e2e/pods/app/eviction", "application/json", false},
+		{http.MethodPost, "/api/v1/namespaces/e2e/serviceaccounts/default/token", "application/json", false},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/e2e/deployments/app", "application/apply-patch+yaml", true},
+		{http.MethodPatch, "/api/v1/namespaces/e2e", "application/apply-patch+yaml", true},
+		{http.MethodPatch, "/apis/rbac.authorization.k8s.io/v1/clusterroles/reader", "application/apply-patch+yaml", true},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/e2e/deployments/app/status", "application/apply-patch+yaml", false},
+		{http.MethodPatch, "/apis/apps/v1/namespaces/e2e/deployments/app", "application/merge-patch+json", false},
+		{http.MethodPut, "/api/v1/namespaces/e2e/pods/app", "application/json", false},
+		{http.MethodPost, "/healthz", "application/json", false},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, "https://cluster.example.com"+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", test.contentType)
+		if got := writesObject(req); got != test.want {
+			t.Errorf("writesObject(%s %s, %s) = %v, want %v", test.method, test.path, test.contentType, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package framework
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// HTMLReportFile is written to RESULTS_DIR by the html reporter
+const HTMLReportFile = "report.html"
+
+// htmlArtifacts are the files of a run the HTML report links to when they exist
+var htmlArtifacts = []string{"out", "junit.xml", JUnitReportFile, JSONReportFile, FlakesFile, BaselineReportFile,
+	PreflightFile, RequirementsManifestFile, SonobuoyResultsFile, OpenMetricsFile, SlowSpecsFile,
+	PerfReportFile}
+
+// htmlSuite is a top-level container of the run with its specs, as shown in the HTML report
+type htmlSuite struct {
+	Name                    string
+	Passed, Failed, Skipped int
+	Duration                time.Duration
+	Specs                   []htmlSpec
+}
+
+// htmlSpec is a spec as shown in the HTML report
+type htmlSpec struct {
+	Name     string
+	Status   string
+	Duration time.Duration
+	Failure  string
+	Location string
+	Log      string
+	// Slow is set for specs that exceeded their budget
+	Slow string
+}
+
+// htmlSummary is what the HTML report template renders
+type htmlSummary struct {
+	Title                   string
+	RunID                   string
+	Succeeded               bool
+	Start                   time.Time
+	Duration                time.Duration
+	Passed, Failed, Skipped int
+	Suites                  []*htmlSuite
+	Artifacts               []string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #59636e; margin-bottom: 1.5em; }
+.badge { display: inline-block; padding: 0.2em 0.7em; border-radius: 1em; color: #fff; font-weight: 600; }
+.passed { background: #1a7f37; } .failed { background: #cf222e; } .skipped { background: #9a6700; } .slow { background: #8250df; }
+.counts span { margin-right: 1.5em; font-size: 1.2em; }
+details { border: 1px solid #d1d9e0; border-radius: 6px; margin-bottom: 0.7em; }
+summary { padding: 0.6em 1em; cursor: pointer; font-weight: 600; }
+table { border-collapse: collapse; width: 100%; }
+td { border-top: 1px solid #d1d9e0; padding: 0.4em 1em; vertical-align: top; }
+td.status { width: 6em; } td.duration { width: 6em; text-align: right; color: #59636e; }
+pre { white-space: pre-wrap; background: #fff8f8; border-left: 3px solid #cf222e; padding: 0.5em; margin: 0.4em 0 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} <span class="badge {{if .Succeeded}}passed">Passed{{else}}failed">Failed{{end}}</span></h1>
+<div class="meta">Run {{.RunID}}, started {{.Start.UTC.Format "2006-01-02 15:04:05 UTC"}}, took {{.Duration}}</div>
+<div class="counts"><span>{{.Passed}} passed</span><span>{{.Failed}} failed</span><span>{{.Skipped}} skipped</span></div>
+<h2>Suites</h2>
+{{range .Suites}}<details{{if .Failed}} open{{end}}>
+<summary><span class="badge {{if .Failed}}failed{{else}}passed{{end}}">{{.Passed}}/{{len .Specs}}</span> {{.Name}} ({{.Duration}})</summary>
+<table>
+{{range .Specs}}<tr>
+<td class="status"><span class="badge {{.Status}}">{{.Status}}</span></td>
+<td>{{.Name}}{{if .Slow}} <span class="badge slow" title="{{.Slow}}">slow</span>{{end}}{{if .Log}} (<a href="{{.Log}}">log</a>){{end}}{{if .Failure}}<pre>{{.Failure}}
+{{.Location}}</pre>{{end}}</td>
+<td class="duration">{{.Duration}}</td>
+</tr>
+{{end}}</table>
+</details>
+{{end}}{{if .Artifacts}}<h2>Artifacts</h2>
+<ul>
+{{range .Artifacts}}<li><a href="{{.}}">{{.}}</a></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// GenerateHTMLReport writes report to path as a single self-contained page with the status and duration of
+// every suite and spec, failure messages and links to the spec logs and other artifacts next to it, to be
+// shared with people who do not read JUnit
+func GenerateHTMLReport(report ginkgo.Report, path string) error {
+	dir := filepath.Dir(path)
+	summary := htmlSummary{
+		Title:     report.SuiteDescription,
+		RunID:     RunID(),
+		Succeeded: report.SuiteSucceeded,
+		Start:     report.StartTime,
+		Duration:  report.RunTime.Round(time.Second),
+	}
+	suites := map[string]*htmlSuite{}
+	for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+		name := spec.LeafNodeText
+		if len(spec.ContainerHierarchyTexts) > 0 {
+			name = spec.ContainerHierarchyTexts[0]
+		}
+		suite, ok := suites[name]
+		if !ok {
+			suite = &htmlSuite{Name: name}
+			suites[name] = suite
+			summary.Suites = append(summary.Suites, suite)
+		}
+
+		item := htmlSpec{Name: spec.FullText(), Status: "passed", Duration: spec.RunTime.Round(time.Millisecond)}
+		switch {
+		case spec.State.Is(types.SpecStateFailureStates):
+			item.Status, item.Failure, item.Location = "failed", spec.Failure.Message, spec.Failure.Location.String()
+			suite.Failed++
+			summary.Failed++
+		case spec.State.Is(types.SpecStateSkipped | types.SpecStatePending):
+			item.Status, item.Failure = "skipped", spec.Failure.Message
+			suite.Skipped++
+			summary.Skipped++
+		default:
+			suite.Passed++
+			summary.Passed++
+		}
+		if slow, ok := slowSpec(spec); ok {
+			item.Slow = slow.String()
+		}
+		if log := filepath.Join(SpecLogsDir, specLogName(spec.FullText())); fileExists(filepath.Join(dir, log)) {
+			item.Log = filepath.ToSlash(log)
+		}
+		suite.Duration += spec.RunTime
+		suite.Specs = append(suite.Specs, item)
+	}
+	// Suites with failures come first
+	sort.SliceStable(summary.Suites, func(i, j int) bool {
+		return summary.Suites[i].Failed > 0 && summary.Suites[j].Failed == 0
+	})
+	for _, suite := range summary.Suites {
+		suite.Duration = suite.Duration.Round(time.Second)
+	}
+	for _, artifact := range htmlArtifacts {
+		if fileExists(filepath.Join(dir, artifact)) {
+			summary.Artifacts = append(summary.Artifacts, artifact)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := htmlReportTemplate.Execute(file, summary); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
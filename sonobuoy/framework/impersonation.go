@@ -0,0 +1,55 @@
+package framework
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+// Identity is a user the suites act as through impersonation, instead of the plugin's own service account.
+// The plugin must be allowed to impersonate it, which the cluster-admin role Sonobuoy grants by default is.
+type Identity struct {
+	UserName string
+	UID      string
+	Groups   []string
+	Extra    map[string][]string
+}
+
+// ServiceAccountIdentity returns the identity of a ServiceAccount, with the groups its tokens carry
+func ServiceAccountIdentity(namespace, name string) Identity {
+	return Identity{
+		UserName: "system:serviceaccount:" + namespace + ":" + name,
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+	}
+}
+
+// ImpersonatedConfig returns a copy of config whose requests carry the Impersonate-User,
+// Impersonate-Group, Impersonate-Uid and Impersonate-Extra headers for identity
+func ImpersonatedConfig(config *rest.Config, identity Identity) *rest.Config {
+	impersonated := rest.CopyConfig(config)
+	impersonated.Impersonate = rest.ImpersonationConfig{
+		UserName: identity.UserName,
+		UID:      identity.UID,
+		Groups:   identity.Groups,
+		Extra:    identity.Extra,
+	}
+	return impersonated
+}
+
+// Impersonate builds clients from the shared RestConfig that act as identity
+func Impersonate(identity Identity) (*Framework, error) {
+	return NewFramework(ImpersonatedConfig(RestConfig, identity))
+}
+
+// ImpersonatingFramework returns a Framework whose clients act as identity, rebuilt before every spec
+// in the container it is called from. The identity function is evaluated then too, so it can depend
+// on names set up by earlier BeforeEach nodes.
+func ImpersonatingFramework(identity func() Identity) *Framework {
+	f := &Framework{}
+	ginkgo.BeforeEach(func() {
+		impersonated, err := Impersonate(identity())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to create impersonating clients")
+		*f = *impersonated
+	})
+	return f
+}
@@ -0,0 +1,143 @@
+package framework
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// SpecLogsDir is the directory under RESULTS_DIR holding one log file per spec
+const SpecLogsDir = "logs"
+
+// Longest spec name kept in a log file name, the rest is replaced by a hash
+const maxLogNameLength = 100
+
+var (
+	specLogMu sync.Mutex
+	specLog   io.WriteCloser
+)
+
+// Logger returns a logger writing JSON lines to the GinkgoWriter and to the running spec's log file.
+// Every record carries the run ID, the parallel process, the cluster in runs against several and, within a
+// spec, its full text.
+func Logger() *slog.Logger {
+	level := slog.LevelInfo
+	if config, err := LoadRunConfig(); err == nil {
+		level = config.LogLevel
+	}
+	logger := slog.New(slog.NewJSONHandler(logWriter{}, &slog.HandlerOptions{Level: level})).With(
+		"run_id", RunID(),
+		"process", ginkgo.GinkgoParallelProcess(),
+	)
+	if context := ClusterContext(); context != "" {
+		logger = logger.With("cluster", context)
+	}
+	if report := ginkgo.CurrentSpecReport(); report.LeafNodeText != "" {
+		logger = logger.With("spec", report.FullText())
+	}
+	return logger
+}
+
+// logWriter fans log records out to the GinkgoWriter, which Ginkgo forwards to stdout for failed specs
+// or verbose runs, and to the running spec's log file
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	specLogMu.Lock()
+	if specLog != nil {
+		specLog.Write(p)
+	}
+	specLogMu.Unlock()
+	return ginkgo.GinkgoWriter.Write(p)
+}
+
+// StartSpecLog opens the running spec's log file under RESULTS_DIR and logs how the spec ended, with the
+// location of the failed assertion, once its cleanup ran. Meant to be registered with BeforeEach. Records
+// only go to the GinkgoWriter when RESULTS_DIR is unset, as for local runs.
+func StartSpecLog() {
+	report := ginkgo.CurrentSpecReport()
+	if resultsDir := os.Getenv("RESULTS_DIR"); resultsDir != "" {
+		dir := filepath.Join(resultsDir, SpecLogsDir)
+		err := os.MkdirAll(dir, 0755)
+		var file *os.File
+		if err == nil {
+			// Retries of a flaky spec append to the same file
+			file, err = os.OpenFile(filepath.Join(dir, specLogName(report.FullText())), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		}
+		if err != nil {
+			Logger().Warn("Failed to open spec log", "error", err)
+		} else {
+			specLogMu.Lock()
+			specLog = file
+			specLogMu.Unlock()
+		}
+	}
+
+	start := time.Now()
+	Logger().Info("Spec started", "location", report.LeafNodeLocation.String(), "attempt", report.NumAttempts)
+	ginkgo.DeferCleanup(func() {
+		report := ginkgo.CurrentSpecReport()
+		if report.Failed() {
+			Logger().Error("Spec failed", "state", report.State.String(), "failure", report.Failure.Message,
+				"location", report.Failure.Location.String(), "duration", time.Since(start).String())
+		} else {
+			Logger().Info("Spec finished", "state", report.State.String(), "duration", time.Since(start).String())
+		}
+
+		specLogMu.Lock()
+		defer specLogMu.Unlock()
+		if specLog != nil {
+			specLog.Close()
+			specLog = nil
+		}
+	})
+}
+
+// specLogName turns a spec's full text into a file name that stays readable and unique
+func specLogName(spec string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, spec)
+	if len(name) > maxLogNameLength {
+		name = name[:maxLogNameLength]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(spec))
+	return fmt.Sprintf("%s-%08x.log", name, hash.Sum32())
+}
+
+// loggingTransport logs every API request with its outcome and latency at debug level
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func newLoggingTransport(next http.RoundTripper) http.RoundTripper {
+	return &loggingTransport{next: next}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs := []any{"method", req.Method, "path", req.URL.Path, "latency", time.Since(start).String()}
+	if req.URL.RawQuery != "" {
+		attrs = append(attrs, "query", req.URL.RawQuery)
+	}
+	if err != nil {
+		Logger().Debug("API request failed", append(attrs, "error", err)...)
+	} else {
+		Logger().Debug("API request", append(attrs, "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
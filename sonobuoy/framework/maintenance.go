@@ -0,0 +1,156 @@
+package framework
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// Labels for specs that may only run within a maintenance window
+const (
+	// LabelDisruptive marks specs that disturb cluster-wide behavior or running workloads
+	LabelDisruptive = "disruptive"
+	// LabelPrivileged marks specs that run privileged or root containers
+	LabelPrivileged = "privileged"
+)
+
+// MaintenanceWindow is a cron expression matching the minutes during which disruptive and privileged
+// specs may run, e.g. "* 2-4 * * 6" for Saturdays between 02:00 and 04:59. The five fields are minute,
+// hour, day of month, month and day of week, each a *, a number, a range or a list, optionally with a
+// /step. As in cron, when both day fields are restricted a day matching either one matches.
+type MaintenanceWindow struct {
+	Expression string
+	fields     [5]uint64
+	anyDay     [2]bool
+}
+
+// Bounds of the cron fields, in order
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseMaintenanceWindow parses a cron expression into a MaintenanceWindow
+func ParseMaintenanceWindow(expression string) (MaintenanceWindow, error) {
+	window := MaintenanceWindow{Expression: expression}
+	parts := strings.Fields(expression)
+	if len(parts) != 5 {
+		return window, fmt.Errorf("maintenance window %q must have 5 fields, has %d", expression, len(parts))
+	}
+	for i, part := range parts {
+		bits, err := parseCronField(part, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return window, fmt.Errorf("maintenance window %q: %v", expression, err)
+		}
+		window.fields[i] = bits
+	}
+	// Sunday may be written as 0 or 7
+	if window.fields[4]&(1<<7) != 0 {
+		window.fields[4] |= 1
+	}
+	// As in cron, a day field starting with * counts as unrestricted even with a step, e.g. */2
+	window.anyDay = [2]bool{strings.HasPrefix(parts[2], "*"), strings.HasPrefix(parts[4], "*")}
+	return window, nil
+}
+
+// Contains reports whether t falls within the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	has := func(field, value int) bool { return w.fields[field]&(1<<value) != 0 }
+	if !has(0, t.Minute()) || !has(1, t.Hour()) || !has(3, int(t.Month())) {
+		return false
+	}
+	dom, dow := has(2, t.Day()), has(4, int(t.Weekday()))
+	switch {
+	case w.anyDay[0] && w.anyDay[1]:
+		return true
+	case w.anyDay[0]:
+		return dow
+	case w.anyDay[1]:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func (w MaintenanceWindow) String() string {
+	return w.Expression
+}
+
+// parseCronField returns the values a cron field matches as a bitset
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loPart); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", item)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiPart); err != nil {
+					return 0, fmt.Errorf("invalid range in %q", item)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", item, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// InMaintenanceWindow reports whether t falls within a configured maintenance window, in the configured
+// time zone. Without any windows configured, every time is.
+func (c *RunConfig) InMaintenanceWindow(t time.Time) bool {
+	if len(c.MaintenanceWindows) == 0 {
+		return true
+	}
+	t = t.In(c.MaintenanceTimezone)
+	for _, window := range c.MaintenanceWindows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnforceMaintenanceWindows skips specs labeled disruptive or privileged outside the configured maintenance
+// windows, so continuous runs on production clusters only disturb them when expected. Read-only specs run
+// anytime. Entry points register it with BeforeEach.
+func EnforceMaintenanceWindows() {
+	var guarded string
+	for _, label := range ginkgo.CurrentSpecReport().Labels() {
+		if label == LabelDisruptive || label == LabelPrivileged {
+			guarded = label
+			break
+		}
+	}
+	if guarded == "" {
+		return
+	}
+
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	if !config.InMaintenanceWindow(time.Now()) {
+		ginkgo.Skip(fmt.Sprintf("Spec is %s and may only run within the maintenance windows %v (%s)",
+			guarded, config.MaintenanceWindows, config.MaintenanceTimezone))
+	}
+}
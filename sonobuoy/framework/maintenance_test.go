@@ -0,0 +1,123 @@
+package framework
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindowErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseMaintenanceWindow(expression); err == nil {
+			t.Errorf("ParseMaintenanceWindow(%q) succeeded, want an error", expression)
+		}
+	}
+}
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	// 2026-10-17 is a Saturday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expression string
+		t          time.Time
+		want       bool
+	}{
+		{"* * * * *", at(17, 13, 37), true},
+		{"* 2-4 * * 6", at(17, 2, 0), true},
+		{"* 2-4 * * 6", at(17, 4, 59), true},
+		{"* 2-4 * * 6", at(17, 5, 0), false},
+		{"* 2-4 * * 6", at(16, 3, 0), false},
+		{"0 9-17 * * 1-5", at(19, 17, 0), true},
+		{"0 9-17 * * 1-5", at(19, 17, 1), false},
+		{"0 9-17 * * 1-5", at(17, 12, 0), false},
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		{"0 0 1,15 * *", at(16, 0, 0), false},
+		// Sunday is 0 and 7
+		{"0 0 * * 0", at(18, 0, 0), true},
+		{"0 0 * * 7", at(18, 0, 0), true},
+		{"0 0 * * 5-7", at(18, 0, 0), true},
+		{"0 0 * * 7", at(17, 0, 0), false},
+		// Steps
+		{"*/15 * * * *", at(17, 10, 45), true},
+		{"*/15 * * * *", at(17, 10, 46), false},
+		{"5/20 * * * *", at(17, 10, 25), true},
+		{"5/20 * * * *", at(17, 10, 20), false},
+		{"0 0-12/6 * * *", at(17, 12, 0), true},
+		{"0 0-12/6 * * *", at(17, 18, 0), false},
+		{"* * * 9-12/3 *", at(17, 0, 0), false},
+		{"* * * 1-12/3 *", at(17, 0, 0), true},
+		// With both day fields restricted, either one matches
+		{"0 0 1 * 1", at(1, 0, 0), true},
+		{"0 0 1 * 1", at(19, 0, 0), true},
+		{"0 0 1 * 1", at(20, 0, 0), false},
+		// A stepped * leaves its day field unrestricted: only Mondays match, whatever the day of month
+		{"0 0 */2 * 1", at(19, 0, 0), true},
+		{"0 0 */2 * 1", at(20, 0, 0), false},
+		{"0 0 1 * */2", at(1, 0, 0), true},
+		{"0 0 1 * */2", at(17, 0, 0), false},
+	}
+	for _, test := range tests {
+		window, err := ParseMaintenanceWindow(test.expression)
+		if err != nil {
+			t.Errorf("ParseMaintenanceWindow(%q): %v", test.expression, err)
+			continue
+		}
+		if got := window.Contains(test.t); got != test.want {
+			t.Errorf("%q.Contains(%s) = %v, want %v", test.expression, test.t.Format(time.RFC1123), got, test.want)
+		}
+	}
+}
+
+func TestInMaintenanceWindowTimezone(t *testing.T) {
+	window, err := ParseMaintenanceWindow("* 21-23 * * 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork := time.FixedZone("EDT", -4*60*60)
+	tests := []struct {
+		name     string
+		windows  []MaintenanceWindow
+		timezone *time.Location
+		t        time.Time
+		want     bool
+	}{
+		{name: "no windows", timezone: time.UTC, t: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "utc", windows: []MaintenanceWindow{window}, timezone: time.UTC,
+			t: time.Date(2026, time.October, 17, 21, 0, 0, 0, time.UTC), want: true},
+		// Saturday 21:30 in New York is already Sunday in UTC
+		{name: "day behind utc", windows: []MaintenanceWindow{window}, timezone: newYork,
+			t: time.Date(2026, time.October, 18, 1, 30, 0, 0, time.UTC), want: true},
+		{name: "same instant in utc", windows: []MaintenanceWindow{window}, timezone: time.UTC,
+			t: time.Date(2026, time.October, 18, 1, 30, 0, 0, time.UTC), want: false},
+		// The window's last minute, and the first one after it
+		{name: "last minute", windows: []MaintenanceWindow{window}, timezone: newYork,
+			t: time.Date(2026, time.October, 18, 3, 59, 59, 0, time.UTC), want: true},
+		{name: "after midnight", windows: []MaintenanceWindow{window}, timezone: newYork,
+			t: time.Date(2026, time.October, 18, 4, 0, 0, 0, time.UTC), want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &RunConfig{MaintenanceWindows: test.windows, MaintenanceTimezone: test.timezone}
+			if got := config.InMaintenanceWindow(test.t); got != test.want {
+				t.Errorf("InMaintenanceWindow(%s) = %v, want %v", test.t.In(test.timezone).Format(time.RFC1123), got, test.want)
+			}
+		})
+	}
+}
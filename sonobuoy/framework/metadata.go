@@ -0,0 +1,68 @@
+package framework
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Annotations stamped on every object the suites create, so a leaked resource can be traced to its origin
+const (
+	AnnotationSpec      = "e2e.sonobuoy.io/spec"
+	AnnotationRunID     = "e2e.sonobuoy.io/run-id"
+	AnnotationRevision  = "e2e.sonobuoy.io/revision"
+	AnnotationStartTime = "e2e.sonobuoy.io/start-time"
+)
+
+var (
+	runID     string
+	runIDOnce sync.Once
+)
+
+// RunID identifies the current run. run.sh exports E2E_RUN_ID so that all suites and parallel
+// processes share it; otherwise an ID is generated once per process.
+func RunID() string {
+	runIDOnce.Do(func() {
+		runID = os.Getenv("E2E_RUN_ID")
+		if runID == "" {
+			runID = fmt.Sprintf("local-%d", time.Now().Unix())
+		}
+	})
+	return runID
+}
+
+// Revision of the suites, baked into the image at build time
+func suitesRevision() string {
+	if revision := os.Getenv("E2E_GIT_REVISION"); revision != "" {
+		return revision
+	}
+	return "unknown"
+}
+
+// stampRunMetadata is the built-in object hook adding run metadata annotations
+func stampRunMetadata(obj *unstructured.Unstructured) {
+	setRunMetadata(obj)
+}
+
+// setRunMetadata adds the run metadata annotations to obj. Objects written by update rather than
+// create, which bypass the object hooks, are stamped directly.
+func setRunMetadata(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationRunID] = RunID()
+	annotations[AnnotationRevision] = suitesRevision()
+
+	// Objects created outside of a spec (e.g. suite events) have no spec metadata
+	if report := ginkgo.CurrentSpecReport(); report.LeafNodeText != "" {
+		annotations[AnnotationSpec] = report.FullText()
+		annotations[AnnotationStartTime] = report.StartTime.UTC().Format(time.RFC3339)
+	}
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,75 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LabelNode marks specs that check a single node at a time, such as its kubelet, local volumes or host
+// network. They are the only specs a daemonset plugin runs, once on every node.
+const LabelNode = "node"
+
+// Report entry name recording the node a daemonset plugin ran a spec on
+const nodeEntry = "Node"
+
+// PluginNode returns the node this process runs on as a Sonobuoy daemonset plugin, or "" when it runs as
+// a single pod checking the whole cluster
+func PluginNode() string {
+	config, err := LoadRunConfig()
+	if err != nil {
+		return ""
+	}
+	return config.NodeName
+}
+
+// TargetNodes returns the nodes node-level specs check: the plugin's own node in daemonset mode, every
+// node otherwise
+func TargetNodes(ctx context.Context) ([]v1.Node, error) {
+	if node := PluginNode(); node != "" {
+		own, err := Clientset.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Node{*own}, nil
+	}
+	nodes, err := Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+// EnforceNodeScope skips specs not labeled node in daemonset mode, where every node's plugin pod would
+// otherwise run the cluster-wide specs again, and records the node on the ones it runs so the aggregated
+// results read per node. Entry points register it with BeforeEach.
+func EnforceNodeScope() {
+	node := PluginNode()
+	if node == "" {
+		return
+	}
+	for _, label := range ginkgo.CurrentSpecReport().Labels() {
+		if label == LabelNode {
+			ginkgo.AddReportEntry(nodeEntry, node)
+			return
+		}
+	}
+	ginkgo.Skip(fmt.Sprintf("Spec is cluster-wide and the daemonset plugin on %s only runs node-level specs", node))
+}
+
+// pinToPluginNode is the built-in object hook binding pods to the plugin's node in daemonset mode, so
+// node-level specs check the node their plugin pod runs on. Pods already bound are left alone.
+func pinToPluginNode(obj *unstructured.Unstructured) {
+	node := PluginNode()
+	if node == "" || obj.GetKind() != "Pod" {
+		return
+	}
+	if bound, _, _ := unstructured.NestedString(obj.Object, "spec", "nodeName"); bound != "" {
+		return
+	}
+	_ = unstructured.SetNestedField(obj.Object, node, "spec", "nodeName")
+}
@@ -0,0 +1,170 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// NotifyFormat is the shape of the payload the notification webhook receives
+type NotifyFormat string
+
+const (
+	// NotifyFormatSlack posts a Slack incoming webhook message with the summary as its text
+	NotifyFormatSlack NotifyFormat = "slack"
+	// NotifyFormatJSON posts a NotificationPayload
+	NotifyFormatJSON NotifyFormat = "json"
+)
+
+// NotifyOn is which runs send a notification
+type NotifyOn string
+
+const (
+	NotifyOnAlways  NotifyOn = "always"
+	NotifyOnFailure NotifyOn = "failure"
+)
+
+// Longest failure message a notification carries, the spec log and reports have the rest
+const maxNotifyMessageLength = 300
+
+// How long posting the notification may take
+const notifyTimeout = 30 * time.Second
+
+// NotificationPayload is what the webhook receives in the json format
+type NotificationPayload struct {
+	// Text is the summary the slack format posts, in Slack's mrkdwn
+	Text      string                `json:"text"`
+	Suite     string                `json:"suite"`
+	RunID     string                `json:"runId"`
+	Cluster   string                `json:"cluster,omitempty"`
+	Succeeded bool                  `json:"succeeded"`
+	Passed    int                   `json:"passed"`
+	Failed    int                   `json:"failed"`
+	Skipped   int                   `json:"skipped"`
+	Duration  float64               `json:"durationSeconds"`
+	Failures  []NotificationFailure `json:"failures,omitempty"`
+}
+
+// NotificationFailure is a failed spec of a NotificationPayload
+type NotificationFailure struct {
+	Spec     string `json:"spec"`
+	Message  string `json:"message"`
+	Location string `json:"location"`
+}
+
+// WebhookNotifier posts a summary of the run with its first failures to a webhook once the suite
+// finished, for teams running the suites on a schedule
+type WebhookNotifier struct {
+	Config NotifyConfig
+}
+
+func (n *WebhookNotifier) SpecStarted(ginkgo.SpecReport) {}
+
+func (n *WebhookNotifier) SpecFinished(ginkgo.SpecReport) {}
+
+func (n *WebhookNotifier) SuiteFinished(report ginkgo.Report) error {
+	if report.SuiteSucceeded && n.Config.On == NotifyOnFailure {
+		return nil
+	}
+	payload := NewNotificationPayload(report, n.Config.MaxFailures)
+	var body any = payload
+	if n.Config.Format == NotifyFormatSlack {
+		body = map[string]string{"text": payload.Text}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	// The URL of a webhook is its credential, so it is left out of errors, which url.Error would include
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Config.URL, bytes.NewReader(data))
+	if err != nil {
+		return withoutURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("posting notification: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// withoutURL strips the URL a url.Error names from err
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("posting notification: %w", urlErr.Err)
+	}
+	return fmt.Errorf("posting notification: %w", err)
+}
+
+// NewNotificationPayload summarizes report with up to maxFailures of its failed specs
+func NewNotificationPayload(report ginkgo.Report, maxFailures int) NotificationPayload {
+	specs := report.SpecReports.WithLeafNodeType(types.NodeTypeIt)
+	payload := NotificationPayload{
+		Suite:     report.SuiteDescription,
+		RunID:     RunID(),
+		Cluster:   ClusterContext(),
+		Succeeded: report.SuiteSucceeded,
+		Passed:    specs.CountWithState(types.SpecStatePassed),
+		Failed:    specs.CountWithState(types.SpecStateFailureStates),
+		Skipped:   specs.CountWithState(types.SpecStateSkipped | types.SpecStatePending),
+		Duration:  report.RunTime.Round(time.Second).Seconds(),
+	}
+	// Failures outside specs, e.g. in BeforeSuite, fail the run as well
+	for _, spec := range report.SpecReports.WithState(types.SpecStateFailureStates) {
+		if len(payload.Failures) == maxFailures {
+			break
+		}
+		name := spec.FullText()
+		if name == "" {
+			name = spec.LeafNodeType.String()
+		}
+		message := spec.Failure.Message
+		if len(message) > maxNotifyMessageLength {
+			message = message[:maxNotifyMessageLength] + "…"
+		}
+		payload.Failures = append(payload.Failures, NotificationFailure{
+			Spec:     name,
+			Message:  message,
+			Location: spec.Failure.Location.String(),
+		})
+	}
+
+	var text strings.Builder
+	status := ":white_check_mark: passed"
+	if !payload.Succeeded {
+		status = ":x: failed"
+	}
+	fmt.Fprintf(&text, "*%s* %s", payload.Suite, status)
+	if payload.Cluster != "" {
+		fmt.Fprintf(&text, " on `%s`", payload.Cluster)
+	}
+	fmt.Fprintf(&text, " in %s (run %s)\n%d passed, %d failed, %d skipped",
+		report.RunTime.Round(time.Second), payload.RunID, payload.Passed, payload.Failed, payload.Skipped)
+	for _, failure := range payload.Failures {
+		fmt.Fprintf(&text, "\n• *%s*\n```%s```", failure.Spec, failure.Message)
+	}
+	if more := report.SpecReports.CountWithState(types.SpecStateFailureStates) - len(payload.Failures); more > 0 {
+		fmt.Fprintf(&text, "\n…and %d more failures", more)
+	}
+	payload.Text = text.String()
+	return payload
+}
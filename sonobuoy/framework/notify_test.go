@@ -0,0 +1,48 @@
+package framework
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// The path of a webhook URL is its credential
+const webhookSecret = "T000/B000/notify-secret"
+
+func TestWebhookNotifierKeepsURLOutOfErrors(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	// Nothing listens on the port of a closed server
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "unreachable", url: closed.URL + "/services/" + webhookSecret, want: "posting notification: "},
+		{name: "unresolvable", url: "http://notify.invalid/services/" + webhookSecret, want: "posting notification: "},
+		{name: "rejected", url: failing.URL + "/services/" + webhookSecret, want: "403 Forbidden: invalid_token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notifier := &WebhookNotifier{Config: NotifyConfig{URL: test.url, Format: NotifyFormatJSON, On: NotifyOnAlways}}
+			err := notifier.SuiteFinished(ginkgo.Report{SuiteDescription: "test"})
+			if err == nil {
+				t.Fatal("SuiteFinished succeeded, want an error")
+			}
+			if strings.Contains(err.Error(), webhookSecret) {
+				t.Errorf("error %q contains the webhook URL", err)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q does not contain %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// OpenMetricsFile is written to RESULTS_DIR by the openmetrics reporter
+const OpenMetricsFile = "metrics.prom"
+
+// How long pushing the metrics to a Pushgateway may take
+const pushTimeout = 30 * time.Second
+
+// OpenMetricsReporter exports the outcome and duration of every spec and of the run as OpenMetrics, to a
+// file under RESULTS_DIR and to a Prometheus Pushgateway, so recurring runs can be graphed and alerted on
+type OpenMetricsReporter struct {
+	// File is the path of the metrics relative to RESULTS_DIR; empty writes no file
+	File string
+	// Pushgateway is the base URL of the Pushgateway to push to; empty pushes nothing
+	Pushgateway string
+	// Job groups the metrics on the Pushgateway, replacing those of the previous run of the job
+	Job string
+}
+
+func (r *OpenMetricsReporter) SpecStarted(ginkgo.SpecReport) {}
+
+func (r *OpenMetricsReporter) SpecFinished(ginkgo.SpecReport) {}
+
+func (r *OpenMetricsReporter) SuiteFinished(report ginkgo.Report) error {
+	metrics := RenderOpenMetrics(report)
+	if resultsDir := os.Getenv("RESULTS_DIR"); resultsDir != "" && r.File != "" {
+		if err := os.WriteFile(filepath.Join(resultsDir, r.File), metrics, 0644); err != nil {
+			return err
+		}
+	}
+	if r.Pushgateway == "" {
+		return nil
+	}
+	return r.push(metrics)
+}
+
+// push replaces the metrics of the reporter's job, and cluster in multi-cluster runs, on the Pushgateway
+func (r *OpenMetricsReporter) push(metrics []byte) error {
+	target := strings.TrimSuffix(r.Pushgateway, "/") + "/metrics/job/" + url.PathEscape(r.Job)
+	if cluster := ClusterContext(); cluster != "" {
+		target += "/cluster/" + url.PathEscape(cluster)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(metrics))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushing metrics to %s: %s: %s", target, resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// RenderOpenMetrics renders report as OpenMetrics text: whether each spec that ran passed and how long it
+// took, labeled by its suite, the top-level container, and the spec counts, outcome and duration of the run
+func RenderOpenMetrics(report ginkgo.Report) []byte {
+	var out bytes.Buffer
+	specs := report.SpecReports.WithLeafNodeType(types.NodeTypeIt)
+
+	fmt.Fprintln(&out, "# TYPE e2e_spec_success gauge")
+	fmt.Fprintln(&out, "# HELP e2e_spec_success Whether the spec passed, 1, or failed, 0, in the last run.")
+	for _, spec := range specs {
+		if spec.State.Is(types.SpecStatePassed | types.SpecStateFailureStates) {
+			fmt.Fprintf(&out, "e2e_spec_success{%s} %d\n", specLabels(spec), boolMetric(spec.State == types.SpecStatePassed))
+		}
+	}
+	fmt.Fprintln(&out, "# TYPE e2e_spec_duration_seconds gauge")
+	fmt.Fprintln(&out, "# UNIT e2e_spec_duration_seconds seconds")
+	fmt.Fprintln(&out, "# HELP e2e_spec_duration_seconds How long the spec ran in the last run.")
+	for _, spec := range specs {
+		if spec.State.Is(types.SpecStatePassed | types.SpecStateFailureStates) {
+			fmt.Fprintf(&out, "e2e_spec_duration_seconds{%s} %.3f\n", specLabels(spec), spec.RunTime.Seconds())
+		}
+	}
+
+	fmt.Fprintln(&out, "# TYPE e2e_run_specs gauge")
+	fmt.Fprintln(&out, "# HELP e2e_run_specs Specs of the last run by state.")
+	for _, state := range []struct {
+		name  string
+		match types.SpecState
+	}{
+		{"passed", types.SpecStatePassed},
+		{"failed", types.SpecStateFailureStates},
+		{"skipped", types.SpecStateSkipped | types.SpecStatePending},
+	} {
+		fmt.Fprintf(&out, "e2e_run_specs{state=%q} %d\n", state.name, specs.CountWithState(state.match))
+	}
+	fmt.Fprintln(&out, "# TYPE e2e_run_success gauge")
+	fmt.Fprintln(&out, "# HELP e2e_run_success Whether the last run passed.")
+	fmt.Fprintf(&out, "e2e_run_success %d\n", boolMetric(report.SuiteSucceeded))
+	fmt.Fprintln(&out, "# TYPE e2e_run_duration_seconds gauge")
+	fmt.Fprintln(&out, "# UNIT e2e_run_duration_seconds seconds")
+	fmt.Fprintln(&out, "# HELP e2e_run_duration_seconds How long the last run took.")
+	fmt.Fprintf(&out, "e2e_run_duration_seconds %.3f\n", report.RunTime.Seconds())
+	fmt.Fprintln(&out, "# TYPE e2e_run_end_timestamp_seconds gauge")
+	fmt.Fprintln(&out, "# UNIT e2e_run_end_timestamp_seconds seconds")
+	fmt.Fprintln(&out, "# HELP e2e_run_end_timestamp_seconds When the last run ended.")
+	fmt.Fprintf(&out, "e2e_run_end_timestamp_seconds %d\n", report.EndTime.Unix())
+	fmt.Fprintln(&out, "# EOF")
+	return out.Bytes()
+}
+
+// specLabels returns the suite and spec labels of spec's metrics
+func specLabels(spec ginkgo.SpecReport) string {
+	suite := spec.LeafNodeText
+	if len(spec.ContainerHierarchyTexts) > 0 {
+		suite = spec.ContainerHierarchyTexts[0]
+	}
+	return fmt.Sprintf(`suite="%s",spec="%s"`, escapeLabel(suite), escapeLabel(spec.FullText()))
+}
+
+// escapeLabel escapes a label value as the OpenMetrics text format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// processNamespace is this Ginkgo process's own test namespace in parallel runs, set by SetupSuite
+var processNamespace string
+
+// setupProcessNamespace gives each Ginkgo process of a parallel run its own namespace, <TEST_NAMESPACE>-p<N>,
+// labeled like TEST_NAMESPACE so the same Pod Security level applies, and deletes it after the suite. Specs
+// that list, count or watch everything in their namespace then never see another process's objects.
+// Processes keep sharing TEST_NAMESPACE when it has ResourceQuotas, since waiting for quota room relies on all
+// of them drawing from the same quota.
+func setupProcessNamespace(ctx context.Context) {
+	suiteConfig, _ := ginkgo.GinkgoConfiguration()
+	if suiteConfig.ParallelTotal <= 1 {
+		return
+	}
+	base := baseNamespace()
+	quotas, err := Clientset.CoreV1().ResourceQuotas(base).List(ctx, metav1.ListOptions{})
+	if err == nil && len(quotas.Items) > 0 {
+		Logger().Info("Sharing namespace between processes to stay within its ResourceQuotas", "namespace", base)
+		return
+	}
+
+	labels := map[string]string{}
+	if source, err := Clientset.CoreV1().Namespaces().Get(ctx, base, metav1.GetOptions{}); err == nil {
+		for key, value := range source.Labels {
+			if key != v1.LabelMetadataName {
+				labels[key] = value
+			}
+		}
+	}
+	name := fmt.Sprintf("%s-p%d", base, ginkgo.GinkgoParallelProcess())
+	_, err = Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}, metav1.CreateOptions{})
+	// A namespace left behind by an interrupted run is reused
+	if err != nil && !errors.IsAlreadyExists(err) {
+		ginkgo.Fail(fmt.Sprintf("Failed to create namespace %s for parallel process %d: %v", name, ginkgo.GinkgoParallelProcess(), err))
+	}
+	processNamespace = name
+	ginkgo.DeferCleanup(func(ctx ginkgo.SpecContext) {
+		processNamespace = ""
+		if err := Cleanup(ctx, Clientset.CoreV1().Namespaces(), name); err != nil {
+			ginkgo.Fail(fmt.Sprintf("Failed to delete namespace %s: %v", name, err))
+		}
+	})
+}
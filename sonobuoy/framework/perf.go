@@ -0,0 +1,249 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// LabelPerf marks benchmarks, which only run when E2E_PERF opts into them
+const LabelPerf = "perf"
+
+// PerfReportFile is written to RESULTS_DIR with the latencies and throughputs every benchmark measured
+const PerfReportFile = "perf.json"
+
+// Names of the report entries holding a LatencySummary and a ThroughputSummary
+const (
+	latencyEntry    = "Latency"
+	throughputEntry = "Throughput"
+)
+
+// Latencies collects the latencies of an operation a benchmark repeats. It is safe for concurrent use.
+type Latencies struct {
+	Operation string
+
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+// NewLatencies returns an empty collection for operation
+func NewLatencies(operation string) *Latencies {
+	return &Latencies{Operation: operation}
+}
+
+// Observe adds a sample
+func (l *Latencies) Observe(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples = append(l.samples, latency)
+}
+
+// Time runs op and adds how long it took when it succeeded
+func (l *Latencies) Time(op func() error) error {
+	start := time.Now()
+	if err := op(); err != nil {
+		return err
+	}
+	l.Observe(time.Since(start))
+	return nil
+}
+
+// Summary returns the percentiles of the samples
+func (l *Latencies) Summary() LatencySummary {
+	l.mu.Lock()
+	sorted := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	summary := LatencySummary{Operation: l.Operation, Samples: len(sorted)}
+	if len(sorted) == 0 {
+		return summary
+	}
+	summary.P50 = percentile(sorted, 50).Seconds()
+	summary.P95 = percentile(sorted, 95).Seconds()
+	summary.P99 = percentile(sorted, 99).Seconds()
+	summary.Max = sorted[len(sorted)-1].Seconds()
+	return summary
+}
+
+// percentile returns the nearest-rank percentile p of sorted, which must not be empty
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// LatencySummary holds the percentiles of an operation's latencies, in seconds
+type LatencySummary struct {
+	Operation string  `json:"operation"`
+	Samples   int     `json:"samples"`
+	P50       float64 `json:"p50"`
+	P95       float64 `json:"p95"`
+	P99       float64 `json:"p99"`
+	Max       float64 `json:"max"`
+	// Spec is the benchmark that measured the latencies, filled in the perf report
+	Spec string `json:"spec,omitempty"`
+}
+
+// String formats the summary as a line of the report
+func (s LatencySummary) String() string {
+	return fmt.Sprintf("%s: p50 %s, p95 %s, p99 %s, max %s over %d samples", s.Operation,
+		secondsDuration(s.P50), secondsDuration(s.P95), secondsDuration(s.P99), secondsDuration(s.Max), s.Samples)
+}
+
+// ReportLatencies adds the summaries to the current spec's report and to the perf report of the run
+func ReportLatencies(summaries ...LatencySummary) {
+	for _, summary := range summaries {
+		Logger().Info("Latencies measured", "operation", summary.Operation, "samples", summary.Samples,
+			"p50", summary.P50, "p95", summary.P95, "p99", summary.P99, "max", summary.Max)
+		ginkgo.AddReportEntry(latencyEntry, summary)
+	}
+}
+
+// ThroughputSummary is how many items of an operation a benchmark completed in how long
+type ThroughputSummary struct {
+	Operation string  `json:"operation"`
+	Items     int     `json:"items"`
+	Seconds   float64 `json:"seconds"`
+	PerSecond float64 `json:"perSecond"`
+	// Spec is the benchmark that measured the throughput, filled in the perf report
+	Spec string `json:"spec,omitempty"`
+}
+
+// String formats the summary as a line of the report
+func (s ThroughputSummary) String() string {
+	return fmt.Sprintf("%s: %d in %s, %.2f/s", s.Operation, s.Items, secondsDuration(s.Seconds), s.PerSecond)
+}
+
+// ReportThroughput adds that items of operation completed in elapsed to the current spec's report and to
+// the perf report of the run
+func ReportThroughput(operation string, items int, elapsed time.Duration) {
+	summary := ThroughputSummary{Operation: operation, Items: items, Seconds: elapsed.Seconds()}
+	if elapsed > 0 {
+		summary.PerSecond = float64(items) / elapsed.Seconds()
+	}
+	Logger().Info("Throughput measured", "operation", operation, "items", items, "seconds", summary.Seconds,
+		"per_second", summary.PerSecond)
+	ginkgo.AddReportEntry(throughputEntry, summary)
+}
+
+// PerfReport is what the benchmarks of a run measured
+type PerfReport struct {
+	Latencies   []LatencySummary    `json:"latencies,omitempty"`
+	Throughputs []ThroughputSummary `json:"throughputs,omitempty"`
+}
+
+// WritePerfReport writes the latencies and throughputs every benchmark of the run reported to RESULTS_DIR,
+// for storage vendors and regression tracking. Meant to be registered with ReportAfterSuite.
+func WritePerfReport(report ginkgo.Report) {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+	var perf PerfReport
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			switch entry.Name {
+			case latencyEntry:
+				var summary LatencySummary
+				if json.Unmarshal([]byte(entry.Value.AsJSON), &summary) == nil {
+					summary.Spec = spec.FullText()
+					perf.Latencies = append(perf.Latencies, summary)
+				}
+			case throughputEntry:
+				var summary ThroughputSummary
+				if json.Unmarshal([]byte(entry.Value.AsJSON), &summary) == nil {
+					summary.Spec = spec.FullText()
+					perf.Throughputs = append(perf.Throughputs, summary)
+				}
+			}
+		}
+	}
+	if len(perf.Latencies) == 0 && len(perf.Throughputs) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(perf, "", "  ")
+	if err != nil {
+		Logger().Warn("Failed to marshal perf report", "error", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(resultsDir, PerfReportFile), data, 0644); err != nil {
+		Logger().Warn("Failed to write perf report", "error", err)
+	}
+}
+
+// RequirePerf skips the spec unless E2E_PERF opts into benchmarks, which load the cluster for a while, and
+// returns the perf settings
+func RequirePerf() *PerfConfig {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	requirement := Requirement{Name: "opt-in:perf", Required: "enabled", Actual: "disabled, set E2E_PERF=true"}
+	if config.Perf.Enabled {
+		requirement.Actual = "enabled"
+		requirement.Satisfied = true
+	}
+	require(requirement)
+	return &config.Perf
+}
+
+// PerfClientset returns a clientset without client-side rate limiting, so benchmarks measure the API
+// server rather than the client's own throttling
+func PerfClientset() (*kubernetes.Clientset, error) {
+	config := rest.CopyConfig(RestConfig)
+	config.QPS = -1
+	return kubernetes.NewForConfig(config)
+}
+
+// TimeUntil returns how long after start an event of watcher first carried an object satisfying done. Start
+// the watch before what is measured so no event is missed; it is stopped on return.
+func TimeUntil(ctx context.Context, watcher watch.Interface, start time.Time, timeout time.Duration, done func(obj runtime.Object) bool) (time.Duration, error) {
+	var latency time.Duration
+	err := WatchUntil(ctx, watcher, timeout, func(event watch.Event) bool {
+		if event.Type != watch.Deleted && done(event.Object) {
+			latency = time.Since(start)
+			return true
+		}
+		return false
+	})
+	return latency, err
+}
+
+// WatchUntil feeds the events of watcher to track until it returns true, failing after timeout. Watch errors
+// end it as well; the watch is stopped on return.
+func WatchUntil(ctx context.Context, watcher watch.Interface, timeout time.Duration, track func(event watch.Event) bool) error {
+	defer watcher.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed before the condition held")
+			}
+			if event.Type == watch.Error {
+				return fmt.Errorf("watch failed: %v", event.Object)
+			}
+			if track(event) {
+				return nil
+			}
+		case <-timer.C:
+			return fmt.Errorf("condition did not hold within %s", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
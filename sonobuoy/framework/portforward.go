@@ -0,0 +1,87 @@
+package framework
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Channels of a port-forward stream for a single port
+const (
+	portForwardData  byte = 0
+	portForwardError byte = 1
+)
+
+// PortForwardConn is a connection to a port of a pod through the API server. Reads return what the pod
+// sends and writes are delivered to it; an error the kubelet reports fails the next Read.
+type PortForwardConn struct {
+	stream  *ChannelStream
+	port    uint16
+	pending []byte
+	// The kubelet prefixes the first message of each channel with the port number
+	sawPrefix [2]bool
+}
+
+// PortForward opens a connection to port of a running pod, like kubectl port-forward does for every
+// connection it accepts. The connection is closed when ctx is done.
+func PortForward(ctx context.Context, namespace, pod string, port uint16) (*PortForwardConn, error) {
+	request := Clientset.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("pods").
+		Name(pod).
+		SubResource("portforward").
+		Param("ports", strconv.Itoa(int(port)))
+	stream, err := DialChannelStream(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("port-forward to pod %s/%s: %w", namespace, pod, err)
+	}
+	return &PortForwardConn{stream: stream, port: port}, nil
+}
+
+// Read reads what the pod sent
+func (c *PortForwardConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		channel, data, err := c.stream.Read()
+		if err != nil {
+			return 0, err
+		}
+		if channel > portForwardError {
+			continue
+		}
+		if !c.sawPrefix[channel] {
+			if len(data) < 2 || binary.LittleEndian.Uint16(data) != c.port {
+				return 0, fmt.Errorf("port-forward stream for port %d has an invalid prefix", c.port)
+			}
+			c.sawPrefix[channel] = true
+			data = data[2:]
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if channel == portForwardError {
+			return 0, errors.New(string(data))
+		}
+		c.pending = data
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// Write sends p to the pod
+func (c *PortForwardConn) Write(p []byte) (int, error) {
+	if err := c.stream.Write(portForwardData, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection
+func (c *PortForwardConn) Close() error {
+	return c.stream.Close()
+}
+
+var _ io.ReadWriteCloser = &PortForwardConn{}
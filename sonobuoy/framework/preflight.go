@@ -0,0 +1,210 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PreflightMode controls what a failed preflight check does to the run
+type PreflightMode string
+
+const (
+	// PreflightModeFail fails the suite before any spec runs, so a broken cluster does not show up as
+	// hundreds of misleading spec failures
+	PreflightModeFail PreflightMode = "fail"
+	// PreflightModeWarn records the failed checks in the results and runs the specs anyway
+	PreflightModeWarn PreflightMode = "warn"
+	// PreflightModeOff skips the checks
+	PreflightModeOff PreflightMode = "off"
+)
+
+// PreflightFile is written to RESULTS_DIR with the outcome of every preflight check
+const PreflightFile = "preflight.json"
+
+// PreflightCheck is the outcome of one cluster health check
+type PreflightCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// Control plane components that hold a leader election Lease in kube-system
+var controlPlaneLeases = []string{"kube-controller-manager", "kube-scheduler"}
+
+// RunPreflight checks the cluster is healthy enough for spec failures to mean something: nodes are Ready,
+// the control plane is up, PVCs can be provisioned without naming a class and cluster DNS has endpoints
+func RunPreflight(ctx context.Context, c kubernetes.Interface) []PreflightCheck {
+	return []PreflightCheck{
+		checkNodes(ctx, c),
+		checkControlPlane(ctx, c),
+		checkDefaultStorageClass(ctx, c),
+		checkDNS(ctx, c),
+	}
+}
+
+func checkNodes(ctx context.Context, c kubernetes.Interface) PreflightCheck {
+	check := PreflightCheck{Name: "nodes"}
+	nodes, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Detail = fmt.Sprintf("failed to list nodes: %v", err)
+		return check
+	}
+	counts := countNodes(nodes.Items)
+	check.Passed = counts.Total > 0 && counts.Ready == counts.Total
+	check.Detail = fmt.Sprintf("%d of %d nodes Ready, %d schedulable", counts.Ready, counts.Total, counts.Schedulable)
+	return check
+}
+
+func checkControlPlane(ctx context.Context, c kubernetes.Interface) PreflightCheck {
+	check := PreflightCheck{Name: "control-plane", Passed: true}
+	var details []string
+	body, err := c.Discovery().RESTClient().Get().AbsPath("/readyz").Param("verbose", "true").DoRaw(ctx)
+	if err != nil {
+		check.Passed = false
+		// The verbose output marks each failing check with [-]
+		var failing []string
+		for _, line := range strings.Split(string(body), "\n") {
+			if strings.HasPrefix(line, "[-]") {
+				failing = append(failing, strings.TrimPrefix(line, "[-]"))
+			}
+		}
+		if len(failing) == 0 {
+			failing = append(failing, err.Error())
+		}
+		details = append(details, "API server not ready: "+strings.Join(failing, ", "))
+	} else {
+		details = append(details, "API server ready")
+	}
+
+	// Managed control planes may hide these Leases, which only means the check cannot tell
+	for _, name := range controlPlaneLeases {
+		lease, err := c.CoordinationV1().Leases(metav1.NamespaceSystem).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err) || errors.IsForbidden(err):
+			details = append(details, name+" lease not visible")
+		case err != nil:
+			check.Passed = false
+			details = append(details, fmt.Sprintf("failed to get %s lease: %v", name, err))
+		case lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil:
+			check.Passed = false
+			details = append(details, name+" has no leader")
+		default:
+			expiry := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+			if age := time.Since(lease.Spec.RenewTime.Time); age > 2*expiry {
+				check.Passed = false
+				details = append(details, fmt.Sprintf("%s leader last renewed %s ago", name, age.Round(time.Second)))
+			} else {
+				details = append(details, name+" has a leader")
+			}
+		}
+	}
+	check.Detail = strings.Join(details, "; ")
+	return check
+}
+
+func checkDefaultStorageClass(ctx context.Context, c kubernetes.Interface) PreflightCheck {
+	check := PreflightCheck{Name: "default-storage-class"}
+	classes, err := c.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		check.Detail = fmt.Sprintf("failed to list StorageClasses: %v", err)
+		return check
+	}
+	var defaults []string
+	for i := range classes.Items {
+		if isDefaultStorageClass(&classes.Items[i]) {
+			defaults = append(defaults, classes.Items[i].Name)
+		}
+	}
+	check.Passed = len(defaults) > 0
+	if check.Passed {
+		check.Detail = "default StorageClass " + strings.Join(defaults, ", ")
+	} else {
+		check.Detail = fmt.Sprintf("none of %d StorageClasses is the default, so PVCs without a class stay Pending unless E2E_STORAGE_CLASS is set", len(classes.Items))
+	}
+	return check
+}
+
+func checkDNS(ctx context.Context, c kubernetes.Interface) PreflightCheck {
+	check := PreflightCheck{Name: "cluster-dns"}
+	// CoreDNS keeps the kube-dns Service name for compatibility
+	slices, err := c.DiscoveryV1().EndpointSlices(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=kube-dns",
+	})
+	if err != nil {
+		check.Detail = fmt.Sprintf("failed to list kube-dns endpoints: %v", err)
+		return check
+	}
+	ready := 0
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
+		}
+	}
+	check.Passed = ready > 0
+	check.Detail = fmt.Sprintf("%d ready kube-dns endpoints", ready)
+	return check
+}
+
+// checkPreflight runs the preflight checks for SetupSuite according to E2E_PREFLIGHT. The first parallel
+// process records the outcome in the report and in RESULTS_DIR; every process fails in fail mode, so no
+// spec runs against a broken cluster.
+func checkPreflight(ctx context.Context) {
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	if config.Preflight == PreflightModeOff {
+		return
+	}
+
+	checks := RunPreflight(ctx, Clientset)
+	var summary, failed []string
+	for _, check := range checks {
+		state := "passed"
+		if !check.Passed {
+			state = "FAILED"
+			failed = append(failed, check.Name+": "+check.Detail)
+		}
+		summary = append(summary, fmt.Sprintf("%s %s: %s", check.Name, state, check.Detail))
+	}
+	if ginkgo.GinkgoParallelProcess() == 1 {
+		ginkgo.AddReportEntry("Preflight", strings.Join(summary, "\n"))
+		writePreflight(checks)
+	}
+	if len(failed) == 0 {
+		return
+	}
+	if config.Preflight == PreflightModeFail {
+		ginkgo.Fail(fmt.Sprintf("Cluster failed preflight checks, so no spec was run (set E2E_PREFLIGHT=warn to run anyway):\n%s",
+			strings.Join(failed, "\n")))
+	}
+	Logger().Warn("Cluster failed preflight checks, spec failures may stem from them", "checks", failed)
+}
+
+// writePreflight writes the checks to RESULTS_DIR. Nothing is written when RESULTS_DIR is unset, as for local runs.
+func writePreflight(checks []PreflightCheck) {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(checks, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(resultsDir, PreflightFile), data, 0644)
+	}
+	if err != nil {
+		Logger().Error("Failed to write preflight checks", "file", PreflightFile, "error", err)
+	}
+}
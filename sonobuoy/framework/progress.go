@@ -0,0 +1,349 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// How many failures the progress UI keeps on screen
+const recentFailures = 5
+
+// progressSuite is a top-level container of the run, shown as one line of the tree
+type progressSuite struct {
+	name                  string
+	done, failed, skipped int
+	elapsed               time.Duration
+
+	// Spec count and total duration of the suite in the last run, if it was seen then
+	hasHistory bool
+	expected   int
+	estimate   time.Duration
+
+	runningSpec        string
+	runningSince       time.Time
+	runningEstimate    time.Duration
+	hasRunningEstimate bool
+}
+
+// progressUI redraws a live view of the run on a terminal
+type progressUI struct {
+	mu       sync.Mutex
+	out      io.Writer
+	title    string
+	start    time.Time
+	total    int
+	finished int
+	failed   int
+	suites   []*progressSuite
+	byName   map[string]*progressSuite
+	failures []string
+	history  map[string]map[string]time.Duration
+	timings  map[string]map[string]time.Duration
+	stop     chan struct{}
+	stopped  sync.WaitGroup
+}
+
+var progress *progressUI
+
+// RegisterProgressUI registers the report nodes of an interactive progress view for humans running the
+// suites locally: suites as a tree, the running spec, elapsed against estimated times and recent failures.
+// It is only shown when E2E_TUI is true, the specs run serially and stdout is a terminal, so CI and
+// Sonobuoy runs keep Ginkgo's plain output. Call it at package level in an entry point.
+func RegisterProgressUI() bool {
+	ginkgo.ReportBeforeSuite(startProgressUI)
+	ginkgo.ReportBeforeEach(func(spec ginkgo.SpecReport) {
+		if progress != nil {
+			progress.specStarted(spec)
+		}
+	})
+	ginkgo.ReportAfterEach(func(spec ginkgo.SpecReport) {
+		if progress != nil {
+			progress.specFinished(spec)
+		}
+	})
+	ginkgo.ReportAfterSuite("Stop progress UI", func(ginkgo.Report) {
+		if progress != nil {
+			progress.finish()
+		}
+	})
+	return true
+}
+
+func startProgressUI(report ginkgo.Report) {
+	enabled, _ := strconv.ParseBool(os.Getenv("E2E_TUI"))
+	if !enabled || report.SuiteConfig.DryRun {
+		return
+	}
+	if report.SuiteConfig.ParallelTotal > 1 || !isTerminal(os.Stdout) {
+		fmt.Fprintln(os.Stderr, "E2E_TUI needs a serial run writing to a terminal, falling back to plain output")
+		return
+	}
+
+	progress = &progressUI{
+		out:     os.Stdout,
+		title:   report.SuiteDescription,
+		start:   time.Now(),
+		total:   report.PreRunStats.SpecsThatWillRun,
+		byName:  map[string]*progressSuite{},
+		history: loadTimings(),
+		timings: map[string]map[string]time.Duration{},
+		stop:    make(chan struct{}),
+	}
+	// Suites known from the last run are listed up front
+	names := make([]string, 0, len(progress.history))
+	for name := range progress.history {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		progress.suite(name)
+	}
+
+	// Redraw every second so elapsed times keep moving while a spec runs
+	p := progress
+	p.stopped.Add(1)
+	go func() {
+		defer p.stopped.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.draw()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// suite returns the tree node of a top-level container, adding it if needed. Callers hold mu,
+// except while the UI is being set up.
+func (p *progressUI) suite(name string) *progressSuite {
+	if s, ok := p.byName[name]; ok {
+		return s
+	}
+	s := &progressSuite{name: name}
+	if specs, ok := p.history[name]; ok {
+		s.hasHistory = true
+		s.expected = len(specs)
+		for _, d := range specs {
+			s.estimate += d
+		}
+	}
+	p.suites = append(p.suites, s)
+	p.byName[name] = s
+	return s
+}
+
+func (p *progressUI) specStarted(spec ginkgo.SpecReport) {
+	if spec.LeafNodeType != types.NodeTypeIt {
+		return
+	}
+	p.mu.Lock()
+	s := p.suite(suiteName(spec))
+	s.runningSpec = spec.LeafNodeText
+	s.runningSince = time.Now()
+	s.runningEstimate, s.hasRunningEstimate = p.history[s.name][specName(spec)]
+	p.mu.Unlock()
+	p.draw()
+}
+
+func (p *progressUI) specFinished(spec ginkgo.SpecReport) {
+	if spec.LeafNodeType != types.NodeTypeIt {
+		return
+	}
+	p.mu.Lock()
+	s := p.suite(suiteName(spec))
+	s.runningSpec = ""
+	s.done++
+	s.elapsed += spec.RunTime
+	p.finished++
+	switch {
+	case spec.State.Is(types.SpecStateFailureStates):
+		s.failed++
+		p.failed++
+		message := strings.SplitN(spec.Failure.Message, "\n", 2)[0]
+		p.failures = append(p.failures, fmt.Sprintf("%s: %s", spec.FullText(), message))
+		if len(p.failures) > recentFailures {
+			p.failures = p.failures[len(p.failures)-recentFailures:]
+		}
+	case spec.State.Is(types.SpecStateSkipped | types.SpecStatePending):
+		s.skipped++
+	default:
+		// Only specs that ran to completion are representative of how long they take
+		if p.timings[s.name] == nil {
+			p.timings[s.name] = map[string]time.Duration{}
+		}
+		p.timings[s.name][specName(spec)] = spec.RunTime
+	}
+	p.mu.Unlock()
+	p.draw()
+}
+
+// finish stops the redraws, leaves the final view on screen and saves timings for the next estimate
+func (p *progressUI) finish() {
+	close(p.stop)
+	p.stopped.Wait()
+	p.draw()
+	fmt.Fprintln(p.out)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for name, specs := range p.timings {
+		if p.history[name] == nil {
+			p.history[name] = map[string]time.Duration{}
+		}
+		for spec, d := range specs {
+			p.history[name][spec] = d
+		}
+	}
+	if err := saveTimings(p.history); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save spec timings: %v\n", err)
+	}
+}
+
+// estimatedTotal projects the run time from the average spec duration, preferring past runs
+func (p *progressUI) estimatedTotal(elapsed time.Duration) time.Duration {
+	var sum time.Duration
+	var count int
+	for _, specs := range p.history {
+		for _, d := range specs {
+			sum += d
+			count++
+		}
+	}
+	if count == 0 {
+		for _, s := range p.suites {
+			sum += s.elapsed
+			count += s.done - s.skipped
+		}
+	}
+	if count == 0 || p.finished >= p.total {
+		return elapsed
+	}
+	return elapsed + time.Duration(p.total-p.finished)*(sum/time.Duration(count))
+}
+
+func (p *progressUI) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	// Move home and clear the screen, so stray reporter output between frames is wiped
+	b.WriteString("\x1b[H\x1b[2J")
+
+	elapsed := time.Since(p.start)
+	fmt.Fprintf(&b, "\x1b[1m%s\x1b[0m  %d/%d specs  %d failed  elapsed %s / est. %s\n\n",
+		p.title, p.finished, p.total, p.failed, roundSecond(elapsed), roundSecond(p.estimatedTotal(elapsed)))
+
+	for _, s := range p.suites {
+		status, color := "·", "\x1b[2m"
+		switch {
+		case s.runningSpec != "":
+			status, color = "▶", "\x1b[36m"
+		case s.failed > 0:
+			status, color = "✗", "\x1b[31m"
+		case s.done > 0 && (!s.hasHistory || s.done >= s.expected):
+			status, color = "✓", "\x1b[32m"
+		}
+		count, estimate := strconv.Itoa(s.done), ""
+		if s.hasHistory {
+			count += "/" + strconv.Itoa(s.expected)
+			estimate = " (est. " + roundSecond(s.estimate).String() + ")"
+		}
+		fmt.Fprintf(&b, "%s%s %-50s %7s  %8s%s\x1b[0m\n", color, status, truncateText(s.name, 50), count, roundSecond(s.elapsed), estimate)
+
+		if s.runningSpec != "" {
+			estimate = ""
+			if s.hasRunningEstimate {
+				estimate = " (est. " + roundSecond(s.runningEstimate).String() + ")"
+			}
+			fmt.Fprintf(&b, "    \x1b[36m▶ %s  %s%s\x1b[0m\n", truncateText(s.runningSpec, 70), roundSecond(time.Since(s.runningSince)), estimate)
+		}
+	}
+
+	if len(p.failures) > 0 {
+		b.WriteString("\nRecent failures:\n")
+		for _, failure := range p.failures {
+			fmt.Fprintf(&b, "  \x1b[31m✗ %s\x1b[0m\n", truncateText(failure, 120))
+		}
+	}
+	io.WriteString(p.out, b.String())
+}
+
+// suiteName is the top-level container a spec belongs to
+func suiteName(spec ginkgo.SpecReport) string {
+	if len(spec.ContainerHierarchyTexts) == 0 {
+		return spec.LeafNodeText
+	}
+	return spec.ContainerHierarchyTexts[0]
+}
+
+// specName identifies a spec within its suite across runs
+func specName(spec ginkgo.SpecReport) string {
+	return strings.Join(append(append([]string{}, spec.ContainerHierarchyTexts...), spec.LeafNodeText), " ")
+}
+
+func timingsFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sonobuoy-e2e", "timings.json"), nil
+}
+
+// loadTimings reads the spec durations of the last local run, if any
+func loadTimings() map[string]map[string]time.Duration {
+	timings := map[string]map[string]time.Duration{}
+	path, err := timingsFile()
+	if err != nil {
+		return timings
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &timings)
+	}
+	return timings
+}
+
+func saveTimings(timings map[string]map[string]time.Duration) error {
+	path, err := timingsFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(timings)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func roundSecond(d time.Duration) time.Duration {
+	return d.Round(time.Second)
+}
+
+func truncateText(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,255 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// How often WaitForPodRoom checks whether other specs released quota
+const quotaPollInterval = 2 * time.Second
+
+// quotaWorkload locates the pod spec and the number of pods it runs in a pod-bearing kind
+type quotaWorkload struct {
+	podSpec  []string
+	replicas []string
+}
+
+// Kinds whose creates are sized against the namespace's ResourceQuotas
+var quotaWorkloads = map[string]quotaWorkload{
+	"Pod":         {podSpec: []string{"spec"}},
+	"Deployment":  {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	"ReplicaSet":  {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	"StatefulSet": {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "replicas"}},
+	"Job":         {podSpec: []string{"spec", "template", "spec"}, replicas: []string{"spec", "parallelism"}},
+}
+
+// fitToQuota shrinks a pod-bearing workload that could never fit the ResourceQuotas of the namespace it is
+// created in, even in an otherwise empty namespace, to as many replicas as the quota allows, so it does not
+// fail with Forbidden. Creates never wait here: specs wait for quota held by specs running in parallel with
+// WaitForPodRoom, and specs that need all their replicas call RequirePodRoom to be skipped instead.
+func fitToQuota(ctx context.Context, namespace string, obj *unstructured.Unstructured) {
+	if _, ok := quotaWorkloads[obj.GetKind()]; !ok || namespace == "" || Clientset == nil {
+		return
+	}
+	hard, _, err := quotaRoom(ctx, namespace)
+	if err != nil || len(hard) == 0 {
+		return
+	}
+	if replicas, shrunk := shrinkToQuota(obj, hard); shrunk < replicas {
+		Logger().Info("Shrinking replicas to fit the ResourceQuota", "namespace", namespace, "kind", obj.GetKind(),
+			"name", obj.GetName(), "replicas", replicas, "shrunk", shrunk)
+	}
+}
+
+// shrinkToQuota lowers the replicas of a workload to as many pods as the hard quota limits hold, but at
+// least one, returning the replicas before and after
+func shrinkToQuota(obj *unstructured.Unstructured, hard v1.ResourceList) (replicas, shrunk int64) {
+	workload, ok := quotaWorkloads[obj.GetKind()]
+	if !ok || workload.replicas == nil {
+		return 0, 0
+	}
+	replicas, found, err := unstructured.NestedInt64(obj.Object, workload.replicas...)
+	if !found || err != nil || replicas <= 1 {
+		return replicas, replicas
+	}
+	rawSpec, found, err := unstructured.NestedMap(obj.Object, workload.podSpec...)
+	if !found || err != nil {
+		return replicas, replicas
+	}
+	spec := &v1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSpec, spec); err != nil {
+		return replicas, replicas
+	}
+	capacity := podsFitting(hard, podQuotaCost(spec))
+	if capacity >= replicas {
+		return replicas, replicas
+	}
+	shrunk = max(capacity, 1)
+	if err := unstructured.SetNestedField(obj.Object, shrunk, workload.replicas...); err != nil {
+		return replicas, replicas
+	}
+	return replicas, shrunk
+}
+
+// WaitForPodRoom waits until the test namespace's ResourceQuotas have room for replicas pods of spec, so
+// quota-constrained runs wait for specs running in parallel to release quota instead of failing with
+// Forbidden halfway through. It gives up after QuotaWaitTimeout, and returns right away when the quota
+// could never hold them or QuotaWaitTimeout is zero.
+func WaitForPodRoom(ctx context.Context, replicas int64, spec *v1.PodSpec) error {
+	config, err := LoadRunConfig()
+	if err != nil || config.QuotaWaitTimeout == 0 {
+		return err
+	}
+	namespace := TestNamespace()
+	cost := podQuotaCost(spec)
+	hard, room, err := quotaRoom(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if fits := podsFitting(hard, cost); fits < replicas {
+		return fmt.Errorf("the ResourceQuotas of %s hold %d pods, %d requested", namespace, fits, replicas)
+	}
+	if podsFitting(room, cost) >= replicas {
+		return nil
+	}
+
+	Logger().Info("Waiting for quota", "namespace", namespace, "pods", replicas)
+	err = wait.PollUntilContextTimeout(ctx, quotaPollInterval, config.QuotaWaitTimeout, false, func(ctx context.Context) (bool, error) {
+		_, room, err := quotaRoom(ctx, namespace)
+		return err == nil && podsFitting(room, cost) >= replicas, err
+	})
+	if err != nil {
+		return fmt.Errorf("the ResourceQuotas of %s had no room for %d pods within %s: %w", namespace, replicas, config.QuotaWaitTimeout, err)
+	}
+	return nil
+}
+
+// quotaRoom returns, per resource, the tightest hard limit and the least room left across the namespace's ResourceQuotas
+func quotaRoom(ctx context.Context, namespace string) (hard, room v1.ResourceList, err error) {
+	quotas, err := Clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	hard, room = v1.ResourceList{}, v1.ResourceList{}
+	for _, quota := range quotas.Items {
+		for name, limit := range quota.Status.Hard {
+			left := limit.DeepCopy()
+			if used, ok := quota.Status.Used[name]; ok {
+				left.Sub(used)
+			}
+			if current, ok := hard[name]; !ok || limit.Cmp(current) < 0 {
+				hard[name] = limit
+			}
+			if current, ok := room[name]; !ok || left.Cmp(current) < 0 {
+				room[name] = left
+			}
+		}
+	}
+	return hard, room, nil
+}
+
+// podQuotaCost returns what one pod with spec is charged against compute ResourceQuotas
+func podQuotaCost(spec *v1.PodSpec) v1.ResourceList {
+	requests, limits := v1.ResourceList{}, v1.ResourceList{}
+	for _, container := range spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			sum := requests[name]
+			sum.Add(quantity)
+			requests[name] = sum
+		}
+		for name, quantity := range container.Resources.Limits {
+			sum := limits[name]
+			sum.Add(quantity)
+			limits[name] = sum
+		}
+	}
+	// Init containers run one at a time, so the pod is charged for the largest if it exceeds the containers
+	for _, container := range spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current := requests[name]; quantity.Cmp(current) > 0 {
+				requests[name] = quantity
+			}
+		}
+		for name, quantity := range container.Resources.Limits {
+			if current := limits[name]; quantity.Cmp(current) > 0 {
+				limits[name] = quantity
+			}
+		}
+	}
+
+	cost := v1.ResourceList{v1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI)}
+	for name, quantity := range requests {
+		cost[v1.ResourceName("requests."+name)] = quantity
+		if name == v1.ResourceCPU || name == v1.ResourceMemory {
+			cost[name] = quantity
+		}
+	}
+	for name, quantity := range limits {
+		cost[v1.ResourceName("limits."+name)] = quantity
+	}
+	return cost
+}
+
+// podsFitting returns how many pods costing cost fit in room
+func podsFitting(room, cost v1.ResourceList) int64 {
+	fitting := int64(math.MaxInt64)
+	for name, quantity := range cost {
+		available, ok := room[name]
+		if !ok || quantity.IsZero() {
+			continue
+		}
+		if available.Sign() <= 0 {
+			return 0
+		}
+		fitting = min(fitting, available.MilliValue()/quantity.MilliValue())
+	}
+	return fitting
+}
+
+// nodesFitting returns how many pods costing cost fit on the Ready, schedulable nodes next to the pods
+// already running there, by requests as the scheduler counts them
+func nodesFitting(ctx context.Context, cost v1.ResourceList) (int64, error) {
+	nodes, err := Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	pods, err := Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=" + string(v1.PodSucceeded) + ",status.phase!=" + string(v1.PodFailed),
+	})
+	if err != nil {
+		return 0, err
+	}
+	used := map[string]v1.ResourceList{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if used[pod.Spec.NodeName] == nil {
+			used[pod.Spec.NodeName] = v1.ResourceList{}
+		}
+		for name, quantity := range podQuotaCost(&pod.Spec) {
+			sum := used[pod.Spec.NodeName][name]
+			sum.Add(quantity)
+			used[pod.Spec.NodeName][name] = sum
+		}
+	}
+
+	var fitting int64
+	for _, node := range nodes.Items {
+		if countNodes([]v1.Node{node}).Schedulable == 0 {
+			continue
+		}
+		free := v1.ResourceList{}
+		for name, allocatable := range node.Status.Allocatable {
+			left := allocatable.DeepCopy()
+			if quantity, ok := used[node.Name][name]; ok {
+				left.Sub(quantity)
+			}
+			free[name] = left
+		}
+		fitting += podsFitting(free, cost)
+	}
+	return fitting, nil
+}
+
+// pathNamespace returns the namespace a create request path targets, if any
+func pathNamespace(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "namespaces" && (segments[0] == "api" || segments[0] == "apis") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package framework
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testContainer(requests, limits v1.ResourceList) v1.Container {
+	return v1.Container{Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}}
+}
+
+func TestPodQuotaCost(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1.PodSpec
+		want map[v1.ResourceName]string
+	}{
+		{
+			name: "no resources",
+			spec: v1.PodSpec{Containers: []v1.Container{{}}},
+			want: map[v1.ResourceName]string{v1.ResourcePods: "1"},
+		},
+		{
+			name: "containers are summed",
+			spec: v1.PodSpec{Containers: []v1.Container{
+				testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+					v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")}),
+				testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")}, nil),
+			}},
+			want: map[v1.ResourceName]string{
+				v1.ResourcePods:           "1",
+				v1.ResourceCPU:            "150m",
+				v1.ResourceRequestsCPU:    "150m",
+				v1.ResourceMemory:         "64Mi",
+				v1.ResourceRequestsMemory: "64Mi",
+				v1.ResourceLimitsCPU:      "200m",
+			},
+		},
+		{
+			name: "a larger init container is charged instead",
+			spec: v1.PodSpec{
+				InitContainers: []v1.Container{testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}, nil)},
+				Containers:     []v1.Container{testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}, nil)},
+			},
+			want: map[v1.ResourceName]string{v1.ResourcePods: "1", v1.ResourceCPU: "500m", v1.ResourceRequestsCPU: "500m"},
+		},
+		{
+			name: "a smaller init container is not",
+			spec: v1.PodSpec{
+				InitContainers: []v1.Container{testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("10m")}, nil)},
+				Containers:     []v1.Container{testContainer(v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}, nil)},
+			},
+			want: map[v1.ResourceName]string{v1.ResourcePods: "1", v1.ResourceCPU: "100m", v1.ResourceRequestsCPU: "100m"},
+		},
+		{
+			name: "extended resources count as requests only",
+			spec: v1.PodSpec{Containers: []v1.Container{
+				testContainer(v1.ResourceList{"example.com/gpu": resource.MustParse("1")}, v1.ResourceList{"example.com/gpu": resource.MustParse("1")}),
+			}},
+			want: map[v1.ResourceName]string{v1.ResourcePods: "1", "requests.example.com/gpu": "1", "limits.example.com/gpu": "1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cost := podQuotaCost(&test.spec)
+			if len(cost) != len(test.want) {
+				t.Errorf("cost = %v, want %v", cost, test.want)
+			}
+			for name, want := range test.want {
+				if got, ok := cost[name]; !ok || got.Cmp(resource.MustParse(want)) != 0 {
+					t.Errorf("cost[%s] = %v, want %s", name, cost[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPodsFitting(t *testing.T) {
+	cost := v1.ResourceList{
+		v1.ResourcePods:        resource.MustParse("1"),
+		v1.ResourceRequestsCPU: resource.MustParse("100m"),
+		v1.ResourceLimitsCPU:   resource.MustParse("0"),
+	}
+	tests := []struct {
+		name string
+		room v1.ResourceList
+		want int64
+	}{
+		{name: "no quota", room: v1.ResourceList{}, want: math.MaxInt64},
+		{name: "unrelated resources", room: v1.ResourceList{v1.ResourceSecrets: resource.MustParse("2")}, want: math.MaxInt64},
+		{name: "pod count", room: v1.ResourceList{v1.ResourcePods: resource.MustParse("4")}, want: 4},
+		{name: "tightest resource wins", room: v1.ResourceList{
+			v1.ResourcePods:        resource.MustParse("10"),
+			v1.ResourceRequestsCPU: resource.MustParse("350m"),
+		}, want: 3},
+		{name: "zero cost is not limited", room: v1.ResourceList{v1.ResourceLimitsCPU: resource.MustParse("0")}, want: math.MaxInt64},
+		{name: "exhausted", room: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("0")}, want: 0},
+		{name: "overcommitted", room: v1.ResourceList{v1.ResourcePods: resource.MustParse("-2")}, want: 0},
+		{name: "less than one pod", room: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("99m")}, want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := podsFitting(test.room, cost); got != test.want {
+				t.Errorf("podsFitting(%v) = %d, want %d", test.room, got, test.want)
+			}
+		})
+	}
+}
+
+func TestShrinkToQuota(t *testing.T) {
+	// Each pod requests 100m CPU
+	container := map[string]interface{}{
+		"name":      "app",
+		"image":     "alpine:3.20",
+		"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "100m"}},
+	}
+	podSpec := map[string]interface{}{"containers": []interface{}{container}}
+	workload := func(kind, field string, replicas int64) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": kind,
+			"spec": map[string]interface{}{field: replicas, "template": map[string]interface{}{"spec": podSpec}},
+		}}
+	}
+	hard := v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("350m")}
+	tests := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		field string
+		hard  v1.ResourceList
+		want  int64
+	}{
+		{name: "fits", obj: workload("Deployment", "replicas", 3), field: "replicas", hard: hard, want: 3},
+		{name: "deployment too large", obj: workload("Deployment", "replicas", 5), field: "replicas", hard: hard, want: 3},
+		{name: "statefulset too large", obj: workload("StatefulSet", "replicas", 10), field: "replicas", hard: hard, want: 3},
+		{name: "job parallelism", obj: workload("Job", "parallelism", 4), field: "parallelism", hard: hard, want: 3},
+		{name: "never below one", obj: workload("ReplicaSet", "replicas", 2), field: "replicas",
+			hard: v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("50m")}, want: 1},
+		{name: "unrelated quota", obj: workload("Deployment", "replicas", 5), field: "replicas",
+			hard: v1.ResourceList{v1.ResourceSecrets: resource.MustParse("1")}, want: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shrinkToQuota(test.obj, test.hard)
+			got, _, _ := unstructured.NestedInt64(test.obj.Object, "spec", test.field)
+			if got != test.want {
+				t.Errorf("spec.%s = %d after shrinking, want %d", test.field, got, test.want)
+			}
+		})
+	}
+
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Pod", "spec": podSpec}}
+	if replicas, shrunk := shrinkToQuota(pod, v1.ResourceList{v1.ResourcePods: resource.MustParse("0")}); replicas != 0 || shrunk != 0 {
+		t.Errorf("shrinkToQuota() on a pod = %d, %d; want it left alone", replicas, shrunk)
+	}
+}
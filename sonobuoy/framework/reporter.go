@@ -0,0 +1,252 @@
+package framework
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Built-in reporters selected through E2E_REPORTERS
+const (
+	ReporterStdout   = "stdout"
+	ReporterJSON     = "json"
+	ReporterJUnit    = "junit"
+	ReporterSonobuoy = "sonobuoy"
+	ReporterHTML     = "html"
+	// ReporterOpenMetrics also pushes to the Pushgateway E2E_PUSHGATEWAY_URL names, which enables it too
+	ReporterOpenMetrics = "openmetrics"
+)
+
+// Files the built-in reporters write to RESULTS_DIR. They are named apart from the reports ginkgo writes
+// itself, which are written after the suite and would replace them.
+const (
+	JSONReportFile      = "reports/report.json"
+	JUnitReportFile     = "reports/junit.xml"
+	SonobuoyResultsFile = "sonobuoy_results.yaml"
+)
+
+// Statuses of Sonobuoy's manual results format
+const (
+	sonobuoyStatusPassed  = "passed"
+	sonobuoyStatusFailed  = "failed"
+	sonobuoyStatusSkipped = "skipped"
+)
+
+// Reporter is a sink for the results of a run. Implementations registered with RegisterReporter receive
+// every spec of the process they are registered in as it starts and finishes and, on the first parallel
+// process only, the report of the whole run aggregated across processes, so sinks writing one file per
+// run should do so in SuiteFinished.
+type Reporter interface {
+	// SpecStarted is called before a spec runs
+	SpecStarted(spec ginkgo.SpecReport)
+	// SpecFinished is called once a spec ended, with its state and failure
+	SpecFinished(spec ginkgo.SpecReport)
+	// SuiteFinished is called with the report of the run, with the failures of quarantined specs turned
+	// into skipped known issues when a baseline is configured
+	SuiteFinished(report ginkgo.Report) error
+}
+
+var (
+	reportersMu   sync.Mutex
+	sinks         []Reporter
+	builtinsAdded bool
+)
+
+// RegisterReporter adds a sink for the results of the run. Call it at package level or in BeforeSuite,
+// before any spec runs.
+func RegisterReporter(reporter Reporter) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	sinks = append(sinks, reporter)
+}
+
+// RegisterReporters registers the report nodes feeding the reporters registered with RegisterReporter,
+// next to the built-in ones E2E_REPORTERS selects. Call it at package level in an entry point.
+func RegisterReporters() bool {
+	ginkgo.ReportBeforeEach(func(spec ginkgo.SpecReport) {
+		for _, reporter := range registeredReporters() {
+			reporter.SpecStarted(spec)
+		}
+	})
+	ginkgo.ReportAfterEach(func(spec ginkgo.SpecReport) {
+		for _, reporter := range registeredReporters() {
+			reporter.SpecFinished(spec)
+		}
+	})
+	ginkgo.ReportAfterSuite("Run reporters", func(report ginkgo.Report) {
+		if config, err := LoadRunConfig(); err == nil && config.Baseline != nil {
+			report, _ = applyBaseline(report, config.Baseline, config.Platform)
+		}
+		for _, reporter := range registeredReporters() {
+			if err := reporter.SuiteFinished(report); err != nil {
+				Logger().Error("Reporter failed", "reporter", fmt.Sprintf("%T", reporter), "error", err)
+			}
+		}
+	})
+	return true
+}
+
+// registeredReporters returns a snapshot of the registered reporters, adding the built-in ones and the
+// notification webhook the first time, once every parallel process has parsed the run configuration
+func registeredReporters() []Reporter {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	if !builtinsAdded {
+		builtinsAdded = true
+		if config, err := LoadRunConfig(); err == nil {
+			for _, name := range config.Reporters {
+				sinks = append(sinks, builtinReporter(name, config))
+			}
+			if config.Notify.URL != "" {
+				sinks = append(sinks, &WebhookNotifier{Config: config.Notify})
+			}
+		}
+	}
+	return append([]Reporter(nil), sinks...)
+}
+
+// builtinReporter returns the built-in reporter called name, which parseRunConfig validated
+func builtinReporter(name string, config *RunConfig) Reporter {
+	switch name {
+	case ReporterStdout:
+		return NewTextReporter(os.Stdout)
+	case ReporterJSON:
+		return &FileReporter{File: JSONReportFile, Write: reporters.GenerateJSONReport}
+	case ReporterJUnit:
+		return &FileReporter{File: JUnitReportFile, Write: reporters.GenerateJUnitReport}
+	case ReporterHTML:
+		return &FileReporter{File: HTMLReportFile, Write: GenerateHTMLReport}
+	case ReporterOpenMetrics:
+		return &OpenMetricsReporter{File: OpenMetricsFile, Pushgateway: config.Pushgateway.URL, Job: config.Pushgateway.Job}
+	default:
+		return &FileReporter{File: SonobuoyResultsFile, Write: GenerateSonobuoyResults}
+	}
+}
+
+// TextReporter writes a line per finished spec and a summary of the run to a writer
+type TextReporter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewTextReporter returns a TextReporter writing to out
+func NewTextReporter(out io.Writer) *TextReporter {
+	return &TextReporter{out: out}
+}
+
+func (r *TextReporter) SpecStarted(ginkgo.SpecReport) {}
+
+func (r *TextReporter) SpecFinished(spec ginkgo.SpecReport) {
+	if spec.LeafNodeType != types.NodeTypeIt {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintf(r.out, "[%s] %s (%s)\n", spec.State, spec.FullText(), spec.RunTime.Round(time.Millisecond))
+	if spec.State.Is(types.SpecStateFailureStates) {
+		fmt.Fprintf(r.out, "  %s\n  at %s\n", spec.Failure.Message, spec.Failure.Location)
+	}
+}
+
+func (r *TextReporter) SuiteFinished(report ginkgo.Report) error {
+	specs := report.SpecReports.WithLeafNodeType(types.NodeTypeIt)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := fmt.Fprintf(r.out, "%s finished in %s: %d passed, %d failed, %d skipped\n",
+		report.SuiteDescription, report.RunTime.Round(time.Second),
+		specs.CountWithState(types.SpecStatePassed),
+		specs.CountWithState(types.SpecStateFailureStates),
+		specs.CountWithState(types.SpecStateSkipped|types.SpecStatePending))
+	return err
+}
+
+// FileReporter writes the report of the run to a file under RESULTS_DIR once the suite finished. Nothing
+// is written when RESULTS_DIR is unset, as for local runs.
+type FileReporter struct {
+	// File is the path of the report relative to RESULTS_DIR
+	File string
+	// Write renders report to the file at path, e.g. reporters.GenerateJUnitReport
+	Write func(report ginkgo.Report, path string) error
+}
+
+func (r *FileReporter) SpecStarted(ginkgo.SpecReport) {}
+
+func (r *FileReporter) SpecFinished(ginkgo.SpecReport) {}
+
+func (r *FileReporter) SuiteFinished(report ginkgo.Report) error {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return nil
+	}
+	path := filepath.Join(resultsDir, r.File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return r.Write(report, path)
+}
+
+// SonobuoyItem is an entry of Sonobuoy's manual results format: the run, a suite or a spec
+type SonobuoyItem struct {
+	Name    string            `json:"name"`
+	Status  string            `json:"status"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+	Items   []SonobuoyItem    `json:"items,omitempty"`
+}
+
+// GenerateSonobuoyResults writes report to path in Sonobuoy's manual results format, with the specs grouped
+// by their top-level container, for plugins declaring result-format: manual
+func GenerateSonobuoyResults(report ginkgo.Report, path string) error {
+	run := SonobuoyItem{Name: report.SuiteDescription, Status: sonobuoyStatusPassed, Meta: map[string]string{"run-id": RunID()}}
+	if !report.SuiteSucceeded {
+		run.Status = sonobuoyStatusFailed
+	}
+	suites := map[string]int{}
+	for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+		item := SonobuoyItem{
+			Name:   spec.FullText(),
+			Status: sonobuoyStatusPassed,
+			Meta:   map[string]string{"duration": spec.RunTime.Round(time.Millisecond).String()},
+		}
+		switch {
+		case spec.State.Is(types.SpecStateFailureStates):
+			item.Status = sonobuoyStatusFailed
+			item.Details = map[string]string{"failure": spec.Failure.Message, "location": spec.Failure.Location.String()}
+		case spec.State.Is(types.SpecStateSkipped | types.SpecStatePending):
+			item.Status = sonobuoyStatusSkipped
+			if spec.Failure.Message != "" {
+				item.Details = map[string]string{"reason": spec.Failure.Message}
+			}
+		}
+
+		name := spec.LeafNodeText
+		if len(spec.ContainerHierarchyTexts) > 0 {
+			name = spec.ContainerHierarchyTexts[0]
+		}
+		i, ok := suites[name]
+		if !ok {
+			i = len(run.Items)
+			suites[name] = i
+			run.Items = append(run.Items, SonobuoyItem{Name: name, Status: sonobuoyStatusPassed})
+		}
+		suite := &run.Items[i]
+		suite.Items = append(suite.Items, item)
+		if item.Status == sonobuoyStatusFailed {
+			suite.Status = sonobuoyStatusFailed
+		}
+	}
+
+	data, err := yaml.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
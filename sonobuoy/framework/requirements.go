@@ -0,0 +1,533 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RequirementsManifestFile is written to RESULTS_DIR by WriteRequirementsManifest
+const RequirementsManifestFile = "requirements.json"
+
+// Report entry name specs record their requirements under, so they survive parallel runs
+const requirementEntry = "Requirement"
+
+// Requirement is a cluster capability a spec needs, compared against what the cluster provides
+type Requirement struct {
+	Name      string `json:"name"`
+	Required  string `json:"required"`
+	Actual    string `json:"actual"`
+	Satisfied bool   `json:"satisfied"`
+}
+
+// ManifestRequirement is a Requirement along with the specs that checked it
+type ManifestRequirement struct {
+	Requirement
+	Specs []string `json:"specs"`
+}
+
+// Capabilities summarizes what a cluster offers the suites
+type Capabilities struct {
+	ServerVersion    string                   `json:"serverVersion"`
+	APIGroupVersions []string                 `json:"apiGroupVersions"`
+	StorageClasses   []StorageClassCapability `json:"storageClasses"`
+	CSIDrivers       []string                 `json:"csiDrivers"`
+	Nodes            NodeCapability           `json:"nodes"`
+}
+
+// StorageClassCapability describes one StorageClass
+type StorageClassCapability struct {
+	Name                 string `json:"name"`
+	Provisioner          string `json:"provisioner"`
+	Default              bool   `json:"default"`
+	VolumeBindingMode    string `json:"volumeBindingMode"`
+	AllowVolumeExpansion bool   `json:"allowVolumeExpansion"`
+}
+
+// NodeCapability counts the cluster's nodes
+type NodeCapability struct {
+	Total       int `json:"total"`
+	Ready       int `json:"ready"`
+	Schedulable int `json:"schedulable"`
+}
+
+// RequirementsManifest is the machine-readable record of what the suites required and what the cluster
+// had, so fleet tooling can diff capability drift between runs and clusters
+type RequirementsManifest struct {
+	RunID            string                `json:"runID"`
+	Revision         string                `json:"revision"`
+	FrameworkVersion string                `json:"frameworkVersion"`
+	GeneratedAt      time.Time             `json:"generatedAt"`
+	Cluster          *Capabilities         `json:"cluster,omitempty"`
+	DetectionError   string                `json:"detectionError,omitempty"`
+	Requirements     []ManifestRequirement `json:"requirements"`
+}
+
+// RequireAPIGroupVersion skips the spec unless the cluster serves groupVersion, e.g. snapshot.storage.k8s.io/v1
+func RequireAPIGroupVersion(groupVersion string) {
+	ginkgo.GinkgoHelper()
+	actual := "served"
+	if _, err := Clientset.Discovery().ServerResourcesForGroupVersion(groupVersion); err != nil {
+		actual = "not served"
+	}
+	require(Requirement{
+		Name:      "api:" + groupVersion,
+		Required:  "served",
+		Actual:    actual,
+		Satisfied: actual == "served",
+	})
+}
+
+// RequireAPIService skips the spec unless the named APIService, e.g. v1beta1.custom.metrics.k8s.io, is registered
+// and Available
+func RequireAPIService(ctx context.Context, name string) {
+	ginkgo.GinkgoHelper()
+	actual := "available"
+	apiService, err := DynamicClient.Resource(APIServiceResource).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		actual = "not registered"
+	} else if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to get APIService %s: %v", name, err))
+	} else if available, reason := APIServiceAvailable(apiService); !available {
+		actual = "unavailable (" + reason + ")"
+	}
+	require(Requirement{
+		Name:      "apiservice:" + name,
+		Required:  "available",
+		Actual:    actual,
+		Satisfied: actual == "available",
+	})
+}
+
+// RequireStorageClass skips the spec unless the named StorageClass exists, or a default StorageClass
+// when name is empty
+func RequireStorageClass(ctx context.Context, name string) {
+	ginkgo.GinkgoHelper()
+	classes, err := Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to list StorageClasses: %v", err))
+	}
+
+	requirement := Requirement{Name: "storageclass:" + name, Required: "present", Actual: "absent"}
+	if name == "" {
+		requirement.Name = "storageclass:default"
+	}
+	for _, class := range classes.Items {
+		if (name == "" && isDefaultStorageClass(&class)) || class.Name == name {
+			requirement.Actual = "present"
+			requirement.Satisfied = true
+			break
+		}
+	}
+	require(requirement)
+}
+
+// RequireTestStorageClass skips the spec unless the StorageClass storage suites provision from exists, and
+// returns it: the one named by E2E_STORAGE_CLASS, or the default StorageClass
+func RequireTestStorageClass(ctx context.Context) *storagev1.StorageClass {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	name := config.Storage.StorageClass
+	RequireStorageClass(ctx, name)
+	classes, err := Clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to list StorageClasses: %v", err))
+	}
+	for i, class := range classes.Items {
+		if (name == "" && isDefaultStorageClass(&class)) || class.Name == name {
+			return &classes.Items[i]
+		}
+	}
+	ginkgo.Fail(fmt.Sprintf("StorageClass %q disappeared", name))
+	return nil
+}
+
+// RequireCSIDriver skips the spec unless the named CSI driver is registered with a CSIDriver object
+func RequireCSIDriver(ctx context.Context, name string) {
+	ginkgo.GinkgoHelper()
+	actual := "registered"
+	if _, err := Clientset.StorageV1().CSIDrivers().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		actual = "not registered"
+	}
+	require(Requirement{
+		Name:      "csidriver:" + name,
+		Required:  "registered",
+		Actual:    actual,
+		Satisfied: actual == "registered",
+	})
+}
+
+// RequireBlockStorageClass skips the spec unless E2E_BLOCK_STORAGE_CLASS names an existing StorageClass,
+// and returns its name
+func RequireBlockStorageClass(ctx context.Context) string {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	return requireConfiguredStorageClass(ctx, "block", "E2E_BLOCK_STORAGE_CLASS", config.Storage.BlockClass)
+}
+
+// RequireRWXStorageClass skips the spec unless E2E_RWX_STORAGE_CLASS names an existing StorageClass,
+// and returns its name
+func RequireRWXStorageClass(ctx context.Context) string {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	return requireConfiguredStorageClass(ctx, "rwx", "E2E_RWX_STORAGE_CLASS", config.Storage.RWXClass)
+}
+
+// RequireRWOPStorageClass skips the spec unless E2E_RWOP_STORAGE_CLASS names an existing StorageClass,
+// and returns its name
+func RequireRWOPStorageClass(ctx context.Context) string {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	return requireConfiguredStorageClass(ctx, "rwop", "E2E_RWOP_STORAGE_CLASS", config.Storage.RWOPClass)
+}
+
+// RequireCapacityStorageClass skips the spec unless E2E_CAPACITY_STORAGE_CLASS names an existing StorageClass,
+// and returns its name
+func RequireCapacityStorageClass(ctx context.Context) string {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	return requireConfiguredStorageClass(ctx, "capacity", "E2E_CAPACITY_STORAGE_CLASS", config.Storage.CapacityClass)
+}
+
+// requireConfiguredStorageClass skips the spec unless the StorageClass set through variable for a capability
+// is configured and exists
+func requireConfiguredStorageClass(ctx context.Context, capability, variable, name string) string {
+	ginkgo.GinkgoHelper()
+	requirement := Requirement{Name: "storage:" + capability, Required: "configured", Actual: "not configured, set " + variable}
+	if name != "" {
+		requirement.Actual = "configured"
+		requirement.Satisfied = true
+	}
+	require(requirement)
+	RequireStorageClass(ctx, name)
+	return name
+}
+
+// RequireExternalMetric skips the spec unless E2E_EXTERNAL_METRIC names a metric and the external metrics API
+// is available, and returns the metric's name
+func RequireExternalMetric(ctx context.Context) string {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	requirement := Requirement{Name: "metrics:external", Required: "configured", Actual: "not configured, set E2E_EXTERNAL_METRIC"}
+	if config.ExternalMetric != "" {
+		requirement.Actual = "configured"
+		requirement.Satisfied = true
+	}
+	require(requirement)
+	RequireAPIService(ctx, "v1beta1.external.metrics.k8s.io")
+	return config.ExternalMetric
+}
+
+// RequireNodePressure skips the spec unless E2E_NODE_PRESSURE allows pushing a node into resource pressure
+func RequireNodePressure() {
+	ginkgo.GinkgoHelper()
+	config, err := LoadRunConfig()
+	if err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	requirement := Requirement{Name: "opt-in:node-pressure", Required: "enabled", Actual: "disabled, set E2E_NODE_PRESSURE=true"}
+	if config.NodePressure {
+		requirement.Actual = "enabled"
+		requirement.Satisfied = true
+	}
+	require(requirement)
+}
+
+// RequireNodeProxy skips the spec unless the suites may reach kubelets through the API server's nodes/proxy
+// subresource, which RBAC often withholds from the plugin's service account
+func RequireNodeProxy(ctx context.Context) {
+	ginkgo.GinkgoHelper()
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "get", Resource: "nodes", Subresource: "proxy"},
+		},
+	}
+	review, err := Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to review access to nodes/proxy: %v", err))
+	}
+	actual := "allowed"
+	if !review.Status.Allowed {
+		actual = "denied"
+	}
+	require(Requirement{
+		Name:      "rbac:nodes/proxy",
+		Required:  "allowed",
+		Actual:    actual,
+		Satisfied: review.Status.Allowed,
+	})
+}
+
+// RequireReadyNodes skips the spec unless at least n schedulable nodes are Ready
+func RequireReadyNodes(ctx context.Context, n int) {
+	ginkgo.GinkgoHelper()
+	nodes, err := Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to list nodes: %v", err))
+	}
+	ready := countNodes(nodes.Items).Schedulable
+	require(Requirement{
+		Name:      "nodes:ready-schedulable",
+		Required:  ">=" + strconv.Itoa(n),
+		Actual:    strconv.Itoa(ready),
+		Satisfied: ready >= n,
+	})
+}
+
+// RequirePodRoom skips the spec unless replicas pods of spec fit both the test namespace's ResourceQuotas and
+// the free capacity of the schedulable nodes, so specs creating many pods are skipped up front instead of
+// timing out on pods that stay Pending, then waits with WaitForPodRoom for specs running in parallel to
+// release the quota they hold
+func RequirePodRoom(ctx context.Context, replicas int64, spec *v1.PodSpec) {
+	ginkgo.GinkgoHelper()
+	cost := podQuotaCost(spec)
+	// Quota held by specs running in parallel is waited for below, so only the hard limit decides the skip
+	hard, _, err := quotaRoom(ctx, TestNamespace())
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to list ResourceQuotas: %v", err))
+	}
+	quotaFits := podsFitting(hard, cost)
+	nodesFit, err := nodesFitting(ctx, cost)
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to compute free node capacity: %v", err))
+	}
+	actual := fmt.Sprintf("%d on nodes", nodesFit)
+	if quotaFits < math.MaxInt64 {
+		actual = fmt.Sprintf("%d in quota, %s", quotaFits, actual)
+	}
+	require(Requirement{
+		Name:      "capacity:pods",
+		Required:  fmt.Sprintf(">=%d pods", replicas),
+		Actual:    actual,
+		Satisfied: quotaFits >= replicas && nodesFit >= replicas,
+	})
+	if err := WaitForPodRoom(ctx, replicas, spec); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+}
+
+// RequireClaimRoom skips the spec unless the test namespace's ResourceQuotas allow claims more PVCs
+// requesting size each, e.g. 1Gi
+func RequireClaimRoom(ctx context.Context, claims int64, size string) {
+	ginkgo.GinkgoHelper()
+	cost := v1.ResourceList{
+		v1.ResourcePersistentVolumeClaims: *resource.NewQuantity(1, resource.DecimalSI),
+		v1.ResourceRequestsStorage:        resource.MustParse(size),
+	}
+	hard, _, err := quotaRoom(ctx, TestNamespace())
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("Failed to list ResourceQuotas: %v", err))
+	}
+	fits := podsFitting(hard, cost)
+	actual := "no quota"
+	if fits < math.MaxInt64 {
+		actual = fmt.Sprintf("%d in quota", fits)
+	}
+	require(Requirement{
+		Name:      "capacity:pvcs",
+		Required:  fmt.Sprintf(">=%d claims of %s", claims, size),
+		Actual:    actual,
+		Satisfied: fits >= claims,
+	})
+}
+
+// require records a requirement on the current spec and skips it when unsatisfied
+func require(requirement Requirement) {
+	ginkgo.GinkgoHelper()
+	ginkgo.AddReportEntry(requirementEntry, requirement, ginkgo.ReportEntryVisibilityNever)
+	if !requirement.Satisfied {
+		ginkgo.Skip(fmt.Sprintf("Cluster does not meet requirement %s: need %s, have %s",
+			requirement.Name, requirement.Required, requirement.Actual))
+	}
+}
+
+// DetectCapabilities collects the server version, served API groups, storage and node counts of a cluster
+func DetectCapabilities(ctx context.Context, c kubernetes.Interface) (*Capabilities, error) {
+	capabilities := &Capabilities{}
+
+	serverVersion, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	capabilities.ServerVersion = serverVersion.GitVersion
+
+	groups, err := c.Discovery().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API groups: %w", err)
+	}
+	for _, group := range groups.Groups {
+		for _, groupVersion := range group.Versions {
+			capabilities.APIGroupVersions = append(capabilities.APIGroupVersions, groupVersion.GroupVersion)
+		}
+	}
+	sort.Strings(capabilities.APIGroupVersions)
+
+	classes, err := c.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list StorageClasses: %w", err)
+	}
+	for _, class := range classes.Items {
+		capability := StorageClassCapability{
+			Name:              class.Name,
+			Provisioner:       class.Provisioner,
+			Default:           isDefaultStorageClass(&class),
+			VolumeBindingMode: string(storagev1.VolumeBindingImmediate),
+		}
+		if class.VolumeBindingMode != nil {
+			capability.VolumeBindingMode = string(*class.VolumeBindingMode)
+		}
+		if class.AllowVolumeExpansion != nil {
+			capability.AllowVolumeExpansion = *class.AllowVolumeExpansion
+		}
+		capabilities.StorageClasses = append(capabilities.StorageClasses, capability)
+	}
+
+	drivers, err := c.StorageV1().CSIDrivers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSIDrivers: %w", err)
+	}
+	for _, driver := range drivers.Items {
+		capabilities.CSIDrivers = append(capabilities.CSIDrivers, driver.Name)
+	}
+
+	nodes, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	capabilities.Nodes = countNodes(nodes.Items)
+
+	return capabilities, nil
+}
+
+// WriteRequirementsManifest writes the requirements recorded by every spec and the detected cluster
+// capabilities to RESULTS_DIR, meant to be registered with ReportAfterSuite. Nothing is written when
+// RESULTS_DIR is unset, as for local runs.
+func WriteRequirementsManifest(report ginkgo.Report) {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+
+	manifest := RequirementsManifest{
+		RunID:            RunID(),
+		Revision:         suitesRevision(),
+		FrameworkVersion: Version,
+		GeneratedAt:      time.Now().UTC(),
+		Requirements:     collectRequirements(report),
+	}
+	config, err := LoadConfig()
+	var clientset *kubernetes.Clientset
+	if err == nil {
+		clientset, err = kubernetes.NewForConfig(config)
+	}
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+		defer cancel()
+		manifest.Cluster, err = DetectCapabilities(ctx, clientset)
+	}
+	if err != nil {
+		manifest.DetectionError = err.Error()
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(resultsDir, RequirementsManifestFile), data, 0644)
+	}
+	if err != nil {
+		Logger().Error("Failed to write requirements manifest", "file", RequirementsManifestFile, "error", err)
+	}
+}
+
+// collectRequirements merges the requirement entries of all specs, sorted so manifests diff cleanly
+func collectRequirements(report ginkgo.Report) []ManifestRequirement {
+	byKey := map[string]*ManifestRequirement{}
+	for _, spec := range report.SpecReports {
+		for _, entry := range spec.ReportEntries {
+			if entry.Name != requirementEntry {
+				continue
+			}
+			// Entries from parallel processes arrive JSON-decoded, so round-trip the raw value either way
+			var requirement Requirement
+			data, err := json.Marshal(entry.Value.GetRawValue())
+			if err != nil || json.Unmarshal(data, &requirement) != nil {
+				continue
+			}
+			key := requirement.Name + "\x00" + requirement.Required
+			if byKey[key] == nil {
+				byKey[key] = &ManifestRequirement{Requirement: requirement}
+			}
+			byKey[key].Specs = append(byKey[key].Specs, spec.FullText())
+		}
+	}
+
+	requirements := make([]ManifestRequirement, 0, len(byKey))
+	for _, requirement := range byKey {
+		sort.Strings(requirement.Specs)
+		requirements = append(requirements, *requirement)
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		if requirements[i].Name != requirements[j].Name {
+			return requirements[i].Name < requirements[j].Name
+		}
+		return requirements[i].Required < requirements[j].Required
+	})
+	return requirements
+}
+
+func isDefaultStorageClass(class *storagev1.StorageClass) bool {
+	return strings.EqualFold(class.Annotations["storageclass.kubernetes.io/is-default-class"], "true")
+}
+
+func countNodes(nodes []v1.Node) NodeCapability {
+	var counts NodeCapability
+	for _, node := range nodes {
+		counts.Total++
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				ready = true
+			}
+		}
+		if ready {
+			counts.Ready++
+			if !node.Spec.Unschedulable {
+				counts.Schedulable++
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,178 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// RevisionAnnotation is set by the deployment controller on Deployments and their ReplicaSets
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Annotations the deployment controller owns, never copied from a ReplicaSet during rollback
+var rollbackSkippedAnnotations = map[string]bool{
+	RevisionAnnotation:                                 true,
+	"deployment.kubernetes.io/revision-history":        true,
+	"deployment.kubernetes.io/desired-replicas":        true,
+	"deployment.kubernetes.io/max-replicas":            true,
+	"kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// Revision returns the rollout revision recorded on a Deployment or ReplicaSet, or 0 if none
+func Revision(obj metav1.Object) int64 {
+	revision, err := strconv.ParseInt(obj.GetAnnotations()[RevisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// ListReplicaSets returns the ReplicaSets owned by the Deployment, sorted by ascending revision
+func ListReplicaSets(ctx context.Context, c kubernetes.Interface, deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, rs := range list.Items {
+		if controller := metav1.GetControllerOf(&rs); controller != nil && controller.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return Revision(&owned[i]) < Revision(&owned[j])
+	})
+	return owned, nil
+}
+
+// RolloutUndo rolls a Deployment back to toRevision, or to the previous revision when toRevision is 0,
+// following the same rules as kubectl rollout undo. It returns the updated Deployment.
+func RolloutUndo(ctx context.Context, c kubernetes.Interface, namespace, name string, toRevision int64) (*appsv1.Deployment, error) {
+	var updated *appsv1.Deployment
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deployment, err := c.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if deployment.Spec.Paused {
+			return fmt.Errorf("cannot roll back paused deployment %s/%s", namespace, name)
+		}
+
+		replicaSets, err := ListReplicaSets(ctx, c, deployment)
+		if err != nil {
+			return err
+		}
+		target, err := findRollbackTarget(replicaSets, Revision(deployment), toRevision)
+		if err != nil {
+			return fmt.Errorf("deployment %s/%s: %w", namespace, name, err)
+		}
+
+		template := target.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		if apiequality.Semantic.DeepEqual(&deployment.Spec.Template, template) {
+			return fmt.Errorf("deployment %s/%s already matches revision %d", namespace, name, Revision(target))
+		}
+
+		deployment.Spec.Template = *template
+		for key, value := range target.Annotations {
+			if rollbackSkippedAnnotations[key] {
+				continue
+			}
+			if deployment.Annotations == nil {
+				deployment.Annotations = map[string]string{}
+			}
+			deployment.Annotations[key] = value
+		}
+
+		updated, err = c.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	})
+	return updated, err
+}
+
+// findRollbackTarget picks the ReplicaSet for toRevision, or the newest one older than current when toRevision is 0
+func findRollbackTarget(replicaSets []appsv1.ReplicaSet, current, toRevision int64) (*appsv1.ReplicaSet, error) {
+	var target *appsv1.ReplicaSet
+	for i := range replicaSets {
+		revision := Revision(&replicaSets[i])
+		if toRevision == 0 && revision < current {
+			target = &replicaSets[i]
+		} else if toRevision != 0 && revision == toRevision {
+			return &replicaSets[i], nil
+		}
+	}
+	if target == nil {
+		if toRevision == 0 {
+			return nil, fmt.Errorf("no previous revision to roll back to")
+		}
+		return nil, fmt.Errorf("revision %d not found", toRevision)
+	}
+	return target, nil
+}
+
+// WaitForRolloutComplete waits until every replica of the Deployment runs the current template and is available
+func WaitForRolloutComplete(ctx context.Context, c kubernetes.Interface, namespace, name string, timeout time.Duration) (*appsv1.Deployment, error) {
+	defer recordWait(fmt.Sprintf("rollout of deployment %s/%s", namespace, name), types.NewCodeLocation(1).String(), time.Now())
+	var deployment *appsv1.Deployment
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		deployment, err = c.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		return deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.UpdatedReplicas == replicas &&
+			deployment.Status.Replicas == replicas &&
+			deployment.Status.AvailableReplicas == replicas, nil
+	})
+	if err != nil {
+		return deployment, fmt.Errorf("rollout of deployment %s/%s did not complete: %w", namespace, name, err)
+	}
+	return deployment, nil
+}
+
+// VerifyRolledBack checks that a completed Deployment runs the given pod template
+// and that the ReplicaSet holding it was promoted to the newest revision
+func VerifyRolledBack(ctx context.Context, c kubernetes.Interface, deployment *appsv1.Deployment, template *v1.PodTemplateSpec) error {
+	replicaSets, err := ListReplicaSets(ctx, c, deployment)
+	if err != nil {
+		return err
+	}
+	if len(replicaSets) == 0 {
+		return fmt.Errorf("deployment %s/%s has no ReplicaSets", deployment.Namespace, deployment.Name)
+	}
+
+	newest := replicaSets[len(replicaSets)-1]
+	current := newest.Spec.Template.DeepCopy()
+	delete(current.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if !apiequality.Semantic.DeepEqual(current, template) {
+		return fmt.Errorf("newest ReplicaSet %s does not carry the rolled back template", newest.Name)
+	}
+	if Revision(&newest) != Revision(deployment) {
+		return fmt.Errorf("deployment revision %d does not match newest ReplicaSet revision %d", Revision(deployment), Revision(&newest))
+	}
+	if newest.Status.AvailableReplicas != *deployment.Spec.Replicas {
+		return fmt.Errorf("ReplicaSet %s has %d/%d available replicas", newest.Name, newest.Status.AvailableReplicas, *deployment.Spec.Replicas)
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+package framework
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// DeletionPolicy controls how suites clean up the resources they create
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyForeground deletes dependents first and waits until the object is gone,
+	// so a spec never starts while the previous spec's pods are still terminating
+	DeletionPolicyForeground DeletionPolicy = "Foreground"
+	// DeletionPolicyBackground issues the delete and returns immediately
+	DeletionPolicyBackground DeletionPolicy = "Background"
+)
+
+// PaginationPageSize is the limit the pagination suite lists with. E2E_PAGINATION_OBJECTS must exceed it so
+// the lists span more than one page.
+const PaginationPageSize = 50
+
+// RunConfig holds the run-wide settings read from the environment
+type RunConfig struct {
+	// DeletionPolicy is read from E2E_DELETION_POLICY, defaulting to Foreground
+	DeletionPolicy DeletionPolicy
+	// DeletionTimeout bounds how long a foreground cleanup waits, read from E2E_DELETION_TIMEOUT
+	DeletionTimeout time.Duration
+	// Chaos configures the opt-in chaos adapter, read from the E2E_CHAOS_* variables
+	Chaos ChaosConfig
+	// QuotaWaitTimeout bounds how long WaitForPodRoom waits for ResourceQuota room, read from
+	// E2E_QUOTA_WAIT_TIMEOUT. Zero disables the wait.
+	QuotaWaitTimeout time.Duration
+	// ExecTimeout bounds how long ExecInPod waits for a command, read from E2E_EXEC_TIMEOUT
+	ExecTimeout time.Duration
+	// PrivateRegistry points the imagePullSecrets suite at an existing private registry, read from the
+	// E2E_PRIVATE_* variables. Without one, the suite deploys its own.
+	PrivateRegistry PrivateRegistryConfig
+	// Storage names the classes storage suites provision and snapshot with, and StorageClasses with capabilities
+	// the default one may lack, read from E2E_STORAGE_CLASS, E2E_SNAPSHOT_CLASS and the E2E_*_STORAGE_CLASS
+	// variables. Specs needing a capability are skipped when its class is not set.
+	Storage StorageConfig
+	// ExternalMetric names a metric the external metrics API serves in the test namespace, read from
+	// E2E_EXTERNAL_METRIC. HPA specs scaling on it are skipped when it is not set.
+	ExternalMetric string
+	// PaginationObjects is how many ConfigMaps the pagination suite pages through, read from
+	// E2E_PAGINATION_OBJECTS
+	PaginationObjects int
+	// NodePressure opts into specs that push a node into resource pressure so the kubelet evicts pods,
+	// read from E2E_NODE_PRESSURE
+	NodePressure bool
+	// NodeName is the node the plugin pod runs on when deployed as a daemonset plugin, read from NODE_NAME,
+	// which the plugin sets from the pod's spec.nodeName. Only node-level specs run then.
+	NodeName string
+	// Preflight decides whether failed cluster health checks stop the run, read from E2E_PREFLIGHT,
+	// defaulting to warn
+	Preflight PreflightMode
+	// Baseline quarantines specs known to fail, read from the YAML file named by E2E_BASELINE
+	Baseline *Baseline
+	// Platform selects the known issues of the baseline that apply, read from E2E_PLATFORM, e.g. eks or kind
+	Platform string
+	// LogLevel is the lowest level the framework logger emits, read from E2E_LOG_LEVEL, defaulting to info.
+	// API requests are logged at debug.
+	LogLevel slog.Level
+	// ScenarioDir holds YAML scenarios to run next to the built-in ones, read from E2E_SCENARIO_DIR
+	ScenarioDir string
+	// MaintenanceWindows restrict when disruptive and privileged specs run, read from E2E_MAINTENANCE_WINDOWS
+	// as cron expressions separated by semicolons. Without any, they run anytime.
+	MaintenanceWindows []MaintenanceWindow
+	// MaintenanceTimezone is the time zone the windows are in, read from E2E_MAINTENANCE_TIMEZONE
+	MaintenanceTimezone *time.Location
+	// Reporters are the built-in result sinks to run next to those registered with RegisterReporter, read
+	// from E2E_REPORTERS as a comma-separated list of stdout, json, junit, sonobuoy, html and openmetrics
+	Reporters []string
+	// Pushgateway is where the openmetrics reporter pushes the results of the run, read from the
+	// E2E_PUSHGATEWAY_* variables
+	Pushgateway PushgatewayConfig
+	// Notify is the webhook notified with a summary of the run once it finished, read from the
+	// E2E_NOTIFY_* variables
+	Notify NotifyConfig
+	// SpecBudgets are how long specs may take before they are flagged as slow, by label or DefaultBudget,
+	// read from E2E_SPEC_BUDGETS as comma-separated label=duration pairs
+	SpecBudgets map[string]time.Duration
+	// Perf configures the benchmarks, read from E2E_PERF and the variables of each benchmark
+	Perf PerfConfig
+	// Audit is where the audit events attached to failed specs come from, read from the E2E_AUDIT_*
+	// variables
+	Audit AuditConfig
+	// Client tunes the rate limiting and timeouts of the clients built by LoadConfig, read from the
+	// E2E_CLIENT_* variables
+	Client ClientConfig
+	// Soak repeats the run for a while to burn a cluster in, read from E2E_SOAK and E2E_SOAK_ITERATION
+	Soak SoakConfig
+}
+
+// PrivateRegistryConfig holds an image only pullable with credentials, and the registry they are for
+type PrivateRegistryConfig struct {
+	Image    string
+	Server   string
+	Username string
+	Password string
+}
+
+// StorageConfig names the StorageClasses that provision volumes with optional capabilities
+type StorageConfig struct {
+	// StorageClass is the class storage suites provision from, read from E2E_STORAGE_CLASS. Empty uses the
+	// default StorageClass.
+	StorageClass string
+	// SnapshotClass is the VolumeSnapshotClass the snapshot suite snapshots with, read from E2E_SNAPSHOT_CLASS.
+	// Empty uses the first one found.
+	SnapshotClass string
+	// BlockClass provisions raw block volumes, read from E2E_BLOCK_STORAGE_CLASS
+	BlockClass string
+	// RWXClass provisions volumes pods on different nodes can mount ReadWriteMany, read from E2E_RWX_STORAGE_CLASS
+	RWXClass string
+	// RWOPClass provisions ReadWriteOncePod volumes, which needs a CSI driver, read from E2E_RWOP_STORAGE_CLASS
+	RWOPClass string
+	// CapacityClass is a WaitForFirstConsumer class whose CSI driver publishes CSIStorageCapacity objects,
+	// read from E2E_CAPACITY_STORAGE_CLASS
+	CapacityClass string
+}
+
+// PushgatewayConfig names a Prometheus Pushgateway and the job the results are grouped under there
+type PushgatewayConfig struct {
+	// URL is the base URL of the Pushgateway, read from E2E_PUSHGATEWAY_URL
+	URL string
+	// Job is the job the metrics are pushed as, read from E2E_PUSHGATEWAY_JOB, defaulting to sonobuoy-e2e
+	Job string
+}
+
+// NotifyConfig configures the notification sent at the end of a run
+type NotifyConfig struct {
+	// URL is the webhook to post to, read from E2E_NOTIFY_WEBHOOK_URL; empty sends nothing
+	URL string
+	// Format is the payload posted, read from E2E_NOTIFY_FORMAT, defaulting to slack for Slack webhooks
+	// and json otherwise
+	Format NotifyFormat
+	// On is which runs notify, read from E2E_NOTIFY_ON
+	On NotifyOn
+	// MaxFailures caps the failed specs listed, read from E2E_NOTIFY_MAX_FAILURES
+	MaxFailures int
+}
+
+// PerfConfig configures the benchmarks, which only run when enabled
+type PerfConfig struct {
+	// Enabled opts into the benchmarks, read from E2E_PERF
+	Enabled bool
+	// Iterations is how often a benchmark repeats what it measures, read from E2E_PERF_ITERATIONS
+	Iterations int
+	// APILatencySLOs are the p99 latencies the create, get, list and delete calls of the API latency
+	// benchmark may take, read from E2E_APILATENCY_SLO as comma-separated operation=duration pairs
+	APILatencySLOs map[string]time.Duration
+	// StorageClasses are the StorageClasses the provisioning benchmark measures, read from
+	// E2E_PERF_STORAGE_CLASSES as a comma-separated list, defaulting to the one storage suites use
+	StorageClasses []string
+	// StorageIterations is how many volumes the provisioning benchmark provisions per StorageClass, read from
+	// E2E_PERF_STORAGE_ITERATIONS
+	StorageIterations int
+	// ChurnDeployments is how many Deployments the churn benchmark creates at once, read from
+	// E2E_PERF_CHURN_DEPLOYMENTS
+	ChurnDeployments int
+	// ChurnReplicas is the replicas of each Deployment of the churn benchmark, read from E2E_PERF_CHURN_REPLICAS
+	ChurnReplicas int
+	// PropagationIterations is how often the Service propagation benchmark adds and removes a backend, read
+	// from E2E_PERF_PROPAGATION_ITERATIONS
+	PropagationIterations int
+	// HPAIterations is how often the HPA reaction benchmark loads the Deployment, read from
+	// E2E_PERF_HPA_ITERATIONS
+	HPAIterations int
+}
+
+// AuditConfig names the source of the cluster's audit events
+type AuditConfig struct {
+	// LogPath is an audit log in the JSON format readable by the suites, e.g. mounted from the control
+	// plane node, read from E2E_AUDIT_LOG
+	LogPath string
+	// WebhookAddr is the address the suites receive the audit webhook backend's events on, read from
+	// E2E_AUDIT_WEBHOOK_ADDR
+	WebhookAddr string
+	// WebhookCertFile and WebhookKeyFile are the certificate and key the receiver serves TLS with, read from
+	// E2E_AUDIT_WEBHOOK_TLS_CERT_FILE and E2E_AUDIT_WEBHOOK_TLS_KEY_FILE
+	WebhookCertFile string
+	WebhookKeyFile  string
+	// WebhookToken is the bearer token the backend's webhook kubeconfig sends, read from
+	// E2E_AUDIT_WEBHOOK_TOKEN
+	WebhookToken string
+	// WebhookClientCA is a PEM file of the certificate authorities whose client certificates the backend may
+	// present instead of the token, read from E2E_AUDIT_WEBHOOK_CLIENT_CA
+	WebhookClientCA string
+	// Delay is how long a failed spec waits for the backend to flush its events, read from E2E_AUDIT_DELAY
+	Delay time.Duration
+	// MaxEvents caps the events attached to a failed spec, keeping the last ones, read from
+	// E2E_AUDIT_MAX_EVENTS
+	MaxEvents int
+}
+
+// ClientConfig tunes how hard the suites' clients may hit the API server
+type ClientConfig struct {
+	// QPS is the sustained rate of requests each client sends, read from E2E_CLIENT_QPS
+	QPS float32
+	// Burst is how many requests each client may send at once above QPS, read from E2E_CLIENT_BURST
+	Burst int
+	// Timeout bounds every request, read from E2E_CLIENT_TIMEOUT. Zero leaves requests to the spec's deadline.
+	Timeout time.Duration
+	// CABundle is a PEM file of certificate authorities trusted next to the kubeconfig's, read from
+	// E2E_CA_BUNDLE, e.g. for a proxy that intercepts TLS
+	CABundle string
+}
+
+// SoakConfig is how long run.sh repeats the run, and which repetition is running
+type SoakConfig struct {
+	// Duration repeats the run until it passed, read from E2E_SOAK as hours, minutes and seconds, e.g. 2h
+	// or 1h30m
+	Duration time.Duration
+	// Iterations repeats the run this many times instead, read from E2E_SOAK as a plain number
+	Iterations int
+	// Iteration is the repetition running, counting from 1, which run.sh sets in E2E_SOAK_ITERATION. Zero
+	// outside of a soak.
+	Iteration int
+}
+
+var (
+	runConfig     *RunConfig
+	runConfigErr  error
+	runConfigOnce sync.Once
+)
+
+// LoadRunConfig parses the run configuration once per process
+func LoadRunConfig() (*RunConfig, error) {
+	runConfigOnce.Do(func() {
+		runConfig, runConfigErr = parseRunConfig()
+	})
+	return runConfig, runConfigErr
+}
+
+func parseRunConfig() (*RunConfig, error) {
+	config := &RunConfig{
+		DeletionPolicy:   DeletionPolicyForeground,
+		DeletionTimeout:  3 * time.Minute,
+		QuotaWaitTimeout: 5 * time.Minute,
+		ExecTimeout:      time.Minute,
+		Chaos: ChaosConfig{
+			Action:               ChaosActionPodKill,
+			Duration:             30 * time.Second,
+			RecoverySLO:          2 * time.Minute,
+			LitmusServiceAccount: "litmus-admin",
+		},
+		PaginationObjects:   300,
+		Preflight:           PreflightModeWarn,
+		MaintenanceTimezone: time.UTC,
+		Pushgateway:         PushgatewayConfig{Job: "sonobuoy-e2e"},
+		Notify:              NotifyConfig{On: NotifyOnAlways, MaxFailures: 5},
+		Audit:               AuditConfig{MaxEvents: 500},
+		Perf: PerfConfig{
+			Iterations:            100,
+			StorageIterations:     10,
+			ChurnDeployments:      10,
+			ChurnReplicas:         5,
+			PropagationIterations: 5,
+			HPAIterations:         3,
+			// The API call latency SLOs of Kubernetes' scalability SIG
+			APILatencySLOs: map[string]time.Duration{
+				"create": time.Second,
+				"get":    time.Second,
+				"list":   5 * time.Second,
+				"delete": time.Second,
+			},
+		},
+		Client: ClientConfig{
+			QPS:   rest.DefaultQPS,
+			Burst: rest.DefaultBurst,
+		},
+	}
+
+	if policy := os.Getenv("E2E_DELETION_POLICY"); policy != "" {
+		switch DeletionPolicy(policy) {
+		case DeletionPolicyForeground, DeletionPolicyBackground:
+			config.DeletionPolicy = DeletionPolicy(policy)
+		default:
+			return nil, fmt.Errorf("invalid E2E_DELETION_POLICY %q: must be %s or %s", policy, DeletionPolicyForeground, DeletionPolicyBackground)
+		}
+	}
+
+	if mode := os.Getenv("E2E_PREFLIGHT"); mode != "" {
+		switch PreflightMode(mode) {
+		case PreflightModeFail, PreflightModeWarn, PreflightModeOff:
+			config.Preflight = PreflightMode(mode)
+		default:
+			return nil, fmt.Errorf("invalid E2E_PREFLIGHT %q: must be %s, %s or %s", mode, PreflightModeFail, PreflightModeWarn, PreflightModeOff)
+		}
+	}
+
+	if level := os.Getenv("E2E_LOG_LEVEL"); level != "" {
+		if err := config.LogLevel.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid E2E_LOG_LEVEL %q: must be debug, info, warn or error", level)
+		}
+	}
+
+	if timeout := os.Getenv("E2E_DELETION_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid E2E_DELETION_TIMEOUT %q: %v", timeout, err)
+		}
+		config.DeletionTimeout = duration
+	}
+
+	if engine := os.Getenv("E2E_CHAOS_ENGINE"); engine != "" {
+		switch ChaosEngine(engine) {
+		case ChaosEngineChaosMesh, ChaosEngineLitmus:
+			config.Chaos.Engine = ChaosEngine(engine)
+		default:
+			return nil, fmt.Errorf("invalid E2E_CHAOS_ENGINE %q: must be %s or %s", engine, ChaosEngineChaosMesh, ChaosEngineLitmus)
+		}
+	}
+	if action := os.Getenv("E2E_CHAOS_EXPERIMENT"); action != "" {
+		switch ChaosAction(action) {
+		case ChaosActionPodKill, ChaosActionNetworkDelay:
+			config.Chaos.Action = ChaosAction(action)
+		default:
+			return nil, fmt.Errorf("invalid E2E_CHAOS_EXPERIMENT %q: must be %s or %s", action, ChaosActionPodKill, ChaosActionNetworkDelay)
+		}
+	}
+	for name, target := range map[string]*time.Duration{
+		"E2E_QUOTA_WAIT_TIMEOUT": &config.QuotaWaitTimeout,
+		"E2E_EXEC_TIMEOUT":       &config.ExecTimeout,
+		"E2E_CHAOS_DURATION":     &config.Chaos.Duration,
+		"E2E_CHAOS_RECOVERY_SLO": &config.Chaos.RecoverySLO,
+		"E2E_CLIENT_TIMEOUT":     &config.Client.Timeout,
+		"E2E_AUDIT_DELAY":        &config.Audit.Delay,
+	} {
+		if value := os.Getenv(name); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %v", name, value, err)
+			}
+			*target = duration
+		}
+	}
+	if qps := os.Getenv("E2E_CLIENT_QPS"); qps != "" {
+		value, err := strconv.ParseFloat(qps, 32)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid E2E_CLIENT_QPS %q: must be a positive number", qps)
+		}
+		config.Client.QPS = float32(value)
+	}
+	for name, target := range map[string]*int{
+		"E2E_CLIENT_BURST":        &config.Client.Burst,
+		"E2E_NOTIFY_MAX_FAILURES": &config.Notify.MaxFailures,
+		"E2E_AUDIT_MAX_EVENTS":    &config.Audit.MaxEvents,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s %q: must be a non-negative integer", name, value)
+			}
+			*target = n
+		}
+	}
+	config.Client.CABundle = os.Getenv("E2E_CA_BUNDLE")
+	for name, target := range map[string]*map[string]time.Duration{
+		"E2E_SPEC_BUDGETS":   &config.SpecBudgets,
+		"E2E_APILATENCY_SLO": &config.Perf.APILatencySLOs,
+	} {
+		if err := parseDurationMap(name, target); err != nil {
+			return nil, err
+		}
+	}
+	if perf := os.Getenv("E2E_PERF"); perf != "" {
+		enabled, err := strconv.ParseBool(perf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid E2E_PERF %q: %v", perf, err)
+		}
+		config.Perf.Enabled = enabled
+	}
+	for name, target := range map[string]*int{
+		"E2E_PERF_ITERATIONS":             &config.Perf.Iterations,
+		"E2E_PERF_STORAGE_ITERATIONS":     &config.Perf.StorageIterations,
+		"E2E_PERF_CHURN_DEPLOYMENTS":      &config.Perf.ChurnDeployments,
+		"E2E_PERF_CHURN_REPLICAS":         &config.Perf.ChurnReplicas,
+		"E2E_PERF_PROPAGATION_ITERATIONS": &config.Perf.PropagationIterations,
+		"E2E_PERF_HPA_ITERATIONS":         &config.Perf.HPAIterations,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid %s %q: must be a positive integer", name, value)
+			}
+			*target = n
+		}
+	}
+	if soak := os.Getenv("E2E_SOAK"); soak != "" {
+		// run.sh does the repeating, so only the units it reads are accepted
+		if n, err := strconv.Atoi(soak); err == nil && n > 0 {
+			config.Soak.Iterations = n
+		} else if duration, err := time.ParseDuration(soak); err == nil && duration > 0 && soakDuration.MatchString(soak) {
+			config.Soak.Duration = duration
+		} else {
+			return nil, fmt.Errorf("invalid E2E_SOAK %q: must be a number of iterations or a duration in whole hours, minutes and seconds, e.g. 2h", soak)
+		}
+	}
+	if iteration := os.Getenv("E2E_SOAK_ITERATION"); iteration != "" {
+		n, err := strconv.Atoi(iteration)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid E2E_SOAK_ITERATION %q: must be a positive integer", iteration)
+		}
+		config.Soak.Iteration = n
+	}
+	for _, class := range strings.Split(os.Getenv("E2E_PERF_STORAGE_CLASSES"), ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			config.Perf.StorageClasses = append(config.Perf.StorageClasses, class)
+		}
+	}
+	config.Audit.LogPath = os.Getenv("E2E_AUDIT_LOG")
+	config.Audit.WebhookAddr = os.Getenv("E2E_AUDIT_WEBHOOK_ADDR")
+	config.Audit.WebhookCertFile = os.Getenv("E2E_AUDIT_WEBHOOK_TLS_CERT_FILE")
+	config.Audit.WebhookKeyFile = os.Getenv("E2E_AUDIT_WEBHOOK_TLS_KEY_FILE")
+	config.Audit.WebhookToken = os.Getenv("E2E_AUDIT_WEBHOOK_TOKEN")
+	config.Audit.WebhookClientCA = os.Getenv("E2E_AUDIT_WEBHOOK_CLIENT_CA")
+	if audit := config.Audit; audit.WebhookAddr != "" {
+		// The events carry request URIs and user names, so only an authenticated backend may send them
+		if audit.WebhookCertFile == "" || audit.WebhookKeyFile == "" {
+			return nil, fmt.Errorf("invalid E2E_AUDIT_WEBHOOK_ADDR %q: E2E_AUDIT_WEBHOOK_TLS_CERT_FILE and E2E_AUDIT_WEBHOOK_TLS_KEY_FILE are required", audit.WebhookAddr)
+		}
+		if audit.WebhookToken == "" && audit.WebhookClientCA == "" {
+			return nil, fmt.Errorf("invalid E2E_AUDIT_WEBHOOK_ADDR %q: E2E_AUDIT_WEBHOOK_TOKEN or E2E_AUDIT_WEBHOOK_CLIENT_CA is required", audit.WebhookAddr)
+		}
+	}
+	if names := os.Getenv("E2E_REPORTERS"); names != "" {
+		for _, name := range strings.Split(names, ",") {
+			switch name = strings.TrimSpace(name); name {
+			case ReporterStdout, ReporterJSON, ReporterJUnit, ReporterSonobuoy, ReporterHTML, ReporterOpenMetrics:
+				config.Reporters = append(config.Reporters, name)
+			case "":
+			default:
+				return nil, fmt.Errorf("invalid E2E_REPORTERS %q: %q is not one of %s, %s, %s, %s, %s or %s",
+					names, name, ReporterStdout, ReporterJSON, ReporterJUnit, ReporterSonobuoy, ReporterHTML, ReporterOpenMetrics)
+			}
+		}
+	}
+	if gateway := os.Getenv("E2E_PUSHGATEWAY_URL"); gateway != "" {
+		if parsed, err := url.Parse(gateway); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid E2E_PUSHGATEWAY_URL %q: must be an absolute URL", gateway)
+		}
+		config.Pushgateway.URL = gateway
+		if !slices.Contains(config.Reporters, ReporterOpenMetrics) {
+			config.Reporters = append(config.Reporters, ReporterOpenMetrics)
+		}
+	}
+	if job := os.Getenv("E2E_PUSHGATEWAY_JOB"); job != "" {
+		config.Pushgateway.Job = job
+	}
+	if webhook := os.Getenv("E2E_NOTIFY_WEBHOOK_URL"); webhook != "" {
+		parsed, err := url.Parse(webhook)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			// The URL of a webhook is its credential, so it is left out of the error
+			return nil, fmt.Errorf("invalid E2E_NOTIFY_WEBHOOK_URL: must be an absolute URL")
+		}
+		config.Notify.URL = webhook
+		config.Notify.Format = NotifyFormatJSON
+		if parsed.Host == "hooks.slack.com" {
+			config.Notify.Format = NotifyFormatSlack
+		}
+	}
+	if format := os.Getenv("E2E_NOTIFY_FORMAT"); format != "" {
+		switch NotifyFormat(format) {
+		case NotifyFormatSlack, NotifyFormatJSON:
+			config.Notify.Format = NotifyFormat(format)
+		default:
+			return nil, fmt.Errorf("invalid E2E_NOTIFY_FORMAT %q: must be %s or %s", format, NotifyFormatSlack, NotifyFormatJSON)
+		}
+	}
+	if on := os.Getenv("E2E_NOTIFY_ON"); on != "" {
+		switch NotifyOn(on) {
+		case NotifyOnAlways, NotifyOnFailure:
+			config.Notify.On = NotifyOn(on)
+		default:
+			return nil, fmt.Errorf("invalid E2E_NOTIFY_ON %q: must be %s or %s", on, NotifyOnAlways, NotifyOnFailure)
+		}
+	}
+	if account := os.Getenv("E2E_LITMUS_SERVICE_ACCOUNT"); account != "" {
+		config.Chaos.LitmusServiceAccount = account
+	}
+
+	config.NodeName = os.Getenv("NODE_NAME")
+	config.Platform = os.Getenv("E2E_PLATFORM")
+	if path := os.Getenv("E2E_BASELINE"); path != "" {
+		baseline, err := LoadBaseline(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid E2E_BASELINE %q: %v", path, err)
+		}
+		config.Baseline = baseline
+	}
+	config.ScenarioDir = os.Getenv("E2E_SCENARIO_DIR")
+	config.Storage.StorageClass = os.Getenv("E2E_STORAGE_CLASS")
+	config.Storage.SnapshotClass = os.Getenv("E2E_SNAPSHOT_CLASS")
+	config.Storage.BlockClass = os.Getenv("E2E_BLOCK_STORAGE_CLASS")
+	config.Storage.RWXClass = os.Getenv("E2E_RWX_STORAGE_CLASS")
+	config.Storage.RWOPClass = os.Getenv("E2E_RWOP_STORAGE_CLASS")
+	config.Storage.CapacityClass = os.Getenv("E2E_CAPACITY_STORAGE_CLASS")
+	config.ExternalMetric = os.Getenv("E2E_EXTERNAL_METRIC")
+	if objects := os.Getenv("E2E_PAGINATION_OBJECTS"); objects != "" {
+		n, err := strconv.Atoi(objects)
+		if err != nil || n <= PaginationPageSize {
+			return nil, fmt.Errorf("invalid E2E_PAGINATION_OBJECTS %q: must be an integer greater than the page size of %d", objects, PaginationPageSize)
+		}
+		config.PaginationObjects = n
+	}
+	if pressure := os.Getenv("E2E_NODE_PRESSURE"); pressure != "" {
+		enabled, err := strconv.ParseBool(pressure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid E2E_NODE_PRESSURE %q: %v", pressure, err)
+		}
+		config.NodePressure = enabled
+	}
+
+	config.PrivateRegistry = PrivateRegistryConfig{
+		Image:    os.Getenv("E2E_PRIVATE_IMAGE"),
+		Server:   os.Getenv("E2E_PRIVATE_REGISTRY"),
+		Username: os.Getenv("E2E_PRIVATE_REGISTRY_USERNAME"),
+		Password: os.Getenv("E2E_PRIVATE_REGISTRY_PASSWORD"),
+	}
+	if registry := &config.PrivateRegistry; registry.Image != "" {
+		if registry.Username == "" || registry.Password == "" {
+			return nil, fmt.Errorf("invalid E2E_PRIVATE_IMAGE %q: E2E_PRIVATE_REGISTRY_USERNAME and E2E_PRIVATE_REGISTRY_PASSWORD are required", registry.Image)
+		}
+		if registry.Server == "" {
+			registry.Server = strings.SplitN(registry.Image, "/", 2)[0]
+		}
+	}
+
+	if windows := os.Getenv("E2E_MAINTENANCE_WINDOWS"); windows != "" {
+		for _, expression := range strings.Split(windows, ";") {
+			if strings.TrimSpace(expression) == "" {
+				continue
+			}
+			window, err := ParseMaintenanceWindow(strings.TrimSpace(expression))
+			if err != nil {
+				return nil, fmt.Errorf("invalid E2E_MAINTENANCE_WINDOWS %q: %v", windows, err)
+			}
+			config.MaintenanceWindows = append(config.MaintenanceWindows, window)
+		}
+	}
+	if timezone := os.Getenv("E2E_MAINTENANCE_TIMEZONE"); timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid E2E_MAINTENANCE_TIMEZONE %q: %v", timezone, err)
+		}
+		config.MaintenanceTimezone = location
+	}
+	return config, nil
+}
+
+// parseDurationMap adds the comma-separated key=duration pairs of the variable name to durations, replacing
+// the defaults of the keys it sets
+func parseDurationMap(name string, durations *map[string]time.Duration) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	if *durations == nil {
+		*durations = map[string]time.Duration{}
+	}
+	for _, pair := range strings.Split(value, ",") {
+		key, text, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		duration, err := time.ParseDuration(text)
+		if !ok || key == "" || err != nil || duration <= 0 {
+			return fmt.Errorf("invalid %s %q: %q is not a key=duration pair with a positive duration", name, value, pair)
+		}
+		(*durations)[key] = duration
+	}
+	return nil
+}
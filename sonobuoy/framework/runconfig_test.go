@@ -0,0 +1,36 @@
+package framework
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRunConfigPaginationObjects(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+		err   bool
+	}{
+		{value: "", want: 300},
+		{value: "51", want: 51},
+		{value: "1000", want: 1000},
+		{value: "50", err: true},
+		{value: "0", err: true},
+		{value: "-1", err: true},
+		{value: "many", err: true},
+	}
+	for _, test := range tests {
+		t.Setenv("E2E_PAGINATION_OBJECTS", test.value)
+		config, err := parseRunConfig()
+		switch {
+		case test.err:
+			if err == nil || !strings.Contains(err.Error(), "E2E_PAGINATION_OBJECTS") {
+				t.Errorf("parseRunConfig() with E2E_PAGINATION_OBJECTS=%q = %v, want an error", test.value, err)
+			}
+		case err != nil:
+			t.Errorf("parseRunConfig() with E2E_PAGINATION_OBJECTS=%q = %v", test.value, err)
+		case config.PaginationObjects != test.want:
+			t.Errorf("E2E_PAGINATION_OBJECTS=%q gave %d objects, want %d", test.value, config.PaginationObjects, test.want)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// Scenario is an end-to-end workflow written in YAML rather than Go, so QA engineers can add one without
+// touching the suites. Its steps run in order as a single spec; see DescribeScenarios.
+type Scenario struct {
+	Name string `json:"name"`
+	// Labels are added to the spec, e.g. disruptive to keep it within maintenance windows
+	Labels []string       `json:"labels,omitempty"`
+	Steps  []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep does exactly one of its actions
+type ScenarioStep struct {
+	Name string `json:"name,omitempty"`
+	// Apply server-side applies the manifest, creating the object or updating the fields it lists. Each step
+	// is a field manager of its own, so a later step listing a few fields leaves the others as they are.
+	Apply  map[string]interface{} `json:"apply,omitempty"`
+	Wait   *ScenarioWait          `json:"wait,omitempty"`
+	Exec   *ScenarioExec          `json:"exec,omitempty"`
+	Assert *ScenarioCheck         `json:"assert,omitempty"`
+	Delete *ScenarioObjects       `json:"delete,omitempty"`
+}
+
+// ScenarioObjects selects objects of a kind by name or label selector. Namespaced kinds default to the
+// test namespace.
+type ScenarioObjects struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Selector   string `json:"selector,omitempty"`
+}
+
+// ScenarioCheck holds for the selected objects when there are Count of them, or at least one without
+// Count, and every one has the status Condition True and Field equal to Equals
+type ScenarioCheck struct {
+	ScenarioObjects
+	Count     *int        `json:"count,omitempty"`
+	Condition string      `json:"condition,omitempty"`
+	Field     string      `json:"field,omitempty"`
+	Equals    interface{} `json:"equals,omitempty"`
+}
+
+// ScenarioWait waits for a check to hold, for Timeout or 2m
+type ScenarioWait struct {
+	ScenarioCheck
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// ScenarioExec runs Command in the named pod, or the first running pod matching Selector, and checks
+// that its output Contains a string
+type ScenarioExec struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Pod       string   `json:"pod,omitempty"`
+	Selector  string   `json:"selector,omitempty"`
+	Container string   `json:"container,omitempty"`
+	Command   []string `json:"command"`
+	Contains  string   `json:"contains,omitempty"`
+}
+
+// ScenarioData is available to scenario files as a text/template, e.g. name: app-{{ .Suffix }}
+type ScenarioData struct {
+	// Namespace is the test namespace
+	Namespace string
+	// Suffix is unique to the spec run, for names that must not collide with other runs
+	Suffix string
+}
+
+// ParseScenario renders a scenario file with data and parses it, rejecting unknown fields and steps
+// that do not do exactly one thing
+func ParseScenario(source []byte, data ScenarioData) (*Scenario, error) {
+	tmpl, err := template.New("scenario").Option("missingkey=error").Parse(string(source))
+	if err != nil {
+		return nil, err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, err
+	}
+	raw, err := yaml.ToJSON(rendered.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	scenario := &Scenario{}
+	if err := decoder.Decode(scenario); err != nil {
+		return nil, err
+	}
+
+	if scenario.Name == "" {
+		return nil, fmt.Errorf("scenario has no name")
+	}
+	if len(scenario.Steps) == 0 {
+		return nil, fmt.Errorf("scenario %q has no steps", scenario.Name)
+	}
+	for i, step := range scenario.Steps {
+		actions := 0
+		for _, set := range []bool{step.Apply != nil, step.Wait != nil, step.Exec != nil, step.Assert != nil, step.Delete != nil} {
+			if set {
+				actions++
+			}
+		}
+		if actions != 1 {
+			return nil, fmt.Errorf("scenario %q step %d must have exactly one of apply, wait, exec, assert or delete", scenario.Name, i+1)
+		}
+		if step.Wait != nil && step.Wait.Timeout != "" {
+			if _, err := time.ParseDuration(step.Wait.Timeout); err != nil {
+				return nil, fmt.Errorf("scenario %q step %d: invalid timeout %q: %v", scenario.Name, i+1, step.Wait.Timeout, err)
+			}
+		}
+	}
+	return scenario, nil
+}
+
+// DescribeScenarios registers a spec for every *.yaml scenario in fsys, labeled with the scenario's labels.
+// A file that does not parse registers a failing spec instead, so a broken scenario cannot go unnoticed.
+func DescribeScenarios(fsys fs.FS) bool {
+	paths, _ := fs.Glob(fsys, "*.yaml")
+	for _, path := range paths {
+		path := path
+		source, err := fs.ReadFile(fsys, path)
+		var scenario *Scenario
+		if err == nil {
+			scenario, err = ParseScenario(source, ScenarioData{Namespace: TestNamespace(), Suffix: "0"})
+		}
+		if err != nil {
+			ginkgo.It(fmt.Sprintf("Scenario %s should be valid", path), func() {
+				ginkgo.Fail(fmt.Sprintf("Invalid scenario %s: %v", path, err))
+			})
+			continue
+		}
+
+		ginkgo.Describe("Scenario: "+scenario.Name, ginkgo.Label(scenario.Labels...), func() {
+			ginkgo.It("should complete every step", func(ctx ginkgo.SpecContext) {
+				data := ScenarioData{Namespace: TestNamespace(), Suffix: strconv.FormatInt(time.Now().UnixNano(), 10)}
+				scenario, err := ParseScenario(source, data)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to parse scenario %s", path)
+				RunScenario(ctx, scenario)
+			})
+		})
+	}
+	return true
+}
+
+// RunScenario runs the steps of scenario in the current spec, reporting each one with By. Objects the
+// steps create are deleted when the spec ends.
+func RunScenario(ctx context.Context, scenario *Scenario) {
+	ginkgo.GinkgoHelper()
+	runner := &scenarioRunner{
+		mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(Clientset.Discovery())),
+	}
+	for i, step := range scenario.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		ginkgo.By(name)
+		gomega.Expect(runner.run(ctx, i, step)).To(gomega.Succeed(), "Scenario %q failed at %s", scenario.Name, name)
+	}
+}
+
+// Field manager of the objects the i-th step of a scenario applies
+func scenarioFieldManager(i int) string {
+	return fmt.Sprintf("sonobuoy-e2e-scenario-step-%d", i+1)
+}
+
+type scenarioRunner struct {
+	mapper meta.RESTMapper
+}
+
+// run runs the i-th step of a scenario
+func (r *scenarioRunner) run(ctx context.Context, i int, step ScenarioStep) error {
+	switch {
+	case step.Apply != nil:
+		return r.apply(ctx, scenarioFieldManager(i), step.Apply)
+	case step.Wait != nil:
+		timeout := 2 * time.Minute
+		if step.Wait.Timeout != "" {
+			timeout, _ = time.ParseDuration(step.Wait.Timeout)
+		}
+		var err error
+		deadline := time.Now().Add(timeout)
+		for {
+			if err = r.check(ctx, step.Wait.ScenarioCheck); err == nil || time.Now().After(deadline) {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+	case step.Exec != nil:
+		return r.exec(ctx, step.Exec)
+	case step.Assert != nil:
+		return r.check(ctx, *step.Assert)
+	default:
+		return r.delete(ctx, step.Delete)
+	}
+}
+
+// resource returns the client for the selected kind, scoped to its namespace if it has one
+func (r *scenarioRunner) resource(objects ScenarioObjects) (dynamic.ResourceInterface, error) {
+	gv, err := schema.ParseGroupVersion(objects.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := r.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: objects.Kind}, gv.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return DynamicClient.Resource(mapping.Resource), nil
+	}
+	namespace := objects.Namespace
+	if namespace == "" {
+		namespace = TestNamespace()
+	}
+	return DynamicClient.Resource(mapping.Resource).Namespace(namespace), nil
+}
+
+// list returns the selected objects; a named object that does not exist selects nothing
+func (r *scenarioRunner) list(ctx context.Context, objects ScenarioObjects) (dynamic.ResourceInterface, []unstructured.Unstructured, error) {
+	resource, err := r.resource(objects)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case objects.Name != "":
+		obj, err := resource.Get(ctx, objects.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return resource, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return resource, []unstructured.Unstructured{*obj}, nil
+	case objects.Selector != "":
+		list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: objects.Selector})
+		if err != nil {
+			return nil, nil, err
+		}
+		return resource, list.Items, nil
+	default:
+		return nil, nil, fmt.Errorf("%s needs a name or a selector", objects.Kind)
+	}
+}
+
+func (r *scenarioRunner) apply(ctx context.Context, fieldManager string, manifest map[string]interface{}) error {
+	obj := &unstructured.Unstructured{Object: manifest}
+	resource, err := r.resource(ScenarioObjects{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Namespace: obj.GetNamespace()})
+	if err != nil {
+		return err
+	}
+
+	_, err = resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	created := apierrors.IsNotFound(err)
+	if err != nil && !created {
+		return err
+	}
+	// A step states what the fields it lists should be, so it takes them over from other managers
+	if _, err := resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: fieldManager, Force: true}); err != nil {
+		return err
+	}
+	if created {
+		ginkgo.DeferCleanup(func(ctx ginkgo.SpecContext) {
+			err := Cleanup(ctx, Dynamic(resource), obj.GetName())
+			gomega.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to delete %s %s", obj.GetKind(), obj.GetName())
+		})
+	}
+	return nil
+}
+
+func (r *scenarioRunner) check(ctx context.Context, check ScenarioCheck) error {
+	_, objects, err := r.list(ctx, check.ScenarioObjects)
+	if err != nil {
+		return err
+	}
+	return checkObjects(check, objects)
+}
+
+// checkObjects reports why check does not hold for the objects it selected, if it does not
+func checkObjects(check ScenarioCheck, objects []unstructured.Unstructured) error {
+	if check.Count != nil && len(objects) != *check.Count {
+		return fmt.Errorf("found %d %s, expected %d", len(objects), check.Kind, *check.Count)
+	}
+	if check.Count == nil && len(objects) == 0 {
+		return fmt.Errorf("found no %s", check.Kind)
+	}
+
+	for _, obj := range objects {
+		if check.Condition != "" && !hasTrueCondition(&obj, check.Condition) {
+			return fmt.Errorf("%s %s does not have condition %s", check.Kind, obj.GetName(), check.Condition)
+		}
+		if check.Field != "" {
+			value, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(strings.TrimPrefix(check.Field, "."), ".")...)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("%s %s has no %s", check.Kind, obj.GetName(), check.Field)
+			}
+			if fmt.Sprint(value) != fmt.Sprint(check.Equals) {
+				return fmt.Errorf("%s %s has %s %v, expected %v", check.Kind, obj.GetName(), check.Field, value, check.Equals)
+			}
+		}
+	}
+	return nil
+}
+
+// hasTrueCondition reports whether obj has the status condition of the given type set to True
+func hasTrueCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if ok && fields["type"] == conditionType && fields["status"] == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *scenarioRunner) exec(ctx context.Context, exec *ScenarioExec) error {
+	namespace := exec.Namespace
+	if namespace == "" {
+		namespace = TestNamespace()
+	}
+	pod := exec.Pod
+	if pod == "" {
+		pods, err := Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: exec.Selector})
+		if err != nil {
+			return err
+		}
+		for _, candidate := range pods.Items {
+			if candidate.Status.Phase == v1.PodRunning && candidate.DeletionTimestamp == nil {
+				pod = candidate.Name
+				break
+			}
+		}
+		if pod == "" {
+			return fmt.Errorf("no running pod matches %q", exec.Selector)
+		}
+	}
+
+	result, err := ExecInPod(ctx, namespace, pod, exec.Container, exec.Command...)
+	if err != nil {
+		return fmt.Errorf("%v\nstdout: %s\nstderr: %s", err, result.Stdout, result.Stderr)
+	}
+	if !strings.Contains(result.Stdout, exec.Contains) {
+		return fmt.Errorf("output of %v in pod %s does not contain %q:\n%s", exec.Command, pod, exec.Contains, result.Stdout)
+	}
+	return nil
+}
+
+func (r *scenarioRunner) delete(ctx context.Context, objects *ScenarioObjects) error {
+	resource, selected, err := r.list(ctx, *objects)
+	if err != nil {
+		return err
+	}
+	for _, obj := range selected {
+		if err := Cleanup(ctx, Dynamic(resource), obj.GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
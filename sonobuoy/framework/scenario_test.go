@@ -0,0 +1,134 @@
+package framework
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseScenario(t *testing.T) {
+	source := `name: app {{ .Suffix }}
+labels: [disruptive]
+steps:
+- apply:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: data-{{ .Suffix }}
+      namespace: {{ .Namespace }}
+- wait:
+    apiVersion: v1
+    kind: ConfigMap
+    name: data-{{ .Suffix }}
+    timeout: 30s
+`
+	scenario, err := ParseScenario([]byte(source), ScenarioData{Namespace: "e2e", Suffix: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scenario.Name != "app 42" || len(scenario.Steps) != 2 || len(scenario.Labels) != 1 {
+		t.Fatalf("parsed %+v", scenario)
+	}
+	metadata := scenario.Steps[0].Apply["metadata"].(map[string]interface{})
+	if metadata["name"] != "data-42" || metadata["namespace"] != "e2e" {
+		t.Errorf("apply step metadata %v was not rendered with the data", metadata)
+	}
+	if wait := scenario.Steps[1].Wait; wait == nil || wait.Name != "data-42" || wait.Timeout != "30s" {
+		t.Errorf("wait step %+v", wait)
+	}
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "template", source: "name: {{ .Suffix", want: "unclosed action"},
+		{name: "missing data", source: "name: {{ .Cluster }}", want: "Cluster"},
+		{name: "yaml", source: "name: [unclosed", want: "yaml"},
+		{name: "unknown field", source: "name: x\nstep: []", want: `unknown field "step"`},
+		{name: "unknown step field", source: "name: x\nsteps:\n- exec: {command: [ls]}\n  retry: 3", want: `unknown field "retry"`},
+		{name: "no name", source: "steps:\n- exec: {command: [ls]}", want: "no name"},
+		{name: "no steps", source: "name: x", want: "has no steps"},
+		{name: "empty step", source: "name: x\nsteps:\n- name: nothing", want: "step 1 must have exactly one"},
+		{
+			name:   "two actions",
+			source: "name: x\nsteps:\n- exec: {command: [ls]}\n- exec: {command: [ls]}\n  delete: {apiVersion: v1, kind: Pod, name: p}",
+			want:   "step 2 must have exactly one",
+		},
+		{name: "timeout", source: "name: x\nsteps:\n- wait: {apiVersion: v1, kind: Pod, name: p, timeout: soon}", want: `invalid timeout "soon"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(test.source), ScenarioData{Namespace: "e2e", Suffix: "1"})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseScenario() = %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckObjects(t *testing.T) {
+	pod := func(name, phase string, ready string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+			"status": map[string]interface{}{
+				"phase":      phase,
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": ready}},
+			},
+		}}
+		return obj
+	}
+	count := func(n int) *int { return &n }
+	running := []unstructured.Unstructured{pod("a", "Running", "True"), pod("b", "Running", "True")}
+	objects := ScenarioObjects{APIVersion: "v1", Kind: "Pod", Selector: "app=x"}
+
+	tests := []struct {
+		name    string
+		check   ScenarioCheck
+		objects []unstructured.Unstructured
+		want    string
+	}{
+		{name: "any", check: ScenarioCheck{ScenarioObjects: objects}, objects: running},
+		{name: "none", check: ScenarioCheck{ScenarioObjects: objects}, want: "found no Pod"},
+		{name: "count", check: ScenarioCheck{ScenarioObjects: objects, Count: count(2)}, objects: running},
+		{name: "zero count", check: ScenarioCheck{ScenarioObjects: objects, Count: count(0)}},
+		{name: "wrong count", check: ScenarioCheck{ScenarioObjects: objects, Count: count(3)}, objects: running, want: "found 2 Pod, expected 3"},
+		{name: "condition", check: ScenarioCheck{ScenarioObjects: objects, Condition: "Ready"}, objects: running},
+		{
+			name:    "condition not true",
+			check:   ScenarioCheck{ScenarioObjects: objects, Condition: "Ready"},
+			objects: append(running, pod("c", "Running", "False")),
+			want:    "Pod c does not have condition Ready",
+		},
+		{name: "missing condition", check: ScenarioCheck{ScenarioObjects: objects, Condition: "Initialized"}, objects: running, want: "does not have condition Initialized"},
+		{name: "field", check: ScenarioCheck{ScenarioObjects: objects, Field: "status.phase", Equals: "Running"}, objects: running},
+		{name: "leading dot", check: ScenarioCheck{ScenarioObjects: objects, Field: ".status.phase", Equals: "Running"}, objects: running},
+		// YAML numbers decode as float64 while objects hold int64
+		{name: "number", check: ScenarioCheck{ScenarioObjects: objects, Field: "spec.replicas", Equals: float64(3)}, objects: running},
+		{
+			name:    "field differs",
+			check:   ScenarioCheck{ScenarioObjects: objects, Field: "status.phase", Equals: "Running"},
+			objects: append(running, pod("c", "Pending", "False")),
+			want:    "Pod c has status.phase Pending, expected Running",
+		},
+		{name: "missing field", check: ScenarioCheck{ScenarioObjects: objects, Field: "status.podIP", Equals: "10.0.0.1"}, objects: running, want: "Pod a has no status.podIP"},
+		{name: "field through a list", check: ScenarioCheck{ScenarioObjects: objects, Field: "status.conditions.type", Equals: "Ready"}, objects: running, want: "status.conditions"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkObjects(test.check, test.objects)
+			switch {
+			case test.want == "" && err != nil:
+				t.Errorf("checkObjects() = %v, want nil", err)
+			case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+				t.Errorf("checkObjects() = %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ServerInfo is what the API server reported about itself when the suite started
+type ServerInfo struct {
+	// GitVersion is the version as the server reports it, e.g. v1.28.3+k3s1
+	GitVersion string
+	// Version is GitVersion parsed for comparisons
+	Version *version.Version
+	// resources holds the names of the served resources and subresources by group version
+	resources map[string]sets.Set[string]
+}
+
+// serverInfo is detected by SetupSuite, once per process
+var serverInfo *ServerInfo
+
+// DetectServer queries the server version and the resources it serves. Group versions of aggregated API
+// servers that fail discovery count as not served rather than failing detection.
+func DetectServer(c kubernetes.Interface) (*ServerInfo, error) {
+	serverVersion, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	parsed, err := version.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server version %q: %w", serverVersion.GitVersion, err)
+	}
+	info := &ServerInfo{GitVersion: serverVersion.GitVersion, Version: parsed, resources: map[string]sets.Set[string]{}}
+
+	_, lists, err := c.Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover served resources: %w", err)
+	}
+	for _, list := range lists {
+		names := sets.New[string]()
+		for _, resource := range list.APIResources {
+			names.Insert(resource.Name)
+		}
+		info.resources[list.GroupVersion] = names
+	}
+	return info, nil
+}
+
+// AtLeast reports whether the server is at least minimum, e.g. 1.27
+func (s *ServerInfo) AtLeast(minimum string) bool {
+	return s.Version.AtLeast(version.MustParseGeneric(minimum))
+}
+
+// HasResource reports whether the server serves resource, e.g. volumesnapshots or pods/ephemeralcontainers,
+// in groupVersion, e.g. snapshot.storage.k8s.io/v1 or v1 for the core group
+func (s *ServerInfo) HasResource(groupVersion, resource string) bool {
+	return s.resources[groupVersion].Has(resource)
+}
+
+// Server returns what SetupSuite detected about the API server
+func Server() *ServerInfo {
+	ginkgo.GinkgoHelper()
+	if serverInfo == nil {
+		ginkgo.Fail("API server was not detected; register framework.SetupSuite with BeforeSuite")
+	}
+	return serverInfo
+}
+
+// SkipUnlessVersionAtLeast skips the spec unless the API server is at least minimum, e.g. 1.27, for features
+// that older servers reject or silently drop
+func SkipUnlessVersionAtLeast(minimum string) {
+	ginkgo.GinkgoHelper()
+	server := Server()
+	require(Requirement{
+		Name:      "version:server",
+		Required:  ">=" + minimum,
+		Actual:    server.GitVersion,
+		Satisfied: server.AtLeast(minimum),
+	})
+}
+
+// SkipUnlessResourceExists skips the spec unless the server serves resource in groupVersion, e.g.
+// volumesnapshots in snapshot.storage.k8s.io/v1, whether built in, from a CRD or an extension API server
+func SkipUnlessResourceExists(groupVersion, resource string) {
+	ginkgo.GinkgoHelper()
+	actual := "not served"
+	if Server().HasResource(groupVersion, resource) {
+		actual = "served"
+	}
+	require(Requirement{
+		Name:      "resource:" + groupVersion + "/" + resource,
+		Required:  "served",
+		Actual:    actual,
+		Satisfied: actual == "served",
+	})
+}
@@ -0,0 +1,228 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// SoakIterationFile is written to the RESULTS_DIR of each soak iteration with what it ran and left behind
+const SoakIterationFile = "soak_iteration.json"
+
+// SoakSummaryFile is written next to the iterations' results with the failure rates and leaks of the soak
+// so far, updated as each iteration finishes
+const SoakSummaryFile = "soak.json"
+
+// soakDuration matches the durations run.sh can count down: whole hours, minutes and seconds
+var soakDuration = regexp.MustCompile(`^([0-9]+[hms])+$`)
+
+// How long counting the objects the run left behind may take
+const leakCountTimeout = 2 * time.Minute
+
+// leakResources are the resources searched for objects the run left behind
+var leakResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "namespaces"},
+	{Version: "v1", Resource: "pods"},
+	{Version: "v1", Resource: "services"},
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "secrets"},
+	{Version: "v1", Resource: "serviceaccounts"},
+	{Version: "v1", Resource: "persistentvolumeclaims"},
+	{Version: "v1", Resource: "persistentvolumes"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+}
+
+// SoakIteration is what one repetition of a soak ran, and the objects of the run left in the cluster after it
+type SoakIteration struct {
+	Iteration int       `json:"iteration"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Specs     []string  `json:"specs"`
+	Failed    []string  `json:"failed"`
+	// Leaked counts the objects created by any iteration so far and not deleted, by resource
+	Leaked map[string]int `json:"leaked"`
+}
+
+// SoakSpec is how often a spec failed over the iterations of a soak
+type SoakSpec struct {
+	Name        string  `json:"name"`
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failureRate"`
+}
+
+// SoakLeaks is how many objects the run had left behind after an iteration
+type SoakLeaks struct {
+	Iteration int            `json:"iteration"`
+	End       time.Time      `json:"end"`
+	Leaked    map[string]int `json:"leaked"`
+	Total     int            `json:"total"`
+}
+
+// SoakSummary sums up the iterations of a soak: the specs that failed in any of them, most often failing
+// first, and how the objects left behind grew
+type SoakSummary struct {
+	RunID       string      `json:"runID"`
+	Iterations  int         `json:"iterations"`
+	SpecRuns    int         `json:"specRuns"`
+	Failures    int         `json:"failures"`
+	FailureRate float64     `json:"failureRate"`
+	Failing     []SoakSpec  `json:"failing"`
+	Leaks       []SoakLeaks `json:"leaks"`
+}
+
+// WriteSoakReport records what the iteration of a soak ran, failed and leaked in its RESULTS_DIR, then sums
+// up every iteration so far next to it, so a burn-in shows which specs fail intermittently and whether the
+// cluster accumulates objects. run.sh gives each iteration a numbered directory under a common one, where
+// the summary goes. Meant to be registered with ReportAfterSuite; it does nothing outside of a soak.
+func WriteSoakReport(report ginkgo.Report) {
+	config, err := LoadRunConfig()
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if err != nil || config.Soak.Iteration == 0 || resultsDir == "" {
+		return
+	}
+	iteration := SoakIteration{
+		Iteration: config.Soak.Iteration,
+		Start:     report.StartTime,
+		End:       report.EndTime,
+		Specs:     []string{},
+		Failed:    []string{},
+	}
+	for _, spec := range report.SpecReports.WithLeafNodeType(types.NodeTypeIt) {
+		if spec.State.Is(types.SpecStateSkipped | types.SpecStatePending) {
+			continue
+		}
+		iteration.Specs = append(iteration.Specs, spec.FullText())
+		if spec.State.Is(types.SpecStateFailureStates) {
+			iteration.Failed = append(iteration.Failed, spec.FullText())
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leakCountTimeout)
+	defer cancel()
+	if iteration.Leaked, err = countLeakedObjects(ctx); err != nil {
+		Logger().Warn("Failed to count leaked objects", "error", err)
+	}
+	if err := writeJSON(filepath.Join(resultsDir, SoakIterationFile), iteration); err != nil {
+		Logger().Error("Failed to write soak iteration", "file", SoakIterationFile, "error", err)
+		return
+	}
+
+	soakDir := filepath.Dir(resultsDir)
+	summary, err := summarizeSoak(soakDir)
+	if err == nil {
+		err = writeJSON(filepath.Join(soakDir, SoakSummaryFile), summary)
+	}
+	if err != nil {
+		Logger().Error("Failed to write soak summary", "file", SoakSummaryFile, "error", err)
+		return
+	}
+	if leaks := summary.Leaks; len(leaks) > 1 && leaks[len(leaks)-1].Total > leaks[0].Total {
+		Logger().Warn("Objects left behind by the soak grew", "first", leaks[0].Total, "now", leaks[len(leaks)-1].Total)
+	}
+}
+
+// countLeakedObjects counts the objects of leakResources annotated with the run's ID and not being deleted
+func countLeakedObjects(ctx context.Context) (map[string]int, error) {
+	restConfig, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	leaked := map[string]int{}
+	for _, resource := range leakResources {
+		list, err := client.Resource(resource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return leaked, fmt.Errorf("list %s: %w", resource.Resource, err)
+		}
+		for _, item := range list.Items {
+			if item.GetAnnotations()[AnnotationRunID] == RunID() && item.GetDeletionTimestamp() == nil {
+				leaked[resource.Resource]++
+			}
+		}
+	}
+	return leaked, nil
+}
+
+// summarizeSoak sums up the iterations recorded in the directories under soakDir
+func summarizeSoak(soakDir string) (SoakSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(soakDir, "*", SoakIterationFile))
+	if err != nil {
+		return SoakSummary{}, err
+	}
+	summary := SoakSummary{RunID: RunID(), Failing: []SoakSpec{}, Leaks: []SoakLeaks{}}
+	specs := map[string]*SoakSpec{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return summary, err
+		}
+		var iteration SoakIteration
+		if err := json.Unmarshal(data, &iteration); err != nil {
+			return summary, fmt.Errorf("%s: %w", path, err)
+		}
+		summary.Iterations++
+		for _, name := range iteration.Specs {
+			if specs[name] == nil {
+				specs[name] = &SoakSpec{Name: name}
+			}
+			specs[name].Runs++
+		}
+		for _, name := range iteration.Failed {
+			if specs[name] != nil {
+				specs[name].Failures++
+			}
+		}
+		leaks := SoakLeaks{Iteration: iteration.Iteration, End: iteration.End, Leaked: iteration.Leaked}
+		for _, count := range iteration.Leaked {
+			leaks.Total += count
+		}
+		summary.Leaks = append(summary.Leaks, leaks)
+	}
+
+	for _, spec := range specs {
+		summary.SpecRuns += spec.Runs
+		summary.Failures += spec.Failures
+		if spec.Failures > 0 {
+			spec.FailureRate = float64(spec.Failures) / float64(spec.Runs)
+			summary.Failing = append(summary.Failing, *spec)
+		}
+	}
+	if summary.SpecRuns > 0 {
+		summary.FailureRate = float64(summary.Failures) / float64(summary.SpecRuns)
+	}
+	sort.Slice(summary.Failing, func(i, j int) bool {
+		if summary.Failing[i].FailureRate != summary.Failing[j].FailureRate {
+			return summary.Failing[i].FailureRate > summary.Failing[j].FailureRate
+		}
+		return summary.Failing[i].Name < summary.Failing[j].Name
+	})
+	// Directories sort by name, which puts iteration 10 before 2
+	sort.Slice(summary.Leaks, func(i, j int) bool { return summary.Leaks[i].Iteration < summary.Leaks[j].Iteration })
+	return summary, nil
+}
+
+// writeJSON writes v to path as indented JSON
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
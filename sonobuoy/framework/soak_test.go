@@ -0,0 +1,82 @@
+package framework
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeSoakIteration records iteration in its numbered directory under soakDir, as run.sh lays them out
+func writeSoakIteration(t *testing.T, soakDir string, iteration SoakIteration) {
+	t.Helper()
+	dir := filepath.Join(soakDir, fmt.Sprint(iteration.Iteration))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, SoakIterationFile), iteration); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSummarizeSoak(t *testing.T) {
+	soakDir := t.TempDir()
+	iterations := []SoakIteration{
+		{Iteration: 1, Specs: []string{"a", "b", "c"}, Failed: []string{"a"}, Leaked: map[string]int{"pods": 1}},
+		{Iteration: 2, Specs: []string{"a", "b", "c"}, Failed: []string{"a", "b"}, Leaked: map[string]int{"pods": 1, "secrets": 2}},
+		{Iteration: 10, Specs: []string{"a", "b"}, Failed: []string{}, Leaked: map[string]int{}},
+	}
+	for _, iteration := range iterations {
+		writeSoakIteration(t, soakDir, iteration)
+	}
+
+	summary, err := summarizeSoak(soakDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Iterations != 3 || summary.SpecRuns != 8 || summary.Failures != 3 || summary.FailureRate != 3.0/8 {
+		t.Errorf("summary counts %d iterations, %d runs, %d failures, rate %v; want 3, 8, 3, 0.375",
+			summary.Iterations, summary.SpecRuns, summary.Failures, summary.FailureRate)
+	}
+	wantFailing := []SoakSpec{
+		{Name: "a", Runs: 3, Failures: 2, FailureRate: 2.0 / 3},
+		{Name: "b", Runs: 3, Failures: 1, FailureRate: 1.0 / 3},
+	}
+	if !reflect.DeepEqual(summary.Failing, wantFailing) {
+		t.Errorf("failing specs = %v, want %v", summary.Failing, wantFailing)
+	}
+	var totals []int
+	for _, leaks := range summary.Leaks {
+		totals = append(totals, leaks.Iteration, leaks.Total)
+	}
+	if want := []int{1, 1, 2, 3, 10, 0}; !reflect.DeepEqual(totals, want) {
+		t.Errorf("leaks by iteration = %v, want %v", totals, want)
+	}
+}
+
+func TestSummarizeSoakEmpty(t *testing.T) {
+	summary, err := summarizeSoak(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Iterations != 0 || summary.FailureRate != 0 || summary.Failing == nil || summary.Leaks == nil {
+		t.Errorf("summarizeSoak() of no iterations = %+v", summary)
+	}
+}
+
+func TestSummarizeSoakCorruptIteration(t *testing.T) {
+	soakDir := t.TempDir()
+	dir := filepath.Join(soakDir, "1")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, SoakIterationFile)
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := summarizeSoak(soakDir); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("summarizeSoak() = %v, want an error naming %s", err, path)
+	}
+}
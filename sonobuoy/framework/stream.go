@@ -0,0 +1,260 @@
+package framework
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// ChannelProtocol is the WebSocket subprotocol streaming pod subresources speak. Version 4 reports the
+// result of exec and attach as a Status on the error channel.
+const ChannelProtocol = "v4.channel.k8s.io"
+
+// Channels of exec and attach streams
+const (
+	ChannelStdin  byte = 0
+	ChannelStdout byte = 1
+	ChannelStderr byte = 2
+	ChannelError  byte = 3
+)
+
+// Largest message a ChannelStream accepts. The API server relays exec output and forwarded data in chunks far
+// below it, so a larger length is a broken or hostile peer rather than something to allocate.
+const maxStreamMessage = 4 << 20
+
+// WebSocket opcodes, see RFC 6455 section 5.2
+const (
+	wsContinuation byte = 0x0
+	wsText         byte = 0x1
+	wsBinary       byte = 0x2
+	wsClose        byte = 0x8
+	wsPing         byte = 0x9
+	wsPong         byte = 0xa
+)
+
+// ChannelStream is a connection to a streaming pod subresource (exec, attach or portforward), where every
+// message belongs to a numbered channel. It is spoken over WebSocket rather than SPDY, so only the
+// standard library is needed on top of the authenticated transport of RestConfig.
+type ChannelStream struct {
+	conn    io.ReadWriteCloser
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	stop    func() bool
+}
+
+// DialChannelStream upgrades a request for a streaming subresource, e.g.
+// Clientset.CoreV1().RESTClient().Get().Namespace(ns).Resource("pods").Name(pod).SubResource("exec"),
+// to a ChannelStream. The stream is closed when ctx is done.
+func DialChannelStream(ctx context.Context, request *rest.Request) (*ChannelStream, error) {
+	config := rest.CopyConfig(RestConfig)
+	// Upgrading the connection requires HTTP/1.1
+	config.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	client, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	nonce := base64.StdEncoding.EncodeToString(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, request.URL().String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", nonce)
+	req.Header.Set("Sec-WebSocket-Protocol", ChannelProtocol)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		status := &metav1.Status{}
+		if json.Unmarshal(body, status) == nil && status.Message != "" {
+			return nil, fmt.Errorf("%s: %w", request.URL().Path, &apierrors.StatusError{ErrStatus: *status})
+		}
+		return nil, fmt.Errorf("%s: unexpected response %s", request.URL().Path, resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(nonce) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: invalid WebSocket handshake", request.URL().Path)
+	}
+	// After a protocol switch the body is the connection itself
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: connection cannot be upgraded", request.URL().Path)
+	}
+	return &ChannelStream{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		stop:   context.AfterFunc(ctx, func() { conn.Close() }),
+	}, nil
+}
+
+// Read returns the channel and data of the next message, answering pings on the way. It returns io.EOF
+// once the server closed the stream.
+func (s *ChannelStream) Read() (channel byte, data []byte, err error) {
+	for {
+		message, err := s.readMessage()
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(message) > 0 {
+			return message[0], message[1:], nil
+		}
+	}
+}
+
+// Write sends data on channel
+func (s *ChannelStream) Write(channel byte, data []byte) error {
+	return s.writeFrame(wsBinary, append([]byte{channel}, data...))
+}
+
+// Close closes the connection
+func (s *ChannelStream) Close() error {
+	s.stop()
+	return s.conn.Close()
+}
+
+// readMessage reads frames until a complete data message arrives. A close frame ends the stream with io.EOF.
+func (s *ChannelStream) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(s.reader, header); err != nil {
+			return nil, err
+		}
+		fin, opcode := header[0]&0x80 != 0, header[0]&0x0f
+		masked, length := header[1]&0x80 != 0, uint64(header[1]&0x7f)
+		switch length {
+		case 126:
+			extended := make([]byte, 2)
+			if _, err := io.ReadFull(s.reader, extended); err != nil {
+				return nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(extended))
+		case 127:
+			extended := make([]byte, 8)
+			if _, err := io.ReadFull(s.reader, extended); err != nil {
+				return nil, err
+			}
+			length = binary.BigEndian.Uint64(extended)
+		}
+		if length > maxStreamMessage-uint64(len(message)) {
+			return nil, fmt.Errorf("WebSocket message exceeds %d bytes", maxStreamMessage)
+		}
+		var mask []byte
+		if masked {
+			mask = make([]byte, 4)
+			if _, err := io.ReadFull(s.reader, mask); err != nil {
+				return nil, err
+			}
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(s.reader, payload); err != nil {
+			return nil, err
+		}
+		for i := range mask {
+			for j := i; j < len(payload); j += 4 {
+				payload[j] ^= mask[i]
+			}
+		}
+
+		switch opcode {
+		case wsClose:
+			return nil, io.EOF
+		case wsPing:
+			if err := s.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+		case wsPong:
+		case wsContinuation, wsText, wsBinary:
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unexpected WebSocket opcode %#x", opcode)
+		}
+	}
+}
+
+// writeFrame writes a single frame; clients must mask everything they send
+func (s *ChannelStream) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := s.conn.Write(frame)
+	return err
+}
+
+// websocketAccept returns the Sec-WebSocket-Accept the server must answer nonce with
+func websocketAccept(nonce string) string {
+	sum := sha1.Sum([]byte(nonce + "258EAFA5-E914-47DA-95CA-C5AB0DC11B65"))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ParseStreamStatus parses the Status an exec or attach stream reports on ChannelError when the command
+// ends. It returns nil for success, an *ExecExitError when the command exited with a non-zero code, and an
+// error carrying the Status message otherwise.
+func ParseStreamStatus(data []byte) error {
+	status := &metav1.Status{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return fmt.Errorf("invalid stream status %q", data)
+	}
+	if status.Status == metav1.StatusSuccess {
+		return nil
+	}
+	if status.Reason == "NonZeroExitCode" && status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			if cause.Type != "ExitCode" {
+				continue
+			}
+			if code, err := strconv.Atoi(cause.Message); err == nil {
+				return &ExecExitError{ExitCode: code}
+			}
+		}
+	}
+	return errors.New(status.Message)
+}
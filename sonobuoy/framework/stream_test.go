@@ -0,0 +1,274 @@
+package framework
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// serverFrame encodes a frame as a server sends it, unmasked
+func serverFrame(fin bool, opcode byte, payload []byte) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	return append(frame, payload...)
+}
+
+// readClientFrame decodes a single frame the client sent, which must be masked
+func readClientFrame(r io.Reader) (opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("client frame is not masked")
+	}
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(r, mask); err != nil {
+		return 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return header[0] & 0x0f, payload, nil
+}
+
+// dialTestStream points RestConfig at a server that completes the WebSocket handshake and hands the
+// connection to serve, then dials an exec stream through it
+func dialTestStream(t *testing.T, serve func(conn *bufio.ReadWriter) error) (*ChannelStream, chan error) {
+	t.Helper()
+	served := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Sec-WebSocket-Protocol") != ChannelProtocol {
+			http.Error(w, "unexpected subprotocol", http.StatusBadRequest)
+			return
+		}
+		conn, buffered, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			served <- err
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(buffered, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: %s\r\n\r\n",
+			websocketAccept(r.Header.Get("Sec-WebSocket-Key")), ChannelProtocol)
+		if err := buffered.Flush(); err != nil {
+			served <- err
+			return
+		}
+		served <- serve(buffered)
+	}))
+	t.Cleanup(server.Close)
+	stream, err := dialTestExec(t, server.URL)
+	if err != nil {
+		t.Fatalf("DialChannelStream: %v", err)
+	}
+	t.Cleanup(func() { stream.Close() })
+	return stream, served
+}
+
+// dialTestExec dials the exec subresource of a pod through the API server at host
+func dialTestExec(t *testing.T, host string) (*ChannelStream, error) {
+	t.Helper()
+	previous := RestConfig
+	RestConfig = &rest.Config{Host: host}
+	t.Cleanup(func() { RestConfig = previous })
+	clientset, err := kubernetes.NewForConfig(RestConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := clientset.CoreV1().RESTClient().Get().Namespace("test").Resource("pods").Name("pod").SubResource("exec")
+	return DialChannelStream(context.Background(), request)
+}
+
+func TestChannelStreamFraming(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	stream, served := dialTestStream(t, func(conn *bufio.ReadWriter) error {
+		// A message split across a continuation frame, with a ping in between
+		conn.Write(serverFrame(false, wsBinary, []byte{ChannelStdout, 'h', 'e'}))
+		conn.Write(serverFrame(true, wsPing, []byte("ping")))
+		conn.Write(serverFrame(true, wsContinuation, []byte("llo")))
+		// A message needing the 16-bit length
+		conn.Write(serverFrame(true, wsBinary, append([]byte{ChannelStderr}, long...)))
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+
+		opcode, payload, err := readClientFrame(conn)
+		if err != nil {
+			return err
+		}
+		if opcode != wsPong || string(payload) != "ping" {
+			return fmt.Errorf("got frame %#x %q, want a pong echoing the ping", opcode, payload)
+		}
+		opcode, payload, err = readClientFrame(conn)
+		if err != nil {
+			return err
+		}
+		if opcode != wsBinary || string(payload) != "\x00input" {
+			return fmt.Errorf("got frame %#x %q, want stdin input", opcode, payload)
+		}
+		conn.Write(serverFrame(true, wsClose, nil))
+		return conn.Flush()
+	})
+
+	channel, data, err := stream.Read()
+	if err != nil || channel != ChannelStdout || string(data) != "hello" {
+		t.Errorf("Read() = %d, %q, %v; want stdout \"hello\"", channel, data, err)
+	}
+	channel, data, err = stream.Read()
+	if err != nil || channel != ChannelStderr || string(data) != long {
+		t.Errorf("Read() = %d, %d bytes, %v; want %d bytes of stderr", channel, len(data), err, len(long))
+	}
+	if err := stream.Write(ChannelStdin, []byte("input")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, _, err := stream.Read(); err != io.EOF {
+		t.Errorf("Read() after close = %v, want io.EOF", err)
+	}
+	if err := <-served; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChannelStreamRejectsOversizedMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]byte
+	}{
+		{
+			name: "64-bit length",
+			// Only the header is sent: the length alone must be refused, not allocated
+			frames: [][]byte{{0x80 | wsBinary, 127, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		},
+		{
+			name: "fragments",
+			frames: [][]byte{
+				serverFrame(false, wsBinary, make([]byte, maxStreamMessage/2)),
+				serverFrame(false, wsContinuation, make([]byte, maxStreamMessage/2)),
+				serverFrame(true, wsContinuation, []byte{0}),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream, _ := dialTestStream(t, func(conn *bufio.ReadWriter) error {
+				for _, frame := range test.frames {
+					conn.Write(frame)
+				}
+				if err := conn.Flush(); err != nil {
+					return err
+				}
+				// Keep the connection open until the client gives up on it
+				_, err := io.Copy(io.Discard, conn)
+				return err
+			})
+			_, _, err := stream.Read()
+			if err == nil || !strings.Contains(err.Error(), "exceeds") {
+				t.Errorf("Read() = %v, want an error for the message size", err)
+			}
+		})
+	}
+}
+
+func TestDialChannelStreamReportsRejection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"pods \"pod\" is forbidden","reason":"Forbidden","code":403}`)
+	}))
+	defer server.Close()
+
+	_, err := dialTestExec(t, server.URL)
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("DialChannelStream() = %v, want a Forbidden status error", err)
+	}
+}
+
+func TestParseStreamStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		exitCode int
+		message  string
+	}{
+		{name: "success", status: `{"metadata":{},"status":"Success"}`},
+		{
+			name: "non-zero exit code",
+			status: `{"metadata":{},"status":"Failure","message":"command terminated with non-zero exit code","reason":"NonZeroExitCode",` +
+				`"details":{"causes":[{"reason":"ExitCode","message":"3"}]}}`,
+			exitCode: 3,
+		},
+		{
+			name:    "non-zero exit code without a code",
+			status:  `{"metadata":{},"status":"Failure","message":"command terminated","reason":"NonZeroExitCode","details":{}}`,
+			message: "command terminated",
+		},
+		{
+			name:    "failure",
+			status:  `{"metadata":{},"status":"Failure","message":"container not found (\"app\")","reason":"InternalError"}`,
+			message: `container not found ("app")`,
+		},
+		{name: "invalid", status: `not json`, message: `invalid stream status "not json"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ParseStreamStatus([]byte(test.status))
+			var exitErr *ExecExitError
+			switch {
+			case test.exitCode != 0:
+				if !errors.As(err, &exitErr) || exitErr.ExitCode != test.exitCode {
+					t.Errorf("ParseStreamStatus() = %v, want exit code %d", err, test.exitCode)
+				}
+			case test.message != "":
+				if err == nil || err.Error() != test.message || errors.As(err, &exitErr) {
+					t.Errorf("ParseStreamStatus() = %v, want %q", err, test.message)
+				}
+			case err != nil:
+				t.Errorf("ParseStreamStatus() = %v, want nil", err)
+			}
+		})
+	}
+}
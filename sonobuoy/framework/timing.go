@@ -0,0 +1,240 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/onsi/gomega"
+	gomegatypes "github.com/onsi/gomega/types"
+)
+
+// SlowSpecsFile is written to RESULTS_DIR with the specs that exceeded their budget
+const SlowSpecsFile = "slow_specs.json"
+
+// slowEntry names the report entry flagging a spec that exceeded its budget
+const slowEntry = "Slow spec"
+
+// DefaultBudget is the key of E2E_SPEC_BUDGETS applying to specs with none of the other labels
+const DefaultBudget = "default"
+
+// WaitTiming is how long a spec waited in an Eventually or a framework wait helper
+type WaitTiming struct {
+	What     string  `json:"what"`
+	Location string  `json:"location,omitempty"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// SlowSpec is a spec that took longer than its budget, with what it waited for
+type SlowSpec struct {
+	Spec          string       `json:"spec"`
+	Labels        []string     `json:"labels,omitempty"`
+	BudgetSeconds float64      `json:"budgetSeconds"`
+	Seconds       float64      `json:"seconds"`
+	Waits         []WaitTiming `json:"waits,omitempty"`
+}
+
+var (
+	waitsMu sync.Mutex
+	// waits is nil unless the running spec's waits are timed
+	waits []WaitTiming
+)
+
+// recordWait adds a wait of the running spec that started at start
+func recordWait(what, location string, start time.Time) {
+	waitsMu.Lock()
+	defer waitsMu.Unlock()
+	if waits != nil {
+		waits = append(waits, WaitTiming{What: what, Location: location, Seconds: time.Since(start).Seconds()})
+	}
+}
+
+// EnforceSpecBudgets flags the current spec as slow in the report when it took longer than the budget
+// E2E_SPEC_BUDGETS sets for its labels, with the time it spent in each Eventually and wait helper. The
+// largest budget of the spec's labels applies, or the default one for specs without a budgeted label.
+// Meant to be registered with BeforeEach; the flag does not fail the spec.
+func EnforceSpecBudgets() {
+	config, err := LoadRunConfig()
+	if err != nil || len(config.SpecBudgets) == 0 {
+		return
+	}
+	report := ginkgo.CurrentSpecReport()
+	budget, ok := specBudget(report.Labels(), config.SpecBudgets)
+	if !ok {
+		return
+	}
+	waitsMu.Lock()
+	waits = []WaitTiming{}
+	waitsMu.Unlock()
+
+	start := time.Now()
+	ginkgo.DeferCleanup(func() {
+		waitsMu.Lock()
+		timed := waits
+		waits = nil
+		waitsMu.Unlock()
+
+		elapsed := time.Since(start)
+		if elapsed <= budget {
+			return
+		}
+		// The longest waits explain most of the overrun
+		sort.SliceStable(timed, func(i, j int) bool { return timed[i].Seconds > timed[j].Seconds })
+		slow := SlowSpec{
+			Spec:          report.FullText(),
+			Labels:        report.Labels(),
+			BudgetSeconds: budget.Seconds(),
+			Seconds:       elapsed.Seconds(),
+			Waits:         timed,
+		}
+		Logger().Warn("Spec exceeded its budget", "budget", budget.String(), "duration", elapsed.Round(time.Millisecond).String())
+		ginkgo.AddReportEntry(slowEntry, slow)
+	})
+}
+
+// specBudget returns the largest budget of labels, or the default one
+func specBudget(labels []string, budgets map[string]time.Duration) (time.Duration, bool) {
+	var budget time.Duration
+	found := false
+	for _, label := range labels {
+		if value, ok := budgets[label]; ok && value > budget {
+			budget, found = value, true
+		}
+	}
+	if !found {
+		budget, found = budgets[DefaultBudget]
+	}
+	return budget, found
+}
+
+// String summarizes the slow spec in the report entry
+func (s SlowSpec) String() string {
+	summary := fmt.Sprintf("took %s, over its budget of %s", secondsDuration(s.Seconds), secondsDuration(s.BudgetSeconds))
+	for _, wait := range s.Waits {
+		summary += fmt.Sprintf("\n  %s at %s: %s", secondsDuration(wait.Seconds), wait.Location, wait.What)
+	}
+	return summary
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+}
+
+// slowSpec returns the slow spec entry of spec, which may come from another parallel process
+func slowSpec(spec types.SpecReport) (SlowSpec, bool) {
+	for _, entry := range spec.ReportEntries {
+		if entry.Name != slowEntry {
+			continue
+		}
+		var slow SlowSpec
+		if err := json.Unmarshal([]byte(entry.Value.AsJSON), &slow); err == nil {
+			return slow, true
+		}
+	}
+	return SlowSpec{}, false
+}
+
+// WriteSlowSpecReport writes the specs that exceeded their budget to RESULTS_DIR, slowest first, so the
+// performance of a cluster can be compared between runs. Meant to be registered with ReportAfterSuite.
+func WriteSlowSpecReport(report ginkgo.Report) {
+	resultsDir := os.Getenv("RESULTS_DIR")
+	if resultsDir == "" {
+		return
+	}
+	config, err := LoadRunConfig()
+	if err != nil || len(config.SpecBudgets) == 0 {
+		return
+	}
+	slow := []SlowSpec{}
+	for _, spec := range report.SpecReports {
+		if entry, ok := slowSpec(spec); ok {
+			slow = append(slow, entry)
+		}
+	}
+	sort.SliceStable(slow, func(i, j int) bool {
+		return slow[i].Seconds-slow[i].BudgetSeconds > slow[j].Seconds-slow[j].BudgetSeconds
+	})
+
+	data, err := json.MarshalIndent(slow, "", "  ")
+	if err != nil {
+		Logger().Warn("Failed to marshal slow spec report", "error", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(resultsDir, SlowSpecsFile), data, 0644); err != nil {
+		Logger().Warn("Failed to write slow spec report", "error", err)
+	}
+}
+
+// Eventually is gomega's Eventually, timing how long the spec waits for the assertion to pass for the
+// slow spec report. Use it in place of the dot-imported one.
+func Eventually(actualOrCtx interface{}, args ...interface{}) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: gomega.EventuallyWithOffset(1, actualOrCtx, args...)}
+}
+
+// timedAssertion records how long Should and ShouldNot took
+type timedAssertion struct {
+	assertion gomegatypes.AsyncAssertion
+	offset    int
+}
+
+func (a *timedAssertion) Should(matcher gomegatypes.GomegaMatcher, optionalDescription ...interface{}) bool {
+	defer recordWait(describeWait(matcher, optionalDescription), types.NewCodeLocation(1+a.offset).String(), time.Now())
+	return a.assertion.WithOffset(1+a.offset).Should(matcher, optionalDescription...)
+}
+
+func (a *timedAssertion) ShouldNot(matcher gomegatypes.GomegaMatcher, optionalDescription ...interface{}) bool {
+	defer recordWait(describeWait(matcher, optionalDescription), types.NewCodeLocation(1+a.offset).String(), time.Now())
+	return a.assertion.WithOffset(1+a.offset).ShouldNot(matcher, optionalDescription...)
+}
+
+func (a *timedAssertion) WithOffset(offset int) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion, offset: offset}
+}
+
+func (a *timedAssertion) WithTimeout(interval time.Duration) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.WithTimeout(interval), offset: a.offset}
+}
+
+func (a *timedAssertion) WithPolling(interval time.Duration) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.WithPolling(interval), offset: a.offset}
+}
+
+func (a *timedAssertion) Within(timeout time.Duration) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.Within(timeout), offset: a.offset}
+}
+
+func (a *timedAssertion) ProbeEvery(interval time.Duration) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.ProbeEvery(interval), offset: a.offset}
+}
+
+func (a *timedAssertion) WithContext(ctx context.Context) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.WithContext(ctx), offset: a.offset}
+}
+
+func (a *timedAssertion) WithArguments(argsToForward ...interface{}) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.WithArguments(argsToForward...), offset: a.offset}
+}
+
+func (a *timedAssertion) MustPassRepeatedly(count int) gomegatypes.AsyncAssertion {
+	return &timedAssertion{assertion: a.assertion.MustPassRepeatedly(count), offset: a.offset}
+}
+
+// describeWait names a wait by the description of its assertion, or by its matcher
+func describeWait(matcher gomegatypes.GomegaMatcher, optionalDescription []interface{}) string {
+	if len(optionalDescription) > 0 {
+		if format, ok := optionalDescription[0].(string); ok {
+			return fmt.Sprintf(format, optionalDescription[1:]...)
+		}
+		if describe, ok := optionalDescription[0].(func() string); ok {
+			return describe()
+		}
+	}
+	return fmt.Sprintf("%T", matcher)
+}
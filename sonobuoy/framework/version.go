@@ -0,0 +1,4 @@
+package framework
+
+// Version is the release of the framework, following semantic versioning
+const Version = "0.2.0"
@@ -0,0 +1,29 @@
+package framework
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestChangelogMatchesVersion(t *testing.T) {
+	changelog, err := os.Open("CHANGELOG.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer changelog.Close()
+
+	scanner := bufio.NewScanner(changelog)
+	for scanner.Scan() {
+		release, ok := strings.CutPrefix(scanner.Text(), "## ")
+		if !ok || release == "Unreleased" {
+			continue
+		}
+		if release != Version {
+			t.Errorf("newest release in CHANGELOG.md is %s, Version is %s", release, Version)
+		}
+		return
+	}
+	t.Errorf("CHANGELOG.md lists no release, Version is %s", Version)
+}
@@ -0,0 +1,56 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WaitForPodPhase waits until the pod reaches phase. Reaching a different terminal phase fails immediately.
+func WaitForPodPhase(ctx context.Context, c kubernetes.Interface, namespace, name string, phase v1.PodPhase, timeout time.Duration) (*v1.Pod, error) {
+	defer recordWait(fmt.Sprintf("pod %s/%s to be %s", namespace, name, phase), types.NewCodeLocation(1).String(), time.Now())
+	var pod *v1.Pod
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		pod, err = c.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == phase {
+			return true, nil
+		}
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			return false, fmt.Errorf("pod %s/%s is %s", namespace, name, pod.Status.Phase)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return pod, fmt.Errorf("pod %s/%s did not reach phase %s within %s: %v", namespace, name, phase, timeout, err)
+	}
+	return pod, nil
+}
+
+// WaitForPodRunning waits until the pod is Running
+func WaitForPodRunning(ctx context.Context, c kubernetes.Interface, namespace, name string, timeout time.Duration) (*v1.Pod, error) {
+	return WaitForPodPhase(ctx, c, namespace, name, v1.PodRunning, timeout)
+}
+
+// WaitForPodOutput waits until a pod that runs to completion has Succeeded and returns its logs, so specs
+// can check the output of commands run inside it. If the pod fails, the error includes the logs.
+func WaitForPodOutput(ctx context.Context, c kubernetes.Interface, namespace, name string, timeout time.Duration) (string, error) {
+	_, waitErr := WaitForPodPhase(ctx, c, namespace, name, v1.PodSucceeded, timeout)
+	logs, err := c.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{}).DoRaw(ctx)
+	if waitErr != nil {
+		return string(logs), fmt.Errorf("%v, logs:\n%s", waitErr, logs)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs of pod %s/%s: %v", namespace, name, err)
+	}
+	return string(logs), nil
+}
@@ -5,6 +5,18 @@ set -x
 # Define the directory for test results, defaulting to /tmp/results
 results_dir="${RESULTS_DIR:-/tmp/results}"
 mkdir -p ${results_dir}
+export RESULTS_DIR="${results_dir}"
+
+# In self-hosted mode, serve the control API and start runs on request instead of once
+if [ "${E2E_MODE}" = "server" ]; then
+    exec /bin/e2e-server --results-dir=${results_dir}
+fi
+
+# Identify this run on every resource the suites create
+export E2E_RUN_ID="${E2E_RUN_ID:-$(date +%Y%m%d%H%M%S)-${HOSTNAME}}"
+
+# Include a self-contained HTML summary in the results unless E2E_REPORTERS says otherwise
+export E2E_REPORTERS="${E2E_REPORTERS-html}"
 
 # Function to package results and signal Sonobuoy
 saveResults() {
@@ -20,5 +32,106 @@ saveResults() {
 # Ensure that the saveResults function runs upon exit
 trap saveResults EXIT
 
-# Run the Ginkgo test suite
-ginkgo run -r --keep-going --output-dir=${results_dir} --junit-report=junit.xml -p /workspace/tests &>${results_dir}/out
+# As a daemonset plugin, each node's pod only runs the node-level specs against its own node
+label_filter=""
+if [ -n "${NODE_NAME}" ]; then
+    label_filter="--label-filter=node"
+fi
+
+# Run specs in E2E_PARALLELISM processes, or one per CPU by default; 1 runs them serially
+procs="-p"
+if [ -n "${E2E_PARALLELISM}" ]; then
+    procs="--procs=${E2E_PARALLELISM}"
+fi
+
+# Retry failed specs up to E2E_FLAKE_ATTEMPTS times in total; specs passing on a retry are reported as flaky
+flake_attempts=""
+if [ -n "${E2E_FLAKE_ATTEMPTS}" ]; then
+    flake_attempts="--flake-attempts=${E2E_FLAKE_ATTEMPTS}"
+fi
+
+# With a baseline the suite writes junit.xml itself, with known issues skipped, from ginkgo's unfiltered report
+report="--junit-report=junit.xml"
+if [ -n "${E2E_BASELINE}" ]; then
+    report="--json-report=report.json"
+fi
+
+# With E2E_SOAK, repeat the run for a duration such as 2h or 1h30m, or a number of iterations, each writing
+# its results under soak/<iteration> and the suites summing up failure rates and leaked objects in soak/soak.json.
+# A duration only stops new iterations from starting, so the last one may run past it.
+if [ -n "${E2E_SOAK}" ]; then
+    iterations=0
+    deadline=0
+    if [[ "${E2E_SOAK}" =~ ^[0-9]+$ ]]; then
+        iterations=${E2E_SOAK}
+    elif [[ "${E2E_SOAK}" =~ ^([0-9]+[hms])+$ ]]; then
+        seconds=0
+        rest=${E2E_SOAK}
+        while [[ "${rest}" =~ ^([0-9]+)([hms])(.*)$ ]]; do
+            case ${BASH_REMATCH[2]} in
+                h) seconds=$((seconds + 10#${BASH_REMATCH[1]} * 3600)) ;;
+                m) seconds=$((seconds + 10#${BASH_REMATCH[1]} * 60)) ;;
+                s) seconds=$((seconds + 10#${BASH_REMATCH[1]})) ;;
+            esac
+            rest=${BASH_REMATCH[3]}
+        done
+        deadline=$(($(date +%s) + seconds))
+    else
+        echo "invalid E2E_SOAK ${E2E_SOAK}: must be a number of iterations or a duration such as 2h" >> ${results_dir}/out
+        exit 1
+    fi
+
+    # The run fails if any iteration failed
+    status=0
+    iteration=1
+    while [ ${iteration} -le ${iterations} ] || [ $(date +%s) -lt ${deadline} ]; do
+        iteration_dir="${results_dir}/soak/${iteration}"
+        mkdir -p ${iteration_dir}
+        E2E_SOAK_ITERATION=${iteration} RESULTS_DIR="${iteration_dir}" \
+            ginkgo run --keep-going --output-dir=${iteration_dir} ${report} ${label_filter} ${flake_attempts} ${procs} /workspace/tests &>${iteration_dir}/out
+        code=$?
+        echo "iteration ${iteration}: exit code ${code}" >> ${results_dir}/out
+        if [ "${code}" != "0" ]; then
+            status=1
+        fi
+        iteration=$((iteration + 1))
+    done
+    exit ${status}
+fi
+
+# Run all suites as a single Ginkgo suite
+if [ -z "${E2E_CONTEXTS}" ]; then
+    ginkgo run --keep-going --output-dir=${results_dir} ${report} ${label_filter} ${flake_attempts} ${procs} /workspace/tests &>${results_dir}/out
+    exit $?
+fi
+
+# With E2E_CONTEXTS, run them once per kubeconfig context, each cluster writing its own results under
+# clusters/<context>, one after the other or all at once with E2E_CONTEXTS_PARALLEL=true
+runCluster() {
+    context=$1
+    cluster_dir="${results_dir}/clusters/${context}"
+    mkdir -p ${cluster_dir}
+    E2E_CONTEXT="${context}" E2E_RUN_ID="${E2E_RUN_ID}-${context}" RESULTS_DIR="${cluster_dir}" \
+        ginkgo run --keep-going --output-dir=${cluster_dir} ${report} ${label_filter} ${flake_attempts} ${procs} /workspace/tests &>${cluster_dir}/out
+    echo "$?" > ${cluster_dir}/exit-code
+}
+
+for context in ${E2E_CONTEXTS//,/ }; do
+    if [ "${E2E_CONTEXTS_PARALLEL}" = "true" ]; then
+        runCluster ${context} &
+    else
+        runCluster ${context}
+    fi
+done
+wait
+
+# The run fails if any cluster failed
+status=0
+for context in ${E2E_CONTEXTS//,/ }; do
+    code=$(cat ${results_dir}/clusters/${context}/exit-code)
+    echo "${context}: exit code ${code}" >> ${results_dir}/out
+    if [ "${code}" != "0" ]; then
+        status=1
+    fi
+done
+exit ${status}
@@ -0,0 +1,151 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the pods mount the shared volume
+const sharedMountPath = "/shared"
+
+// newClaim returns a PVC of storageClass with a single access mode
+func newClaim(namespace, name, storageClass string, mode v1.PersistentVolumeAccessMode) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{mode},
+			StorageClassName: &storageClass,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+// claimPod returns a long-running pod mounting the PVC at sharedMountPath
+func claimPod(namespace, name, pvcName string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+	pod.Spec.Volumes = []v1.Volume{{
+		Name: "shared",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: pvcName,
+		}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "shared", MountPath: sharedMountPath}}
+	return pod
+}
+
+var _ = Describe("PersistentVolume access modes", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-access-modes-%d", time.Now().UnixNano())
+	})
+
+	// cleanupClaim deletes the pods using the PVC, then the PVC
+	cleanupClaim := func(ctx context.Context, pods ...string) {
+		for _, pod := range pods {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), pod)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pod %s", pod)
+		}
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+	}
+
+	It("should share a ReadWriteMany volume between pods on different nodes", func(ctx SpecContext) {
+		storageClass := framework.RequireRWXStorageClass(ctx)
+		framework.RequireReadyNodes(ctx, 2)
+
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, v1.ReadWriteMany), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ReadWriteMany PVC")
+		pods := []string{name + "-a", name + "-b"}
+		DeferCleanup(cleanupClaim, pods[0], pods[1])
+
+		for _, podName := range pods {
+			pod := claimPod(namespace, podName, name)
+			pod.Labels["e2e-shared-claim"] = name
+			// Required anti-affinity places the second pod on another node than the first
+			pod.Spec.Affinity = &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"e2e-shared-claim": name}},
+					TopologyKey:   v1.LabelHostname,
+				}},
+			}}
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create pod %s", podName)
+		}
+		nodes := map[string]bool{}
+		for _, podName := range pods {
+			pod, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 5*time.Minute)
+			Expect(err).NotTo(HaveOccurred(), "Pod %s did not start with the ReadWriteMany volume", podName)
+			nodes[pod.Spec.NodeName] = true
+		}
+		Expect(nodes).To(HaveLen(2), "Pods were not scheduled on different nodes")
+
+		By("writing from both pods at once")
+		for _, podName := range pods {
+			_, err := framework.ExecInPod(ctx, namespace, podName, "", "sh", "-c", fmt.Sprintf("echo written-by-%[2]s > %[1]s/%[2]s", sharedMountPath, podName))
+			Expect(err).NotTo(HaveOccurred(), "Pod %s could not write to the shared volume", podName)
+		}
+
+		By("reading what the other pod wrote")
+		for i, podName := range pods {
+			other := pods[1-i]
+			framework.Eventually(func() (string, error) {
+				result, err := framework.ExecInPod(ctx, namespace, podName, "", "cat", sharedMountPath+"/"+other)
+				return strings.TrimSpace(result.Stdout), err
+			}, 60*time.Second, 2*time.Second).Should(Equal("written-by-"+other), "Pod %s does not see what %s wrote", podName, other)
+		}
+	})
+
+	It("should keep a second pod from using a ReadWriteOncePod volume", func(ctx SpecContext) {
+		storageClass := framework.RequireRWOPStorageClass(ctx)
+
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, v1.ReadWriteOncePod), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ReadWriteOncePod PVC")
+		first, second := name+"-first", name+"-second"
+		DeferCleanup(cleanupClaim, first, second)
+
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, first, name), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create first pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, first, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "First pod did not start with the ReadWriteOncePod volume")
+
+		By("checking the scheduler keeps the second pod pending")
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, second, name), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create second pod")
+		framework.Eventually(func() (string, error) {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, second, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+					return condition.Message, nil
+				}
+			}
+			return "", nil
+		}, 60*time.Second, 2*time.Second).Should(ContainSubstring("ReadWriteOncePod"), "Second pod was not held back for the ReadWriteOncePod volume")
+
+		By("checking the second pod starts once the first is gone")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), first)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete first pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, second, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Second pod did not start after the first released the volume")
+	})
+})
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Version the dummy APIService registers
+const dummyVersion = "v1alpha1"
+
+var _ = Describe("API aggregation layer", func() {
+	It("should have every registered APIService Available", func(ctx SpecContext) {
+		list, err := framework.DynamicClient.Resource(framework.APIServiceResource).List(ctx, metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list APIServices")
+		Expect(list.Items).NotTo(BeEmpty(), "No APIService is registered")
+
+		var unavailable []string
+		aggregated := 0
+		for i := range list.Items {
+			apiService := &list.Items[i]
+			// Built-in group versions are served by kube-apiserver itself and have no service
+			if service, _, _ := unstructured.NestedMap(apiService.Object, "spec", "service"); service != nil {
+				aggregated++
+			}
+			if available, reason := framework.APIServiceAvailable(apiService); !available {
+				unavailable = append(unavailable, fmt.Sprintf("%s (%s)", apiService.GetName(), reason))
+			}
+		}
+		sort.Strings(unavailable)
+		AddReportEntry("APIServices", fmt.Sprintf("%d registered, %d served by extension API servers", len(list.Items), aggregated))
+		Expect(unavailable).To(BeEmpty(), "Unavailable APIServices break discovery for every client:\n%s", strings.Join(unavailable, "\n"))
+	})
+
+	// A registered but unavailable APIService makes discovery partially fail, which stalls namespace deletion
+	// and garbage collection cluster-wide while it exists
+	It("should report an APIService without a backing service as unavailable and fail its requests", Label(framework.LabelDisruptive), func(ctx SpecContext) {
+		namespace := framework.TestNamespace()
+		group := fmt.Sprintf("run%d.e2e.sonobuoy.io", time.Now().UnixNano())
+		name := dummyVersion + "." + group
+		path := "/apis/" + group + "/" + dummyVersion
+
+		apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": framework.APIServiceResource.GroupVersion().String(),
+			"kind":       "APIService",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec": map[string]interface{}{
+				"group":   group,
+				"version": dummyVersion,
+				// Nothing by that name exists, so the aggregator has nowhere to proxy to
+				"service": map[string]interface{}{
+					"namespace": namespace,
+					"name":      "e2e-missing-apiserver",
+					"port":      int64(443),
+				},
+				"insecureSkipTLSVerify": true,
+				"groupPriorityMinimum":  int64(100),
+				"versionPriority":       int64(100),
+			},
+		}}
+		_, err := framework.DynamicClient.Resource(framework.APIServiceResource).Create(ctx, apiService, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to register APIService")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Dynamic(framework.DynamicClient.Resource(framework.APIServiceResource)), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete APIService")
+		})
+
+		By("waiting for the availability controller to mark it unavailable")
+		framework.Eventually(func() (string, error) {
+			registered, err := framework.DynamicClient.Resource(framework.APIServiceResource).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			if available, reason := framework.APIServiceAvailable(registered); !available {
+				return reason, nil
+			}
+			return "Available", nil
+		}, 60*time.Second, 2*time.Second).Should(HavePrefix("ServiceNotFound"), "APIService without a service was not reported unavailable")
+
+		By("requesting the group version through the aggregator")
+		_, err = framework.Clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+		Expect(errors.IsServiceUnavailable(err)).To(BeTrue(), "Request to an unavailable APIService did not fail with 503: %v", err)
+
+		By("unregistering it")
+		err = framework.Cleanup(ctx, framework.Dynamic(framework.DynamicClient.Resource(framework.APIServiceResource)), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete APIService")
+		framework.Eventually(func() error {
+			_, err := framework.Clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+			return err
+		}, 60*time.Second, 2*time.Second).Should(Satisfy(errors.IsNotFound), "Group version is still routed after its APIService was deleted")
+	})
+})
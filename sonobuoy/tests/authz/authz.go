@@ -0,0 +1,236 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Secret the Role grants access to by name
+const allowedSecretName = "e2e-authz-allowed"
+
+// accessCheck is one cell of the expected access matrix
+type accessCheck struct {
+	verb        string
+	resource    string
+	subresource string
+	name        string
+	// otherNamespace checks the same access outside the namespace the Role is bound in
+	otherNamespace bool
+	allowed        bool
+}
+
+func (c accessCheck) String() string {
+	resource := c.resource
+	if c.subresource != "" {
+		resource += "/" + c.subresource
+	}
+	if c.name != "" {
+		resource += "/" + c.name
+	}
+	if c.otherNamespace {
+		resource += " in another namespace"
+	}
+	return c.verb + " " + resource
+}
+
+// The access the Role below is expected to grant, and a selection of access it must not
+var accessMatrix = []accessCheck{
+	{verb: "get", resource: "configmaps", allowed: true},
+	{verb: "list", resource: "configmaps", allowed: true},
+	{verb: "watch", resource: "configmaps", allowed: true},
+	{verb: "delete", resource: "configmaps", allowed: false},
+	{verb: "create", resource: "pods", allowed: true},
+	{verb: "get", resource: "pods", allowed: false},
+	{verb: "get", resource: "pods", subresource: "log", allowed: false},
+	{verb: "get", resource: "secrets", name: allowedSecretName, allowed: true},
+	{verb: "get", resource: "secrets", name: "e2e-authz-denied", allowed: false},
+	{verb: "list", resource: "secrets", allowed: false},
+	{verb: "get", resource: "configmaps", otherNamespace: true, allowed: false},
+}
+
+var _ = Describe("RBAC Authorization Reviews", func() {
+	var namespace string
+	var otherNamespace string
+	var name string
+	var identity framework.Identity
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		otherNamespace = "kube-system"
+		name = fmt.Sprintf("test-authz-%d", time.Now().UnixNano())
+		identity = framework.ServiceAccountIdentity(namespace, name)
+
+		_, err := framework.Clientset.CoreV1().ServiceAccounts(namespace).Create(ctx, &v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ServiceAccount")
+
+		_, err = framework.Clientset.RbacV1().Roles(namespace).Create(ctx, &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list", "watch"}},
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"create"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{allowedSecretName}, Verbs: []string{"get"}},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Role")
+
+		_, err = framework.Clientset.RbacV1().RoleBindings(namespace).Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: namespace}},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create RoleBinding")
+	})
+
+	// Clients acting as the ServiceAccount, declared after the BeforeEach that names it
+	serviceAccount := framework.ImpersonatingFramework(func() framework.Identity { return identity })
+
+	It("should match the access matrix with SubjectAccessReview", func(ctx SpecContext) {
+		subjectAccessReview := func(check accessCheck) bool {
+			reviewNamespace := namespace
+			if check.otherNamespace {
+				reviewNamespace = otherNamespace
+			}
+			review, err := framework.Clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					User:   identity.UserName,
+					Groups: identity.Groups,
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace:   reviewNamespace,
+						Verb:        check.verb,
+						Resource:    check.resource,
+						Subresource: check.subresource,
+						Name:        check.name,
+					},
+				},
+			}, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create SubjectAccessReview for %s", check)
+			return review.Status.Allowed
+		}
+
+		// RBAC changes reach the authorizer asynchronously
+		framework.Eventually(func() bool {
+			return subjectAccessReview(accessMatrix[0])
+		}, 30*time.Second, time.Second).Should(BeTrue(), "RoleBinding did not take effect")
+
+		for _, check := range accessMatrix {
+			Expect(subjectAccessReview(check)).To(Equal(check.allowed), "Unexpected SubjectAccessReview result for %s", check)
+		}
+	})
+
+	It("should report the granted rules with SelfSubjectRulesReview", func(ctx SpecContext) {
+		// Review as the ServiceAccount itself, authenticating with a short-lived token
+		expirationSeconds := int64(600)
+		tokenRequest, err := framework.Clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to request ServiceAccount token")
+		userConfig := rest.AnonymousClientConfig(framework.RestConfig)
+		userConfig.BearerToken = tokenRequest.Status.Token
+		userClient, err := kubernetes.NewForConfig(userConfig)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ServiceAccount client")
+
+		selfSubjectRulesReview := func(reviewNamespace string) []authorizationv1.ResourceRule {
+			review, err := userClient.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+				Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: reviewNamespace},
+			}, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create SelfSubjectRulesReview")
+			Expect(review.Status.EvaluationError).To(BeEmpty(), "SelfSubjectRulesReview reported an evaluation error")
+			return review.Status.ResourceRules
+		}
+
+		var rules []authorizationv1.ResourceRule
+		framework.Eventually(func() bool {
+			rules = selfSubjectRulesReview(namespace)
+			return rulesAllow(rules, accessMatrix[0])
+		}, 30*time.Second, time.Second).Should(BeTrue(), "RoleBinding did not take effect")
+		otherRules := selfSubjectRulesReview(otherNamespace)
+
+		for _, check := range accessMatrix {
+			checkRules := rules
+			if check.otherNamespace {
+				checkRules = otherRules
+			}
+			Expect(rulesAllow(checkRules, check)).To(Equal(check.allowed), "Unexpected SelfSubjectRulesReview result for %s", check)
+		}
+	})
+
+	It("should enforce the access matrix on requests made as the ServiceAccount", func(ctx SpecContext) {
+		configMaps := serviceAccount.Clientset.CoreV1().ConfigMaps(namespace)
+
+		// RBAC changes reach the authorizer asynchronously
+		framework.Eventually(func() error {
+			_, err := configMaps.List(ctx, metav1.ListOptions{})
+			return err
+		}, 30*time.Second, time.Second).Should(Succeed(), "RoleBinding did not take effect")
+
+		// Authorization happens before lookup, so a denied request is Forbidden even for missing objects
+		err := configMaps.Delete(ctx, name, metav1.DeleteOptions{})
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "Expected delete configmaps to be forbidden, got: %v", err)
+
+		_, err = serviceAccount.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "Expected list pods to be forbidden, got: %v", err)
+
+		_, err = serviceAccount.Clientset.CoreV1().Secrets(namespace).Get(ctx, allowedSecretName, metav1.GetOptions{})
+		Expect(errors.IsNotFound(err)).To(BeTrue(), "Expected get of the allowed secret to reach the lookup, got: %v", err)
+
+		_, err = serviceAccount.Clientset.CoreV1().Secrets(namespace).Get(ctx, "e2e-authz-denied", metav1.GetOptions{})
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "Expected get of another secret to be forbidden, got: %v", err)
+
+		_, err = serviceAccount.Clientset.CoreV1().ConfigMaps(otherNamespace).List(ctx, metav1.ListOptions{})
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "Expected list configmaps in another namespace to be forbidden, got: %v", err)
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.RbacV1().RoleBindings(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete RoleBinding")
+
+		err = framework.Cleanup(ctx, framework.Clientset.RbacV1().Roles(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Role")
+
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().ServiceAccounts(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ServiceAccount")
+	})
+})
+
+// rulesAllow evaluates a core API group access check against the rules returned by a SelfSubjectRulesReview
+func rulesAllow(rules []authorizationv1.ResourceRule, check accessCheck) bool {
+	resource := check.resource
+	if check.subresource != "" {
+		resource += "/" + check.subresource
+	}
+	for _, rule := range rules {
+		if !matchesRule(rule.Verbs, check.verb) || !matchesRule(rule.APIGroups, "") || !matchesRule(rule.Resources, resource) {
+			continue
+		}
+		if len(rule.ResourceNames) == 0 || (check.name != "" && matchesRule(rule.ResourceNames, check.name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRule reports whether a rule field lists the value or the "*" wildcard
+func matchesRule(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
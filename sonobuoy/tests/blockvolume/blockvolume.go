@@ -0,0 +1,98 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the pods see the raw block device
+const devicePath = "/dev/e2e-block"
+
+// How much the specs write to the device, in 4KiB blocks
+const blockCount = 256
+
+// blockPod returns a pod running script with the PVC attached as a raw block device. Opening a block
+// device needs root, which the baseline Pod Security Standard allows.
+func blockPod(namespace, name, pvcName, script string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+	framework.Unrestricted(&pod.Spec)
+	pod.Spec.Volumes = []v1.Volume{{
+		Name: "block",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: pvcName,
+		}},
+	}}
+	pod.Spec.Containers[0].VolumeDevices = []v1.VolumeDevice{{Name: "block", DevicePath: devicePath}}
+	return pod
+}
+
+// Storage vendors validate their drivers with these specs, so they only run against the StorageClass
+// named by E2E_BLOCK_STORAGE_CLASS
+var _ = Describe("Raw block volumes", func() {
+	var namespace string
+	var pvcName string
+	var storageClass string
+
+	BeforeEach(func(ctx SpecContext) {
+		storageClass = framework.RequireBlockStorageClass(ctx)
+
+		namespace = framework.CreateNamespace(ctx, "test-block", framework.PodSecurityBaseline)
+		pvcName = namespace
+
+		volumeMode := v1.PersistentVolumeBlock
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: namespace},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				StorageClassName: &storageClass,
+				VolumeMode:       &volumeMode,
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create block PVC")
+	})
+
+	It("should expose the volume as a block device that keeps what is written to it", func(ctx SpecContext) {
+		By("writing random data to the device")
+		writer := fmt.Sprintf("writer-%d", time.Now().UnixNano())
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, blockPod(namespace, writer, pvcName, fmt.Sprintf(
+			`stat -c %%F %[1]s && dd if=/dev/urandom of=/tmp/data bs=4096 count=%[2]d 2>/dev/null && `+
+				`dd if=/tmp/data of=%[1]s bs=4096 count=%[2]d conv=fsync 2>/dev/null && `+
+				`dd if=%[1]s bs=4096 count=%[2]d 2>/dev/null | cmp - /tmp/data && sha256sum < /tmp/data`,
+			devicePath, blockCount)), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create writer pod")
+		// Provisioning a volume on a real backend can take a while
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, writer, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Writer pod did not complete")
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		Expect(lines).To(HaveLen(2), "Unexpected output: %q", output)
+		Expect(lines[0]).To(Equal("block special file"), "Volume is not a block device at %s", devicePath)
+		written := strings.Fields(lines[1])[0]
+
+		By("reading the data back from a new pod")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), writer)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete writer pod")
+		reader := fmt.Sprintf("reader-%d", time.Now().UnixNano())
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, blockPod(namespace, reader, pvcName, fmt.Sprintf(
+			`dd if=%s bs=4096 count=%d 2>/dev/null | sha256sum`, devicePath, blockCount)), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create reader pod")
+		output, err = framework.WaitForPodOutput(ctx, framework.Clientset, namespace, reader, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Reader pod did not complete")
+		Expect(strings.Fields(output)).NotTo(BeEmpty(), "Reader pod printed nothing")
+		Expect(strings.Fields(output)[0]).To(Equal(written), "Data read back from the device differs from what was written")
+	})
+})
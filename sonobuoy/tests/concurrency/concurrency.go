@@ -0,0 +1,247 @@
+package e2e
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Optimistic concurrency is what RetryOnConflict relies on: a write carrying a stale
+// resourceVersion (or a failed delete precondition) must be refused with 409 Conflict.
+var _ = Describe("Optimistic Concurrency Control", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-concurrency-%d", time.Now().UnixNano())
+	})
+
+	Context("ConfigMaps", func() {
+		BeforeEach(func(ctx SpecContext) {
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"counter": "0",
+				},
+			}
+			_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		})
+
+		It("should reject an update carrying a stale resourceVersion", func(ctx SpecContext) {
+			first, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap")
+			stale := first.DeepCopy()
+
+			first.Data["counter"] = "1"
+			_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, first, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to update ConfigMap")
+
+			stale.Data["counter"] = "2"
+			_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, stale, metav1.UpdateOptions{})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Expected Conflict for stale update, got: %v", err)
+
+			stored, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap")
+			Expect(stored.Data["counter"]).To(Equal("1"), "Stale update overwrote the ConfigMap")
+		})
+
+		It("should honor resourceVersion and UID preconditions on delete", func(ctx SpecContext) {
+			current, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap")
+			staleVersion := current.ResourceVersion
+
+			current.Data["counter"] = "1"
+			current, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, current, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to update ConfigMap")
+
+			err = framework.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{ResourceVersion: &staleVersion},
+			})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Expected Conflict for stale resourceVersion precondition, got: %v", err)
+
+			wrongUID := types.UID("00000000-0000-0000-0000-000000000000")
+			err = framework.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &wrongUID},
+			})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Expected Conflict for mismatched UID precondition, got: %v", err)
+
+			err = framework.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{ResourceVersion: &current.ResourceVersion, UID: &current.UID},
+			})
+			Expect(err).NotTo(HaveOccurred(), "Delete with matching preconditions failed")
+		})
+
+		It("should converge concurrent writers that use RetryOnConflict", func(ctx SpecContext) {
+			const writers = 4
+			const incrementsPerWriter = 5
+			// Enough steps for every writer to lose the race a few times
+			backoff := wait.Backoff{Steps: 20, Duration: 10 * time.Millisecond, Factor: 1.5, Jitter: 0.5}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, writers)
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for j := 0; j < incrementsPerWriter; j++ {
+						errs <- retry.RetryOnConflict(backoff, func() error {
+							configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+							if err != nil {
+								return err
+							}
+							counter, err := strconv.Atoi(configMap.Data["counter"])
+							if err != nil {
+								return err
+							}
+							configMap.Data["counter"] = strconv.Itoa(counter + 1)
+							_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+							return err
+						})
+					}
+				}()
+			}
+			go func() {
+				wg.Wait()
+				close(errs)
+			}()
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred(), "Writer failed to apply its increment")
+			}
+
+			stored, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap")
+			Expect(stored.Data["counter"]).To(Equal(strconv.Itoa(writers*incrementsPerWriter)), "Lost updates between concurrent writers")
+		})
+
+		AfterEach(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+		})
+	})
+
+	Context("Secrets", func() {
+		BeforeEach(func(ctx SpecContext) {
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string][]byte{
+					"password": []byte("secret"),
+				},
+				Type: v1.SecretTypeOpaque,
+			}
+			_, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create secret")
+		})
+
+		It("should reject an update with an explicitly stale resourceVersion", func(ctx SpecContext) {
+			current, err := framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get secret")
+			staleVersion := current.ResourceVersion
+
+			current.Data["password"] = []byte("newsecret")
+			_, err = framework.Clientset.CoreV1().Secrets(namespace).Update(ctx, current, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to update secret")
+
+			current.ResourceVersion = staleVersion
+			current.Data["password"] = []byte("stale")
+			_, err = framework.Clientset.CoreV1().Secrets(namespace).Update(ctx, current, metav1.UpdateOptions{})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Expected Conflict for stale update, got: %v", err)
+		})
+
+		AfterEach(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete secret")
+		})
+	})
+
+	Context("Deployments", func() {
+		BeforeEach(func(ctx SpecContext) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: int32Ptr(1),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": name},
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": name},
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:    "alpine",
+									Image:   "alpine:3.20",
+									Command: []string{"sh", "-c", "sleep 3600"},
+								},
+							},
+						},
+					},
+				},
+			}
+			framework.Restrict(&deployment.Spec.Template.Spec)
+			_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+		})
+
+		It("should reject a stale spec update after the object has moved on", func(ctx SpecContext) {
+			stale, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get deployment")
+
+			// Bump the deployment so the copy we hold is out of date
+			err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				dep, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				// Updates bypass the object hooks, so keep the run metadata labels and annotations in place
+				if dep.Labels == nil {
+					dep.Labels = map[string]string{}
+				}
+				dep.Labels["e2e-bump"] = "true"
+				_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, dep, metav1.UpdateOptions{})
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to bump deployment")
+
+			stale.Spec.Replicas = int32Ptr(2)
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, stale, metav1.UpdateOptions{})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Expected Conflict for stale update, got: %v", err)
+		})
+
+		AfterEach(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+		})
+	})
+})
+
+// Helper function to return a pointer to int32
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,21 @@
+//go:build standalone
+
+package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Register the setup, spec and report nodes every entry point shares
+var _ = framework.RegisterEntryPoint()
+
+// Entry point for running the suite on its own
+func TestConcurrency(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Optimistic Concurrency Suite")
+}
@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// ConfigMap CRUD test suite with unique configmap names
+var _ = Describe("ConfigMap CRUD Operations", func() {
+	var namespace string
+	var configMapName string
+
+	BeforeEach(func(ctx SpecContext) {
+		// Define namespace and generate a unique ConfigMap name with a timestamp
+		namespace = framework.TestNamespace()
+		configMapName = fmt.Sprintf("test-configmap-%d", time.Now().UnixNano())
+
+		// Create a ConfigMap before each test
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				"config-key": "config-value",
+			},
+		}
+
+		_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+	})
+
+	// Read the ConfigMap
+	It("should read the ConfigMap successfully", func(ctx SpecContext) {
+		configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to read ConfigMap")
+		Expect(configMap.Data["config-key"]).To(Equal("config-value"))
+	})
+
+	// Update the ConfigMap
+	It("should update the ConfigMap successfully", func(ctx SpecContext) {
+		configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap for update")
+
+		// Modify the ConfigMap data
+		configMap.Data["config-key"] = "updated-value"
+		_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update ConfigMap")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), configMapName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+	})
+})
@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("CertificateSigningRequest Lifecycle", func() {
+	var csrName string
+	var userName string
+
+	BeforeEach(func() {
+		csrName = fmt.Sprintf("test-csr-%d", time.Now().UnixNano())
+		userName = fmt.Sprintf("e2e-csr-user-%d", time.Now().UnixNano())
+	})
+
+	It("should issue a client certificate that authenticates against the API server", func(ctx SpecContext) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred(), "Failed to generate private key")
+
+		request, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: userName},
+		}, key)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create certificate request")
+
+		// The kube-apiserver-client signer rejects requests for anything but client auth
+		expirationSeconds := int32(600)
+		csr := &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: csrName,
+			},
+			Spec: certificatesv1.CertificateSigningRequestSpec{
+				Request:           pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: request}),
+				SignerName:        certificatesv1.KubeAPIServerClientSignerName,
+				ExpirationSeconds: &expirationSeconds,
+				Usages: []certificatesv1.KeyUsage{
+					certificatesv1.UsageDigitalSignature,
+					certificatesv1.UsageClientAuth,
+				},
+			},
+		}
+		_, err = framework.Clientset.CertificatesV1().CertificateSigningRequests().Create(ctx, csr, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create CertificateSigningRequest")
+
+		// Approve through the approval subresource, as kubectl certificate approve does
+		csr, err = framework.Clientset.CertificatesV1().CertificateSigningRequests().Get(ctx, csrName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get CertificateSigningRequest")
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:           certificatesv1.CertificateApproved,
+			Status:         v1.ConditionTrue,
+			Reason:         "E2EApprove",
+			Message:        "Approved by the sonobuoy e2e suite",
+			LastUpdateTime: metav1.Now(),
+		})
+		_, err = framework.Clientset.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csrName, csr, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to approve CertificateSigningRequest")
+
+		var certificatePEM []byte
+		framework.Eventually(func() bool {
+			csr, err := framework.Clientset.CertificatesV1().CertificateSigningRequests().Get(ctx, csrName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get CertificateSigningRequest")
+			for _, condition := range csr.Status.Conditions {
+				Expect(condition.Type).NotTo(BeElementOf(certificatesv1.CertificateDenied, certificatesv1.CertificateFailed),
+					"CertificateSigningRequest was not signed: %s", condition.Message)
+			}
+			certificatePEM = csr.Status.Certificate
+			return len(certificatePEM) > 0
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Certificate was not issued within the timeout")
+
+		block, _ := pem.Decode(certificatePEM)
+		Expect(block).NotTo(BeNil(), "Issued certificate is not PEM encoded")
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).NotTo(HaveOccurred(), "Failed to parse issued certificate")
+		Expect(certificate.Subject.CommonName).To(Equal(userName))
+		Expect(certificate.ExtKeyUsage).To(ContainElement(x509.ExtKeyUsageClientAuth))
+		Expect(key.PublicKey.Equal(certificate.PublicKey)).To(BeTrue(), "Certificate was issued for a different key")
+
+		// Authenticate with only the issued certificate. The new user has no RBAC grants,
+		// so the request must be forbidden for that user rather than unauthorized.
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).NotTo(HaveOccurred(), "Failed to encode private key")
+		userConfig := rest.AnonymousClientConfig(framework.RestConfig)
+		userConfig.CertData = certificatePEM
+		userConfig.KeyData = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+		userClient, err := kubernetes.NewForConfig(userConfig)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create client from issued certificate")
+
+		_, err = userClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		Expect(errors.IsUnauthorized(err)).To(BeFalse(), "API server did not accept the issued certificate: %v", err)
+		Expect(errors.IsForbidden(err)).To(BeTrue(), "Expected the certificate user to be forbidden, got: %v", err)
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("User %q", userName)), "Request was not authenticated as the certificate subject")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CertificatesV1().CertificateSigningRequests(), csrName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete CertificateSigningRequest")
+	})
+})
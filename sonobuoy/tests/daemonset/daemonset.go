@@ -0,0 +1,233 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Readiness delay that keeps old and new pods side by side long enough to be observed
+const readinessDelaySeconds = 5
+
+// rolloutObservation is what watching the DaemonSet's pods during a rolling update saw at worst
+type rolloutObservation struct {
+	// Most pods running on one node at once
+	maxPodsPerNode int
+	// Most nodes running more than one pod at once
+	maxSurgedNodes int
+	// Most nodes without a Ready pod at once
+	maxUnavailableNodes int
+}
+
+// podState is what the observer tracks of a DaemonSet pod
+type podState struct {
+	node  string
+	ready bool
+}
+
+// observeRollout tracks the DaemonSet's pods on nodes until stop is closed and returns the worst it saw.
+// Terminating pods are not counted; they no longer serve and the controller does not count them either.
+func observeRollout(ctx context.Context, namespace, name string, nodes []string, stop <-chan struct{}) <-chan rolloutObservation {
+	GinkgoHelper()
+	pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=" + name})
+	Expect(err).NotTo(HaveOccurred(), "Failed to list DaemonSet pods")
+	watcher, err := framework.Clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector:   "app=" + name,
+		ResourceVersion: pods.ResourceVersion,
+	})
+	Expect(err).NotTo(HaveOccurred(), "Failed to watch DaemonSet pods")
+
+	states := map[string]podState{}
+	track := func(pod *v1.Pod, deleted bool) {
+		if deleted || pod.DeletionTimestamp != nil || pod.Spec.NodeName == "" {
+			delete(states, pod.Name)
+			return
+		}
+		ready := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+				ready = true
+			}
+		}
+		states[pod.Name] = podState{node: pod.Spec.NodeName, ready: ready}
+	}
+	for i := range pods.Items {
+		track(&pods.Items[i], false)
+	}
+
+	result := make(chan rolloutObservation, 1)
+	go func() {
+		defer GinkgoRecover()
+		defer watcher.Stop()
+		var observed rolloutObservation
+		for {
+			select {
+			case <-stop:
+				result <- observed
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					result <- observed
+					return
+				}
+				pod, isPod := event.Object.(*v1.Pod)
+				if !isPod {
+					continue
+				}
+				track(pod, event.Type == watch.Deleted)
+			}
+
+			perNode, readyNodes := map[string]int{}, map[string]bool{}
+			for _, state := range states {
+				perNode[state.node]++
+				if state.ready {
+					readyNodes[state.node] = true
+				}
+			}
+			surged, unavailable := 0, 0
+			for _, node := range nodes {
+				observed.maxPodsPerNode = max(observed.maxPodsPerNode, perNode[node])
+				if perNode[node] > 1 {
+					surged++
+				}
+				if !readyNodes[node] {
+					unavailable++
+				}
+			}
+			observed.maxSurgedNodes = max(observed.maxSurgedNodes, surged)
+			observed.maxUnavailableNodes = max(observed.maxUnavailableNodes, unavailable)
+		}
+	}()
+	return result
+}
+
+var _ = Describe("DaemonSet rolling update", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func(ctx SpecContext) {
+		framework.RequireReadyNodes(ctx, 1)
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-daemonset-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().DaemonSets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete DaemonSet")
+	})
+
+	// createDaemonSet creates the DaemonSet with the given rolling update limits and returns the nodes it runs on
+	// once all its pods are available
+	createDaemonSet := func(ctx context.Context, maxSurge, maxUnavailable intstr.IntOrString) []string {
+		labels := map[string]string{"app": name}
+		daemonSet := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+					Type: appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+						MaxSurge:       &maxSurge,
+						MaxUnavailable: &maxUnavailable,
+					},
+				},
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{
+							Name:    "alpine",
+							Image:   podImage,
+							Command: []string{"sleep", "3600"},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler:        v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"true"}}},
+								InitialDelaySeconds: readinessDelaySeconds,
+								PeriodSeconds:       1,
+							},
+						}},
+					},
+				},
+			},
+		}
+		framework.Restrict(&daemonSet.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().DaemonSets(namespace).Create(ctx, daemonSet, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create DaemonSet")
+		waitForDaemonSetRollout(ctx, namespace, name)
+
+		pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=" + name})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list DaemonSet pods")
+		var nodes []string
+		for _, pod := range pods.Items {
+			nodes = append(nodes, pod.Spec.NodeName)
+		}
+		Expect(nodes).NotTo(BeEmpty(), "DaemonSet runs on no node")
+		return nodes
+	}
+
+	// rollOut changes the pod template and returns what was observed until the update completed
+	rollOut := func(ctx context.Context, nodes []string) rolloutObservation {
+		stop := make(chan struct{})
+		observation := observeRollout(ctx, namespace, name, nodes, stop)
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			daemonSet, err := framework.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			daemonSet.Spec.Template.Annotations = map[string]string{"e2e/restartedAt": time.Now().Format(time.RFC3339)}
+			_, err = framework.Clientset.AppsV1().DaemonSets(namespace).Update(ctx, daemonSet, metav1.UpdateOptions{})
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update DaemonSet template")
+		waitForDaemonSetRollout(ctx, namespace, name)
+		close(stop)
+		return <-observation
+	}
+
+	It("should surge a new pod before removing the old one with maxSurge", func(ctx SpecContext) {
+		nodes := createDaemonSet(ctx, intstr.FromInt(1), intstr.FromInt(0))
+		observed := rollOut(ctx, nodes)
+		AddReportEntry("Rollout observation", fmt.Sprintf("%+v on %d nodes", observed, len(nodes)))
+
+		Expect(observed.maxUnavailableNodes).To(BeZero(), "A node was left without a Ready pod despite maxUnavailable 0")
+		Expect(observed.maxPodsPerNode).To(Equal(2), "No node ran the old and the new pod side by side")
+		Expect(observed.maxSurgedNodes).To(Equal(1), "More nodes surged at once than maxSurge allows")
+	})
+
+	It("should replace pods in place without surging with maxUnavailable", func(ctx SpecContext) {
+		nodes := createDaemonSet(ctx, intstr.FromInt(0), intstr.FromInt(1))
+		observed := rollOut(ctx, nodes)
+		AddReportEntry("Rollout observation", fmt.Sprintf("%+v on %d nodes", observed, len(nodes)))
+
+		Expect(observed.maxPodsPerNode).To(Equal(1), "A node ran two pods despite maxSurge 0")
+		Expect(observed.maxUnavailableNodes).To(Equal(1), "More nodes were unavailable at once than maxUnavailable allows")
+	})
+})
+
+// waitForDaemonSetRollout waits until every scheduled pod of the DaemonSet is updated and available
+func waitForDaemonSetRollout(ctx context.Context, namespace, name string) {
+	GinkgoHelper()
+	framework.Eventually(func() (bool, error) {
+		daemonSet, err := framework.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		status := daemonSet.Status
+		return status.ObservedGeneration == daemonSet.Generation &&
+			status.DesiredNumberScheduled > 0 &&
+			status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+			status.NumberAvailable == status.DesiredNumberScheduled, nil
+	}, 5*time.Minute, 2*time.Second).Should(BeTrue(), "DaemonSet rollout did not complete within the timeout")
+}
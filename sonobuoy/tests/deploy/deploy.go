@@ -0,0 +1,188 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Deployment CRUD test suite with unique deployment names
+var _ = Describe("Deployment CRUD Operations", func() {
+	var namespace string
+	var deploymentName string
+
+	BeforeEach(func(ctx SpecContext) {
+		// Define namespace and generate a unique Deployment name with a timestamp
+		namespace = framework.TestNamespace()
+		deploymentName = fmt.Sprintf("test-deployment-%d", time.Now().UnixNano())
+
+		// Create a Deployment before each test
+		replicas := int32(1)
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deploymentName,
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app": "test-app",
+					},
+				},
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"app": "test-app",
+						},
+					},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name:    "alpine",
+								Image:   "alpine:3.20",
+								Command: []string{"sh", "-c", "sleep 3600"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		framework.Restrict(&deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+
+		// Wait for the Deployment to be available
+		framework.Eventually(func() bool {
+			dep, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get deployment status")
+			return dep.Status.AvailableReplicas == 1
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Deployment was not ready within the timeout")
+	})
+
+	// Read the Deployment
+	It("should read the Deployment successfully", func(ctx SpecContext) {
+		deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to read deployment")
+		Expect(deployment.Spec.Replicas).To(Equal(int32Ptr(1)))
+	})
+
+	// Update the Deployment with Conflict Handling
+	It("should update the Deployment successfully", func(ctx SpecContext) {
+		// Retry loop to handle conflicts
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			// Fetch the latest version of the Deployment
+			deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			// Modify the Deployment spec (e.g., change the number of replicas)
+			replicas := int32(2)
+			deployment.Spec.Replicas = &replicas
+
+			// Update the Deployment
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update deployment")
+
+		// Wait for the Deployment to scale up
+		framework.Eventually(func() bool {
+			dep, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get deployment status")
+			return dep.Status.AvailableReplicas == 2
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Deployment did not scale within the timeout")
+	})
+
+	// Delete the Deployment
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+})
+
+// A rollout that cannot make progress must surface ProgressDeadlineExceeded, the signal CD tools rely on
+var _ = Describe("Deployment Progress Deadline", func() {
+	var namespace string
+	var deploymentName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		deploymentName = fmt.Sprintf("test-deployment-deadline-%d", time.Now().UnixNano())
+	})
+
+	It("should report ProgressDeadlineExceeded for a rollout that cannot progress", func(ctx SpecContext) {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deploymentName,
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas:                int32Ptr(1),
+				ProgressDeadlineSeconds: int32Ptr(30),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app": deploymentName,
+					},
+				},
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"app": deploymentName,
+						},
+					},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Name:    "alpine",
+								Image:   "alpine:e2e-tag-that-does-not-exist", // Pull never succeeds
+								Command: []string{"sh", "-c", "sleep 3600"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		framework.Restrict(&deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+
+		// Wait for the Progressing condition to flip to False
+		framework.Eventually(func() string {
+			dep, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get deployment status")
+			for _, condition := range dep.Status.Conditions {
+				if condition.Type == appsv1.DeploymentProgressing && condition.Status == v1.ConditionFalse {
+					return condition.Reason
+				}
+			}
+			return ""
+		}, 180*time.Second, 5*time.Second).Should(Equal("ProgressDeadlineExceeded"), "Deployment did not report ProgressDeadlineExceeded within the timeout")
+
+		dep, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get deployment status")
+		Expect(dep.Status.AvailableReplicas).To(BeZero(), "Deployment unexpectedly has available replicas")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+})
+
+// Helper function to return a pointer to int32
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,180 @@
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Verbs of a resource supporting the full set of object operations
+var readWrite = []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+
+// expectedResource is a resource, or resource/subresource, a conformant API server serves with at least verbs
+type expectedResource struct {
+	name  string
+	verbs []string
+}
+
+// Minor version in which upstream stopped serving each deprecated group version
+var removedIn = map[string]string{
+	"extensions/v1beta1":                   "1.22",
+	"apps/v1beta1":                         "1.16",
+	"apps/v1beta2":                         "1.16",
+	"admissionregistration.k8s.io/v1beta1": "1.22",
+	"apiextensions.k8s.io/v1beta1":         "1.22",
+	"apiregistration.k8s.io/v1beta1":       "1.22",
+	"authentication.k8s.io/v1beta1":        "1.22",
+	"authorization.k8s.io/v1beta1":         "1.22",
+	"certificates.k8s.io/v1beta1":          "1.22",
+	"coordination.k8s.io/v1beta1":          "1.22",
+	"networking.k8s.io/v1beta1":            "1.22",
+	"rbac.authorization.k8s.io/v1beta1":    "1.22",
+	"scheduling.k8s.io/v1beta1":            "1.22",
+	"batch/v1beta1":                        "1.25",
+	"discovery.k8s.io/v1beta1":             "1.25",
+	"events.k8s.io/v1beta1":                "1.25",
+	"node.k8s.io/v1beta1":                  "1.25",
+	"policy/v1beta1":                       "1.25",
+	"autoscaling/v2beta1":                  "1.25",
+	"autoscaling/v2beta2":                  "1.26",
+	"flowcontrol.apiserver.k8s.io/v1beta1": "1.26",
+	"storage.k8s.io/v1beta1":               "1.27",
+	"flowcontrol.apiserver.k8s.io/v1beta2": "1.29",
+	"flowcontrol.apiserver.k8s.io/v1beta3": "1.32",
+}
+
+// Walks the discovery API the way kubectl and controllers do, without creating anything
+var _ = Describe("API discovery", func() {
+	DescribeTable("should serve the core resources of a group version with their verbs",
+		func(groupVersion string, expected []expectedResource) {
+			list, err := framework.Clientset.Discovery().ServerResourcesForGroupVersion(groupVersion)
+			Expect(err).NotTo(HaveOccurred(), "Group version %s is not served", groupVersion)
+			served := map[string]sets.Set[string]{}
+			for _, resource := range list.APIResources {
+				served[resource.Name] = sets.New[string](resource.Verbs...)
+			}
+
+			var problems []string
+			for _, resource := range expected {
+				verbs, ok := served[resource.name]
+				if !ok {
+					problems = append(problems, resource.name+" is not served")
+					continue
+				}
+				if missing := sets.New[string](resource.verbs...).Difference(verbs); missing.Len() > 0 {
+					problems = append(problems, fmt.Sprintf("%s lacks verbs %v", resource.name, sets.List(missing)))
+				}
+			}
+			Expect(problems).To(BeEmpty(), "Group version %s is incomplete:\n%s", groupVersion, strings.Join(problems, "\n"))
+		},
+		Entry("core/v1", "v1", []expectedResource{
+			{"pods", readWrite},
+			{"pods/log", []string{"get"}},
+			{"pods/exec", []string{"create", "get"}},
+			{"pods/eviction", []string{"create"}},
+			{"pods/status", []string{"get", "patch", "update"}},
+			{"services", []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			{"configmaps", readWrite},
+			{"secrets", readWrite},
+			{"serviceaccounts", readWrite},
+			{"serviceaccounts/token", []string{"create"}},
+			{"persistentvolumeclaims", readWrite},
+			{"persistentvolumes", readWrite},
+			{"namespaces", []string{"create", "delete", "get", "list", "patch", "update", "watch"}},
+			{"nodes", readWrite},
+			{"nodes/proxy", []string{"get"}},
+			{"events", readWrite},
+			{"endpoints", readWrite},
+			{"resourcequotas", readWrite},
+			{"limitranges", readWrite},
+		}),
+		Entry("apps/v1", "apps/v1", []expectedResource{
+			{"deployments", readWrite},
+			{"deployments/scale", []string{"get", "patch", "update"}},
+			{"replicasets", readWrite},
+			{"statefulsets", readWrite},
+			{"daemonsets", readWrite},
+			{"controllerrevisions", readWrite},
+		}),
+		Entry("batch/v1", "batch/v1", []expectedResource{
+			{"jobs", readWrite},
+			{"cronjobs", readWrite},
+		}),
+		Entry("autoscaling/v2", "autoscaling/v2", []expectedResource{
+			{"horizontalpodautoscalers", readWrite},
+		}),
+		Entry("policy/v1", "policy/v1", []expectedResource{
+			{"poddisruptionbudgets", readWrite},
+		}),
+		Entry("rbac.authorization.k8s.io/v1", "rbac.authorization.k8s.io/v1", []expectedResource{
+			{"roles", readWrite},
+			{"rolebindings", readWrite},
+			{"clusterroles", readWrite},
+			{"clusterrolebindings", readWrite},
+		}),
+		Entry("networking.k8s.io/v1", "networking.k8s.io/v1", []expectedResource{
+			{"networkpolicies", readWrite},
+			{"ingresses", readWrite},
+			{"ingressclasses", readWrite},
+		}),
+		Entry("storage.k8s.io/v1", "storage.k8s.io/v1", []expectedResource{
+			{"storageclasses", readWrite},
+			{"csidrivers", readWrite},
+			{"csinodes", readWrite},
+			{"volumeattachments", readWrite},
+		}),
+		Entry("coordination.k8s.io/v1", "coordination.k8s.io/v1", []expectedResource{
+			{"leases", readWrite},
+		}),
+		Entry("discovery.k8s.io/v1", "discovery.k8s.io/v1", []expectedResource{
+			{"endpointslices", readWrite},
+		}),
+		Entry("authorization.k8s.io/v1", "authorization.k8s.io/v1", []expectedResource{
+			{"selfsubjectaccessreviews", []string{"create"}},
+			{"subjectaccessreviews", []string{"create"}},
+		}),
+		Entry("admissionregistration.k8s.io/v1", "admissionregistration.k8s.io/v1", []expectedResource{
+			{"validatingwebhookconfigurations", readWrite},
+			{"mutatingwebhookconfigurations", readWrite},
+		}),
+		Entry("apiextensions.k8s.io/v1", "apiextensions.k8s.io/v1", []expectedResource{
+			{"customresourcedefinitions", readWrite},
+		}),
+	)
+
+	It("should not serve API versions upstream has removed", func() {
+		server := framework.Server()
+		groups, err := framework.Clientset.Discovery().ServerGroups()
+		Expect(err).NotTo(HaveOccurred(), "Failed to list API groups")
+
+		var deprecated, removed []string
+		for _, group := range groups.Groups {
+			for _, groupVersion := range group.Versions {
+				removal, ok := removedIn[groupVersion.GroupVersion]
+				if !ok {
+					continue
+				}
+				if server.AtLeast(removal) {
+					removed = append(removed, fmt.Sprintf("%s (removed in %s)", groupVersion.GroupVersion, removal))
+				} else {
+					deprecated = append(deprecated, fmt.Sprintf("%s (removed in %s)", groupVersion.GroupVersion, removal))
+				}
+			}
+		}
+		sort.Strings(deprecated)
+		sort.Strings(removed)
+		// Still served before their removal release, but manifests using them break on upgrade
+		if len(deprecated) > 0 {
+			AddReportEntry("Deprecated API versions", strings.Join(deprecated, "\n"))
+		}
+		Expect(removed).To(BeEmpty(), "API server %s serves group versions upstream removed, likely from a stale aggregated API server:\n%s",
+			server.GitVersion, strings.Join(removed, "\n"))
+	})
+})
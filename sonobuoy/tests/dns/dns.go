@@ -0,0 +1,135 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Service fronting the cluster DNS, kept under this name by CoreDNS installations for compatibility
+const clusterDNSService = "kube-dns"
+
+// resolvConf is the parsed content of a pod's /etc/resolv.conf
+type resolvConf struct {
+	nameservers []string
+	searches    []string
+	options     []string
+}
+
+// parseResolvConf parses the nameserver, search and options lines of a resolv.conf
+func parseResolvConf(content string) resolvConf {
+	var conf resolvConf
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			conf.nameservers = append(conf.nameservers, fields[1])
+		case "search":
+			conf.searches = append(conf.searches, fields[1:]...)
+		case "options":
+			conf.options = append(conf.options, fields[1:]...)
+		}
+	}
+	return conf
+}
+
+var _ = Describe("Pod DNS policy", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-dns-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// resolvConfOf runs a pod with the DNS policy and config and returns its parsed /etc/resolv.conf
+	resolvConfOf := func(ctx context.Context, policy v1.DNSPolicy, config *v1.PodDNSConfig) resolvConf {
+		pod := framework.NewPod(namespace, name, podImage, "cat", "/etc/resolv.conf")
+		pod.Spec.DNSPolicy = policy
+		pod.Spec.DNSConfig = config
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		AddReportEntry("resolv.conf", output)
+		return parseResolvConf(output)
+	}
+
+	// clusterDNS returns the ClusterIP of the cluster DNS service
+	clusterDNS := func(ctx context.Context) string {
+		service, err := framework.Clientset.CoreV1().Services(metav1.NamespaceSystem).Get(ctx, clusterDNSService, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			Skip(fmt.Sprintf("Cluster DNS service %s/%s not found", metav1.NamespaceSystem, clusterDNSService))
+		}
+		Expect(err).NotTo(HaveOccurred(), "Failed to get cluster DNS service")
+		return service.Spec.ClusterIP
+	}
+
+	It("should point ClusterFirst pods at the cluster DNS with namespace search domains", func(ctx SpecContext) {
+		dnsIP := clusterDNS(ctx)
+		conf := resolvConfOf(ctx, v1.DNSClusterFirst, nil)
+		Expect(conf.nameservers).To(Equal([]string{dnsIP}), "Pod does not resolve through the cluster DNS")
+		Expect(conf.searches).NotTo(BeEmpty(), "Pod has no search domains")
+		Expect(conf.searches[0]).To(HavePrefix(namespace+".svc."), "First search domain is not the pod's namespace")
+		Expect(conf.options).To(ContainElement("ndots:5"), "Pod does not use the cluster's ndots")
+	})
+
+	It("should give Default pods the node's resolver configuration", func(ctx SpecContext) {
+		dnsIP := clusterDNS(ctx)
+		conf := resolvConfOf(ctx, v1.DNSDefault, nil)
+		Expect(conf.nameservers).NotTo(ContainElement(dnsIP), "Default pod resolves through the cluster DNS")
+		for _, search := range conf.searches {
+			Expect(search).NotTo(HavePrefix(namespace+".svc."), "Default pod has cluster search domains")
+		}
+	})
+
+	It("should use only the dnsConfig with None", func(ctx SpecContext) {
+		ndots := "2"
+		conf := resolvConfOf(ctx, v1.DNSNone, &v1.PodDNSConfig{
+			Nameservers: []string{"192.0.2.53", "192.0.2.54"},
+			Searches:    []string{"e2e.example", "sonobuoy.example"},
+			Options: []v1.PodDNSConfigOption{
+				{Name: "ndots", Value: &ndots},
+				{Name: "edns0"},
+			},
+		})
+		Expect(conf.nameservers).To(Equal([]string{"192.0.2.53", "192.0.2.54"}))
+		Expect(conf.searches).To(Equal([]string{"e2e.example", "sonobuoy.example"}))
+		Expect(conf.options).To(ConsistOf("ndots:2", "edns0"))
+	})
+
+	It("should merge dnsConfig into the ClusterFirst configuration", func(ctx SpecContext) {
+		dnsIP := clusterDNS(ctx)
+		ndots := "1"
+		conf := resolvConfOf(ctx, v1.DNSClusterFirst, &v1.PodDNSConfig{
+			Nameservers: []string{"192.0.2.53"},
+			Searches:    []string{"e2e.example"},
+			Options:     []v1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+		})
+		Expect(conf.nameservers).To(Equal([]string{dnsIP, "192.0.2.53"}), "Extra nameserver was not appended to the cluster DNS")
+		Expect(conf.searches[0]).To(HavePrefix(namespace+".svc."), "Cluster search domains were replaced")
+		Expect(conf.searches).To(ContainElement("e2e.example"), "Extra search domain was not appended")
+		Expect(conf.options).To(ContainElement("ndots:1"), "dnsConfig option did not override the cluster's ndots")
+		Expect(conf.options).NotTo(ContainElement("ndots:5"), "Overridden ndots option is still present")
+	})
+})
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("Pod hostAliases", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-hostaliases-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should add hostAliases to /etc/hosts and resolve them", func(ctx SpecContext) {
+		aliases := []v1.HostAlias{
+			{IP: "192.0.2.10", Hostnames: []string{"e2e-alias.example", "e2e-alias"}},
+			{IP: "2001:db8::10", Hostnames: []string{"e2e-alias6.example"}},
+		}
+		// getent resolves through /etc/hosts like applications do, unlike nslookup which only asks DNS
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c",
+			"cat /etc/hosts && echo --- && getent hosts e2e-alias.example && getent hosts e2e-alias && getent hosts e2e-alias6.example")
+		pod.Spec.HostAliases = aliases
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod could not resolve its hostAliases")
+
+		hosts, resolved, found := strings.Cut(output, "---")
+		Expect(found).To(BeTrue(), "Unexpected output: %s", output)
+		for _, alias := range aliases {
+			var quoted []string
+			for _, hostname := range alias.Hostnames {
+				quoted = append(quoted, regexp.QuoteMeta(hostname))
+			}
+			Expect(hosts).To(MatchRegexp(`(?m)^%s\s+%s\s*$`, regexp.QuoteMeta(alias.IP), strings.Join(quoted, `\s+`)),
+				"/etc/hosts has no entry for %s", alias.IP)
+		}
+
+		var lookups [][]string
+		for _, line := range strings.Split(strings.TrimSpace(resolved), "\n") {
+			lookups = append(lookups, strings.Fields(line))
+		}
+		Expect(lookups).To(HaveLen(3), "Unexpected lookups: %s", resolved)
+		Expect(lookups[0]).To(HaveExactElements("192.0.2.10", "e2e-alias.example", "e2e-alias"))
+		Expect(lookups[1]).To(HaveExactElements("192.0.2.10", "e2e-alias.example", "e2e-alias"))
+		Expect(lookups[2]).To(HaveExactElements("2001:db8::10", "e2e-alias6.example"))
+	})
+})
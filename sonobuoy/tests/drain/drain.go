@@ -0,0 +1,154 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Replicas of the drained Deployment before the spec scales it up
+const replicas = 3
+
+// cordon marks the node unschedulable, or schedulable again, with the patch kubectl cordon sends
+func cordon(ctx context.Context, node string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := framework.Clientset.CoreV1().Nodes().Patch(ctx, node, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// Does what kubectl cordon, drain and uncordon do, but only evicts the pods the spec created, so other
+// workloads on the node are left alone
+var _ = Describe("Node cordon and drain", Label(framework.LabelDisruptive), func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func(ctx SpecContext) {
+		// The drained pods need another node to move to
+		framework.RequireReadyNodes(ctx, 2)
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-drain-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PodDisruptionBudget")
+		err = framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployment")
+	})
+
+	// podsOn returns the spec's pods on the node, including ones still terminating
+	podsOn := func(ctx context.Context, node string) ([]v1.Pod, error) {
+		pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "app=" + name,
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return pods.Items, nil
+	}
+
+	It("should keep new pods off a cordoned node and drain its pods within the PodDisruptionBudget", func(ctx SpecContext) {
+		deployment := framework.NewDeployment(namespace, name, podImage, replicas, "sleep", "3600")
+		// The spec scales up to twice as many pods once a node is cordoned
+		framework.RequirePodRoom(ctx, 2*replicas, &deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+		maxUnavailable := intstr.FromInt(1)
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MaxUnavailable: &maxUnavailable,
+				Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+		}
+		_, err = framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace).Create(ctx, pdb, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PodDisruptionBudget")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not become available")
+
+		pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=" + name})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+		existing := sets.New[string]()
+		for _, pod := range pods.Items {
+			existing.Insert(pod.Name)
+		}
+		node := pods.Items[0].Spec.NodeName
+
+		By(fmt.Sprintf("cordoning node %s", node))
+		Expect(cordon(ctx, node, true)).To(Succeed(), "Failed to cordon node")
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(cordon(ctx, node, false)).To(Succeed(), "Failed to uncordon node %s", node)
+		})
+		framework.Eventually(func() ([]v1.Taint, error) {
+			cordoned, err := framework.Clientset.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return cordoned.Spec.Taints, nil
+		}, 30*time.Second, time.Second).Should(ContainElement(HaveField("Key", v1.TaintNodeUnschedulable)),
+			"Node controller did not taint the cordoned node")
+
+		By("scaling up and checking no new pod lands on the cordoned node")
+		patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, 2*replicas)
+		_, err = framework.Clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to scale Deployment")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not scale up around the cordoned node")
+		onNode, err := podsOn(ctx, node)
+		Expect(err).NotTo(HaveOccurred(), "Failed to list pods on node")
+		for _, pod := range onNode {
+			Expect(existing.Has(pod.Name)).To(BeTrue(), "New pod %s was scheduled to the cordoned node", pod.Name)
+		}
+
+		By("evicting the spec's pods from the cordoned node")
+		refused := 0
+		for _, pod := range onNode {
+			// The budget lets one pod go at a time, so later evictions wait for the replacement to become ready
+			framework.Eventually(func() error {
+				err := framework.Clientset.CoreV1().Pods(namespace).EvictV1(ctx, &policyv1.Eviction{
+					ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: namespace},
+				})
+				if errors.IsTooManyRequests(err) {
+					refused++
+				}
+				if errors.IsNotFound(err) {
+					return nil
+				}
+				return err
+			}, 180*time.Second, 2*time.Second).Should(Succeed(), "Failed to evict pod %s", pod.Name)
+		}
+		AddReportEntry("Drain", fmt.Sprintf("%d pods evicted from %s, %d evictions refused by the budget", len(onNode), node, refused))
+
+		framework.Eventually(func() ([]v1.Pod, error) {
+			return podsOn(ctx, node)
+		}, 180*time.Second, 2*time.Second).Should(BeEmpty(), "Evicted pods did not leave the cordoned node")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not recover on the remaining nodes")
+
+		By("uncordoning the node")
+		Expect(cordon(ctx, node, false)).To(Succeed(), "Failed to uncordon node")
+		framework.Eventually(func() (*v1.Node, error) {
+			return framework.Clientset.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+		}, 30*time.Second, time.Second).Should(And(
+			HaveField("Spec.Unschedulable", BeFalse()),
+			HaveField("Spec.Taints", Not(ContainElement(HaveField("Key", v1.TaintNodeUnschedulable)))),
+		), "Uncordoned node is still unschedulable")
+	})
+})
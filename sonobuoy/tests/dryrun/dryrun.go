@@ -0,0 +1,447 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// dryRunAll is the only dryRun value accepted by the apiserver
+var dryRunAll = []string{metav1.DryRunAll}
+
+// Dry-run requests go through admission, defaulting and validation but must never be persisted.
+// Every resource type covered by the other suites is exercised here.
+var _ = Describe("Server-side Dry-Run Validation", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-dryrun-%d", time.Now().UnixNano())
+	})
+
+	Context("Secrets", func() {
+		newSecret := func() *v1.Secret {
+			return &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string][]byte{
+					"password": []byte("secret"),
+				},
+			}
+		}
+
+		It("should default the type without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, newSecret(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of secret failed")
+			Expect(created.Type).To(Equal(v1.SecretTypeOpaque), "Secret type was not defaulted")
+
+			_, err = framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the secret")
+		})
+
+		It("should reject an invalid secret on dry-run create", func(ctx SpecContext) {
+			secret := newSecret()
+			secret.Data["not/a/valid/key"] = []byte("value")
+
+			_, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			secret, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, newSecret(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create secret")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete secret")
+			})
+
+			secret.Data["password"] = []byte("newsecret")
+			_, err = framework.Clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of secret failed")
+
+			err = framework.Clientset.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of secret failed")
+
+			stored, err := framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Secret was removed by dry-run delete")
+			Expect(stored.Data["password"]).To(Equal([]byte("secret")), "Dry-run update persisted the secret")
+		})
+	})
+
+	Context("ConfigMaps", func() {
+		newConfigMap := func() *v1.ConfigMap {
+			return &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Data: map[string]string{
+					"config-key": "config-value",
+				},
+			}
+		}
+
+		It("should not persist on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, newConfigMap(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of ConfigMap failed")
+			Expect(created.Data["config-key"]).To(Equal("config-value"))
+
+			_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the ConfigMap")
+		})
+
+		It("should reject an invalid ConfigMap on dry-run create", func(ctx SpecContext) {
+			configMap := newConfigMap()
+			configMap.Data["not/a/valid/key"] = "value"
+
+			_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, newConfigMap(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+			})
+
+			configMap.Data["config-key"] = "updated-value"
+			_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of ConfigMap failed")
+
+			err = framework.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of ConfigMap failed")
+
+			stored, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "ConfigMap was removed by dry-run delete")
+			Expect(stored.Data["config-key"]).To(Equal("config-value"), "Dry-run update persisted the ConfigMap")
+		})
+	})
+
+	Context("Deployments", func() {
+		newDeployment := func() *appsv1.Deployment {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: int32Ptr(1),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": name},
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": name},
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:    "alpine",
+									Image:   "alpine:3.20",
+									Command: []string{"sh", "-c", "sleep 3600"},
+								},
+							},
+						},
+					},
+				},
+			}
+			framework.Restrict(&deployment.Spec.Template.Spec)
+			return deployment
+		}
+
+		It("should apply defaults without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, newDeployment(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of deployment failed")
+			Expect(created.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType), "Strategy was not defaulted")
+			Expect(created.Spec.RevisionHistoryLimit).NotTo(BeNil(), "RevisionHistoryLimit was not defaulted")
+			Expect(created.Spec.ProgressDeadlineSeconds).NotTo(BeNil(), "ProgressDeadlineSeconds was not defaulted")
+
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the deployment")
+		})
+
+		It("should reject a selector that does not match the template on dry-run create", func(ctx SpecContext) {
+			deployment := newDeployment()
+			deployment.Spec.Template.Labels = map[string]string{"app": "something-else"}
+
+			_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, newDeployment(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+			})
+
+			deployment.Spec.Replicas = int32Ptr(3)
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of deployment failed")
+
+			err = framework.Clientset.AppsV1().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of deployment failed")
+
+			stored, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Deployment was removed by dry-run delete")
+			Expect(stored.Spec.Replicas).To(Equal(int32Ptr(1)), "Dry-run update persisted the deployment")
+		})
+	})
+
+	Context("Jobs", func() {
+		newJob := func() *batchv1.Job {
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: batchv1.JobSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:    "basic-task",
+									Image:   "alpine:3.20",
+									Command: []string{"sh", "-c", "echo 'Calculating something basic'"},
+								},
+							},
+							RestartPolicy: v1.RestartPolicyNever,
+						},
+					},
+				},
+			}
+			framework.Restrict(&job.Spec.Template.Spec)
+			return job
+		}
+
+		It("should apply defaults without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.BatchV1().Jobs(namespace).Create(ctx, newJob(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of job failed")
+			Expect(created.Spec.BackoffLimit).NotTo(BeNil(), "BackoffLimit was not defaulted")
+			Expect(created.Spec.Completions).To(Equal(int32Ptr(1)), "Completions was not defaulted")
+			Expect(created.Spec.Parallelism).To(Equal(int32Ptr(1)), "Parallelism was not defaulted")
+
+			_, err = framework.Clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the job")
+		})
+
+		It("should reject restartPolicy Always on dry-run create", func(ctx SpecContext) {
+			job := newJob()
+			job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyAlways
+
+			_, err := framework.Clientset.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			job, err := framework.Clientset.BatchV1().Jobs(namespace).Create(ctx, newJob(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create job")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.BatchV1().Jobs(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete job")
+			})
+
+			job.Spec.BackoffLimit = int32Ptr(1)
+			_, err = framework.Clientset.BatchV1().Jobs(namespace).Update(ctx, job, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of job failed")
+
+			err = framework.Clientset.BatchV1().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of job failed")
+
+			stored, err := framework.Clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Job was removed by dry-run delete")
+			Expect(stored.Spec.BackoffLimit).NotTo(Equal(int32Ptr(1)), "Dry-run update persisted the job")
+		})
+	})
+
+	Context("PersistentVolumeClaims", func() {
+		newPVC := func() *v1.PersistentVolumeClaim {
+			return &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceStorage: resource.MustParse("10Mi"),
+						},
+					},
+				},
+			}
+		}
+
+		It("should default the volume mode without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newPVC(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of PVC failed")
+			Expect(created.Spec.VolumeMode).NotTo(BeNil(), "VolumeMode was not defaulted")
+			Expect(*created.Spec.VolumeMode).To(Equal(v1.PersistentVolumeFilesystem))
+
+			_, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the PVC")
+		})
+
+		It("should reject a PVC without a storage request on dry-run create", func(ctx SpecContext) {
+			pvc := newPVC()
+			pvc.Spec.Resources.Requests = nil
+
+			_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newPVC(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create PVC")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+			})
+
+			pvc.Labels = map[string]string{"dry-run": "true"}
+			_, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of PVC failed")
+
+			err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of PVC failed")
+
+			stored, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "PVC was removed by dry-run delete")
+			Expect(stored.Labels).NotTo(HaveKey("dry-run"), "Dry-run update persisted the PVC")
+			Expect(stored.DeletionTimestamp).To(BeNil(), "Dry-run delete marked the PVC for deletion")
+		})
+	})
+
+	Context("HorizontalPodAutoscalers", func() {
+		newHPA := func() *autoscalingv1.HorizontalPodAutoscaler {
+			return &autoscalingv1.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+				},
+				Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+						Kind:       "Deployment",
+						Name:       name,
+						APIVersion: "apps/v1",
+					},
+					MaxReplicas:                    5,
+					TargetCPUUtilizationPercentage: int32Ptr(50),
+				},
+			}
+		}
+
+		It("should default minReplicas without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Create(ctx, newHPA(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of HPA failed")
+			Expect(created.Spec.MinReplicas).To(Equal(int32Ptr(1)), "MinReplicas was not defaulted")
+
+			_, err = framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the HPA")
+		})
+
+		It("should reject maxReplicas below minReplicas on dry-run create", func(ctx SpecContext) {
+			hpa := newHPA()
+			hpa.Spec.MinReplicas = int32Ptr(3)
+			hpa.Spec.MaxReplicas = 2
+
+			_, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Create(ctx, hpa, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "Expected Invalid error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			hpa, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Create(ctx, newHPA(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create HPA")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete HPA")
+			})
+
+			hpa.Spec.MaxReplicas = 10
+			_, err = framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(ctx, hpa, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of HPA failed")
+
+			err = framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of HPA failed")
+
+			stored, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "HPA was removed by dry-run delete")
+			Expect(stored.Spec.MaxReplicas).To(Equal(int32(5)), "Dry-run update persisted the HPA")
+		})
+	})
+
+	Context("PriorityClasses", func() {
+		newPriorityClass := func() *schedulingv1.PriorityClass {
+			return &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Value:         1000,
+				GlobalDefault: false,
+				Description:   "Test Priority Class",
+			}
+		}
+
+		It("should default the preemption policy without persisting on dry-run create", func(ctx SpecContext) {
+			created, err := framework.Clientset.SchedulingV1().PriorityClasses().Create(ctx, newPriorityClass(), metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run create of PriorityClass failed")
+			Expect(created.PreemptionPolicy).NotTo(BeNil(), "PreemptionPolicy was not defaulted")
+			Expect(*created.PreemptionPolicy).To(Equal(v1.PreemptLowerPriority))
+
+			_, err = framework.Clientset.SchedulingV1().PriorityClasses().Get(ctx, name, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "Dry-run create persisted the PriorityClass")
+		})
+
+		It("should reject a value above the user-definable range on dry-run create", func(ctx SpecContext) {
+			priorityClass := newPriorityClass()
+			priorityClass.Value = 2000000000
+
+			_, err := framework.Clientset.SchedulingV1().PriorityClasses().Create(ctx, priorityClass, metav1.CreateOptions{DryRun: dryRunAll})
+			Expect(errors.IsInvalid(err) || errors.IsForbidden(err)).To(BeTrue(), "Expected Invalid or Forbidden error, got: %v", err)
+		})
+
+		It("should not persist dry-run update and delete", func(ctx SpecContext) {
+			priorityClass, err := framework.Clientset.SchedulingV1().PriorityClasses().Create(ctx, newPriorityClass(), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create PriorityClass")
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.SchedulingV1().PriorityClasses(), name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete PriorityClass")
+			})
+
+			priorityClass.Description = "Updated by dry-run"
+			_, err = framework.Clientset.SchedulingV1().PriorityClasses().Update(ctx, priorityClass, metav1.UpdateOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run update of PriorityClass failed")
+
+			err = framework.Clientset.SchedulingV1().PriorityClasses().Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunAll})
+			Expect(err).NotTo(HaveOccurred(), "Dry-run delete of PriorityClass failed")
+
+			stored, err := framework.Clientset.SchedulingV1().PriorityClasses().Get(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "PriorityClass was removed by dry-run delete")
+			Expect(stored.Description).To(Equal("Test Priority Class"), "Dry-run update persisted the PriorityClass")
+		})
+	})
+})
+
+// Helper function to return a pointer to int32
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,98 @@
+// Package tests runs every e2e suite as a single Ginkgo suite, so that `go test ./...` or
+// `ginkgo run ./tests` produces one report with shared setup. Individual suites can still be
+// run on their own with the standalone build tag, e.g. `go test -tags standalone ./tests/configmap`.
+package tests
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+
+	// Suites register their specs when imported
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/accessmodes"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/apiservice"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/authz"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/blockvolume"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/concurrency"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/configmap"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/csr"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/daemonset"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/deploy"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/discovery"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/dns"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/drain"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/dryrun"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/emptydir"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/ephemeral"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/ephemeralvolume"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/eviction"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/fsgroup"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/hostnamespaces"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/hpa"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/imagepull"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/imagepullsecrets"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/jobs"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/kubelet"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/largeobjects"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/lease"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/limits"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/metrics"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/multicontainer"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/node"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/pagination"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/perf/apilatency"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/perf/churn"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/perf/hpareaction"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/perf/propagation"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/perf/pvclatency"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/podsecurity"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/podsubresources"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/priorityclass"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/projected"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/pvc"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/pvcclone"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/qos"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/reclaimpolicy"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/replicaset"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/resilience"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/restartpolicy"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/rollout"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/scale"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/scenarios"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/schedulinggates"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/seccomp"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/secrets"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/securitycontext"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/selectors"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/snapshot"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/statefulset"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/storagecapacity"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/subpath"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/sysctls"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/token"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/watch"
+	_ "github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/tests/webhooks"
+)
+
+// Register the setup, spec and report nodes every entry point shares
+var _ = framework.RegisterEntryPoint()
+
+// TestMain rejects an invalid run configuration before any spec runs
+func TestMain(m *testing.M) {
+	if _, err := framework.LoadRunConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// Entry point for running all suites together
+func TestE2E(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sonobuoy E2E Suite")
+}
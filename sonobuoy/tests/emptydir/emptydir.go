@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the emptyDir is mounted in every container
+const cacheMountPath = "/cache"
+
+// Reason the kubelet gives pods it evicted
+const evictedReason = "Evicted"
+
+// emptyDirPod returns a pod mounting an emptyDir with the given medium and size limit in every container
+func emptyDirPod(namespace, name string, medium v1.StorageMedium, sizeLimit *resource.Quantity, command ...string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, command...)
+	pod.Spec.Volumes = []v1.Volume{{
+		Name:         "cache",
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: medium, SizeLimit: sizeLimit}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "cache", MountPath: cacheMountPath}}
+	return pod
+}
+
+var _ = Describe("EmptyDir volumes", Label(framework.LabelNode), func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-emptydir-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should share files between the containers of a pod", func(ctx SpecContext) {
+		pod := emptyDirPod(namespace, podName, v1.StorageMediumDefault, nil,
+			"sh", "-c", "echo shared-by-writer > "+cacheMountPath+"/shared && sleep 3600")
+		pod.Spec.Containers[0].Name = "writer"
+		reader := *pod.Spec.Containers[0].DeepCopy()
+		reader.Name = "reader"
+		reader.Command = []string{"sleep", "3600"}
+		pod.Spec.Containers = append(pod.Spec.Containers, reader)
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+
+		framework.Eventually(func() (string, error) {
+			result, err := framework.ExecInPod(ctx, namespace, podName, "reader", "cat", cacheMountPath+"/shared")
+			return strings.TrimSpace(result.Stdout), err
+		}, 60*time.Second, 2*time.Second).Should(Equal("shared-by-writer"), "Reader container did not see the writer's file")
+
+		_, err = framework.ExecInPod(ctx, namespace, podName, "reader", "sh", "-c", "echo shared-by-reader > "+cacheMountPath+"/reply")
+		Expect(err).NotTo(HaveOccurred(), "Reader container could not write to the emptyDir")
+		result, err := framework.ExecInPod(ctx, namespace, podName, "writer", "cat", cacheMountPath+"/reply")
+		Expect(err).NotTo(HaveOccurred(), "Writer container did not see the reader's file")
+		Expect(strings.TrimSpace(result.Stdout)).To(Equal("shared-by-reader"))
+	})
+
+	It("should back a Memory medium emptyDir with tmpfs", func(ctx SpecContext) {
+		sizeLimit := resource.MustParse("16Mi")
+		pod := emptyDirPod(namespace, podName, v1.StorageMediumMemory, &sizeLimit, "sh", "-c",
+			fmt.Sprintf("grep ' %[1]s ' /proc/mounts | cut -d' ' -f3 && echo in-memory > %[1]s/file && cat %[1]s/file", cacheMountPath))
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		Expect(strings.Fields(output)).To(Equal([]string{"tmpfs", "in-memory"}), "Memory medium emptyDir is not a writable tmpfs")
+	})
+
+	// The kubelet measures emptyDir usage periodically, so eviction can take a couple of minutes
+	It("should evict a pod whose emptyDir exceeds its sizeLimit", func(ctx SpecContext) {
+		sizeLimit := resource.MustParse("10Mi")
+		pod := emptyDirPod(namespace, podName, v1.StorageMediumDefault, &sizeLimit, "sh", "-c",
+			fmt.Sprintf("dd if=/dev/zero of=%s/fill bs=1M count=20 && sleep 3600", cacheMountPath))
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		pod, err = framework.WaitForPodPhase(ctx, framework.Clientset, namespace, podName, v1.PodFailed, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Pod exceeding its emptyDir sizeLimit was not evicted")
+		Expect(pod.Status.Reason).To(Equal(evictedReason), "Pod failed for another reason: %s", pod.Status.Message)
+		Expect(pod.Status.Message).To(ContainSubstring("emptyDir"), "Pod was evicted for another reason")
+	})
+})
@@ -0,0 +1,93 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+const debuggerName = "debugger"
+
+// What the target container writes to its own filesystem for the debugger to find
+const targetMarker = "written by the target container"
+
+func boolPtr(b bool) *bool { return &b }
+
+// This is what kubectl debug --target does: the ephemeral container joins the process namespace of the
+// target container, and reaches its filesystem through /proc/<pid>/root.
+var _ = Describe("Ephemeral Debug Containers", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-ephemeral-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should attach a debug container that inspects the target's processes and filesystem", func(ctx SpecContext) {
+		pods := framework.Clientset.CoreV1().Pods(namespace)
+		target := framework.NewPod(namespace, podName, podImage, "sh", "-c",
+			fmt.Sprintf("echo '%s' > /tmp/marker && exec sleep 3600", targetMarker))
+		_, err := pods.Create(ctx, target, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create target pod")
+		target, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Target pod did not start")
+
+		// Ephemeral containers are not covered by the pod's container defaults, so they are restricted explicitly
+		script := `pid=$(pgrep -x sleep) || { echo "target process not visible"; exit 1; }
+echo "target pid $pid"
+cat /proc/$pid/root/tmp/marker`
+		target.Spec.EphemeralContainers = append(target.Spec.EphemeralContainers, v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{
+				Name:    debuggerName,
+				Image:   podImage,
+				Command: []string{"sh", "-c", script},
+				SecurityContext: &v1.SecurityContext{
+					AllowPrivilegeEscalation: boolPtr(false),
+					Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+				},
+			},
+			TargetContainerName: target.Spec.Containers[0].Name,
+		})
+		_, err = pods.UpdateEphemeralContainers(ctx, podName, target, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to add ephemeral container")
+
+		framework.Eventually(func() (*v1.ContainerStateTerminated, error) {
+			pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			for _, status := range pod.Status.EphemeralContainerStatuses {
+				if status.Name == debuggerName {
+					return status.State.Terminated, nil
+				}
+			}
+			return nil, nil
+		}, 120*time.Second, 2*time.Second).ShouldNot(BeNil(), "Ephemeral container did not run to completion")
+
+		logs, err := pods.GetLogs(podName, &v1.PodLogOptions{Container: debuggerName}).DoRaw(ctx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to get ephemeral container logs")
+		Expect(string(logs)).To(ContainSubstring("target pid"), "Debug container cannot see the target's processes:\n%s", logs)
+		Expect(string(logs)).To(ContainSubstring(targetMarker), "Debug container cannot read the target's filesystem:\n%s", logs)
+
+		// Adding the debugger must not disturb the target
+		pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get pod")
+		Expect(pod.Status.Phase).To(Equal(v1.PodRunning), "Target pod stopped running")
+		Expect(pod.Status.ContainerStatuses[0].RestartCount).To(BeZero(), "Target container restarted")
+	})
+})
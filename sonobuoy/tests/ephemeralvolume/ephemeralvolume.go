@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Name of the ephemeral volume in the pod; its PVC is named <pod>-<volume>
+const scratchVolume = "scratch"
+
+var _ = Describe("Generic ephemeral volumes", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func(ctx SpecContext) {
+		// The claim template relies on the default StorageClass to provision a volume
+		framework.RequireStorageClass(ctx, "")
+
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-ephemeral-volume-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should create a PVC owned by the pod and delete it with the pod", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", "echo persisted > /scratch/file && cat /scratch/file && sleep 3600")
+		pod.Spec.Volumes = []v1.Volume{{
+			Name: scratchVolume,
+			VolumeSource: v1.VolumeSource{Ephemeral: &v1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": podName}},
+					Spec: v1.PersistentVolumeClaimSpec{
+						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Mi")},
+						},
+					},
+				},
+			}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: scratchVolume, MountPath: "/scratch"}}
+		created, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod with an ephemeral volume")
+
+		By("checking the PVC is created from the template and owned by the pod")
+		pvcName := podName + "-" + scratchVolume
+		var pvc *v1.PersistentVolumeClaim
+		framework.Eventually(func() error {
+			pvc, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+			return err
+		}, 60*time.Second, 2*time.Second).Should(Succeed(), "PVC for the ephemeral volume was not created")
+		Expect(pvc.Labels).To(HaveKeyWithValue("app", podName), "PVC does not carry the template's labels")
+		owner := metav1.GetControllerOf(pvc)
+		Expect(owner).NotTo(BeNil(), "PVC has no controller owner reference")
+		Expect(owner.Kind).To(Equal("Pod"))
+		Expect(owner.Name).To(Equal(podName))
+		Expect(owner.UID).To(Equal(created.UID), "PVC is owned by another pod of the same name")
+
+		By("checking the pod can use the volume")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod with an ephemeral volume did not start")
+		framework.Eventually(func() (string, error) {
+			result, err := framework.ExecInPod(ctx, namespace, podName, "", "cat", "/scratch/file")
+			return strings.TrimSpace(result.Stdout), err
+		}, 60*time.Second, 2*time.Second).Should(Equal("persisted"), "Pod could not write to its ephemeral volume")
+		pvc, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PVC")
+		Expect(pvc.Status.Phase).To(Equal(v1.ClaimBound), "PVC of a running pod is not bound")
+
+		By("deleting the pod and checking the PVC is garbage collected")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		framework.Eventually(func() bool {
+			_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+			return apierrors.IsNotFound(err)
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "PVC outlived the pod that owned it")
+	})
+})
@@ -0,0 +1,128 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+var _ = Describe("Eviction API", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-eviction-%d", time.Now().UnixNano())
+
+		// sleep runs as PID 1 and ignores SIGTERM, so the evicted pod stays Terminating for its grace period
+		pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PodDisruptionBudget")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// evict asks the Eviction API to evict the pod, as kubectl drain does
+	evict := func(ctx context.Context) error {
+		return framework.Clientset.CoreV1().Pods(namespace).EvictV1(ctx, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		})
+	}
+
+	// waitForEvicted waits until the pod is terminating and returns it
+	waitForEvicted := func(ctx context.Context) *v1.Pod {
+		var pod *v1.Pod
+		framework.Eventually(func() (*metav1.Time, error) {
+			var err error
+			pod, err = framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return pod.DeletionTimestamp, nil
+		}, 30*time.Second, time.Second).ShouldNot(BeNil(), "Evicted pod was not marked for deletion")
+		return pod
+	}
+
+	It("should evict a pod and mark it with a DisruptionTarget condition", func(ctx SpecContext) {
+		Expect(evict(ctx)).To(Succeed(), "Failed to evict pod")
+
+		pod := waitForEvicted(ctx)
+		Expect(pod.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", v1.DisruptionTarget),
+			HaveField("Status", v1.ConditionTrue),
+			HaveField("Reason", "EvictionByEvictionAPI"),
+		)), "Evicted pod has no DisruptionTarget condition")
+
+		framework.Eventually(func() bool {
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Evicted pod was not deleted within the timeout")
+	})
+
+	It("should refuse an eviction a PodDisruptionBudget forbids with 429", func(ctx SpecContext) {
+		minAvailable := intstr.FromInt(1)
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+		}
+		_, err := framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace).Create(ctx, pdb, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PodDisruptionBudget")
+
+		// Wait for the disruption controller to count the pod, so the refusal is the budget's and not a stale status
+		framework.Eventually(func() (*policyv1.PodDisruptionBudget, error) {
+			return framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace).Get(ctx, name, metav1.GetOptions{})
+		}, 60*time.Second, 2*time.Second).Should(And(
+			HaveField("Status.ObservedGeneration", BeNumerically(">=", 1)),
+			HaveField("Status.CurrentHealthy", int32(1)),
+			HaveField("Status.DisruptionsAllowed", int32(0)),
+		), "Disruption controller did not process the PodDisruptionBudget")
+
+		By("evicting the only pod the budget protects")
+		err = evict(ctx)
+		Expect(errors.IsTooManyRequests(err)).To(BeTrue(), "Eviction violating the budget was not refused with 429: %v", err)
+		pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get pod")
+		Expect(pod.DeletionTimestamp).To(BeNil(), "Refused eviction deleted the pod")
+
+		By("relaxing the budget")
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pdb, err := framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			minAvailable := intstr.FromInt(0)
+			pdb.Spec.MinAvailable = &minAvailable
+			_, err = framework.Clientset.PolicyV1().PodDisruptionBudgets(namespace).Update(ctx, pdb, metav1.UpdateOptions{})
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update PodDisruptionBudget")
+
+		// The eviction is refused until the controller has recomputed the allowed disruptions
+		framework.Eventually(evict, 60*time.Second, 2*time.Second).Should(Succeed(), "Eviction was still refused after the budget allowed it")
+		waitForEvicted(ctx)
+	})
+})
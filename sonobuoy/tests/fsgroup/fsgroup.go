@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the pods mount the volume
+const dataMountPath = "/data"
+
+// Identities the pods run as: a non-root user, the fsGroup volumes are given, a second fsGroup to change
+// them to, and a supplemental group files are moved to behind the kubelet's back
+const (
+	runAsUser    = int64(1000)
+	fsGroup      = int64(2000)
+	otherFSGroup = int64(3000)
+	strayGroup   = int64(4000)
+)
+
+var _ = Describe("fsGroup volume permissions", func() {
+	var namespace string
+	var pvcName string
+
+	BeforeEach(func(ctx SpecContext) {
+		class := framework.RequireTestStorageClass(ctx)
+
+		namespace = framework.TestNamespace()
+		pvcName = fmt.Sprintf("test-fsgroup-%d", time.Now().UnixNano())
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: namespace},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				StorageClassName: &class.Name,
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PVC")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), pvcName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+	})
+
+	// runWithClaim runs script as runAsUser with the PVC mounted at dataMountPath, given group, change policy
+	// and supplemental groups, deletes the pod and returns its output
+	runWithClaim := func(ctx context.Context, group int64, policy v1.PodFSGroupChangePolicy, supplementalGroups []int64, script string) string {
+		name := fmt.Sprintf("%s-%d", pvcName, time.Now().UnixNano())
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+		uid := runAsUser
+		pod.Spec.SecurityContext.RunAsUser = &uid
+		pod.Spec.SecurityContext.RunAsGroup = &uid
+		pod.Spec.SecurityContext.FSGroup = &group
+		pod.Spec.SecurityContext.FSGroupChangePolicy = &policy
+		pod.Spec.SecurityContext.SupplementalGroups = supplementalGroups
+		pod.Spec.Volumes = []v1.Volume{{
+			Name: "data",
+			VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+			}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: dataMountPath}}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, name, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		return strings.TrimSpace(output)
+	}
+
+	// fileGroup returns the group owning the file below dataMountPath, as seen by a pod with the given fsGroup and policy
+	fileGroup := func(ctx context.Context, group int64, policy v1.PodFSGroupChangePolicy, path string) string {
+		return runWithClaim(ctx, group, policy, nil, fmt.Sprintf("stat -c %%g %s/%s", dataMountPath, path))
+	}
+
+	It("should make the volume writable by a non-root user through fsGroup", func(ctx SpecContext) {
+		script := fmt.Sprintf(`stat -c '%%g' %[1]s && mkdir %[1]s/dir && echo written > %[1]s/dir/file && stat -c '%%u %%g' %[1]s/dir/file && cat %[1]s/dir/file`, dataMountPath)
+		lines := strings.Split(runWithClaim(ctx, fsGroup, v1.FSGroupChangeAlways, nil, script), "\n")
+		Expect(lines).To(HaveLen(3), "Unexpected output: %v", lines)
+		if lines[0] != fmt.Sprint(fsGroup) {
+			Skip(fmt.Sprintf("The volume's CSI driver does not apply fsGroup: the volume is owned by group %s", lines[0]))
+		}
+		Expect(lines[1]).To(Equal(fmt.Sprintf("%d %d", runAsUser, fsGroup)), "Files written by the non-root user do not inherit the fsGroup")
+		Expect(lines[2]).To(Equal("written"), "Non-root user could not write to the volume")
+	})
+
+	It("should only change ownership on root mismatch with OnRootMismatch", func(ctx SpecContext) {
+		By("populating the volume with the first fsGroup")
+		created := runWithClaim(ctx, fsGroup, v1.FSGroupChangeAlways, nil, fmt.Sprintf("stat -c %%g %[1]s && mkdir %[1]s/dir && touch %[1]s/dir/file", dataMountPath))
+		if created != fmt.Sprint(fsGroup) {
+			Skip(fmt.Sprintf("The volume's CSI driver does not apply fsGroup: the volume is owned by group %s", created))
+		}
+
+		By("moving a file to another group while the volume root keeps the fsGroup")
+		runWithClaim(ctx, fsGroup, v1.FSGroupChangeAlways, []int64{strayGroup}, fmt.Sprintf("chgrp %d %s/dir/file", strayGroup, dataMountPath))
+
+		By("checking OnRootMismatch leaves the file alone when the root matches")
+		Expect(fileGroup(ctx, fsGroup, v1.FSGroupChangeOnRootMismatch, "dir/file")).To(Equal(fmt.Sprint(strayGroup)),
+			"OnRootMismatch changed ownership although the volume root matched the fsGroup")
+
+		By("checking Always changes the file back")
+		Expect(fileGroup(ctx, fsGroup, v1.FSGroupChangeAlways, "dir/file")).To(Equal(fmt.Sprint(fsGroup)),
+			"Always did not change the ownership of every file")
+
+		By("checking OnRootMismatch changes everything when the root does not match")
+		Expect(fileGroup(ctx, otherFSGroup, v1.FSGroupChangeOnRootMismatch, "dir/file")).To(Equal(fmt.Sprint(otherFSGroup)),
+			"OnRootMismatch did not change ownership although the volume root did not match the fsGroup")
+	})
+})
@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Node agents typically need the node's network, processes or IPC. Host namespaces violate the baseline
+// Pod Security Standard, so each spec works in its own namespace labeled with the level it needs.
+var _ = Describe("Host Namespaces", Label(framework.LabelPrivileged), func() {
+	var namespace string
+	var podName string
+
+	// runPod creates the pod and returns what it printed once it completed
+	runPod := func(ctx context.Context, pod *v1.Pod) string {
+		GinkgoHelper()
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod %s", pod.Name)
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, pod.Name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod %s did not complete", pod.Name)
+		return output
+	}
+
+	BeforeEach(func() {
+		podName = fmt.Sprintf("test-pod-%d", time.Now().UnixNano())
+	})
+
+	It("should give hostNetwork pods the node's IP", Label(framework.LabelNode), func(ctx SpecContext) {
+		namespace = framework.CreateNamespace(ctx, "test-hostnamespaces", framework.PodSecurityPrivileged)
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", `ip -4 addr show | grep -F " $HOST_IP/"`)
+		pod.Spec.HostNetwork = true
+		pod.Spec.Containers[0].Env = []v1.EnvVar{{
+			Name:      "HOST_IP",
+			ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.hostIP"}},
+		}}
+
+		output := runPod(ctx, pod)
+		pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get pod")
+		Expect(pod.Status.PodIP).To(Equal(pod.Status.HostIP), "hostNetwork pod was given its own IP")
+		Expect(output).To(ContainSubstring(pod.Status.HostIP), "Node IP is not configured on the pod's interfaces")
+	})
+
+	It("should let hostPID pods see the node's processes", func(ctx SpecContext) {
+		namespace = framework.CreateNamespace(ctx, "test-hostnamespaces", framework.PodSecurityPrivileged)
+		// PID 1 is the node's init rather than the container's own command
+		script := "cat /proc/1/comm; ls -d /proc/[0-9]* | wc -l"
+
+		isolated := runPod(ctx, framework.NewPod(namespace, podName+"-isolated", podImage, "sh", "-c", script))
+		hostPID := framework.NewPod(namespace, podName, podImage, "sh", "-c", script)
+		hostPID.Spec.HostPID = true
+		shared := runPod(ctx, hostPID)
+
+		isolatedLines, sharedLines := strings.Fields(isolated), strings.Fields(shared)
+		Expect(isolatedLines).To(HaveLen(2), "Unexpected output: %q", isolated)
+		Expect(sharedLines).To(HaveLen(2), "Unexpected output: %q", shared)
+		Expect(isolatedLines[0]).To(Equal("sh"), "Pod without hostPID does not have its own PID namespace")
+		Expect(sharedLines[0]).NotTo(Equal("sh"), "hostPID pod does not see the node's init process")
+		Expect(sharedLines[1]).NotTo(Equal(isolatedLines[1]), "hostPID pod sees no more processes than an isolated pod")
+	})
+
+	It("should let hostIPC pods share the node's IPC namespace", func(ctx SpecContext) {
+		namespace = framework.CreateNamespace(ctx, "test-hostnamespaces", framework.PodSecurityPrivileged)
+		// Under hostIPC the runtime mounts the node's /dev/shm, so a marker written by one pod is visible
+		// to every other hostIPC pod on the node, and to no other pod
+		marker := "/dev/shm/" + podName
+		writer := framework.NewPod(namespace, podName, podImage, "sh", "-c",
+			fmt.Sprintf("touch %s && trap 'rm -f %s; exit 0' TERM && while true; do sleep 1; done", marker, marker))
+		writer.Spec.HostIPC = true
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, writer, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		writer, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "hostIPC pod did not start")
+
+		probe := fmt.Sprintf("if [ -e %s ]; then echo shared; else echo isolated; fi", marker)
+		reader := framework.NewPod(namespace, podName+"-hostipc", podImage, "sh", "-c", probe)
+		reader.Spec.HostIPC = true
+		reader.Spec.NodeName = writer.Spec.NodeName
+		Expect(runPod(ctx, reader)).To(ContainSubstring("shared"), "hostIPC pods on the same node do not share IPC")
+
+		isolated := framework.NewPod(namespace, podName+"-isolated", podImage, "sh", "-c", probe)
+		isolated.Spec.NodeName = writer.Spec.NodeName
+		Expect(runPod(ctx, isolated)).To(ContainSubstring("isolated"), "Pod without hostIPC sees the node's IPC")
+	})
+
+	It("should reject host namespaces under restricted enforcement", func(ctx SpecContext) {
+		namespace = framework.CreateNamespace(ctx, "test-hostnamespaces", framework.PodSecurityRestricted)
+		pods := framework.Clientset.CoreV1().Pods(namespace)
+
+		for name, enable := range map[string]func(*v1.PodSpec){
+			"hostNetwork": func(spec *v1.PodSpec) { spec.HostNetwork = true },
+			"hostPID":     func(spec *v1.PodSpec) { spec.HostPID = true },
+			"hostIPC":     func(spec *v1.PodSpec) { spec.HostIPC = true },
+		} {
+			pod := framework.NewPod(namespace, podName+"-"+strings.ToLower(name), podImage, "sleep", "3600")
+			enable(&pod.Spec)
+			_, err := pods.Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).To(HaveOccurred(), "%s pod was admitted", name)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue(), "Expected Forbidden for %s, got: %v", name, err)
+			Expect(err.Error()).To(ContainSubstring("host namespaces"), "%s pod was rejected for another reason", name)
+		}
+	})
+})
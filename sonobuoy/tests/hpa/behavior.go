@@ -0,0 +1,173 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// CPU each pod requests, and the limit that keeps a busy pod at 200% utilization
+const (
+	cpuRequest = "100m"
+	cpuLimit   = "200m"
+)
+
+// Period of the scale-up policy; the controller must not add more pods than allowed within it
+const scaleUpPeriodSeconds = 60
+
+// Scale-down stabilization window; the controller must keep the highest recommendation within it
+const scaleDownWindowSeconds = 60
+
+// replicaChange is a change of the Deployment's replicas observed while the HPA acted on it
+type replicaChange struct {
+	at       time.Time
+	replicas int32
+}
+
+// Needs metrics-server, or another provider of the resource metrics API, to report pod CPU usage
+var _ = Describe("HPA scaling behavior", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		framework.RequireAPIGroupVersion("autoscaling/v2")
+		framework.RequireAPIGroupVersion("metrics.k8s.io/v1beta1")
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-hpa-behavior-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete HPA")
+		err = framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+
+	// createDeployment creates a Deployment of replicas pods requesting cpuRequest each and waits for it to roll out
+	createDeployment := func(ctx context.Context, replicas int32, command ...string) {
+		deployment := framework.NewDeployment(namespace, name, podImage, replicas, command...)
+		deployment.Spec.Template.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpuRequest)},
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpuLimit)},
+		}
+		// The specs' HPAs scale between one and three replicas
+		framework.RequirePodRoom(ctx, 3, &deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// createHPA creates an HPA targeting 50% CPU utilization of the Deployment with the given behavior
+	createHPA := func(ctx context.Context, maxReplicas int32, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       name,
+				},
+				MinReplicas: int32Ptr(1),
+				MaxReplicas: maxReplicas,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: v1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: int32Ptr(50),
+						},
+					},
+				}},
+				Behavior: behavior,
+			},
+		}
+		_, err := framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(ctx, hpa, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create HPA")
+	}
+
+	// watchReplicas polls the Deployment's replicas until done returns true and returns every change seen,
+	// starting with the initial count, along with the reasons of every true HPA condition seen meanwhile
+	watchReplicas := func(ctx context.Context, timeout time.Duration, done func(replicas int32) bool) ([]replicaChange, map[string]bool) {
+		var history []replicaChange
+		reasons := map[string]bool{}
+		framework.Eventually(func() (bool, error) {
+			deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			replicas := *deployment.Spec.Replicas
+			if len(history) == 0 || history[len(history)-1].replicas != replicas {
+				history = append(history, replicaChange{at: time.Now(), replicas: replicas})
+			}
+			hpa, err := framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			for _, condition := range hpa.Status.Conditions {
+				if condition.Status == v1.ConditionTrue {
+					reasons[condition.Reason] = true
+				}
+			}
+			return done(replicas), nil
+		}, timeout, 2*time.Second).Should(BeTrue(), "HPA did not reach the expected replicas within the timeout")
+		AddReportEntry("Replica history", fmt.Sprintf("%+v", history))
+		return history, reasons
+	}
+
+	It("should add pods no faster than the scaleUp policy allows", func(ctx SpecContext) {
+		createDeployment(ctx, 1, "sh", "-c", "while :; do :; done")
+		createHPA(ctx, 3, &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleUp: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: int32Ptr(0),
+				Policies: []autoscalingv2.HPAScalingPolicy{{
+					Type:          autoscalingv2.PodsScalingPolicy,
+					Value:         1,
+					PeriodSeconds: scaleUpPeriodSeconds,
+				}},
+			},
+		})
+
+		// Busy pods use 200% of their request, so the HPA wants far more than maxReplicas right away
+		history, reasons := watchReplicas(ctx, 6*time.Minute, func(replicas int32) bool { return replicas == 3 })
+		Expect(history).To(HaveLen(3), "Replicas did not step 1, 2, 3: %+v", history)
+		for i := 1; i < len(history); i++ {
+			Expect(history[i].replicas).To(Equal(history[i-1].replicas+1), "Scale-up added more than one pod at once: %+v", history)
+		}
+		gap := history[2].at.Sub(history[1].at)
+		// Polling can observe the first step up to one interval late
+		Expect(gap).To(BeNumerically(">=", scaleUpPeriodSeconds*time.Second-2*time.Second),
+			"Second pod was added %s after the first, within the policy period", gap)
+		Expect(reasons).To(HaveKey("ScaleUpLimit"), "HPA never reported that the scale-up policy limited it")
+	})
+
+	It("should hold replicas for the scaleDown stabilization window", func(ctx SpecContext) {
+		createDeployment(ctx, 3, "sleep", "3600")
+		created := time.Now()
+		createHPA(ctx, 3, &autoscalingv2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: int32Ptr(scaleDownWindowSeconds),
+			},
+		})
+
+		// Idle pods recommend a single replica, but the window remembers the three the HPA started with
+		history, reasons := watchReplicas(ctx, 5*time.Minute, func(replicas int32) bool { return replicas == 1 })
+		scaledDown := history[len(history)-1].at.Sub(created)
+		Expect(scaledDown).To(BeNumerically(">=", scaleDownWindowSeconds*time.Second),
+			"HPA scaled down %s after it was created, within the stabilization window", scaledDown)
+		Expect(reasons).To(HaveKey("ScaleDownStabilized"), "HPA never reported that the stabilization window held a scale-down")
+	})
+})
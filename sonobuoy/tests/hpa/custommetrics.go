@@ -0,0 +1,118 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// externalMetricList is the part of an external.metrics.k8s.io/v1beta1 ExternalMetricValueList the spec reads
+type externalMetricList struct {
+	Items []struct {
+		MetricName string            `json:"metricName"`
+		Value      resource.Quantity `json:"value"`
+		Timestamp  metav1.Time       `json:"timestamp"`
+	} `json:"items"`
+}
+
+// Needs a metrics adapter, e.g. prometheus-adapter or KEDA, registered with the aggregation layer
+var _ = Describe("HPA on custom metrics", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-hpa-metric-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete HPA")
+		err = framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+
+	It("should serve metrics through the custom metrics API", func(ctx SpecContext) {
+		framework.RequireAPIService(ctx, "v1beta1.custom.metrics.k8s.io")
+
+		resources, err := framework.Clientset.Discovery().ServerResourcesForGroupVersion("custom.metrics.k8s.io/v1beta1")
+		Expect(err).NotTo(HaveOccurred(), "Failed to discover custom metrics")
+		Expect(resources.APIResources).NotTo(BeEmpty(), "Custom metrics API is available but serves no metrics")
+		AddReportEntry("Custom metrics", fmt.Sprintf("%d metrics served", len(resources.APIResources)))
+	})
+
+	It("should read an external metric and scale on it", func(ctx SpecContext) {
+		metric := framework.RequireExternalMetric(ctx)
+
+		By("reading the metric through the external metrics API")
+		raw, err := framework.Clientset.Discovery().RESTClient().Get().
+			AbsPath("/apis/external.metrics.k8s.io/v1beta1/namespaces", namespace, metric).
+			DoRaw(ctx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to read external metric %s", metric)
+		var values externalMetricList
+		Expect(json.Unmarshal(raw, &values)).To(Succeed(), "Failed to decode external metric %s", metric)
+		Expect(values.Items).NotTo(BeEmpty(), "External metrics API returned no value for %s", metric)
+		AddReportEntry("External metric", fmt.Sprintf("%s = %s at %s", metric, values.Items[0].Value.String(), values.Items[0].Timestamp))
+
+		By("creating an HPA targeting the metric")
+		deployment := framework.NewDeployment(namespace, name, podImage, 1, "sleep", "3600")
+		_, err = framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+
+		// A target no metric reaches keeps the Deployment at minReplicas; the spec is about reading the metric
+		target := resource.MustParse("1P")
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       name,
+				},
+				MinReplicas: int32Ptr(1),
+				MaxReplicas: 2,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricSource{
+						Metric: autoscalingv2.MetricIdentifier{Name: metric},
+						Target: autoscalingv2.MetricTarget{
+							Type:         autoscalingv2.AverageValueMetricType,
+							AverageValue: &target,
+						},
+					},
+				}},
+			},
+		}
+		_, err = framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(ctx, hpa, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create HPA")
+
+		By("waiting for the HPA to read the metric")
+		framework.Eventually(func() (*autoscalingv2.HorizontalPodAutoscaler, error) {
+			return framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+		}, 180*time.Second, 5*time.Second).Should(And(
+			HaveField("Status.Conditions", ContainElement(And(
+				HaveField("Type", autoscalingv2.ScalingActive),
+				HaveField("Status", v1.ConditionTrue),
+				HaveField("Reason", "ValidMetricFound"),
+			))),
+			HaveField("Status.CurrentMetrics", ContainElement(HaveField("External.Metric.Name", metric))),
+		), "HPA did not read external metric %s", metric)
+
+		updated, err := framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get HPA")
+		Expect(updated.Status.DesiredReplicas).To(Equal(int32(1)), "HPA scaled beyond minReplicas on an unreachable target")
+		for _, condition := range updated.Status.Conditions {
+			AddReportEntry("HPA condition", fmt.Sprintf("%s=%s (%s): %s", condition.Type, condition.Status, condition.Reason, condition.Message))
+		}
+	})
+})
@@ -1,7 +1,6 @@
 package e2e
 
 import (
-	"context"
 	"fmt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -9,52 +8,19 @@ import (
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
-	"os"
-	"path/filepath"
-	"testing"
 	"time"
-)
-
-var clientset *kubernetes.Clientset
-
-var _ = BeforeSuite(func() {
-	var config *rest.Config
-	var err error
-
-	// Use in-cluster config if available, or default to KUBECONFIG
-	config, err = rest.InClusterConfig()
-	if err != nil {
-		kubeconfig := os.Getenv("KUBECONFIG")
-		if kubeconfig == "" {
-			if home := homedir.HomeDir(); home != "" {
-				kubeconfig = filepath.Join(home, ".kube", "config")
-			} else {
-				kubeconfig = "/root/.kube/config"
-			}
-		}
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		Expect(err).NotTo(HaveOccurred(), "Failed to load kubeconfig")
-	}
 
-	clientset, err = kubernetes.NewForConfig(config)
-	Expect(err).NotTo(HaveOccurred(), "Failed to create Kubernetes client")
-})
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
 
 var _ = Describe("HPA and Deployment Tests", func() {
 	var namespace string
 	var deploymentName string
 	var hpaName string
 
-	BeforeEach(func() {
+	BeforeEach(func(ctx SpecContext) {
 		// Define the namespace and names for the HPA and deployment
-		namespace = os.Getenv("TEST_NAMESPACE")
-		if namespace == "" {
-			namespace = "default"
-		}
+		namespace = framework.TestNamespace()
 		deploymentName = fmt.Sprintf("test-deployment-%d", time.Now().UnixNano())
 		hpaName = fmt.Sprintf("test-hpa-%d", time.Now().UnixNano())
 
@@ -81,7 +47,7 @@ var _ = Describe("HPA and Deployment Tests", func() {
 						Containers: []v1.Container{
 							{
 								Name:  "nginx",
-								Image: "nginx",
+								Image: "nginxinc/nginx-unprivileged:1.25-alpine", // Runs as an arbitrary non-root user
 							},
 						},
 					},
@@ -89,7 +55,8 @@ var _ = Describe("HPA and Deployment Tests", func() {
 			},
 		}
 
-		_, err := clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
+		framework.Restrict(&deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
 
 		// Create an HPA for the deployment
@@ -110,53 +77,47 @@ var _ = Describe("HPA and Deployment Tests", func() {
 			},
 		}
 
-		_, err = clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Create(context.TODO(), hpa, metav1.CreateOptions{})
+		_, err = framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Create(ctx, hpa, metav1.CreateOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to create HPA")
 	})
 
-	It("should read an HPA", func() {
+	It("should read an HPA", func(ctx SpecContext) {
 		// Test to verify HPA creation
-		hpa, err := clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), hpaName, metav1.GetOptions{})
+		hpa, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, hpaName, metav1.GetOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to get HPA")
 		Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
 	})
 
-	It("should scale the deployment by updating HPA", func() {
+	It("should scale the deployment by updating HPA", func(ctx SpecContext) {
 		// Get the existing HPA
-		hpa, err := clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), hpaName, metav1.GetOptions{})
+		hpa, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, hpaName, metav1.GetOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to get HPA")
 
 		// Update the MaxReplicas and TargetCPUUtilizationPercentage to simulate a scaling change
 		hpa.Spec.MaxReplicas = 10
 		hpa.Spec.TargetCPUUtilizationPercentage = int32Ptr(30) // Lower the CPU threshold
 
-		_, err = clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(context.TODO(), hpa, metav1.UpdateOptions{})
+		_, err = framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Update(ctx, hpa, metav1.UpdateOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to update HPA")
 
 		// Verify the changes
-		updatedHPA, err := clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), hpaName, metav1.GetOptions{})
+		updatedHPA, err := framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(ctx, hpaName, metav1.GetOptions{})
 		Expect(err).NotTo(HaveOccurred(), "Failed to get updated HPA")
 		Expect(updatedHPA.Spec.MaxReplicas).To(Equal(int32(10)))
 		Expect(*updatedHPA.Spec.TargetCPUUtilizationPercentage).To(Equal(int32(30)))
 
 	})
 
-	AfterEach(func() {
+	AfterEach(func(ctx SpecContext) {
 		// Clean up the HPA and deployment after each test
-		err := clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), hpaName, metav1.DeleteOptions{})
+		err := framework.Cleanup(ctx, framework.Clientset.AutoscalingV1().HorizontalPodAutoscalers(namespace), hpaName)
 		Expect(err).NotTo(HaveOccurred(), "Failed to delete HPA")
 
-		err = clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), deploymentName, metav1.DeleteOptions{})
+		err = framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
 		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
 	})
 })
 
-// Entry point for running the Ginkgo tests
-func TestHPA(t *testing.T) {
-	RegisterFailHandler(Fail)
-	RunSpecs(t, "HPA and Deployment Suite")
-}
-
 func int32Ptr(i int32) *int32 {
 	return &i
 }
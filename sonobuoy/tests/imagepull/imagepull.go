@@ -0,0 +1,122 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Event reasons and messages the kubelet reports while resolving a container's image
+const (
+	reasonPulling  = "Pulling"
+	reasonPulled   = "Pulled"
+	alreadyPresent = "already present on machine"
+)
+
+// imageDigest returns the sha256 digest in an image reference or a container's imageID, e.g. the digest
+// of docker.io/library/alpine@sha256:..., or "" if it has none
+func imageDigest(ref string) string {
+	_, digest, found := strings.Cut(ref, "@")
+	if !found {
+		return ""
+	}
+	return digest
+}
+
+var _ = Describe("Image Pull Policy and Digests", Ordered, func() {
+	var namespace string
+	var nodeName string
+	var imageID string
+
+	// runPod runs a pod of image with the given pull policy on nodeName until it succeeds and returns it
+	runPod := func(ctx context.Context, name, image string, policy v1.PullPolicy) *v1.Pod {
+		GinkgoHelper()
+		pod := framework.NewPod(namespace, name, image, "true")
+		pod.Spec.Containers[0].ImagePullPolicy = policy
+		pod.Spec.NodeName = nodeName
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod %s", name)
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		})
+		pod, err = framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodSucceeded, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod %s did not complete", name)
+		return pod
+	}
+
+	// imageEvents returns the messages of the kubelet's image events for the pod, by reason. Events are
+	// recorded asynchronously, so it waits until a Pulled event arrived.
+	imageEvents := func(ctx context.Context, name string) map[string][]string {
+		GinkgoHelper()
+		selector := fields.Set{"involvedObject.kind": "Pod", "involvedObject.name": name}.AsSelector().String()
+		messages := map[string][]string{}
+		framework.Eventually(func() ([]string, error) {
+			events, err := framework.Clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+			if err != nil {
+				return nil, err
+			}
+			clear(messages)
+			for _, event := range events.Items {
+				messages[event.Reason] = append(messages[event.Reason], event.Message)
+			}
+			return messages[reasonPulled], nil
+		}, 60*time.Second, 2*time.Second).ShouldNot(BeEmpty(), "No Pulled event was recorded for pod %s", name)
+		return messages
+	}
+
+	// Every spec runs on the node the image was first pulled to, so it is known to be present there
+	BeforeAll(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		name := fmt.Sprintf("test-imagepull-seed-%d", time.Now().UnixNano())
+		pod := runPod(ctx, name, podImage, v1.PullIfNotPresent)
+		nodeName = pod.Spec.NodeName
+		imageID = pod.Status.ContainerStatuses[0].ImageID
+	})
+
+	It("should reuse a present image with IfNotPresent", func(ctx SpecContext) {
+		name := fmt.Sprintf("test-imagepull-ifnotpresent-%d", time.Now().UnixNano())
+		runPod(ctx, name, podImage, v1.PullIfNotPresent)
+
+		events := imageEvents(ctx, name)
+		Expect(events[reasonPulling]).To(BeEmpty(), "Present image was pulled again")
+		Expect(events[reasonPulled]).To(ContainElement(ContainSubstring(alreadyPresent)), "Pulled event does not report the image as present")
+	})
+
+	It("should contact the registry for a present image with Always", func(ctx SpecContext) {
+		name := fmt.Sprintf("test-imagepull-always-%d", time.Now().UnixNano())
+		runPod(ctx, name, podImage, v1.PullAlways)
+
+		events := imageEvents(ctx, name)
+		Expect(events[reasonPulling]).NotTo(BeEmpty(), "Image was not pulled despite the Always policy")
+		Expect(events[reasonPulled]).NotTo(ContainElement(ContainSubstring(alreadyPresent)), "Always policy used the present image")
+	})
+
+	It("should run the pinned image when pulling by digest", func(ctx SpecContext) {
+		digest := imageDigest(imageID)
+		if digest == "" {
+			Skip(fmt.Sprintf("Container runtime reports no repository digest for %s: %q", podImage, imageID))
+		}
+		repository, _, _ := strings.Cut(podImage, ":")
+		pinned := repository + "@" + digest
+
+		name := fmt.Sprintf("test-imagepull-digest-%d", time.Now().UnixNano())
+		pod := runPod(ctx, name, pinned, v1.PullAlways)
+
+		// Mirrors may redirect the repository, but must neither drop the pin nor serve other content
+		Expect(imageDigest(pod.Spec.Containers[0].Image)).To(Equal(digest), "Digest pin was rewritten to %s", pod.Spec.Containers[0].Image)
+		Expect(imageDigest(pod.Status.ContainerStatuses[0].ImageID)).To(Equal(digest), "Runtime ran an image with another digest")
+	})
+})
@@ -0,0 +1,279 @@
+package e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	registryImage = "registry:2.8.3"
+	// Provides htpasswd, which registry images no longer ship
+	htpasswdImage = "httpd:2.4-alpine"
+	craneImage    = "gcr.io/go-containerregistry/crane:debug"
+	sourceImage   = "alpine:3.20"
+)
+
+// The deployed registry listens on the node's loopback, which container runtimes pull from over plain HTTP
+const registryPort = 30500
+
+// Waiting reasons of a container whose image cannot be pulled
+var pullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff"}
+
+// privateRegistry is where the private image lives and the credentials that pull it
+type privateRegistry struct {
+	image    string
+	server   string
+	username string
+	password string
+	// nodeName pins pulling pods to the node serving a deployed registry
+	nodeName string
+}
+
+// registryLabels labels the suite privileged when it has to deploy a registry on a node's network
+func registryLabels() Labels {
+	if config, err := framework.LoadRunConfig(); err == nil && config.PrivateRegistry.Image != "" {
+		return Labels{}
+	}
+	return Label(framework.LabelPrivileged)
+}
+
+// dockerConfigJSON returns the .dockerconfigjson of a kubernetes.io/dockerconfigjson Secret for the registry
+func dockerConfigJSON(registry *privateRegistry) []byte {
+	GinkgoHelper()
+	auth := base64.StdEncoding.EncodeToString([]byte(registry.username + ":" + registry.password))
+	config, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			registry.server: map[string]string{
+				"username": registry.username,
+				"password": registry.password,
+				"auth":     auth,
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred(), "Failed to encode docker config")
+	return config
+}
+
+// Credentials are only checked when an image is pulled, so the specs always pull an image that exists
+// solely behind the registry's authentication.
+var _ = Describe("Private Registry Image Pulls", Ordered, registryLabels(), func() {
+	var namespace string
+	var suffix int64
+	var registry *privateRegistry
+	var secretName string
+
+	// deployRegistry runs a registry requiring a password in its own namespace, on one node's network,
+	// and pushes a copy of sourceImage to it
+	deployRegistry := func(ctx context.Context) *privateRegistry {
+		GinkgoHelper()
+		registryNamespace := framework.CreateNamespace(ctx, "test-registry", framework.PodSecurityPrivileged)
+
+		password := make([]byte, 16)
+		_, err := rand.Read(password)
+		Expect(err).NotTo(HaveOccurred(), "Failed to generate registry password")
+		registry := &privateRegistry{
+			image:    fmt.Sprintf("localhost:%d/e2e/private:%d", registryPort, suffix),
+			server:   fmt.Sprintf("localhost:%d", registryPort),
+			username: "e2e",
+			password: hex.EncodeToString(password),
+		}
+
+		pod := framework.NewPod(registryNamespace, "registry", registryImage)
+		framework.Unrestricted(&pod.Spec)
+		pod.Spec.RestartPolicy = v1.RestartPolicyAlways
+		pod.Spec.HostNetwork = true
+		pod.Spec.Volumes = []v1.Volume{{Name: "auth", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+		authMount := []v1.VolumeMount{{Name: "auth", MountPath: "/auth"}}
+		pod.Spec.InitContainers = []v1.Container{{
+			Name:         "htpasswd",
+			Image:        htpasswdImage,
+			Command:      []string{"sh", "-c", `htpasswd -Bbn "$USERNAME" "$PASSWORD" > /auth/htpasswd`},
+			Env:          []v1.EnvVar{{Name: "USERNAME", Value: registry.username}, {Name: "PASSWORD", Value: registry.password}},
+			VolumeMounts: authMount,
+		}}
+		container := &pod.Spec.Containers[0]
+		container.Env = []v1.EnvVar{
+			{Name: "REGISTRY_HTTP_ADDR", Value: fmt.Sprintf(":%d", registryPort)},
+			{Name: "REGISTRY_AUTH", Value: "htpasswd"},
+			{Name: "REGISTRY_AUTH_HTPASSWD_REALM", Value: "e2e"},
+			{Name: "REGISTRY_AUTH_HTPASSWD_PATH", Value: "/auth/htpasswd"},
+		}
+		container.VolumeMounts = authMount
+		container.ReadinessProbe = &v1.Probe{
+			ProbeHandler:  v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt32(registryPort)}},
+			PeriodSeconds: 2,
+		}
+		_, err = framework.Clientset.CoreV1().Pods(registryNamespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create registry pod")
+		framework.Eventually(func() (bool, error) {
+			pod, err = framework.Clientset.CoreV1().Pods(registryNamespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == v1.PodReady {
+					return condition.Status == v1.ConditionTrue, nil
+				}
+			}
+			return false, nil
+		}, 180*time.Second, 2*time.Second).Should(BeTrue(), "Registry did not become ready")
+		registry.nodeName = pod.Spec.NodeName
+
+		// Pushing from the registry's node reaches it on loopback as well, over plain HTTP
+		push := framework.NewPod(registryNamespace, "push", craneImage, "sh", "-c",
+			`crane auth login "$REGISTRY" -u "$USERNAME" -p "$PASSWORD" && crane copy --insecure "$SOURCE" "$TARGET"`)
+		push.Spec.HostNetwork = true
+		push.Spec.NodeName = registry.nodeName
+		push.Spec.Containers[0].Env = []v1.EnvVar{
+			{Name: "HOME", Value: "/tmp"},
+			{Name: "REGISTRY", Value: registry.server},
+			{Name: "USERNAME", Value: registry.username},
+			{Name: "PASSWORD", Value: registry.password},
+			{Name: "SOURCE", Value: sourceImage},
+			{Name: "TARGET", Value: registry.image},
+		}
+		_, err = framework.Clientset.CoreV1().Pods(registryNamespace).Create(ctx, push, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create push pod")
+		_, err = framework.WaitForPodOutput(ctx, framework.Clientset, registryNamespace, push.Name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Failed to push the private image")
+		return registry
+	}
+
+	// newPullPod returns a pod running the private image, always pulling it so cached copies do not hide
+	// missing credentials
+	newPullPod := func(name string) *v1.Pod {
+		pod := framework.NewPod(namespace, name, registry.image, "sleep", "3600")
+		pod.Spec.Containers[0].ImagePullPolicy = v1.PullAlways
+		pod.Spec.NodeName = registry.nodeName
+		return pod
+	}
+
+	// waitForPull waits until the pod's image was pulled or failed to, and reports whether it was pulled
+	waitForPull := func(ctx context.Context, name string) bool {
+		GinkgoHelper()
+		var pulled bool
+		framework.Eventually(func() error {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.ImageID != "" {
+					pulled = true
+					return nil
+				}
+				if status.State.Waiting != nil {
+					for _, reason := range pullFailureReasons {
+						if status.State.Waiting.Reason == reason {
+							pulled = false
+							return nil
+						}
+					}
+				}
+			}
+			return fmt.Errorf("image of pod %s is still being pulled", name)
+		}, 180*time.Second, 2*time.Second).Should(Succeed(), "Pull of the private image never finished")
+		return pulled
+	}
+
+	BeforeAll(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		suffix = time.Now().UnixNano()
+		secretName = fmt.Sprintf("test-pull-secret-%d", suffix)
+
+		config, err := framework.LoadRunConfig()
+		Expect(err).NotTo(HaveOccurred(), "Invalid run configuration")
+		if external := config.PrivateRegistry; external.Image != "" {
+			registry = &privateRegistry{
+				image:    external.Image,
+				server:   external.Server,
+				username: external.Username,
+				password: external.Password,
+			}
+		} else {
+			registry = deployRegistry(ctx)
+		}
+
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{v1.DockerConfigJsonKey: dockerConfigJSON(registry)},
+		}
+		_, err = framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pull Secret")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), secretName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Secret")
+		})
+	})
+
+	var podName string
+
+	BeforeEach(func() {
+		podName = fmt.Sprintf("test-private-pull-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should fail to pull the private image without imagePullSecrets", func(ctx SpecContext) {
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, newPullPod(podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		Expect(waitForPull(ctx, podName)).To(BeFalse(), "Private image was pulled without credentials")
+	})
+
+	It("should pull the private image with imagePullSecrets", func(ctx SpecContext) {
+		pod := newPullPod(podName)
+		pod.Spec.ImagePullSecrets = []v1.LocalObjectReference{{Name: secretName}}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		Expect(waitForPull(ctx, podName)).To(BeTrue(), "Private image could not be pulled with its imagePullSecret")
+	})
+
+	It("should propagate the imagePullSecrets of the pod's ServiceAccount", func(ctx SpecContext) {
+		serviceAccount := &v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: namespace,
+			},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: secretName}},
+		}
+		_, err := framework.Clientset.CoreV1().ServiceAccounts(namespace).Create(ctx, serviceAccount, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ServiceAccount")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ServiceAccounts(namespace), podName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ServiceAccount")
+		})
+
+		pod := newPullPod(podName)
+		pod.Spec.ServiceAccountName = serviceAccount.Name
+		created, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		// The ServiceAccount admission plugin copies the secrets into the pod
+		Expect(created.Spec.ImagePullSecrets).To(ContainElement(v1.LocalObjectReference{Name: secretName}),
+			"ServiceAccount imagePullSecrets were not added to the pod")
+		Expect(waitForPull(ctx, podName)).To(BeTrue(), "Private image could not be pulled with the ServiceAccount's imagePullSecret")
+	})
+})
@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Job CRUD test suite
+var _ = Describe("Jobs CRUD Operations", func() {
+	var namespace string
+	var jobName string
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		jobName = fmt.Sprintf("test-job-%d", time.Now().UnixNano())
+
+		// Create a Job before each test
+		job := &v1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      jobName,
+				Namespace: namespace,
+			},
+			Spec: v1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:    "basic-task",
+								Image:   "alpine:3.20",
+								Command: []string{"sh", "-c", "echo 'Calculating something basic'"},
+							},
+						},
+						RestartPolicy: corev1.RestartPolicyNever,
+					},
+				},
+			},
+		}
+
+		framework.Restrict(&job.Spec.Template.Spec)
+		_, err := framework.Clientset.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create job")
+	})
+
+	// Read the Job
+	It("should read the job successfully", func(ctx SpecContext) {
+		job, err := framework.Clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to read job")
+		Expect(job.Name).To(Equal(jobName))
+	})
+
+	//// Update the Job
+	//It("should update the job successfully", func(ctx SpecContext) {
+	//	// Get the job and modify it
+	//	job, err := framework.Clientset.BatchV1().Jobs(namespace).Get(ctx, jobName, metav1.GetOptions{})
+	//	Expect(err).NotTo(HaveOccurred(), "Failed to get job for update")
+	//
+	//	job.Spec.Template.Spec.Containers[0].Command = []string{"perl", "-Mbignum=bpi", "-wle", "print bpi(1000)"}
+	//	_, err = framework.Clientset.BatchV1().Jobs(namespace).Update(ctx, job, metav1.UpdateOptions{})
+	//	Expect(err).NotTo(HaveOccurred(), "Failed to update job")
+	//})
+
+	// Delete the Job
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.BatchV1().Jobs(namespace), jobName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete job")
+	})
+})
@@ -0,0 +1,151 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Value a drift report shows for a setting a node's kubelet does not report
+const unset = "<unset>"
+
+// Kubelet settings that must agree across nodes; drift in any other setting is only reported
+var consistentSettings = []string{
+	"clusterDomain",
+	"clusterDNS",
+	"authentication.anonymous.enabled",
+	"authentication.webhook.enabled",
+	"authorization.mode",
+	"readOnlyPort",
+}
+
+// nodeProxy gets path from the node's kubelet through the API server, as kubectl get --raw /api/v1/nodes/<node>/proxy/<path> does
+func nodeProxy(ctx context.Context, node, path string) ([]byte, error) {
+	return framework.Clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(node).SubResource("proxy").Suffix(path).
+		DoRaw(ctx)
+}
+
+// flatten adds the leaves of a decoded JSON value to settings under dotted keys; lists are kept whole
+func flatten(prefix string, value interface{}, settings map[string]string) {
+	if object, ok := value.(map[string]interface{}); ok {
+		for key, child := range object {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, settings)
+		}
+		return
+	}
+	encoded, _ := json.Marshal(value)
+	settings[prefix] = string(encoded)
+}
+
+// readyNodes returns the names of the target nodes whose kubelets are expected to answer
+func readyNodes(ctx context.Context) []string {
+	GinkgoHelper()
+	nodes, err := framework.TargetNodes(ctx)
+	Expect(err).NotTo(HaveOccurred(), "Failed to list nodes")
+	var names []string
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				names = append(names, node.Name)
+			}
+		}
+	}
+	Expect(names).NotTo(BeEmpty(), "Cluster has no Ready nodes")
+	return names
+}
+
+// Reaches every kubelet through the API server, so it needs no pods or host access on the nodes. As a
+// daemonset plugin each node checks its own kubelet, and drift shows across the per-node reports.
+var _ = Describe("Kubelet endpoints", Label(framework.LabelNode), func() {
+	var nodes []string
+
+	BeforeEach(func(ctx SpecContext) {
+		framework.RequireNodeProxy(ctx)
+		nodes = readyNodes(ctx)
+	})
+
+	It("should report every kubelet healthy on /healthz", func(ctx SpecContext) {
+		var unhealthy []string
+		for _, node := range nodes {
+			body, err := nodeProxy(ctx, node, "healthz")
+			if err != nil {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: %v", node, err))
+			} else if strings.TrimSpace(string(body)) != "ok" {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", node, body))
+			}
+		}
+		Expect(unhealthy).To(BeEmpty(), "Kubelets are not healthy:\n%s", strings.Join(unhealthy, "\n"))
+	})
+
+	It("should serve every kubelet's configuration on /configz without drift in cluster-wide settings", func(ctx SpecContext) {
+		configs := map[string]map[string]string{}
+		keys := map[string]bool{}
+		for _, node := range nodes {
+			body, err := nodeProxy(ctx, node, "configz")
+			Expect(err).NotTo(HaveOccurred(), "Failed to get configz of node %s", node)
+			var configz struct {
+				KubeletConfig map[string]interface{} `json:"kubeletconfig"`
+			}
+			Expect(json.Unmarshal(body, &configz)).To(Succeed(), "Node %s returned malformed configz", node)
+			Expect(configz.KubeletConfig).NotTo(BeEmpty(), "Node %s returned no kubelet configuration", node)
+			settings := map[string]string{}
+			flatten("", configz.KubeletConfig, settings)
+			configs[node] = settings
+			for key := range settings {
+				keys[key] = true
+			}
+		}
+
+		By("comparing the configuration across nodes")
+		drifted := map[string]string{}
+		for key := range keys {
+			values := map[string][]string{}
+			for _, node := range nodes {
+				value, ok := configs[node][key]
+				if !ok {
+					value = unset
+				}
+				values[value] = append(values[value], node)
+			}
+			if len(values) < 2 {
+				continue
+			}
+			var groups []string
+			for value, holders := range values {
+				groups = append(groups, fmt.Sprintf("%s on %s", value, strings.Join(holders, ",")))
+			}
+			sort.Strings(groups)
+			drifted[key] = strings.Join(groups, "; ")
+		}
+
+		var report, violations []string
+		for key, description := range drifted {
+			report = append(report, key+": "+description)
+		}
+		sort.Strings(report)
+		for _, setting := range consistentSettings {
+			for key, description := range drifted {
+				if key == setting || strings.HasPrefix(key, setting+".") {
+					violations = append(violations, key+": "+description)
+				}
+			}
+		}
+		sort.Strings(violations)
+		AddReportEntry("Kubelet config drift", fmt.Sprintf("%d of %d settings differ across %d nodes\n%s",
+			len(drifted), len(keys), len(nodes), strings.Join(report, "\n")))
+		Expect(violations).To(BeEmpty(), "Kubelets disagree on cluster-wide settings:\n%s", strings.Join(violations, "\n"))
+	})
+})
@@ -0,0 +1,182 @@
+package e2e
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Most bytes the values of a ConfigMap or a Secret may hold together
+const maxDataSize = 1 << 20
+
+// Key holding the payload, and where pods mount it
+const (
+	dataKey   = "data"
+	mountPath = "/data"
+)
+
+// largeObject creates and reads back ConfigMaps or Secrets holding a single key
+type largeObject struct {
+	// binary payloads are only accepted by Secrets; ConfigMap data must be UTF-8
+	binary bool
+	create func(ctx context.Context, namespace, name string, value []byte) error
+	get    func(ctx context.Context, namespace, name string) ([]byte, error)
+	delete func(ctx context.Context, namespace, name string) error
+	volume func(name string) v1.VolumeSource
+}
+
+var configMaps = largeObject{
+	create: func(ctx context.Context, namespace, name string, value []byte) error {
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{dataKey: string(value)},
+		}
+		_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	},
+	get: func(ctx context.Context, namespace, name string) ([]byte, error) {
+		configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return []byte(configMap.Data[dataKey]), nil
+	},
+	delete: func(ctx context.Context, namespace, name string) error {
+		return framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), name)
+	},
+	volume: func(name string) v1.VolumeSource {
+		return v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: name}}}
+	},
+}
+
+var secrets = largeObject{
+	binary: true,
+	create: func(ctx context.Context, namespace, name string, value []byte) error {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string][]byte{dataKey: value},
+		}
+		_, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	},
+	get: func(ctx context.Context, namespace, name string) ([]byte, error) {
+		secret, err := framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return secret.Data[dataKey], nil
+	},
+	delete: func(ctx context.Context, namespace, name string) error {
+		return framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), name)
+	},
+	volume: func(name string) v1.VolumeSource {
+		return v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: name}}
+	},
+}
+
+// payload returns size random bytes, or random hex digits when binary is false, so that truncated or
+// corrupted data cannot go unnoticed the way a repeated character would
+func payload(size int, binary bool) []byte {
+	random := rand.New(rand.NewSource(GinkgoRandomSeed()))
+	value := make([]byte, size)
+	random.Read(value)
+	if !binary {
+		encoded := make([]byte, hex.EncodedLen(size))
+		hex.Encode(encoded, value)
+		value = encoded[:size]
+	}
+	return value
+}
+
+// Objects are stored in etcd whole, so the API server caps the data of ConfigMaps and Secrets at 1MiB.
+// The specs check both sides of the limit, and that the largest objects still reach pods intact.
+var _ = Describe("Large object limits", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-large-%d", time.Now().UnixNano())
+	})
+
+	DescribeTable("should accept data at the size limit",
+		func(ctx SpecContext, object largeObject) {
+			value := payload(maxDataSize, object.binary)
+			Expect(object.create(ctx, namespace, name, value)).To(Succeed(), "Failed to create an object holding %d bytes", maxDataSize)
+			DeferCleanup(func(ctx SpecContext) {
+				Expect(object.delete(ctx, namespace, name)).To(Succeed(), "Failed to delete object")
+			})
+
+			stored, err := object.get(ctx, namespace, name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to read the object back")
+			Expect(stored).To(HaveLen(maxDataSize), "Object came back with a different size")
+			Expect(stored).To(Equal(value), "Object came back with different data")
+		},
+		Entry("ConfigMap", configMaps),
+		Entry("Secret", secrets),
+	)
+
+	DescribeTable("should reject data over the size limit as too long",
+		func(ctx SpecContext, object largeObject) {
+			err := object.create(ctx, namespace, name, payload(maxDataSize+1, object.binary))
+			if err == nil {
+				DeferCleanup(func(ctx SpecContext) {
+					Expect(object.delete(ctx, namespace, name)).To(Succeed(), "Failed to delete object")
+				})
+			}
+			Expect(err).To(HaveOccurred(), "Object holding %d bytes was accepted", maxDataSize+1)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "Expected an Invalid error, got: %v", err)
+			_, tooLong := apierrors.StatusCause(err, metav1.CauseType(field.ErrorTypeTooLong))
+			Expect(tooLong).To(BeTrue(), "Rejection does not name the data as too long: %v", err)
+			Expect(err.Error()).To(ContainSubstring(strconv.Itoa(maxDataSize)), "Rejection does not state the limit: %v", err)
+		},
+		Entry("ConfigMap", configMaps),
+		Entry("Secret", secrets),
+	)
+
+	DescribeTable("should mount data at the size limit into a pod intact",
+		func(ctx SpecContext, object largeObject) {
+			value := payload(maxDataSize, object.binary)
+			Expect(object.create(ctx, namespace, name, value)).To(Succeed(), "Failed to create an object holding %d bytes", maxDataSize)
+			DeferCleanup(func(ctx SpecContext) {
+				Expect(object.delete(ctx, namespace, name)).To(Succeed(), "Failed to delete object")
+			})
+
+			podName := name + "-reader"
+			file := mountPath + "/" + dataKey
+			pod := framework.NewPod(namespace, podName, "alpine:3.20", "sh", "-c", fmt.Sprintf("wc -c < %s && sha256sum %s", file, file))
+			pod.Spec.Volumes = []v1.Volume{{Name: "data", VolumeSource: object.volume(name)}}
+			pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: mountPath, ReadOnly: true}}
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+			DeferCleanup(func(ctx SpecContext) {
+				Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)).To(Succeed(), "Failed to delete pod")
+			})
+
+			output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 180*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "Pod failed to read the mounted file")
+			fields := strings.Fields(output)
+			Expect(fields).To(HaveLen(3), "Unexpected pod output %q", output)
+			Expect(fields[0]).To(Equal(strconv.Itoa(maxDataSize)), "Mounted file has a different size")
+			sum := sha256.Sum256(value)
+			Expect(fields[1]).To(Equal(hex.EncodeToString(sum[:])), "Mounted file has different content")
+		},
+		Entry("ConfigMap", configMaps),
+		Entry("Secret", secrets),
+	)
+})
@@ -0,0 +1,145 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// coordination.k8s.io Leases back the leader election of most controllers and operators
+var _ = Describe("Lease API and Leader Election", func() {
+	var namespace string
+	var leaseName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		leaseName = fmt.Sprintf("test-lease-%d", time.Now().UnixNano())
+
+		// Registered first so it runs after any candidates started by the spec have stopped
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoordinationV1().Leases(namespace), leaseName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Lease")
+		})
+	})
+
+	It("should create, renew and hand over a Lease", func(ctx SpecContext) {
+		holder := "holder-a"
+		duration := int32(15)
+		acquired := metav1.NewMicroTime(time.Now())
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      leaseName,
+				Namespace: namespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: &duration,
+				AcquireTime:          &acquired,
+				RenewTime:            &acquired,
+			},
+		}
+		_, err := framework.Clientset.CoordinationV1().Leases(namespace).Create(ctx, lease, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Lease")
+
+		// Renew
+		lease, err = framework.Clientset.CoordinationV1().Leases(namespace).Get(ctx, leaseName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get Lease")
+		renewed := metav1.NewMicroTime(time.Now().Add(time.Second))
+		lease.Spec.RenewTime = &renewed
+		lease, err = framework.Clientset.CoordinationV1().Leases(namespace).Update(ctx, lease, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to renew Lease")
+		Expect(lease.Spec.RenewTime.Time).To(BeTemporally("~", renewed.Time, time.Second))
+
+		// Acquire by another holder
+		newHolder := "holder-b"
+		transitions := int32(1)
+		lease.Spec.HolderIdentity = &newHolder
+		lease.Spec.AcquireTime = &renewed
+		lease.Spec.LeaseTransitions = &transitions
+		_, err = framework.Clientset.CoordinationV1().Leases(namespace).Update(ctx, lease, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to hand over Lease")
+
+		stored, err := framework.Clientset.CoordinationV1().Leases(namespace).Get(ctx, leaseName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get Lease")
+		Expect(*stored.Spec.HolderIdentity).To(Equal(newHolder))
+		Expect(*stored.Spec.LeaseTransitions).To(Equal(int32(1)))
+	})
+
+	It("should transfer leadership between two competing clients", func(ctx SpecContext) {
+		leaders := make(chan string, 10)
+
+		// Each candidate uses its own client and context, like two replicas of an operator
+		startCandidate := func(identity string) context.CancelFunc {
+			candidateClient, err := kubernetes.NewForConfig(framework.RestConfig)
+			Expect(err).NotTo(HaveOccurred(), "Failed to create candidate client")
+
+			lock := &resourcelock.LeaseLock{
+				LeaseMeta:  metav1.ObjectMeta{Name: leaseName, Namespace: namespace},
+				Client:     candidateClient.CoordinationV1(),
+				LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+			}
+			elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+				Lock:            lock,
+				LeaseDuration:   15 * time.Second,
+				RenewDeadline:   10 * time.Second,
+				RetryPeriod:     2 * time.Second,
+				ReleaseOnCancel: true,
+				Name:            leaseName,
+				Callbacks: leaderelection.LeaderCallbacks{
+					OnStartedLeading: func(ctx context.Context) {
+						leaders <- identity
+					},
+					OnStoppedLeading: func() {},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create leader elector")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				elector.Run(ctx)
+			}()
+			stop := func() {
+				cancel()
+				framework.Eventually(done, 30*time.Second).Should(BeClosed(), "Candidate %s did not stop", identity)
+			}
+			DeferCleanup(stop)
+			return stop
+		}
+
+		stopA := startCandidate("candidate-a")
+		var firstLeader string
+		framework.Eventually(leaders, 60*time.Second).Should(Receive(&firstLeader), "No candidate acquired the lease")
+		Expect(firstLeader).To(Equal("candidate-a"))
+
+		startCandidate("candidate-b")
+		Consistently(leaders, 20*time.Second).ShouldNot(Receive(), "Lease was acquired while still held")
+
+		// Releasing on cancel lets the other candidate take over without waiting for expiry
+		stopA()
+		var secondLeader string
+		framework.Eventually(leaders, 60*time.Second).Should(Receive(&secondLeader), "Leadership was not transferred")
+		Expect(secondLeader).To(Equal("candidate-b"))
+
+		lease, err := framework.Clientset.CoordinationV1().Leases(namespace).Get(ctx, leaseName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get Lease")
+		Expect(lease.Spec.HolderIdentity).NotTo(BeNil())
+		Expect(*lease.Spec.HolderIdentity).To(Equal("candidate-b"), "Lease holder does not reflect the new leader")
+		Expect(lease.Spec.LeaseTransitions).NotTo(BeNil())
+		Expect(*lease.Spec.LeaseTransitions).To(BeNumerically(">=", 1), "Lease transitions were not recorded")
+	})
+
+})
@@ -0,0 +1,154 @@
+package e2e
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Exit code of a process killed by SIGKILL, as the OOM killer does
+const sigkillExitCode = 137
+
+// Reason the kubelet gives pods it evicted
+const evictedReason = "Evicted"
+
+// CPU limit of the throttling spec and the CFS quota it maps to with the default 100ms period
+const (
+	cpuLimit        = "100m"
+	cfsPeriodMicros = 100000
+	cfsQuotaMicros  = 10000
+)
+
+// Where the container sees its CPU quota and throttling counters under cgroup v2 and v1
+const (
+	cgroupV2CPUMax   = "/sys/fs/cgroup/cpu.max"
+	cgroupV2CPUStat  = "/sys/fs/cgroup/cpu.stat"
+	cgroupV1CPUQuota = "/sys/fs/cgroup/cpu/cpu.cfs_quota_us"
+	cgroupV1CPUStat  = "/sys/fs/cgroup/cpu/cpu.stat"
+)
+
+// parseCPUStat returns the counters of a cgroup cpu.stat file by name
+func parseCPUStat(stat string) map[string]int64 {
+	counters := map[string]int64{}
+	for _, line := range strings.Split(strings.TrimSpace(stat), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			counters[fields[0]] = value
+		}
+	}
+	return counters
+}
+
+// Checks that the kubelet and container runtime turn limits into cgroup settings the kernel enforces,
+// which depends on the distribution's cgroup driver and version
+var _ = Describe("Resource limit enforcement", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-limits-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should OOM kill a container exceeding its memory limit", func(ctx SpecContext) {
+		// tail buffers its newline-free input, so it grows towards 256Mi until the limit is hit
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", "head -c 268435456 /dev/zero | tail")
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("32Mi")},
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		failed, err := framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodFailed, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Memory hog was not killed")
+		Expect(failed.Status.ContainerStatuses).To(HaveLen(1))
+		terminated := failed.Status.ContainerStatuses[0].State.Terminated
+		Expect(terminated).NotTo(BeNil(), "Container has not terminated")
+		Expect(terminated.Reason).To(Equal("OOMKilled"), "Container was not reported OOMKilled")
+		Expect(terminated.ExitCode).To(Equal(int32(sigkillExitCode)), "OOM killed container did not exit from SIGKILL")
+	})
+
+	It("should throttle a container exceeding its CPU limit", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", "while :; do :; done")
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpuLimit)},
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+
+		By("reading the CFS quota of the container's cgroup")
+		// cgroup v2 mounts the container's own cgroup at /sys/fs/cgroup, v1 has a hierarchy per controller
+		result, err := framework.ExecInPod(ctx, namespace, name, "", "cat", cgroupV2CPUMax)
+		statPath := cgroupV2CPUStat
+		if err == nil {
+			AddReportEntry("cgroup", "v2")
+			Expect(strings.TrimSpace(result.Stdout)).To(Equal(fmt.Sprintf("%d %d", cfsQuotaMicros, cfsPeriodMicros)),
+				"cpu.max does not reflect the %s limit", cpuLimit)
+		} else {
+			AddReportEntry("cgroup", "v1")
+			result, err = framework.ExecInPod(ctx, namespace, name, "", "cat", cgroupV1CPUQuota)
+			Expect(err).NotTo(HaveOccurred(), "Found neither a cgroup v2 nor a cgroup v1 CPU controller")
+			Expect(strings.TrimSpace(result.Stdout)).To(Equal(strconv.Itoa(cfsQuotaMicros)),
+				"cpu.cfs_quota_us does not reflect the %s limit", cpuLimit)
+			statPath = cgroupV1CPUStat
+		}
+
+		By("checking the kernel throttles the busy loop")
+		framework.Eventually(func() (map[string]int64, error) {
+			result, err := framework.ExecInPod(ctx, namespace, name, "", "cat", statPath)
+			if err != nil {
+				return nil, err
+			}
+			return parseCPUStat(result.Stdout), nil
+		}, 60*time.Second, 5*time.Second).Should(And(
+			HaveKeyWithValue("nr_periods", BeNumerically(">", 0)),
+			HaveKeyWithValue("nr_throttled", BeNumerically(">", 0)),
+		), "Container exceeding its CPU limit was not throttled")
+
+		result, err = framework.ExecInPod(ctx, namespace, name, "", "cat", statPath)
+		Expect(err).NotTo(HaveOccurred(), "Failed to read cpu.stat")
+		counters := parseCPUStat(result.Stdout)
+		// A loop that never sleeps should be throttled in most periods it runs in
+		Expect(counters["nr_throttled"]*2).To(BeNumerically(">=", counters["nr_periods"]),
+			"Busy container was throttled in only %d of %d periods", counters["nr_throttled"], counters["nr_periods"])
+		AddReportEntry("CPU throttling", fmt.Sprintf("%d of %d periods throttled", counters["nr_throttled"], counters["nr_periods"]))
+	})
+
+	// The kubelet measures local storage usage periodically, so eviction can take a couple of minutes
+	It("should evict a pod writing past its ephemeral-storage limit", func(ctx SpecContext) {
+		// The container's writable layer counts towards the limit like its logs and emptyDirs do
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", "dd if=/dev/zero of=/tmp/fill bs=1M count=64 && sleep 3600")
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("16Mi")},
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		evicted, err := framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodFailed, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Pod exceeding its ephemeral-storage limit was not evicted")
+		Expect(evicted.Status.Reason).To(Equal(evictedReason), "Pod failed for another reason: %s", evicted.Status.Message)
+		Expect(evicted.Status.Message).To(ContainSubstring("ephemeral"), "Pod was evicted for another reason")
+	})
+})
@@ -0,0 +1,130 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Oldest a sample may be; metrics-server scrapes every 15s by default, and node clocks may be slightly skewed
+const maxSampleAge = 2 * time.Minute
+
+// CPU the busy pod is limited to, and the least usage it must report while spinning
+const (
+	busyCPULimit = "200m"
+	busyCPUFloor = "50m"
+)
+
+// sample is the part of a metrics.k8s.io/v1beta1 NodeMetrics or PodMetrics common to both. The typed
+// client lives in k8s.io/metrics, so the suite decodes the raw responses.
+type sample struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time     `json:"timestamp"`
+	Window            metav1.Duration `json:"window"`
+	// Usage of a node; PodMetrics report usage per container instead
+	Usage      v1.ResourceList `json:"usage"`
+	Containers []struct {
+		Name  string          `json:"name"`
+		Usage v1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// getMetrics reads a metrics.k8s.io/v1beta1 path, e.g. nodes or namespaces/<ns>/pods/<name>, into out
+func getMetrics(ctx context.Context, out interface{}, path ...string) error {
+	raw, err := framework.Clientset.Discovery().RESTClient().Get().
+		AbsPath(append([]string{"/apis/metrics.k8s.io/v1beta1"}, path...)...).
+		DoRaw(ctx)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// expectFresh checks that the sample was taken over a window ending within maxSampleAge
+func expectFresh(g Gomega, s sample) {
+	age := time.Since(s.Timestamp.Time)
+	g.Expect(s.Timestamp.IsZero()).To(BeFalse(), "%s has no timestamp", s.Name)
+	g.Expect(age).To(BeNumerically("<", maxSampleAge), "%s sample is %s old", s.Name, age)
+	g.Expect(age).To(BeNumerically(">", -maxSampleAge), "%s sample is %s in the future", s.Name, -age)
+	g.Expect(s.Window.Duration).To(BeNumerically(">", 0), "%s sample has no window", s.Name)
+}
+
+// Validates what HPA specs rely on: metrics-server, or another resource metrics provider, serving fresh usage
+var _ = Describe("Resource metrics API", func() {
+	BeforeEach(func(ctx SpecContext) {
+		framework.RequireAPIService(ctx, "v1beta1.metrics.k8s.io")
+	})
+
+	It("should report fresh usage for every Ready node", func(ctx SpecContext) {
+		nodes, err := framework.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list nodes")
+		var ready []string
+		for _, node := range nodes.Items {
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+					ready = append(ready, node.Name)
+				}
+			}
+		}
+		Expect(ready).NotTo(BeEmpty(), "No node is Ready")
+
+		framework.Eventually(func(g Gomega) {
+			var list struct {
+				Items []sample `json:"items"`
+			}
+			g.Expect(getMetrics(ctx, &list, "nodes")).To(Succeed(), "Failed to list node metrics")
+			reported := map[string]sample{}
+			for _, item := range list.Items {
+				reported[item.Name] = item
+			}
+			for _, name := range ready {
+				g.Expect(reported).To(HaveKey(name), "No metrics for Ready node %s", name)
+				node := reported[name]
+				expectFresh(g, node)
+				g.Expect(node.Usage.Cpu().IsZero()).To(BeFalse(), "Node %s reports no CPU usage", name)
+				g.Expect(node.Usage.Memory().IsZero()).To(BeFalse(), "Node %s reports no memory usage", name)
+			}
+		}, 2*time.Minute, 5*time.Second).Should(Succeed())
+	})
+
+	It("should report non-trivial CPU for a busy pod", func(ctx SpecContext) {
+		namespace := framework.TestNamespace()
+		name := fmt.Sprintf("test-metrics-%d", time.Now().UnixNano())
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", "while :; do :; done")
+		pod.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse(busyCPULimit)},
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		})
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Busy pod did not start")
+
+		// The first samples of a new pod cover less than a full window, so wait for one showing the load
+		floor := resource.MustParse(busyCPUFloor)
+		framework.Eventually(func(g Gomega) {
+			var metrics sample
+			g.Expect(getMetrics(ctx, &metrics, "namespaces", namespace, "pods", name)).To(Succeed(), "Failed to get pod metrics")
+			expectFresh(g, metrics)
+			g.Expect(metrics.Containers).To(HaveLen(1), "Pod metrics do not cover its container")
+			usage := metrics.Containers[0].Usage.Cpu()
+			g.Expect(usage.Cmp(floor)).To(BeNumerically(">=", 0), "Busy pod reports %s CPU, want at least %s", usage, busyCPUFloor)
+			AddReportEntry("Busy pod CPU", usage.String())
+		}, 3*time.Minute, 5*time.Second).Should(Succeed())
+	})
+})
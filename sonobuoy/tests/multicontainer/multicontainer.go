@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where both containers mount the shared emptyDir
+const sharedMountPath = "/shared"
+
+// Port the server container listens on, reachable from its sibling on localhost
+const serverPort = 8080
+
+var _ = Describe("Multi-container pods", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-multicontainer-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should let containers talk over localhost and exchange files through a shared emptyDir", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c",
+			fmt.Sprintf("mkdir -p %[1]s/www && echo from-server > %[1]s/www/index.html && exec httpd -f -p %[2]d -h %[1]s/www", sharedMountPath, serverPort))
+		pod.Spec.Containers[0].Name = "server"
+		pod.Spec.Containers[0].ReadinessProbe = &v1.Probe{
+			ProbeHandler:  v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"test", "-f", sharedMountPath + "/www/index.html"}}},
+			PeriodSeconds: 1,
+		}
+		client := *pod.Spec.Containers[0].DeepCopy()
+		client.Name = "client"
+		client.Command = []string{"sleep", "3600"}
+		client.ReadinessProbe = nil
+		pod.Spec.Containers = append(pod.Spec.Containers, client)
+		pod.Spec.Volumes = []v1.Volume{{Name: "shared", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].VolumeMounts = []v1.VolumeMount{{Name: "shared", MountPath: sharedMountPath}}
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+
+		By("fetching the server's page from the client over localhost")
+		var page string
+		framework.Eventually(func() error {
+			result, err := framework.ExecInPod(ctx, namespace, name, "client", "wget", "-qO-", fmt.Sprintf("http://localhost:%d/index.html", serverPort))
+			page = strings.TrimSpace(result.Stdout)
+			return err
+		}, 60*time.Second, 2*time.Second).Should(Succeed(), "Client could not reach the server on localhost")
+		Expect(page).To(Equal("from-server"), "Client received another page than the server wrote")
+
+		By("passing a file from the client to the server")
+		_, err = framework.ExecInPod(ctx, namespace, name, "client", "sh", "-c", fmt.Sprintf("echo from-client > %s/reply", sharedMountPath))
+		Expect(err).NotTo(HaveOccurred(), "Client could not write to the shared volume")
+		result, err := framework.ExecInPod(ctx, namespace, name, "server", "cat", sharedMountPath+"/reply")
+		Expect(err).NotTo(HaveOccurred(), "Server could not read the client's file")
+		Expect(strings.TrimSpace(result.Stdout)).To(Equal("from-client"))
+
+		By("checking both containers share the pod's hostname")
+		serverHost, err := framework.ExecInPod(ctx, namespace, name, "server", "hostname")
+		Expect(err).NotTo(HaveOccurred(), "Failed to get server hostname")
+		clientHost, err := framework.ExecInPod(ctx, namespace, name, "client", "hostname")
+		Expect(err).NotTo(HaveOccurred(), "Failed to get client hostname")
+		Expect(clientHost.Stdout).To(Equal(serverHost.Stdout), "Containers do not share the pod's UTS namespace")
+	})
+})
@@ -0,0 +1,113 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Oldest minor version a kubelet or kube-proxy may run behind the API server, per the version skew policy
+const maxMinorSkew = 3
+
+// Resources every node must report a non-zero allocatable amount of
+var schedulableResources = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+
+// checkSkew returns why a node component's version violates the skew policy against the server, or ""
+func checkSkew(component, componentVersion string, server *version.Version) string {
+	parsed, err := version.ParseGeneric(componentVersion)
+	if err != nil {
+		return fmt.Sprintf("%s version %q does not parse: %v", component, componentVersion, err)
+	}
+	if parsed.Major() != server.Major() || parsed.Minor() > server.Minor() {
+		return fmt.Sprintf("%s %s is newer than the API server %s", component, componentVersion, server)
+	}
+	if server.Minor()-parsed.Minor() > maxMinorSkew {
+		return fmt.Sprintf("%s %s is more than %d minor versions behind the API server %s", component, componentVersion, maxMinorSkew, server)
+	}
+	return ""
+}
+
+// Only reads what the nodes report, so it is safe against any cluster
+var _ = Describe("Node information", Label(framework.LabelNode), func() {
+	var nodes []v1.Node
+
+	BeforeEach(func(ctx SpecContext) {
+		var err error
+		nodes, err = framework.TargetNodes(ctx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to list nodes")
+		Expect(nodes).NotTo(BeEmpty(), "Cluster has no nodes")
+	})
+
+	It("should have every node Ready", func() {
+		var notReady []string
+		for _, node := range nodes {
+			var ready *v1.NodeCondition
+			for i := range node.Status.Conditions {
+				if node.Status.Conditions[i].Type == v1.NodeReady {
+					ready = &node.Status.Conditions[i]
+				}
+			}
+			if ready == nil {
+				notReady = append(notReady, node.Name+" (no Ready condition)")
+			} else if ready.Status != v1.ConditionTrue {
+				notReady = append(notReady, fmt.Sprintf("%s (%s: %s)", node.Name, ready.Reason, ready.Message))
+			}
+		}
+		Expect(notReady).To(BeEmpty(), "Nodes are not Ready:\n%s", strings.Join(notReady, "\n"))
+	})
+
+	It("should report allocatable resources within capacity", func() {
+		for _, node := range nodes {
+			for _, name := range schedulableResources {
+				allocatable, ok := node.Status.Allocatable[name]
+				Expect(ok).To(BeTrue(), "Node %s reports no allocatable %s", node.Name, name)
+				Expect(allocatable.Sign()).To(Equal(1), "Node %s has no allocatable %s", node.Name, name)
+			}
+			for name, allocatable := range node.Status.Allocatable {
+				capacity, ok := node.Status.Capacity[name]
+				Expect(ok).To(BeTrue(), "Node %s reports allocatable %s without a capacity", node.Name, name)
+				Expect(allocatable.Cmp(capacity)).To(BeNumerically("<=", 0),
+					"Node %s has more allocatable %s (%s) than capacity (%s)", node.Name, name, allocatable.String(), capacity.String())
+			}
+		}
+	})
+
+	It("should carry the standard os, arch and hostname labels", func() {
+		for _, node := range nodes {
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelOSStable, node.Status.NodeInfo.OperatingSystem),
+				"Node %s has no %s label matching its operating system", node.Name, v1.LabelOSStable)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelArchStable, node.Status.NodeInfo.Architecture),
+				"Node %s has no %s label matching its architecture", node.Name, v1.LabelArchStable)
+			Expect(node.Labels).To(HaveKeyWithValue(v1.LabelHostname, Not(BeEmpty())),
+				"Node %s has no %s label", node.Name, v1.LabelHostname)
+		}
+	})
+
+	It("should run kubelets and kube-proxies within the version skew policy", func() {
+		server := framework.Server().Version
+
+		var violations []string
+		for _, node := range nodes {
+			info := node.Status.NodeInfo
+			AddReportEntry("Node versions", fmt.Sprintf("%s: kubelet %s, kube-proxy %s, runtime %s, kernel %s",
+				node.Name, info.KubeletVersion, info.KubeProxyVersion, info.ContainerRuntimeVersion, info.KernelVersion))
+			if violation := checkSkew("kubelet", info.KubeletVersion, server); violation != "" {
+				violations = append(violations, node.Name+": "+violation)
+			}
+			// Newer kubelets no longer report the kube-proxy version, and some clusters run none
+			if info.KubeProxyVersion != "" {
+				if violation := checkSkew("kube-proxy", info.KubeProxyVersion, server); violation != "" {
+					violations = append(violations, node.Name+": "+violation)
+				}
+			}
+		}
+		Expect(violations).To(BeEmpty(), "Node components violate the version skew policy:\n%s", strings.Join(violations, "\n"))
+	})
+})
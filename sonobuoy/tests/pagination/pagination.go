@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const pageSize = framework.PaginationPageSize
+
+// Paginated lists must return every object exactly once and in key order,
+// even when an apiserver or etcd proxy sits in between
+var _ = Describe("List Pagination", func() {
+	var namespace string
+	var runLabel string
+	var expectedNames []string
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		config, err := framework.LoadRunConfig()
+		Expect(err).NotTo(HaveOccurred(), "Failed to load run configuration")
+		count := config.PaginationObjects
+		runLabel = fmt.Sprintf("%d", time.Now().UnixNano())
+
+		expectedNames = make([]string, count)
+		for i := range expectedNames {
+			expectedNames[i] = fmt.Sprintf("test-page-%s-%04d", runLabel, i)
+		}
+
+		// Create the ConfigMaps with a few workers to keep setup time reasonable
+		names := make(chan string)
+		errs := make(chan error, count)
+		var wg sync.WaitGroup
+		for w := 0; w < 10; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for name := range names {
+					configMap := &v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      name,
+							Namespace: namespace,
+							Labels:    map[string]string{"e2e-pagination": runLabel},
+						},
+						Data: map[string]string{"name": name},
+					}
+					_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+					errs <- err
+				}
+			}()
+		}
+		for _, name := range expectedNames {
+			names <- name
+		}
+		close(names)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		}
+	})
+
+	It("should return every object exactly once and in order across pages", func(ctx SpecContext) {
+		var listed []string
+		continueToken := ""
+		pages := 0
+		for {
+			list, err := framework.Clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: "e2e-pagination=" + runLabel,
+				Limit:         pageSize,
+				Continue:      continueToken,
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list ConfigMaps (page %d)", pages)
+			Expect(len(list.Items)).To(BeNumerically("<=", pageSize), "Page exceeded the requested limit")
+			pages++
+
+			for _, item := range list.Items {
+				listed = append(listed, item.Name)
+			}
+			continueToken = list.Continue
+			if continueToken == "" {
+				break
+			}
+			Expect(pages).To(BeNumerically("<=", len(expectedNames)), "Pagination did not terminate")
+		}
+
+		Expect(pages).To(BeNumerically(">=", (len(expectedNames)+pageSize-1)/pageSize), "Apiserver ignored the limit")
+		Expect(sort.StringsAreSorted(listed)).To(BeTrue(), "Paginated results are not in key order")
+		Expect(listed).To(Equal(expectedNames), "Paginated results are incomplete or contain duplicates")
+	})
+
+	It("should report the remaining item count and reject a malformed continue token", func(ctx SpecContext) {
+		list, err := framework.Clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "e2e-pagination=" + runLabel,
+			Limit:         pageSize,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list ConfigMaps")
+		Expect(list.Continue).NotTo(BeEmpty(), "Expected a continue token for a partial list")
+		if list.RemainingItemCount != nil {
+			Expect(*list.RemainingItemCount).To(Equal(int64(len(expectedNames) - len(list.Items))))
+		}
+
+		_, err = framework.Clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "e2e-pagination=" + runLabel,
+			Limit:         pageSize,
+			Continue:      "not-a-valid-token",
+		})
+		Expect(errors.IsBadRequest(err)).To(BeTrue(), "Expected BadRequest for malformed continue token, got: %v", err)
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.CleanupCollection(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), metav1.ListOptions{
+			LabelSelector: "e2e-pagination=" + runLabel,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMaps")
+	})
+})
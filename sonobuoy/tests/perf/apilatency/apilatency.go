@@ -0,0 +1,96 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Operations measured, keyed as in E2E_APILATENCY_SLO
+var operations = []string{"create", "get", "list", "delete"}
+
+// Size of the data of each ConfigMap, small enough that the API server rather than the payload dominates
+const payloadSize = 1024
+
+// The latencies are those of the calls as clients see them, round trip included, so they are an upper bound
+// of the API server's own request latency metrics the Kubernetes SLOs are defined on.
+var _ = Describe("API call latency", Label(framework.LabelPerf), func() {
+	It("should create, get, list and delete ConfigMaps within the latency SLOs", func(ctx SpecContext) {
+		perf := framework.RequirePerf()
+		namespace := framework.TestNamespace()
+		run := fmt.Sprintf("apilatency-%d", time.Now().UnixNano())
+		selector := "e2e.sonobuoy.io/benchmark=" + run
+
+		clientset, err := framework.PerfClientset()
+		Expect(err).NotTo(HaveOccurred(), "Failed to create benchmark client")
+		configMaps := clientset.CoreV1().ConfigMaps(namespace)
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.CleanupCollection(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), metav1.ListOptions{LabelSelector: selector})
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMaps")
+		})
+
+		latencies := map[string]*framework.Latencies{}
+		for _, operation := range operations {
+			latencies[operation] = framework.NewLatencies(operation)
+		}
+		names := make([]string, perf.Iterations)
+		for i := range names {
+			names[i] = fmt.Sprintf("%s-%d", run, i)
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      names[i],
+					Namespace: namespace,
+					Labels:    map[string]string{"e2e.sonobuoy.io/benchmark": run},
+				},
+				Data: map[string]string{"payload": strings.Repeat("x", payloadSize)},
+			}
+			err := latencies["create"].Time(func() error {
+				_, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap %s", names[i])
+		}
+		for _, name := range names {
+			err := latencies["get"].Time(func() error {
+				_, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap %s", name)
+		}
+		for range names {
+			err := latencies["list"].Time(func() error {
+				list, err := configMaps.List(ctx, metav1.ListOptions{LabelSelector: selector})
+				if err == nil && len(list.Items) != len(names) {
+					err = fmt.Errorf("listed %d ConfigMaps, want %d", len(list.Items), len(names))
+				}
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list ConfigMaps")
+		}
+		for _, name := range names {
+			err := latencies["delete"].Time(func() error {
+				return configMaps.Delete(ctx, name, metav1.DeleteOptions{})
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap %s", name)
+		}
+
+		var violations []string
+		for _, operation := range operations {
+			summary := latencies[operation].Summary()
+			framework.ReportLatencies(summary)
+			if slo, ok := perf.APILatencySLOs[operation]; ok && summary.P99 > slo.Seconds() {
+				violations = append(violations, fmt.Sprintf("%s p99 of %s exceeds the SLO of %s", operation,
+					time.Duration(summary.P99*float64(time.Second)).Round(time.Millisecond), slo))
+			}
+		}
+		Expect(violations).To(BeEmpty(), "API call latencies violate their SLOs")
+	}, SpecTimeout(30*time.Minute))
+})
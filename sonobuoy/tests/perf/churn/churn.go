@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// How long the Deployments may take to become available, and their pods to be gone once deleted
+const churnTimeout = 15 * time.Minute
+
+// Label of every Deployment and pod the benchmark creates
+const benchmarkLabel = "e2e.sonobuoy.io/benchmark"
+
+// available reports whether every replica of the Deployment runs its current template and is available
+func available(deployment *appsv1.Deployment) bool {
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == *deployment.Spec.Replicas &&
+		deployment.Status.AvailableReplicas == *deployment.Spec.Replicas
+}
+
+// The Deployments are created and deleted all at once, so the benchmark measures how fast the controllers,
+// the scheduler and the kubelets work through a burst, as on a rollout of many services.
+var _ = Describe("Deployment churn", Label(framework.LabelPerf), func() {
+	It("should report how fast Deployments become available and are cleaned up", func(ctx SpecContext) {
+		perf := framework.RequirePerf()
+		deployments, replicas := perf.ChurnDeployments, int32(perf.ChurnReplicas)
+		namespace := framework.TestNamespace()
+		run := fmt.Sprintf("churn-%d", time.Now().UnixNano())
+		selector := metav1.ListOptions{LabelSelector: benchmarkLabel + "=" + run}
+
+		newDeployment := func(i int) *appsv1.Deployment {
+			deployment := framework.NewDeployment(namespace, fmt.Sprintf("%s-%d", run, i), podImage, replicas, "sleep", "3600")
+			deployment.Labels = map[string]string{benchmarkLabel: run}
+			deployment.Spec.Template.Labels[benchmarkLabel] = run
+			return deployment
+		}
+		framework.RequirePodRoom(ctx, int64(deployments)*int64(replicas), &newDeployment(0).Spec.Template.Spec)
+
+		clientset, err := framework.PerfClientset()
+		Expect(err).NotTo(HaveOccurred(), "Failed to create benchmark client")
+		client := clientset.AppsV1().Deployments(namespace)
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.CleanupCollection(ctx, framework.Clientset.AppsV1().Deployments(namespace), selector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployments")
+		})
+
+		By(fmt.Sprintf("Creating %d Deployments of %d replicas at once", deployments, replicas))
+		watcher, err := client.Watch(ctx, selector)
+		Expect(err).NotTo(HaveOccurred(), "Failed to watch Deployments")
+		start := time.Now()
+		var wg sync.WaitGroup
+		errs := make([]error, deployments)
+		for i := 0; i < deployments; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = client.Create(ctx, newDeployment(i), metav1.CreateOptions{})
+			}(i)
+		}
+		wg.Wait()
+		Expect(errors.Join(errs...)).NotTo(HaveOccurred(), "Failed to create Deployments")
+
+		availability := framework.NewLatencies("deployment available")
+		ready := map[string]bool{}
+		err = framework.WatchUntil(ctx, watcher, churnTimeout, func(event watch.Event) bool {
+			deployment, ok := event.Object.(*appsv1.Deployment)
+			if ok && !ready[deployment.Name] && available(deployment) {
+				ready[deployment.Name] = true
+				availability.Observe(time.Since(start))
+			}
+			return len(ready) == deployments
+		})
+		Expect(err).NotTo(HaveOccurred(), "Only %d of %d Deployments became available", len(ready), deployments)
+		framework.ReportThroughput("pods available", deployments*int(replicas), time.Since(start))
+
+		By("Deleting the Deployments at once")
+		pods := clientset.CoreV1().Pods(namespace)
+		list, err := pods.List(ctx, selector)
+		Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+		remaining := map[string]bool{}
+		for _, pod := range list.Items {
+			remaining[pod.Name] = true
+		}
+		podWatcher, err := pods.Watch(ctx, metav1.ListOptions{LabelSelector: selector.LabelSelector, ResourceVersion: list.ResourceVersion})
+		Expect(err).NotTo(HaveOccurred(), "Failed to watch pods")
+		start = time.Now()
+		// Background propagation leaves the pods to the garbage collector, whose pace is what is measured
+		background := metav1.DeletePropagationBackground
+		err = client.DeleteCollection(ctx, metav1.DeleteOptions{PropagationPolicy: &background}, selector)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployments")
+		err = framework.WatchUntil(ctx, podWatcher, churnTimeout, func(event watch.Event) bool {
+			if pod, ok := event.Object.(metav1.Object); ok {
+				switch event.Type {
+				case watch.Added:
+					remaining[pod.GetName()] = true
+				case watch.Deleted:
+					delete(remaining, pod.GetName())
+				}
+			}
+			return len(remaining) == 0
+		})
+		Expect(err).NotTo(HaveOccurred(), "%d pods were not deleted", len(remaining))
+		framework.ReportThroughput("pods deleted", len(list.Items), time.Since(start))
+		framework.ReportLatencies(availability.Summary())
+	}, SpecTimeout(time.Hour))
+})
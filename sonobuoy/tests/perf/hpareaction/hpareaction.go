@@ -0,0 +1,202 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// CPU each pod requests, and the limit that keeps a loaded pod at 200% utilization
+const (
+	cpuRequest = "100m"
+	cpuLimit   = "200m"
+)
+
+// Utilization the HPA targets; a single loaded pod at 200% asks for targetReplicas
+const (
+	targetUtilization = 50
+	targetReplicas    = 4
+)
+
+// Pods busy-loop while the load file exists and idle otherwise, so the load is a step under the spec's control
+const (
+	loadFile    = "/tmp/load"
+	loadCommand = "while :; do if [ -f " + loadFile + " ]; then :; else sleep 1; fi; done"
+)
+
+// How long the HPA may take to read metrics, react to the load and have the new pods available, and to
+// scale back down once the load is gone
+const reactionTimeout = 10 * time.Minute
+
+// utilization returns the average CPU utilization the HPA last read, or -1 before it read any
+func utilization(hpa *autoscalingv2.HorizontalPodAutoscaler) int32 {
+	for _, metric := range hpa.Status.CurrentMetrics {
+		if metric.Resource != nil && metric.Resource.Current.AverageUtilization != nil {
+			return *metric.Resource.Current.AverageUtilization
+		}
+	}
+	return -1
+}
+
+// metricsPipeline names the service serving the resource metrics API, e.g. metrics-server or an adapter,
+// whose speed the reaction times mostly depend on
+func metricsPipeline(apiService *unstructured.Unstructured) string {
+	namespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+	name, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+	if name == "" {
+		return "local"
+	}
+	return namespace + "/" + name
+}
+
+// A single pod of an HPA-managed Deployment is loaded at once, and the benchmark times from the step until the
+// HPA reads the load from the resource metrics API, until it first scales the Deployment and until the new
+// pods are available. Scaling up is left to the default behavior, which reaches targetReplicas in a single
+// step, so the times compare the metrics pipelines and the HPA controller rather than scaling policies.
+var _ = Describe("HPA reaction time", Label(framework.LabelPerf), func() {
+	It("should report how fast the HPA reacts to a step in load", func(ctx SpecContext) {
+		perf := framework.RequirePerf()
+		framework.RequireAPIGroupVersion("autoscaling/v2")
+		framework.RequireAPIService(ctx, "v1beta1.metrics.k8s.io")
+		namespace := framework.TestNamespace()
+		name := fmt.Sprintf("hpa-reaction-%d", time.Now().UnixNano())
+
+		apiService, err := framework.DynamicClient.Resource(framework.APIServiceResource).Get(ctx, "v1beta1.metrics.k8s.io", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get the resource metrics APIService")
+		AddReportEntry("Metrics pipeline", metricsPipeline(apiService))
+
+		deployment := framework.NewDeployment(namespace, name, podImage, 1, "sh", "-c", loadCommand)
+		deployment.Spec.Template.Spec.Containers[0].Resources = v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpuRequest)},
+			Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpuLimit)},
+		}
+		framework.RequirePodRoom(ctx, targetReplicas, &deployment.Spec.Template.Spec)
+
+		clientset, err := framework.PerfClientset()
+		Expect(err).NotTo(HaveOccurred(), "Failed to create benchmark client")
+		deployments := clientset.AppsV1().Deployments(namespace)
+		hpas := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+
+		By("Creating an idle Deployment managed by an HPA")
+		_, err = deployments.Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)).To(Succeed(), "Failed to delete Deployment")
+		})
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, reactionTimeout)
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not become available")
+
+		minReplicas, target, noWindow := int32(1), int32(targetUtilization), int32(0)
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    targetReplicas,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   v1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &target},
+					},
+				}},
+				// Without a window the HPA returns to one replica as soon as the load is gone, for the next iteration
+				Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+					ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &noWindow},
+				},
+			},
+		}
+		_, err = hpas.Create(ctx, hpa, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create HPA")
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(framework.Cleanup(ctx, framework.Clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace), name)).To(Succeed(), "Failed to delete HPA")
+		})
+
+		metricObserved := framework.NewLatencies("hpa metric observed")
+		firstScale := framework.NewLatencies("hpa first scale")
+		targetReached := framework.NewLatencies("hpa target replicas available")
+		for i := 0; i < perf.HPAIterations; i++ {
+			By(fmt.Sprintf("Waiting for the HPA to read the idle load, iteration %d", i+1))
+			framework.Eventually(func() (bool, error) {
+				current, err := hpas.Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				idle := utilization(current) >= 0 && utilization(current) < targetUtilization
+				return idle && current.Status.CurrentReplicas == 1 && current.Status.DesiredReplicas == 1, nil
+			}, reactionTimeout, 2*time.Second).Should(BeTrue(), "HPA did not settle on one idle replica")
+			_, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, reactionTimeout)
+			Expect(err).NotTo(HaveOccurred(), "Deployment did not settle on one replica")
+			pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=" + name})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+			var loaded string
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning {
+					loaded = pod.Name
+				}
+			}
+			Expect(loaded).NotTo(BeEmpty(), "No running pod to load")
+
+			By(fmt.Sprintf("Loading pod %s, iteration %d", loaded, i+1))
+			hpaWatch, err := hpas.Watch(ctx, metav1.ListOptions{FieldSelector: "metadata.name=" + name})
+			Expect(err).NotTo(HaveOccurred(), "Failed to watch HPA")
+			deploymentWatch, err := deployments.Watch(ctx, metav1.ListOptions{FieldSelector: "metadata.name=" + name})
+			Expect(err).NotTo(HaveOccurred(), "Failed to watch Deployment")
+			_, err = framework.ExecInPod(ctx, namespace, loaded, "", "touch", loadFile)
+			Expect(err).NotTo(HaveOccurred(), "Failed to load pod %s", loaded)
+			start := time.Now()
+
+			observed := make(chan error, 1)
+			go func() {
+				latency, err := framework.TimeUntil(ctx, hpaWatch, start, reactionTimeout, func(obj runtime.Object) bool {
+					current, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+					return ok && utilization(current) >= targetUtilization
+				})
+				if err == nil {
+					metricObserved.Observe(latency)
+				}
+				observed <- err
+			}()
+			scaled := false
+			err = framework.WatchUntil(ctx, deploymentWatch, reactionTimeout, func(event watch.Event) bool {
+				current, ok := event.Object.(*appsv1.Deployment)
+				if !ok {
+					return false
+				}
+				if !scaled && *current.Spec.Replicas > 1 {
+					scaled = true
+					firstScale.Observe(time.Since(start))
+				}
+				if current.Status.ObservedGeneration >= current.Generation && *current.Spec.Replicas == targetReplicas &&
+					current.Status.AvailableReplicas == targetReplicas {
+					targetReached.Observe(time.Since(start))
+					return true
+				}
+				return false
+			})
+			Expect(err).NotTo(HaveOccurred(), "Deployment did not reach %d available replicas", targetReplicas)
+			Expect(<-observed).To(Succeed(), "HPA did not report the load")
+
+			By(fmt.Sprintf("Removing the load, iteration %d", i+1))
+			_, err = framework.ExecInPod(ctx, namespace, loaded, "", "rm", "-f", loadFile)
+			Expect(err).NotTo(HaveOccurred(), "Failed to unload pod %s", loaded)
+		}
+
+		framework.ReportLatencies(metricObserved.Summary(), firstScale.Summary(), targetReached.Summary())
+	}, SpecTimeout(2*time.Hour))
+})
@@ -0,0 +1,359 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	netexecImage = "registry.k8s.io/e2e-test-images/agnhost:2.43"
+	netexecPort  = 8080
+	probeImage   = "alpine:3.20"
+)
+
+// How long a change may take to reach the EndpointSlices and every node, backend startup included
+const propagationTimeout = 3 * time.Minute
+
+// Most nodes traffic is probed from, to bound the load of large clusters
+const maxProbeNodes = 10
+
+// Consecutive requests that must miss a removed backend before its removal counts as programmed
+const removedStreak = 20
+
+// Exit code of a probe giving up before the exec timeout, to be run again
+const probeRetryCode = 3
+
+// Seconds a probe runs before it gives up, below the default exec timeout
+const probeRound = 40
+
+// addedScript requests the Service's /hostname until a backend other than the known ones answers
+func addedScript(serviceIP string, known []string) string {
+	return fmt.Sprintf(`end=$(( $(date +%%s) + %d ))
+until h=$(wget -qO- -T 1 http://%s/hostname 2>/dev/null) && [ -n "$h" ] && ! echo " %s " | grep -q " $h "; do
+	[ "$(date +%%s)" -ge "$end" ] && exit %d
+	sleep 0.05
+done`, probeRound, serviceIP, strings.Join(known, " "), probeRetryCode)
+}
+
+// removedScript requests the Service's /hostname until the removed backend missed removedStreak requests in
+// a row, and prints how many nanoseconds ago the streak started, measured by the node's own clock
+func removedScript(serviceIP, removed string) string {
+	return fmt.Sprintf(`end=$(( $(date +%%s) + %d )); n=0
+while [ "$n" -lt %d ]; do
+	[ "$(date +%%s)" -ge "$end" ] && exit %d
+	h=$(wget -qO- -T 1 http://%s/hostname 2>/dev/null)
+	if [ -n "$h" ] && [ "$h" != "%s" ]; then
+		[ "$n" -eq 0 ] && streak=$(date +%%s%%N)
+		n=$((n + 1))
+	else
+		n=0
+	fi
+	sleep 0.05
+done
+echo $(( $(date +%%s%%N) - streak ))`, probeRound, removedStreak, probeRetryCode, serviceIP, removed)
+}
+
+// probe runs script in the probe pod until it succeeds, running it again whenever it gives up within
+// propagationTimeout, and returns what it printed and when it finished
+func probe(ctx context.Context, namespace, pod, script string) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, propagationTimeout)
+	defer cancel()
+	for {
+		result, err := framework.ExecInPod(ctx, namespace, pod, "", "sh", "-c", script)
+		var exit *framework.ExecExitError
+		if errors.As(err, &exit) && exit.ExitCode == probeRetryCode && ctx.Err() == nil {
+			continue
+		}
+		return result.Stdout, time.Now(), err
+	}
+}
+
+// probeResult is when traffic from a node reflected a change
+type probeResult struct {
+	node string
+	at   time.Time
+	err  error
+}
+
+// runProbes runs the script returned for each probe pod in parallel and returns when each one finished,
+// corrected by the duration it printed, if any
+func runProbes(ctx context.Context, namespace string, probes map[string]string, script string) <-chan probeResult {
+	results := make(chan probeResult, len(probes))
+	var wg sync.WaitGroup
+	for node, pod := range probes {
+		wg.Add(1)
+		go func(node, pod string) {
+			defer wg.Done()
+			output, at, err := probe(ctx, namespace, pod, script)
+			if nanos, parseErr := strconv.ParseInt(strings.TrimSpace(output), 10, 64); err == nil && parseErr == nil {
+				at = at.Add(-time.Duration(nanos))
+			}
+			results <- probeResult{node: node, at: at, err: err}
+		}(node, pod)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// sliceResult is when the EndpointSlices reflected a change, or why they did not
+type sliceResult struct {
+	at  time.Time
+	err error
+}
+
+// readyEndpoints returns the pods the EndpointSlices list as ready
+func readyEndpoints(slices map[string]*discoveryv1.EndpointSlice) map[string]bool {
+	ready := map[string]bool{}
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.TargetRef != nil && (endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready) {
+				ready[endpoint.TargetRef.Name] = true
+			}
+		}
+	}
+	return ready
+}
+
+// watchSlices returns when the EndpointSlices of the watch first satisfied done
+func watchSlices(ctx context.Context, watcher watch.Interface, done func(ready map[string]bool) bool) (time.Time, error) {
+	slices := map[string]*discoveryv1.EndpointSlice{}
+	var at time.Time
+	err := framework.WatchUntil(ctx, watcher, propagationTimeout, func(event watch.Event) bool {
+		slice, ok := event.Object.(*discoveryv1.EndpointSlice)
+		if !ok {
+			return false
+		}
+		if event.Type == watch.Deleted {
+			delete(slices, slice.Name)
+		} else {
+			slices[slice.Name] = slice
+		}
+		if done(readyEndpoints(slices)) {
+			at = time.Now()
+			return true
+		}
+		return false
+	})
+	return at, err
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// probeNodes returns the Ready, schedulable nodes without taints keeping pods off, up to maxProbeNodes
+func probeNodes(ctx context.Context) []string {
+	GinkgoHelper()
+	nodes, err := framework.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	Expect(err).NotTo(HaveOccurred(), "Failed to list nodes")
+	var names []string
+	for _, node := range nodes.Items {
+		usable := !node.Spec.Unschedulable
+		for _, taint := range node.Spec.Taints {
+			if taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute {
+				usable = false
+			}
+		}
+		ready := false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				ready = true
+			}
+		}
+		if usable && ready && len(names) < maxProbeNodes {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// A backend is added by scaling the Deployment up and removed by scaling it down, and each change is timed
+// until the EndpointSlices show it and until requests to the Service's ClusterIP from a pod on each node
+// reflect it. Additions are timed from the new pod turning Ready, removals from the scale down. The
+// difference between a node's traffic and the EndpointSlice update is the delay of its kube-proxy, or
+// whatever programs Services there.
+var _ = Describe("Service endpoint propagation", Label(framework.LabelPerf), func() {
+	It("should report how long backend changes take to reach EndpointSlices and each node's traffic", func(ctx SpecContext) {
+		perf := framework.RequirePerf()
+		framework.RequireReadyNodes(ctx, 1)
+		namespace := framework.TestNamespace()
+		name := fmt.Sprintf("propagation-%d", time.Now().UnixNano())
+		nodes := probeNodes(ctx)
+		Expect(nodes).NotTo(BeEmpty(), "No node can run the probe pods")
+
+		clientset, err := framework.PerfClientset()
+		Expect(err).NotTo(HaveOccurred(), "Failed to create benchmark client")
+		deployments := clientset.AppsV1().Deployments(namespace)
+		pods := clientset.CoreV1().Pods(namespace)
+		slices := clientset.DiscoveryV1().EndpointSlices(namespace)
+
+		By("Deploying a backend behind a Service and a probe pod on each node")
+		deployment := framework.NewDeployment(namespace, name, netexecImage, 1)
+		container := &deployment.Spec.Template.Spec.Containers[0]
+		container.Args = []string{"netexec", fmt.Sprintf("--http-port=%d", netexecPort)}
+		container.Ports = []v1.ContainerPort{{ContainerPort: netexecPort}}
+		container.ReadinessProbe = &v1.Probe{
+			ProbeHandler:  v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/hostname", Port: intstr.FromInt(netexecPort)}},
+			PeriodSeconds: 1,
+		}
+		_, err = framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)).To(Succeed(), "Failed to delete Deployment")
+		})
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				Selector: map[string]string{"app": name},
+				Ports:    []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(netexecPort)}},
+			},
+		}
+		service, err = framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Service")
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), name)).To(Succeed(), "Failed to delete Service")
+		})
+
+		probes := map[string]string{}
+		for i, node := range nodes {
+			probeName := fmt.Sprintf("%s-probe-%d", name, i)
+			pod := framework.NewPod(namespace, probeName, probeImage, "sleep", "infinity")
+			pod.Spec.NodeName = node
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create probe pod on node %s", node)
+			DeferCleanup(func(ctx SpecContext) {
+				Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), probeName)).To(Succeed(), "Failed to delete probe pod")
+			})
+			probes[node] = probeName
+		}
+		for _, probeName := range probes {
+			_, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, probeName, propagationTimeout)
+			Expect(err).NotTo(HaveOccurred(), "Probe pod did not start")
+		}
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, propagationTimeout)
+		Expect(err).NotTo(HaveOccurred(), "Backend did not become available")
+
+		selector := metav1.ListOptions{LabelSelector: "app=" + name}
+		sliceSelector := metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + name}
+		sliceAdded := framework.NewLatencies("endpointslice add")
+		sliceRemoved := framework.NewLatencies("endpointslice remove")
+		programAdded, programRemoved := map[string]*framework.Latencies{}, map[string]*framework.Latencies{}
+		for _, node := range nodes {
+			programAdded[node] = framework.NewLatencies(node + " programming add")
+			programRemoved[node] = framework.NewLatencies(node + " programming remove")
+		}
+		// A node's traffic may change before the EndpointSlice watch delivered the update
+		programmed := func(latencies *framework.Latencies, at, sliceAt time.Time) {
+			latencies.Observe(max(at.Sub(sliceAt), 0))
+		}
+		scale := func(replicas int) {
+			GinkgoHelper()
+			patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)
+			_, err := deployments.Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to scale Deployment to %d", replicas)
+		}
+
+		for i := 0; i < perf.PropagationIterations; i++ {
+			By(fmt.Sprintf("Adding a backend, iteration %d", i+1))
+			list, err := pods.List(ctx, selector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to list backends")
+			var known []string
+			for _, pod := range list.Items {
+				known = append(known, pod.Name)
+			}
+			isKnown := func(pod string) bool { return strings.Contains(" "+strings.Join(known, " ")+" ", " "+pod+" ") }
+
+			podWatch, err := pods.Watch(ctx, metav1.ListOptions{LabelSelector: selector.LabelSelector, ResourceVersion: list.ResourceVersion})
+			Expect(err).NotTo(HaveOccurred(), "Failed to watch backends")
+			sliceWatch, err := slices.Watch(ctx, sliceSelector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to watch EndpointSlices")
+			traffic := runProbes(ctx, namespace, probes, addedScript(service.Spec.ClusterIP, known))
+			sliceDone := make(chan sliceResult, 1)
+			go func() {
+				at, err := watchSlices(ctx, sliceWatch, func(ready map[string]bool) bool {
+					for pod := range ready {
+						if !isKnown(pod) {
+							return true
+						}
+					}
+					return false
+				})
+				sliceDone <- sliceResult{at: at, err: err}
+			}()
+
+			scale(len(known) + 1)
+			var added string
+			var readyAt time.Time
+			err = framework.WatchUntil(ctx, podWatch, propagationTimeout, func(event watch.Event) bool {
+				pod, ok := event.Object.(*v1.Pod)
+				if ok && !isKnown(pod.Name) && podReady(pod) {
+					added, readyAt = pod.Name, time.Now()
+					return true
+				}
+				return false
+			})
+			Expect(err).NotTo(HaveOccurred(), "New backend did not become Ready")
+			slice := <-sliceDone
+			Expect(slice.err).NotTo(HaveOccurred(), "EndpointSlices did not list the new backend")
+			sliceAt := slice.at
+			sliceAdded.Observe(max(sliceAt.Sub(readyAt), 0))
+			for result := range traffic {
+				Expect(result.err).NotTo(HaveOccurred(), "Traffic from node %s did not reach the new backend", result.node)
+				programmed(programAdded[result.node], result.at, sliceAt)
+			}
+
+			By(fmt.Sprintf("Removing the backend, iteration %d", i+1))
+			// The ReplicaSet removes the pods with the lowest deletion cost first
+			cost := `{"metadata":{"annotations":{"controller.kubernetes.io/pod-deletion-cost":"-1000"}}}`
+			_, err = pods.Patch(ctx, added, types.MergePatchType, []byte(cost), metav1.PatchOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to set the deletion cost of %s", added)
+			sliceWatch, err = slices.Watch(ctx, sliceSelector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to watch EndpointSlices")
+			traffic = runProbes(ctx, namespace, probes, removedScript(service.Spec.ClusterIP, added))
+			start := time.Now()
+			scale(len(known))
+			sliceAt, err = watchSlices(ctx, sliceWatch, func(ready map[string]bool) bool { return !ready[added] })
+			Expect(err).NotTo(HaveOccurred(), "EndpointSlices kept listing the removed backend as ready")
+			sliceRemoved.Observe(sliceAt.Sub(start))
+			for result := range traffic {
+				Expect(result.err).NotTo(HaveOccurred(), "Traffic from node %s kept reaching the removed backend", result.node)
+				programmed(programRemoved[result.node], result.at, sliceAt)
+			}
+			framework.Eventually(func() error {
+				_, err := pods.Get(ctx, added, metav1.GetOptions{})
+				return err
+			}, propagationTimeout, time.Second).Should(Satisfy(apierrors.IsNotFound), "Removed backend %s was not deleted", added)
+		}
+
+		framework.ReportLatencies(sliceAdded.Summary(), sliceRemoved.Summary())
+		for _, node := range nodes {
+			framework.ReportLatencies(programAdded[node].Summary(), programRemoved[node].Summary())
+		}
+	}, SpecTimeout(time.Hour))
+})
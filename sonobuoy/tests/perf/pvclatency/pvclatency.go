@@ -0,0 +1,144 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// How long a single volume may take to provision or mount before the benchmark gives up
+const provisionTimeout = 5 * time.Minute
+
+// Label of every claim and pod the benchmark creates, to clean up what a failed iteration left behind
+const benchmarkLabel = "e2e.sonobuoy.io/benchmark"
+
+func claimBound(obj runtime.Object) bool {
+	claim, ok := obj.(*v1.PersistentVolumeClaim)
+	return ok && claim.Status.Phase == v1.ClaimBound
+}
+
+func podRunning(obj runtime.Object) bool {
+	pod, ok := obj.(*v1.Pod)
+	return ok && pod.Status.Phase == v1.PodRunning
+}
+
+// storageClasses returns the StorageClasses to measure, skipping the spec if one does not exist
+func storageClasses(ctx context.Context, perf *framework.PerfConfig) []*storagev1.StorageClass {
+	GinkgoHelper()
+	if len(perf.StorageClasses) == 0 {
+		return []*storagev1.StorageClass{framework.RequireTestStorageClass(ctx)}
+	}
+	var classes []*storagev1.StorageClass
+	for _, name := range perf.StorageClasses {
+		framework.RequireStorageClass(ctx, name)
+		class, err := framework.Clientset.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get StorageClass %s", name)
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// Volumes are provisioned one at a time, so the latencies are those of an otherwise idle provisioner. The
+// mount latency runs from the pod's creation until its container started, which includes scheduling, the
+// attach and the mount; the image is one most suites pull, so pulling it rarely adds to it.
+var _ = Describe("Volume provisioning latency", Label(framework.LabelPerf), func() {
+	It("should report how long volumes take to bind and mount per StorageClass", func(ctx SpecContext) {
+		perf := framework.RequirePerf()
+		classes := storageClasses(ctx, perf)
+		namespace := framework.TestNamespace()
+		run := fmt.Sprintf("pvclatency-%d", time.Now().UnixNano())
+		selector := metav1.ListOptions{LabelSelector: benchmarkLabel + "=" + run}
+
+		clientset, err := framework.PerfClientset()
+		Expect(err).NotTo(HaveOccurred(), "Failed to create benchmark client")
+		claims := clientset.CoreV1().PersistentVolumeClaims(namespace)
+		pods := clientset.CoreV1().Pods(namespace)
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.CleanupCollection(ctx, framework.Clientset.CoreV1().Pods(namespace), selector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pods")
+			err = framework.CleanupCollection(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), selector)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete PVCs")
+		})
+
+		for _, class := range classes {
+			// WaitForFirstConsumer claims only bind once a pod uses them, so binding is timed from the pod's creation
+			waitForFirstConsumer := class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+			bind := framework.NewLatencies(class.Name + " bind")
+			mount := framework.NewLatencies(class.Name + " mount")
+
+			for i := 0; i < perf.StorageIterations; i++ {
+				name := fmt.Sprintf("%s-%d", run, i)
+				labels := map[string]string{benchmarkLabel: run}
+				byName := metav1.ListOptions{FieldSelector: "metadata.name=" + name}
+
+				claimWatch, err := claims.Watch(ctx, byName)
+				Expect(err).NotTo(HaveOccurred(), "Failed to watch PVC %s", name)
+				claim := &v1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+					Spec: v1.PersistentVolumeClaimSpec{
+						AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+						StorageClassName: &class.Name,
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+						},
+					},
+				}
+				start := time.Now()
+				_, err = claims.Create(ctx, claim, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred(), "Failed to create PVC %s", name)
+				if !waitForFirstConsumer {
+					latency, err := framework.TimeUntil(ctx, claimWatch, start, provisionTimeout, claimBound)
+					Expect(err).NotTo(HaveOccurred(), "PVC %s of StorageClass %s did not bind", name, class.Name)
+					bind.Observe(latency)
+				}
+
+				podWatch, err := pods.Watch(ctx, byName)
+				Expect(err).NotTo(HaveOccurred(), "Failed to watch pod %s", name)
+				pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+				pod.Labels = labels
+				pod.Spec.Volumes = []v1.Volume{{
+					Name:         "data",
+					VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: name}},
+				}}
+				pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: "/data"}}
+				start = time.Now()
+				bound := make(chan error, 1)
+				if waitForFirstConsumer {
+					go func() {
+						latency, err := framework.TimeUntil(ctx, claimWatch, start, provisionTimeout, claimBound)
+						if err == nil {
+							bind.Observe(latency)
+						}
+						bound <- err
+					}()
+				} else {
+					bound <- nil
+				}
+				_, err = pods.Create(ctx, pod, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred(), "Failed to create pod %s", name)
+				latency, err := framework.TimeUntil(ctx, podWatch, start, provisionTimeout, podRunning)
+				Expect(err).NotTo(HaveOccurred(), "Pod %s did not start with a volume of StorageClass %s", name, class.Name)
+				mount.Observe(latency)
+				Expect(<-bound).To(Succeed(), "PVC %s of StorageClass %s did not bind", name, class.Name)
+
+				// The next volume is only provisioned once this one is released
+				Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)).To(Succeed(), "Failed to delete pod %s", name)
+				Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)).To(Succeed(), "Failed to delete PVC %s", name)
+			}
+			framework.ReportLatencies(bind.Summary(), mount.Summary())
+		}
+	}, SpecTimeout(2*time.Hour))
+})
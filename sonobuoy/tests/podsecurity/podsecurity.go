@@ -0,0 +1,196 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// warningRecorder collects the warnings the API server returns, which is how warn mode reports violations
+type warningRecorder struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (r *warningRecorder) HandleWarningHeader(code int, agent string, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, text)
+}
+
+func (r *warningRecorder) Warnings() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.warnings...)
+}
+
+// restrictedPod returns a framework pod, which satisfies the restricted Pod Security Standard by default
+func restrictedPod(namespace, name string) *v1.Pod {
+	return framework.NewPod(namespace, name, podImage, "sleep", "3600")
+}
+
+// baselinePod returns a pod running as root, which baseline allows but restricted does not
+func baselinePod(namespace, name string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+	framework.Unrestricted(&pod.Spec)
+	return pod
+}
+
+// privilegedPod returns a pod that violates the baseline Pod Security Standard
+func privilegedPod(namespace, name string) *v1.Pod {
+	pod := baselinePod(namespace, name)
+	privileged := true
+	pod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{Privileged: &privileged}
+	return pod
+}
+
+// Pod Security Admission replaced PodSecurityPolicy; namespaces opt into a level per mode through labels.
+// Each spec works in its own namespace, which is deleted with its pods afterwards. Namespaces that should
+// not enforce are labeled privileged explicitly, so a cluster-wide default level does not interfere.
+var _ = Describe("Pod Security Standards Admission", Label(framework.LabelPrivileged), func() {
+	var namespace string
+	var podName string
+
+	// createNamespace creates the spec's namespace with the given Pod Security labels
+	createNamespace := func(ctx context.Context, labels map[string]string) {
+		_, err := framework.Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: labels,
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create namespace")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Namespaces(), namespace)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete namespace")
+		})
+	}
+
+	// warningClient returns a client that records the warnings of its requests
+	warningClient := func() (kubernetes.Interface, *warningRecorder) {
+		GinkgoHelper()
+		recorder := &warningRecorder{}
+		config := rest.CopyConfig(framework.RestConfig)
+		config.WarningHandler = recorder
+		clientset, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Kubernetes client")
+		return clientset, recorder
+	}
+
+	// expectRejected checks that a pod create was refused by Pod Security Admission at the given level
+	expectRejected := func(err error, level string) {
+		GinkgoHelper()
+		Expect(err).To(HaveOccurred(), "Non-compliant pod was admitted")
+		Expect(apierrors.IsForbidden(err)).To(BeTrue(), "Expected Forbidden, got: %v", err)
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("violates PodSecurity %q", level+":latest")))
+	}
+
+	BeforeEach(func() {
+		suffix := time.Now().UnixNano()
+		namespace = fmt.Sprintf("test-podsecurity-%d", suffix)
+		podName = fmt.Sprintf("test-pod-%d", suffix)
+	})
+
+	It("should reject privileged pods and admit compliant pods under baseline enforcement", func(ctx SpecContext) {
+		createNamespace(ctx, map[string]string{framework.LabelPodSecurityEnforce: framework.PodSecurityBaseline})
+		pods := framework.Clientset.CoreV1().Pods(namespace)
+
+		_, err := pods.Create(ctx, privilegedPod(namespace, podName+"-privileged"), metav1.CreateOptions{})
+		expectRejected(err, "baseline")
+
+		hostNetwork := baselinePod(namespace, podName+"-hostnetwork")
+		hostNetwork.Spec.HostNetwork = true
+		_, err = pods.Create(ctx, hostNetwork, metav1.CreateOptions{})
+		expectRejected(err, "baseline")
+
+		// Running as root is allowed by baseline
+		_, err = pods.Create(ctx, baselinePod(namespace, podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Baseline-compliant pod was rejected")
+	})
+
+	It("should reject pods that are not hardened and admit compliant pods under restricted enforcement", func(ctx SpecContext) {
+		createNamespace(ctx, map[string]string{framework.LabelPodSecurityEnforce: framework.PodSecurityRestricted})
+		pods := framework.Clientset.CoreV1().Pods(namespace)
+
+		// Baseline-compliant, but lacks runAsNonRoot, seccomp and dropped capabilities
+		_, err := pods.Create(ctx, baselinePod(namespace, podName+"-baseline"), metav1.CreateOptions{})
+		expectRejected(err, "restricted")
+
+		escalating := restrictedPod(namespace, podName+"-escalating")
+		escalation := true
+		escalating.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = &escalation
+		_, err = pods.Create(ctx, escalating, metav1.CreateOptions{})
+		expectRejected(err, "restricted")
+		Expect(err.Error()).To(ContainSubstring("allowPrivilegeEscalation"))
+
+		_, err = pods.Create(ctx, restrictedPod(namespace, podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Restricted-compliant pod was rejected")
+	})
+
+	It("should admit non-compliant pods with a warning in warn mode", func(ctx SpecContext) {
+		createNamespace(ctx, map[string]string{
+			framework.LabelPodSecurityEnforce: framework.PodSecurityPrivileged,
+			framework.LabelPodSecurityWarn:    framework.PodSecurityRestricted,
+		})
+		clientset, recorder := warningClient()
+		_, err := clientset.CoreV1().Pods(namespace).Create(ctx, privilegedPod(namespace, podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Warn mode must not block pods")
+		Expect(recorder.Warnings()).To(ContainElement(ContainSubstring(fmt.Sprintf("would violate PodSecurity %q", "restricted:latest"))))
+
+		// Compliant pods are admitted silently
+		clientset, recorder = warningClient()
+		_, err = clientset.CoreV1().Pods(namespace).Create(ctx, restrictedPod(namespace, podName+"-compliant"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Restricted-compliant pod was rejected")
+		Expect(recorder.Warnings()).NotTo(ContainElement(ContainSubstring("PodSecurity")))
+	})
+
+	It("should admit non-compliant pods without warnings in audit mode", func(ctx SpecContext) {
+		createNamespace(ctx, map[string]string{
+			framework.LabelPodSecurityEnforce: framework.PodSecurityPrivileged,
+			framework.LabelPodSecurityAudit:   framework.PodSecurityRestricted,
+		})
+		clientset, recorder := warningClient()
+
+		// Audit mode only annotates the audit event, which is not visible to the client
+		_, err := clientset.CoreV1().Pods(namespace).Create(ctx, privilegedPod(namespace, podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Audit mode must not block pods")
+		Expect(recorder.Warnings()).NotTo(ContainElement(ContainSubstring("PodSecurity")))
+	})
+
+	It("should warn about existing violating pods when enforcement is tightened", func(ctx SpecContext) {
+		createNamespace(ctx, map[string]string{framework.LabelPodSecurityEnforce: framework.PodSecurityPrivileged})
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, privilegedPod(namespace, podName), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod under privileged enforcement")
+
+		clientset, recorder := warningClient()
+
+		ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get namespace")
+		ns.Labels[framework.LabelPodSecurityEnforce] = framework.PodSecurityBaseline
+		_, err = clientset.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to label namespace")
+
+		// Existing pods keep running, the label change only reports them
+		Expect(strings.Join(recorder.Warnings(), "\n")).To(ContainSubstring(podName))
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Existing pod was removed by enforcement")
+
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, privilegedPod(namespace, podName+"-new"), metav1.CreateOptions{})
+		expectRejected(err, "baseline")
+	})
+})
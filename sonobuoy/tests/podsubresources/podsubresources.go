@@ -0,0 +1,198 @@
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	podImage = "alpine:3.20"
+	// Serves its hostname, which is the pod name, on /hostname
+	netexecImage = "registry.k8s.io/e2e-test-images/agnhost:2.43"
+	netexecPort  = 8080
+)
+
+// Prints a numbered tick every second, forever
+const tickScript = `i=0; while true; do echo "tick $i"; i=$((i+1)); sleep 1; done`
+
+// These are the paths kubectl exec, attach, logs -f and port-forward take. The streaming ones upgrade the
+// connection, which proxies and load balancers in front of the API server frequently break.
+var _ = Describe("Pod Subresources", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-podsubresources-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// startPod creates the pod and waits until it runs
+	startPod := func(ctx context.Context, pod *v1.Pod) {
+		GinkgoHelper()
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+	}
+
+	It("should stream exec stdout and stderr separately while the command runs", func(ctx SpecContext) {
+		startPod(ctx, framework.NewPod(namespace, podName, podImage, "sleep", "3600"))
+
+		streamCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		request := framework.Clientset.CoreV1().RESTClient().Get().
+			Namespace(namespace).Resource("pods").Name(podName).SubResource("exec").
+			Param("stdout", "true").Param("stderr", "true")
+		for _, arg := range []string{"sh", "-c", "echo out-1; echo err-1 >&2; sleep 3; echo out-2; exit 3"} {
+			request = request.Param("command", arg)
+		}
+		stream, err := framework.DialChannelStream(streamCtx, request)
+		Expect(err).NotTo(HaveOccurred(), "Failed to open exec stream")
+		defer stream.Close()
+
+		var stdout, stderr strings.Builder
+		var firstOutput, lastOutput time.Time
+		var status error
+		for {
+			channel, data, err := stream.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred(), "Exec stream broke")
+			switch channel {
+			case framework.ChannelStdout:
+				if len(data) > 0 {
+					if firstOutput.IsZero() {
+						firstOutput = time.Now()
+					}
+					lastOutput = time.Now()
+				}
+				stdout.Write(data)
+			case framework.ChannelStderr:
+				stderr.Write(data)
+			case framework.ChannelError:
+				status = framework.ParseStreamStatus(data)
+			}
+		}
+
+		Expect(stdout.String()).To(Equal("out-1\nout-2\n"), "Unexpected stdout")
+		Expect(stderr.String()).To(Equal("err-1\n"), "Unexpected stderr")
+		Expect(lastOutput.Sub(firstOutput)).To(BeNumerically(">=", 2*time.Second), "Output was buffered until the command ended")
+		Expect(status).To(MatchError(ContainSubstring("exit code 3")), "Exit code was not reported")
+	})
+
+	It("should stream a running container's output through attach", func(ctx SpecContext) {
+		startPod(ctx, framework.NewPod(namespace, podName, podImage, "sh", "-c", tickScript))
+
+		streamCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		request := framework.Clientset.CoreV1().RESTClient().Get().
+			Namespace(namespace).Resource("pods").Name(podName).SubResource("attach").
+			Param("stdout", "true").Param("stderr", "true")
+		stream, err := framework.DialChannelStream(streamCtx, request)
+		Expect(err).NotTo(HaveOccurred(), "Failed to open attach stream")
+		defer stream.Close()
+
+		// Attaching starts mid-stream, so only ticks printed after it are seen
+		var output strings.Builder
+		for strings.Count(output.String(), "tick ") < 2 {
+			channel, data, err := stream.Read()
+			Expect(err).NotTo(HaveOccurred(), "Attach stream broke after %q", output.String())
+			if channel == framework.ChannelStdout {
+				output.Write(data)
+			}
+		}
+	})
+
+	It("should follow logs as they are written", func(ctx SpecContext) {
+		startPod(ctx, framework.NewPod(namespace, podName, podImage, "sh", "-c", tickScript))
+
+		streamCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		logs, err := framework.Clientset.CoreV1().Pods(namespace).GetLogs(podName, &v1.PodLogOptions{Follow: true}).Stream(streamCtx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to follow logs")
+		defer logs.Close()
+
+		scanner := bufio.NewScanner(logs)
+		var lines []string
+		var arrivals []time.Time
+		for len(lines) < 4 && scanner.Scan() {
+			lines = append(lines, scanner.Text())
+			arrivals = append(arrivals, time.Now())
+		}
+		Expect(scanner.Err()).NotTo(HaveOccurred(), "Log stream broke")
+		Expect(lines).To(HaveLen(4), "Log stream ended early")
+		Expect(lines[0]).To(Equal("tick 0"), "Following logs did not start at the beginning")
+		// The last lines are written a second apart, after the stream was opened
+		Expect(arrivals[3].Sub(arrivals[2])).To(BeNumerically(">=", 500*time.Millisecond), "Logs were not streamed as written")
+	})
+
+	It("should only return recent logs with sinceSeconds", func(ctx SpecContext) {
+		startPod(ctx, framework.NewPod(namespace, podName, podImage, "sh", "-c", "echo early; sleep 10; "+tickScript))
+		pods := framework.Clientset.CoreV1().Pods(namespace)
+
+		framework.Eventually(func() (string, error) {
+			logs, err := pods.GetLogs(podName, &v1.PodLogOptions{}).DoRaw(ctx)
+			return string(logs), err
+		}, 60*time.Second, 2*time.Second).Should(ContainSubstring("tick 2"), "Pod did not log its ticks")
+
+		since := int64(3)
+		logs, err := pods.GetLogs(podName, &v1.PodLogOptions{SinceSeconds: &since}).DoRaw(ctx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to get logs")
+		Expect(string(logs)).NotTo(ContainSubstring("early"), "sinceSeconds returned older lines")
+		Expect(string(logs)).To(ContainSubstring("tick"), "sinceSeconds dropped recent lines")
+	})
+
+	It("should forward a local connection to a pod port", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, netexecImage)
+		pod.Spec.Containers[0].Args = []string{"netexec", fmt.Sprintf("--http-port=%d", netexecPort)}
+		pod.Spec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: netexecPort}}
+		startPod(ctx, pod)
+
+		// exchange sends an HTTP request through a port-forwarded connection and returns the response
+		exchange := func() (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			conn, err := framework.PortForward(ctx, namespace, podName, netexecPort)
+			if err != nil {
+				return "", err
+			}
+			defer conn.Close()
+			if _, err := io.WriteString(conn, "GET /hostname HTTP/1.0\r\nHost: localhost\r\n\r\n"); err != nil {
+				return "", err
+			}
+			var response strings.Builder
+			buffer := make([]byte, 4096)
+			for !strings.Contains(response.String(), podName) {
+				n, err := conn.Read(buffer)
+				response.Write(buffer[:n])
+				if err != nil {
+					return response.String(), err
+				}
+			}
+			return response.String(), nil
+		}
+
+		// The server may still be starting up when the container is running
+		framework.Eventually(exchange, 60*time.Second, 2*time.Second).Should(
+			And(HavePrefix("HTTP/1."), ContainSubstring(podName)), "Port-forwarded request did not reach the pod")
+	})
+})
@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("PriorityClass CRUD Operations", func() {
+	var priorityClassName string
+
+	BeforeEach(func(ctx SpecContext) {
+		priorityClassName = fmt.Sprintf("test-priorityclass-%d", time.Now().UnixNano())
+
+		// Create a PriorityClass before each test
+		priorityClass := &v1.PriorityClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: priorityClassName,
+			},
+			Value:         1000,
+			GlobalDefault: false,
+			Description:   "Test Priority Class",
+		}
+
+		_, err := framework.Clientset.SchedulingV1().PriorityClasses().Create(ctx, priorityClass, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PriorityClass")
+	})
+
+	It("should read the PriorityClass successfully", func(ctx SpecContext) {
+		priorityClass, err := framework.Clientset.SchedulingV1().PriorityClasses().Get(ctx, priorityClassName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to read PriorityClass")
+		Expect(priorityClass.Value).To(Equal(int32(1000)))
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		// Delete the PriorityClass after each test
+		err := framework.Cleanup(ctx, framework.Clientset.SchedulingV1().PriorityClasses(), priorityClassName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PriorityClass")
+	})
+})
@@ -0,0 +1,157 @@
+package e2e
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the projected volume is mounted
+const projectedMountPath = "/projected"
+
+// Shortest expiry the kubelet accepts for a projected ServiceAccount token
+const projectedTokenExpirationSeconds = 600
+
+// projectedFile is what the pod reports about a file of the projected volume
+type projectedFile struct {
+	mode    string
+	content string
+}
+
+// File modes the volume requests. With the pod's fsGroup the kubelet adds group read to every file, so
+// the modes already include it to be reported unchanged.
+var (
+	projectedDefaultMode  = int32(0444)
+	projectedSecretMode   = int32(0440)
+	projectedDownwardMode = int32(0640)
+)
+
+var _ = Describe("Projected volumes", func() {
+	var namespace string
+	var name string
+	var audience string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-projected-%d", time.Now().UnixNano())
+		audience = fmt.Sprintf("e2e-audience-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Secret")
+	})
+
+	It("should combine configMap, secret, downwardAPI and serviceAccountToken sources in one mount", func(ctx SpecContext) {
+		_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{"app.conf": "greeting=hello"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		_, err = framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string][]byte{"password": []byte("s3cr3t")},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Secret")
+
+		// Every file is reported on one line as its path relative to the mount, its mode and its base64
+		// content, so binary-safe comparisons do not depend on how the files end
+		paths := []string{"config/app.conf", "secret/password", "podinfo/name", "podinfo/labels", "token"}
+		script := fmt.Sprintf(`cd %s && for f in %s; do echo "$f $(stat -L -c %%a $f) $(base64 -w0 < $f)"; done`,
+			projectedMountPath, strings.Join(paths, " "))
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+		pod.Labels["tier"] = "projected"
+		expirationSeconds := int64(projectedTokenExpirationSeconds)
+		pod.Spec.Volumes = []v1.Volume{{
+			Name: "projected",
+			VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{
+				DefaultMode: &projectedDefaultMode,
+				Sources: []v1.VolumeProjection{
+					{ConfigMap: &v1.ConfigMapProjection{
+						LocalObjectReference: v1.LocalObjectReference{Name: name},
+						Items:                []v1.KeyToPath{{Key: "app.conf", Path: "config/app.conf"}},
+					}},
+					{Secret: &v1.SecretProjection{
+						LocalObjectReference: v1.LocalObjectReference{Name: name},
+						Items:                []v1.KeyToPath{{Key: "password", Path: "secret/password", Mode: &projectedSecretMode}},
+					}},
+					{DownwardAPI: &v1.DownwardAPIProjection{
+						Items: []v1.DownwardAPIVolumeFile{
+							{Path: "podinfo/name", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}, Mode: &projectedDownwardMode},
+							{Path: "podinfo/labels", FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels"}, Mode: &projectedDownwardMode},
+						},
+					}},
+					{ServiceAccountToken: &v1.ServiceAccountTokenProjection{
+						Audience:          audience,
+						ExpirationSeconds: &expirationSeconds,
+						Path:              "token",
+					}},
+				},
+			}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "projected", MountPath: projectedMountPath, ReadOnly: true}}
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod with a projected volume")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		files := map[string]projectedFile{}
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			fields := strings.Fields(line)
+			Expect(fields).To(HaveLen(3), "Unexpected output line %q", line)
+			content, err := base64.StdEncoding.DecodeString(fields[2])
+			Expect(err).NotTo(HaveOccurred(), "Pod reported invalid content for %s", fields[0])
+			files[fields[0]] = projectedFile{mode: fields[1], content: string(content)}
+		}
+		Expect(files).To(HaveLen(len(paths)), "Not every projected file was found: %q", output)
+
+		Expect(files["config/app.conf"]).To(Equal(projectedFile{mode: "444", content: "greeting=hello"}), "ConfigMap key was not projected")
+		Expect(files["secret/password"]).To(Equal(projectedFile{mode: "440", content: "s3cr3t"}), "Secret key was not projected with its mode")
+		Expect(files["podinfo/name"]).To(Equal(projectedFile{mode: "640", content: name}), "Pod name was not projected with its mode")
+		Expect(files["podinfo/labels"].content).To(ContainSubstring(`tier="projected"`), "Pod labels were not projected")
+		Expect(files["token"].mode).To(Equal("444"), "Token was not projected with the default mode")
+
+		By("checking the token is bound to the requested audience and the pod")
+		token := files["token"].content
+		segments := strings.Split(token, ".")
+		Expect(segments).To(HaveLen(3), "Projected token is not a JWT")
+		payload, err := base64.RawURLEncoding.DecodeString(segments[1])
+		Expect(err).NotTo(HaveOccurred(), "Failed to decode token payload")
+		var claims struct {
+			Audience   []string `json:"aud"`
+			Kubernetes struct {
+				Pod struct {
+					Name string `json:"name"`
+				} `json:"pod"`
+			} `json:"kubernetes.io"`
+		}
+		Expect(json.Unmarshal(payload, &claims)).To(Succeed(), "Failed to parse token claims")
+		Expect(claims.Audience).To(ConsistOf(audience), "Token has the wrong audience")
+		Expect(claims.Kubernetes.Pod.Name).To(Equal(name), "Token is not bound to the pod")
+
+		review, err := framework.Clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: []string{audience}},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create TokenReview")
+		Expect(review.Status.Authenticated).To(BeTrue(), "Projected token was not authenticated: %s", review.Status.Error)
+		Expect(review.Status.User.Username).To(Equal(fmt.Sprintf("system:serviceaccount:%s:default", namespace)))
+		Expect(review.Status.Audiences).To(ConsistOf(audience))
+	})
+})
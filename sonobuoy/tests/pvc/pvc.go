@@ -0,0 +1,147 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("PVC and Pod Operations", func() {
+	var namespace string
+	var pvcName string
+	var podName string
+	// Set when the StorageClass only binds PVCs once a pod uses them
+	var waitForFirstConsumer bool
+
+	BeforeEach(func(ctx SpecContext) {
+		// The PVC is provisioned from E2E_STORAGE_CLASS, or else the default StorageClass
+		class := framework.RequireTestStorageClass(ctx)
+		waitForFirstConsumer = class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+
+		namespace = framework.TestNamespace()
+		pvcName = fmt.Sprintf("test-pvc-%d", time.Now().UnixNano())
+		podName = fmt.Sprintf("test-pod-pvc-%d", time.Now().UnixNano())
+
+		// Create a PVC
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pvcName,
+				Namespace: namespace,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				StorageClassName: &class.Name,
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceStorage: resource.MustParse("10Mi"),
+					},
+				},
+			},
+		}
+
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PVC")
+
+		// A WaitForFirstConsumer PVC stays Pending until a pod uses it, so only wait for binding otherwise
+		if waitForFirstConsumer {
+			return
+		}
+		framework.Eventually(func() bool {
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get PVC status")
+			return pvc.Status.Phase == v1.ClaimBound
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "PVC was not bound within the timeout")
+	})
+
+	// createPod creates a pod that mounts the PVC using the lightweight Alpine image
+	createPod := func(ctx context.Context) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: namespace,
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name:    "alpine-container",
+						Image:   "alpine:3.20", // Lightweight image
+						Command: []string{"sh", "-c", "sleep 3600"},
+						VolumeMounts: []v1.VolumeMount{
+							{
+								Name:      "pvc-volume",
+								MountPath: "/mnt/test",
+							},
+						},
+					},
+				},
+				Volumes: []v1.Volume{
+					{
+						Name: "pvc-volume",
+						VolumeSource: v1.VolumeSource{
+							PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+								ClaimName: pvcName,
+							},
+						},
+					},
+				},
+			},
+		}
+
+		framework.Restrict(&pod.Spec)
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+	}
+
+	// waitForPodRunning waits for the pod to be running
+	waitForPodRunning := func(ctx context.Context) {
+		framework.Eventually(func() bool {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get pod")
+			return pod.Status.Phase == v1.PodRunning
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Pod did not reach running state within the timeout")
+	}
+
+	It("should create a pod and mount the PVC successfully", func(ctx SpecContext) {
+		createPod(ctx)
+		waitForPodRunning(ctx)
+	})
+
+	It("should bind a WaitForFirstConsumer PVC only after a pod uses it", func(ctx SpecContext) {
+		if !waitForFirstConsumer {
+			Skip("StorageClass binds PVCs immediately")
+		}
+
+		// Nothing consumes the PVC yet, so it must not be provisioned
+		Consistently(func() v1.PersistentVolumeClaimPhase {
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get PVC status")
+			return pvc.Status.Phase
+		}, 10*time.Second, 2*time.Second).Should(Equal(v1.ClaimPending), "PVC was bound before a pod used it")
+
+		createPod(ctx)
+		waitForPodRunning(ctx)
+
+		pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PVC status")
+		Expect(pvc.Status.Phase).To(Equal(v1.ClaimBound), "PVC was not bound once its pod started")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		// Cleanup: delete the pod and PVC
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), pvcName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+	})
+})
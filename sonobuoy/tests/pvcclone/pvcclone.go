@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Where the pods mount the volume
+const dataMountPath = "/data"
+
+// Matches the provisioning failures of CSI drivers that cannot clone volumes
+var cloneUnsupported = regexp.MustCompile(`(?i)not supported|unsupported|unimplemented`)
+
+// newClaim returns a PVC of storageClass, populated from dataSource if it is set
+func newClaim(namespace, name, storageClass string, dataSource *v1.TypedLocalObjectReference) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			DataSource:       dataSource,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+// claimPod returns a pod running script with the PVC mounted at dataMountPath
+func claimPod(namespace, name, pvcName, script string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+	pod.Spec.Volumes = []v1.Volume{{
+		Name: "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: pvcName,
+		}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: dataMountPath}}
+	return pod
+}
+
+// Cloning is an optional CSI capability without an API to discover it, so the spec is skipped when the
+// driver reports it cannot provision the clone
+var _ = Describe("PVC cloning", func() {
+	var namespace string
+	var sourceName string
+	var cloneName string
+	var storageClass string
+
+	BeforeEach(func(ctx SpecContext) {
+		class := framework.RequireTestStorageClass(ctx)
+		// Only CSI drivers can clone volumes
+		framework.RequireCSIDriver(ctx, class.Provisioner)
+		storageClass = class.Name
+
+		namespace = framework.TestNamespace()
+		sourceName = fmt.Sprintf("test-pvc-clone-source-%d", time.Now().UnixNano())
+		cloneName = fmt.Sprintf("test-pvc-clone-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		for _, name := range []string{sourceName, cloneName} {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+			err = framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+		}
+	})
+
+	It("should populate a PVC from an existing PVC", func(ctx SpecContext) {
+		By("populating the source PVC")
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, sourceName, storageClass, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create source PVC")
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, sourceName, sourceName,
+			fmt.Sprintf("mkdir %[1]s/dir && echo %[2]s > %[1]s/dir/marker", dataMountPath, sourceName)), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create writer pod")
+		_, err = framework.WaitForPodOutput(ctx, framework.Clientset, namespace, sourceName, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Writer pod did not complete")
+		// Some drivers refuse to clone a volume that is still attached to a node
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), sourceName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete writer pod")
+
+		By("cloning the source PVC")
+		_, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, cloneName, storageClass, &v1.TypedLocalObjectReference{
+			Kind: "PersistentVolumeClaim",
+			Name: sourceName,
+		}), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create clone PVC")
+		// The pod also triggers provisioning under WaitForFirstConsumer
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, cloneName, cloneName,
+			fmt.Sprintf("cat %s/dir/marker", dataMountPath)), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create reader pod")
+
+		// Wait until the clone is bound or the driver turned it down
+		var unsupported string
+		framework.Eventually(func() (bool, error) {
+			clone, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, cloneName, metav1.GetOptions{})
+			if err != nil || clone.Status.Phase == v1.ClaimBound {
+				return err == nil, err
+			}
+			events, err := framework.Clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+				FieldSelector: fields.Set{"involvedObject.name": cloneName, "reason": "ProvisioningFailed"}.String(),
+			})
+			if err != nil {
+				return false, err
+			}
+			for _, event := range events.Items {
+				if cloneUnsupported.MatchString(event.Message) {
+					unsupported = event.Message
+					return true, nil
+				}
+			}
+			return false, nil
+		}, 5*time.Minute, 2*time.Second).Should(BeTrue(), "Clone PVC was not provisioned")
+		if unsupported != "" {
+			Skip(fmt.Sprintf("CSI driver of StorageClass %s cannot clone volumes: %s", storageClass, unsupported))
+		}
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, cloneName, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Reader pod did not complete")
+		Expect(strings.TrimSpace(output)).To(Equal(sourceName), "Clone does not hold the source's data")
+
+		clone, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, cloneName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get clone PVC")
+		Expect(clone.Spec.DataSource).NotTo(BeNil(), "Clone PVC lost its dataSource")
+		Expect(clone.Spec.DataSource.Name).To(Equal(sourceName))
+	})
+})
@@ -0,0 +1,176 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Reason the kubelet gives pods it evicted
+const evictedReason = "Evicted"
+
+// Memory a pod holds on to besides what it allocates on purpose
+const containerOverhead = 16 * 1024 * 1024
+
+// qosRank orders QoS classes by how early the kubelet evicts them under memory pressure
+var qosRank = map[v1.PodQOSClass]int{
+	v1.PodQOSBestEffort: 0,
+	v1.PodQOSBurstable:  1,
+	v1.PodQOSGuaranteed: 2,
+}
+
+// resources returns requirements with the given requests and limits, either of which may be empty
+func resources(requestCPU, requestMemory, limitCPU, limitMemory string) v1.ResourceRequirements {
+	list := func(cpu, memory string) v1.ResourceList {
+		if cpu == "" && memory == "" {
+			return nil
+		}
+		l := v1.ResourceList{}
+		if cpu != "" {
+			l[v1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			l[v1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return l
+	}
+	return v1.ResourceRequirements{Requests: list(requestCPU, requestMemory), Limits: list(limitCPU, limitMemory)}
+}
+
+// holdMemory returns a command that allocates bytes of memory and holds on to it: tail buffers its
+// newline-free input until the input ends, which sleep delays
+func holdMemory(bytes int64) []string {
+	return []string{"sh", "-c", fmt.Sprintf("(head -c %d /dev/zero; sleep 3600) | tail", bytes)}
+}
+
+var _ = Describe("Pod QoS classes", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-qos-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.CleanupCollection(ctx, framework.Clientset.CoreV1().Pods(namespace), metav1.ListOptions{LabelSelector: "e2e-qos=" + name})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pods")
+	})
+
+	// createPod creates a pod of the spec's set running command with the given resources on every container
+	createPod := func(ctx context.Context, suffix string, requirements v1.ResourceRequirements, command ...string) *v1.Pod {
+		pod := framework.NewPod(namespace, name+"-"+suffix, podImage, command...)
+		pod.Labels["e2e-qos"] = name
+		pod.Spec.Containers[0].Resources = requirements
+		created, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		return created
+	}
+
+	DescribeTable("should compute the QoS class from requests and limits",
+		func(ctx SpecContext, requirements v1.ResourceRequirements, expected v1.PodQOSClass) {
+			pod := createPod(ctx, "pod", requirements, "sleep", "3600")
+			// A LimitRange in the namespace adds defaults the spec did not ask for
+			if requirements.Requests == nil && requirements.Limits == nil && len(pod.Spec.Containers[0].Resources.Requests) > 0 {
+				Skip("A LimitRange in the test namespace gives every container default resources")
+			}
+			Expect(pod.Status.QOSClass).To(Equal(expected), "API server computed the wrong QoS class")
+
+			running, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, pod.Name, 120*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "Pod did not start")
+			Expect(running.Status.QOSClass).To(Equal(expected), "QoS class changed once the pod ran")
+		},
+		Entry("Guaranteed when requests equal limits", resources("50m", "32Mi", "50m", "32Mi"), v1.PodQOSGuaranteed),
+		Entry("Guaranteed when only limits are set", resources("", "", "50m", "32Mi"), v1.PodQOSGuaranteed),
+		Entry("Burstable when requests are below limits", resources("10m", "16Mi", "50m", "32Mi"), v1.PodQOSBurstable),
+		Entry("Burstable when only some resources are requested", resources("", "16Mi", "", ""), v1.PodQOSBurstable),
+		Entry("BestEffort without requests or limits", resources("", "", "", ""), v1.PodQOSBestEffort),
+	)
+
+	It("should evict BestEffort pods before Burstable and Guaranteed ones under memory pressure", Label(framework.LabelDisruptive), func(ctx SpecContext) {
+		framework.RequireNodePressure()
+
+		By("placing one pod of each QoS class on a node")
+		victim := createPod(ctx, "besteffort", resources("", "", "", ""), holdMemory(64*1024*1024)...)
+		running, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, victim.Name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "BestEffort pod did not start")
+		node, err := framework.Clientset.CoreV1().Nodes().Get(ctx, running.Spec.NodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get node")
+
+		// pinned runs a pod of the spec's set on the BestEffort pod's node and waits for it to run
+		pinned := func(suffix string, requirements v1.ResourceRequirements, command ...string) {
+			pod := framework.NewPod(namespace, name+"-"+suffix, podImage, command...)
+			pod.Labels["e2e-qos"] = name
+			pod.Spec.NodeSelector = map[string]string{v1.LabelHostname: node.Labels[v1.LabelHostname]}
+			pod.Spec.Containers[0].Resources = requirements
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+			_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, pod.Name, 120*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "Pod %s did not start", pod.Name)
+		}
+		pinned("burstable", resources("", "16Mi", "", ""), holdMemory(64*1024*1024)...)
+		pinned("guaranteed", resources("10m", "64Mi", "10m", "64Mi"), holdMemory(32*1024*1024)...)
+
+		By("filling the rest of the node's allocatable memory with a Guaranteed pod")
+		pods, err := framework.Clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.Name).String(),
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list pods on node")
+		free := node.Status.Allocatable.Memory().Value()
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				free -= container.Resources.Requests.Memory().Value()
+			}
+		}
+		Expect(free).To(BeNumerically(">", 2*containerOverhead), "Node %s has no allocatable memory left to fill", node.Name)
+		// The hog stays within its own request, so it ranks after every pod using more than it requested
+		size := resource.NewQuantity(free, resource.BinarySI).String()
+		pinned("hog", resources("10m", size, "10m", size), holdMemory(free-containerOverhead)...)
+
+		By("waiting for the kubelet to evict pods")
+		evictedAt := map[string]time.Time{}
+		classes := map[string]v1.PodQOSClass{}
+		framework.Eventually(func() (map[string]time.Time, error) {
+			pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "e2e-qos=" + name})
+			if err != nil {
+				return nil, err
+			}
+			for _, pod := range pods.Items {
+				classes[pod.Name] = pod.Status.QOSClass
+				if _, seen := evictedAt[pod.Name]; !seen && pod.Status.Phase == v1.PodFailed && pod.Status.Reason == evictedReason {
+					evictedAt[pod.Name] = time.Now()
+				}
+			}
+			return evictedAt, nil
+		}, 5*time.Minute, 2*time.Second).Should(HaveKey(victim.Name), "Kubelet did not evict the BestEffort pod under memory pressure")
+		AddReportEntry("Evictions", fmt.Sprintf("%v", evictedAt))
+
+		for evicted, at := range evictedAt {
+			for other, otherAt := range evictedAt {
+				if qosRank[classes[evicted]] < qosRank[classes[other]] {
+					Expect(at.After(otherAt)).To(BeFalse(), "%s pod %s was evicted after %s pod %s", classes[evicted], evicted, classes[other], other)
+				}
+			}
+			for other, class := range classes {
+				if _, alsoEvicted := evictedAt[other]; !alsoEvicted && qosRank[class] < qosRank[classes[evicted]] {
+					Fail(fmt.Sprintf("%s pod %s was evicted while %s pod %s kept running", classes[evicted], evicted, class, other))
+				}
+			}
+		}
+	})
+})
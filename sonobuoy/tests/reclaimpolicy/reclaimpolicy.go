@@ -0,0 +1,174 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Provisioner of StorageClasses for statically created PVs, which cannot provision volumes for the specs
+const noProvisioner = "kubernetes.io/no-provisioner"
+
+// Where the pods mount the volume
+const dataMountPath = "/data"
+
+// newClaim returns a PVC of storageClass, bound to volumeName if it is set
+func newClaim(namespace, name, storageClass, volumeName string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			VolumeName:       volumeName,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+// runWithClaim runs script in a pod mounting the PVC at dataMountPath, deletes the pod and returns its output.
+// Running a pod also binds PVCs of WaitForFirstConsumer StorageClasses.
+func runWithClaim(ctx context.Context, namespace, pvcName, script string) string {
+	GinkgoHelper()
+	name := fmt.Sprintf("%s-%d", pvcName, time.Now().UnixNano())
+	pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+	pod.Spec.Volumes = []v1.Volume{{
+		Name: "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: pvcName,
+		}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: dataMountPath}}
+	_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred(), "Failed to create pod using PVC %s", pvcName)
+	output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, name, 5*time.Minute)
+	Expect(err).NotTo(HaveOccurred(), "Pod using PVC %s did not complete", pvcName)
+	err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+	Expect(err).NotTo(HaveOccurred(), "Failed to delete pod using PVC %s", pvcName)
+	return output
+}
+
+// Each spec provisions from a copy of the test StorageClass that only differs in its reclaim policy, so
+// the cluster's own classes are left untouched
+var _ = Describe("PersistentVolume reclaim policy", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-reclaim-%d", time.Now().UnixNano())
+	})
+
+	// reclaimClass creates a copy of the test StorageClass with policy and returns its name
+	reclaimClass := func(ctx context.Context, policy v1.PersistentVolumeReclaimPolicy) string {
+		base := framework.RequireTestStorageClass(ctx)
+		if base.Provisioner == noProvisioner {
+			Skip(fmt.Sprintf("StorageClass %s does not provision volumes dynamically", base.Name))
+		}
+		class := &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", name, strings.ToLower(string(policy)))},
+			Provisioner:          base.Provisioner,
+			Parameters:           base.Parameters,
+			MountOptions:         base.MountOptions,
+			AllowVolumeExpansion: base.AllowVolumeExpansion,
+			VolumeBindingMode:    base.VolumeBindingMode,
+			AllowedTopologies:    base.AllowedTopologies,
+			ReclaimPolicy:        &policy,
+		}
+		_, err := framework.Clientset.StorageV1().StorageClasses().Create(ctx, class, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create StorageClass with reclaim policy %s", policy)
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.StorageV1().StorageClasses(), class.Name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete StorageClass")
+		})
+		return class.Name
+	}
+
+	// provision creates a PVC of storageClass, writes a marker to its volume and returns the bound PV
+	provision := func(ctx context.Context, storageClass string) *v1.PersistentVolume {
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, ""), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PVC")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+		})
+		runWithClaim(ctx, namespace, name, fmt.Sprintf("echo %s > %s/marker", name, dataMountPath))
+
+		pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PVC")
+		Expect(pvc.Spec.VolumeName).NotTo(BeEmpty(), "PVC was not bound to a PV")
+		pv, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PV")
+		return pv
+	}
+
+	It("should delete the PV when its claim is deleted under the Delete policy", func(ctx SpecContext) {
+		pv := provision(ctx, reclaimClass(ctx, v1.PersistentVolumeReclaimDelete))
+		Expect(pv.Spec.PersistentVolumeReclaimPolicy).To(Equal(v1.PersistentVolumeReclaimDelete), "PV did not inherit the reclaim policy")
+
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+		framework.Eventually(func() bool {
+			_, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
+			return apierrors.IsNotFound(err)
+		}, 3*time.Minute, 2*time.Second).Should(BeTrue(), "PV %s was not deleted with its claim", pv.Name)
+	})
+
+	It("should keep the PV and its data when its claim is deleted under the Retain policy", func(ctx SpecContext) {
+		storageClass := reclaimClass(ctx, v1.PersistentVolumeReclaimRetain)
+		pv := provision(ctx, storageClass)
+		Expect(pv.Spec.PersistentVolumeReclaimPolicy).To(Equal(v1.PersistentVolumeReclaimRetain), "PV did not inherit the reclaim policy")
+		// A retained volume outlives every claim, so switch it to Delete once the spec is done to free the backend
+		DeferCleanup(func(ctx SpecContext) {
+			retained, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			Expect(err).NotTo(HaveOccurred(), "Failed to get retained PV")
+			retained.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimDelete
+			_, err = framework.Clientset.CoreV1().PersistentVolumes().Update(ctx, retained, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to switch retained PV to the Delete policy")
+		})
+
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+		framework.Eventually(func() (v1.PersistentVolumePhase, error) {
+			retained, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return retained.Status.Phase, nil
+		}, 2*time.Minute, 2*time.Second).Should(Equal(v1.VolumeReleased), "PV %s was not released by its claim", pv.Name)
+		Consistently(func() error {
+			_, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
+			return err
+		}, 10*time.Second, 2*time.Second).Should(Succeed(), "Retained PV %s was deleted", pv.Name)
+
+		By("binding a new claim to the released PV and reading the data back")
+		retained, err := framework.Clientset.CoreV1().PersistentVolumes().Get(ctx, pv.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get retained PV")
+		// Clearing the reference to the deleted claim makes the PV Available again
+		retained.Spec.ClaimRef = nil
+		_, err = framework.Clientset.CoreV1().PersistentVolumes().Update(ctx, retained, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to release PV for a new claim")
+		_, err = framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, pv.Name), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PVC for the retained PV")
+		output := runWithClaim(ctx, namespace, name, fmt.Sprintf("cat %s/marker", dataMountPath))
+		Expect(strings.TrimSpace(output)).To(Equal(name), "Retained PV lost its data")
+	})
+})
@@ -0,0 +1,215 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// controllerUID returns the UID of the object's controller, or "" if it is an orphan
+func controllerUID(obj metav1.Object) types.UID {
+	if controller := metav1.GetControllerOf(obj); controller != nil {
+		return controller.UID
+	}
+	return ""
+}
+
+var _ = Describe("ReplicaSet adoption and orphaning", func() {
+	var namespace string
+	var name string
+	var selector string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-replicaset-%d", time.Now().UnixNano())
+		selector = "app=" + name
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+		err = framework.CleanupCollection(ctx, framework.Clientset.AppsV1().ReplicaSets(namespace), metav1.ListOptions{LabelSelector: selector})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ReplicaSets")
+		err = framework.CleanupCollection(ctx, framework.Clientset.CoreV1().Pods(namespace), metav1.ListOptions{LabelSelector: selector})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pods")
+	})
+
+	// createDeployment creates the Deployment and returns it with its only ReplicaSet once rolled out
+	createDeployment := func(ctx context.Context) (*appsv1.Deployment, *appsv1.ReplicaSet) {
+		deployment := framework.NewDeployment(namespace, name, podImage, 2, "sleep", "3600")
+		framework.RequirePodRoom(ctx, 2, &deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+		deployment, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		replicaSets, err := framework.ListReplicaSets(ctx, framework.Clientset, deployment)
+		Expect(err).NotTo(HaveOccurred(), "Failed to list ReplicaSets")
+		Expect(replicaSets).To(HaveLen(1), "A fresh deployment should own exactly one ReplicaSet")
+		return deployment, &replicaSets[0]
+	}
+
+	// deleteDeployment deletes the Deployment with the given propagation policy and waits until it is gone
+	deleteDeployment := func(ctx context.Context, propagation metav1.DeletionPropagation) {
+		err := framework.Clientset.AppsV1().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+		framework.Eventually(func() bool {
+			_, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "Deployment was not deleted within the timeout")
+	}
+
+	// podUIDs returns the UIDs of the pods matching the selector
+	podUIDs := func(ctx context.Context) []types.UID {
+		pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+		var uids []types.UID
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil {
+				uids = append(uids, pod.UID)
+			}
+		}
+		return uids
+	}
+
+	It("should leave an orphaned ReplicaSet that a matching Deployment adopts", func(ctx SpecContext) {
+		_, replicaSet := createDeployment(ctx)
+		pods := podUIDs(ctx)
+		Expect(pods).To(HaveLen(2), "Deployment pods are not running")
+
+		By("deleting the Deployment with orphan propagation")
+		deleteDeployment(ctx, metav1.DeletePropagationOrphan)
+		framework.Eventually(func() (types.UID, error) {
+			rs, err := framework.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, replicaSet.Name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return controllerUID(rs), nil
+		}, 120*time.Second, 2*time.Second).Should(BeEmpty(), "Orphaned ReplicaSet still names the deleted Deployment as its controller")
+		Consistently(podUIDs, 10*time.Second, 2*time.Second).Should(ConsistOf(pods), "Pods of the orphaned ReplicaSet were deleted")
+
+		By("recreating a Deployment with the same selector and template")
+		deployment, adopted := createDeployment(ctx)
+		Expect(adopted.Name).To(Equal(replicaSet.Name), "Deployment created a new ReplicaSet instead of adopting the orphan")
+		Expect(adopted.UID).To(Equal(replicaSet.UID), "Deployment created a new ReplicaSet instead of adopting the orphan")
+		Expect(adopted.Labels).To(HaveKeyWithValue(appsv1.DefaultDeploymentUniqueLabelKey, replicaSet.Labels[appsv1.DefaultDeploymentUniqueLabelKey]),
+			"Adopted ReplicaSet changed its pod-template-hash")
+		Expect(controllerUID(adopted)).To(Equal(deployment.UID), "Adopted ReplicaSet is not controlled by the new Deployment")
+		Expect(podUIDs(ctx)).To(ConsistOf(pods), "Adoption replaced the ReplicaSet's pods")
+	})
+
+	It("should adopt matching orphan pods and release pods that stop matching", func(ctx SpecContext) {
+		By("creating an orphan pod matching the ReplicaSet's selector")
+		orphanName := name + "-orphan"
+		orphan := framework.NewPod(namespace, orphanName, podImage, "sleep", "3600")
+		orphan.Labels = map[string]string{"app": name}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, orphan, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create orphan pod")
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, orphanName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Orphan pod did not start")
+
+		By("creating a ReplicaSet of one replica")
+		deployment := framework.NewDeployment(namespace, name, podImage, 1, "sleep", "3600")
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: deployment.Spec.Template.Labels},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: deployment.Spec.Replicas,
+				Selector: deployment.Spec.Selector,
+				Template: deployment.Spec.Template,
+			},
+		}
+		replicaSet, err = framework.Clientset.AppsV1().ReplicaSets(namespace).Create(ctx, replicaSet, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ReplicaSet")
+
+		framework.Eventually(func() (types.UID, error) {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, orphanName, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return controllerUID(pod), nil
+		}, 120*time.Second, 2*time.Second).Should(Equal(replicaSet.UID), "ReplicaSet did not adopt the matching orphan pod")
+		Consistently(podUIDs, 10*time.Second, 2*time.Second).Should(HaveLen(1), "ReplicaSet created a pod although it adopted one")
+
+		By("relabeling the adopted pod so it no longer matches")
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, orphanName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			pod.Labels["app"] = name + "-released"
+			_, err = framework.Clientset.CoreV1().Pods(namespace).Update(ctx, pod, metav1.UpdateOptions{})
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to relabel pod")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), orphanName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete released pod")
+		})
+
+		framework.Eventually(func() (types.UID, error) {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, orphanName, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return controllerUID(pod), nil
+		}, 120*time.Second, 2*time.Second).Should(BeEmpty(), "ReplicaSet did not release the pod that stopped matching")
+		framework.Eventually(podUIDs, 120*time.Second, 2*time.Second).Should(HaveLen(1), "ReplicaSet did not replace the released pod")
+	})
+
+	It("should resolve a pod-template-hash collision with an unrelated ReplicaSet", func(ctx SpecContext) {
+		_, replicaSet := createDeployment(ctx)
+		hash := replicaSet.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		Expect(hash).NotTo(BeEmpty(), "ReplicaSet has no pod-template-hash label")
+
+		By("deleting the Deployment and its ReplicaSet")
+		deleteDeployment(ctx, metav1.DeletePropagationBackground)
+		framework.Eventually(func() bool {
+			_, err := framework.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, replicaSet.Name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, 120*time.Second, 2*time.Second).Should(BeTrue(), "ReplicaSet was not garbage collected within the timeout")
+
+		By("taking the ReplicaSet's name with an unrelated ReplicaSet")
+		impostorLabels := map[string]string{"app": name + "-impostor", appsv1.DefaultDeploymentUniqueLabelKey: hash}
+		impostor := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: replicaSet.Name, Namespace: namespace, Labels: impostorLabels},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: new(int32),
+				Selector: &metav1.LabelSelector{MatchLabels: impostorLabels},
+				Template: v1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: impostorLabels},
+					Spec:       *replicaSet.Spec.Template.Spec.DeepCopy(),
+				},
+			},
+		}
+		impostor, err := framework.Clientset.AppsV1().ReplicaSets(namespace).Create(ctx, impostor, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create impostor ReplicaSet")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.AppsV1().ReplicaSets(namespace), impostor.Name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete impostor ReplicaSet")
+		})
+
+		By("recreating the Deployment, whose template hashes to the taken name")
+		deployment, created := createDeployment(ctx)
+		Expect(deployment.Status.CollisionCount).To(HaveValue(BeNumerically(">=", 1)), "Deployment did not record the hash collision")
+		Expect(created.Name).NotTo(Equal(impostor.Name), "Deployment claimed the impostor's name")
+		Expect(created.Labels[appsv1.DefaultDeploymentUniqueLabelKey]).NotTo(Equal(hash), "Deployment reused the colliding pod-template-hash")
+
+		impostor, err = framework.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, impostor.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get impostor ReplicaSet")
+		Expect(controllerUID(impostor)).To(BeEmpty(), "Deployment adopted a ReplicaSet its selector does not match")
+	})
+})
@@ -0,0 +1,86 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Verifies that workloads recover from faults injected by Chaos Mesh or Litmus within the recovery SLO.
+// Opt-in: the specs skip unless E2E_CHAOS_ENGINE names an engine installed in the cluster.
+var _ = Describe("Workload Resilience Under Chaos", Label(framework.LabelDisruptive), func() {
+	var namespace string
+	var deploymentName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		deploymentName = fmt.Sprintf("test-resilience-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployment")
+	})
+
+	It("should restore a Deployment to full availability within the recovery SLO", func(ctx SpecContext) {
+		chaos := framework.RequireChaos()
+
+		deployment := framework.NewDeployment(namespace, deploymentName, "alpine:3.20", 3, "sleep", "3600")
+		framework.RequirePodRoom(ctx, 3, &deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not become available before the experiment")
+
+		podLabels := deployment.Spec.Selector.MatchLabels
+		selector := labels.SelectorFromSet(podLabels).String()
+		podUIDs := func() map[types.UID]bool {
+			pods, err := framework.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+			uids := map[types.UID]bool{}
+			for _, pod := range pods.Items {
+				uids[pod.UID] = true
+			}
+			return uids
+		}
+		original := podUIDs()
+
+		experiment, err := framework.StartChaos(ctx, namespace, podLabels)
+		Expect(err).NotTo(HaveOccurred(), "Failed to start chaos experiment")
+		DeferCleanup(func(ctx SpecContext) {
+			err := experiment.Stop(ctx)
+			Expect(err).NotTo(HaveOccurred(), "Failed to stop chaos experiment")
+		})
+
+		if chaos.Action == framework.ChaosActionPodKill {
+			// The experiment must actually have disrupted the workload for recovery to mean anything
+			framework.Eventually(func() bool {
+				for uid := range original {
+					if !podUIDs()[uid] {
+						return true
+					}
+				}
+				return false
+			}, chaos.Duration+60*time.Second, 2*time.Second).Should(BeTrue(), "No pod was killed by the experiment")
+		} else {
+			time.Sleep(chaos.Duration)
+		}
+
+		err = experiment.Stop(ctx)
+		Expect(err).NotTo(HaveOccurred(), "Failed to stop chaos experiment")
+
+		start := time.Now()
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, chaos.RecoverySLO)
+		recovery := time.Since(start)
+		AddReportEntry("Recovery time", recovery.Round(time.Second).String())
+		Expect(err).NotTo(HaveOccurred(), "Deployment did not recover within the %s SLO", chaos.RecoverySLO)
+	})
+})
@@ -0,0 +1,188 @@
+package e2e
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	netexecImage = "registry.k8s.io/e2e-test-images/agnhost:2.43"
+	netexecPort  = 8080
+)
+
+// Replicas of the Deployment whose pods are killed, one at a time
+const healingReplicas = 3
+
+// Pods are killed after a random pause within these bounds, counted from the Deployment's recovery
+const (
+	minKillInterval = 2 * time.Second
+	maxKillInterval = 10 * time.Second
+)
+
+// Seconds a killed pod keeps serving after SIGTERM, so its endpoint is gone before it stops
+const shutdownDelay = 5
+
+// Share of the requests sent to the Service while pods are killed that must succeed
+const minTrafficSuccess = 0.99
+
+// trafficScript requests the Service's /hostname for seconds once it first answered and prints how many
+// requests succeeded and failed
+func trafficScript(service string, seconds int) string {
+	return fmt.Sprintf(`until wget -qO- -T 2 http://%[1]s/hostname >/dev/null 2>&1; do sleep 1; done
+end=$(( $(date +%%s) + %[2]d )); ok=0; failed=0
+while [ "$(date +%%s)" -lt "$end" ]; do
+	if wget -qO- -T 2 http://%[1]s/hostname >/dev/null 2>&1; then ok=$((ok + 1)); else failed=$((failed + 1)); fi
+	sleep 0.2
+done
+echo "$ok $failed"`, service, seconds)
+}
+
+// Kills pods of a Deployment itself rather than through a chaos engine, so it runs on any cluster. Each
+// kill is followed by a wait for the ReplicaSet's replacement, bounded by E2E_CHAOS_RECOVERY_SLO, and
+// kills go on for E2E_CHAOS_DURATION. The pauses between kills follow Ginkgo's random seed, so a failing
+// sequence can be replayed with --seed.
+var _ = Describe("Deployment self-healing", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-selfhealing-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployment")
+	})
+
+	DescribeTable("should recreate killed pods and stay available",
+		func(ctx SpecContext, serveTraffic bool) {
+			config, err := framework.LoadRunConfig()
+			Expect(err).NotTo(HaveOccurred())
+			chaos := config.Chaos
+
+			deployment := framework.NewDeployment(namespace, name, netexecImage, healingReplicas)
+			container := &deployment.Spec.Template.Spec.Containers[0]
+			container.Args = []string{"netexec", fmt.Sprintf("--http-port=%d", netexecPort), fmt.Sprintf("--delay-shutdown=%d", shutdownDelay)}
+			container.Ports = []v1.ContainerPort{{ContainerPort: netexecPort}}
+			container.ReadinessProbe = &v1.Probe{
+				ProbeHandler:  v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/hostname", Port: intstr.FromInt(netexecPort)}},
+				PeriodSeconds: 1,
+			}
+			framework.RequirePodRoom(ctx, healingReplicas+1, &deployment.Spec.Template.Spec)
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+			_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, name, 120*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "Deployment did not become available before the pods were killed")
+
+			var probeName string
+			if serveTraffic {
+				service := &v1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+					Spec: v1.ServiceSpec{
+						Selector: deployment.Spec.Selector.MatchLabels,
+						Ports:    []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(netexecPort)}},
+					},
+				}
+				_, err := framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred(), "Failed to create Service")
+				DeferCleanup(func(ctx SpecContext) {
+					Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), name)).To(Succeed(), "Failed to delete Service")
+				})
+
+				probeName = name + "-traffic"
+				seconds := int(chaos.Duration.Round(time.Second) / time.Second)
+				probe := framework.NewPod(namespace, probeName, "alpine:3.20", "sh", "-c", trafficScript(name, seconds))
+				_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, probe, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred(), "Failed to create traffic pod")
+				DeferCleanup(func(ctx SpecContext) {
+					Expect(framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), probeName)).To(Succeed(), "Failed to delete traffic pod")
+				})
+				_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, probeName, 120*time.Second)
+				Expect(err).NotTo(HaveOccurred(), "Traffic pod did not start")
+			}
+
+			pods := framework.Clientset.CoreV1().Pods(namespace)
+			selector := metav1.ListOptions{LabelSelector: "app=" + name}
+			// ready lists the Ready pods of the Deployment that are not terminating
+			ready := func() []string {
+				list, err := pods.List(ctx, selector)
+				Expect(err).NotTo(HaveOccurred(), "Failed to list pods")
+				var names []string
+				for _, pod := range list.Items {
+					if pod.DeletionTimestamp != nil {
+						continue
+					}
+					for _, condition := range pod.Status.Conditions {
+						if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+							names = append(names, pod.Name)
+						}
+					}
+				}
+				return names
+			}
+
+			random := rand.New(rand.NewSource(GinkgoRandomSeed()))
+			recreation := framework.NewLatencies("pod recreated")
+			fewestReady := healingReplicas
+			kills := 0
+			for deadline := time.Now().Add(chaos.Duration); time.Now().Before(deadline); {
+				pause := minKillInterval + time.Duration(random.Int63n(int64(maxKillInterval-minKillInterval)))
+				select {
+				case <-time.After(pause):
+				case <-ctx.Done():
+					return
+				}
+
+				running := ready()
+				Expect(running).To(HaveLen(healingReplicas), "Deployment was not fully available before a kill")
+				victim := running[random.Intn(len(running))]
+				By(fmt.Sprintf("Killing pod %s", victim))
+				killed := time.Now()
+				Expect(pods.Delete(ctx, victim, metav1.DeleteOptions{})).To(Succeed(), "Failed to delete pod %s", victim)
+				kills++
+
+				framework.Eventually(func() []string {
+					running := ready()
+					fewestReady = min(fewestReady, len(running))
+					return running
+				}, chaos.RecoverySLO, 500*time.Millisecond).Should(And(HaveLen(healingReplicas), Not(ContainElement(victim))),
+					"ReplicaSet did not replace pod %s within the %s SLO", victim, chaos.RecoverySLO)
+				recreation.Observe(time.Since(killed))
+			}
+
+			AddReportEntry("Pods killed", kills)
+			AddReportEntry("Pod recreation", recreation.Summary())
+			Expect(kills).To(BeNumerically(">", 0), "E2E_CHAOS_DURATION %s left no time to kill a pod", chaos.Duration)
+			Expect(fewestReady).To(BeNumerically(">=", healingReplicas-1), "More than the killed pod became unavailable at once")
+
+			if serveTraffic {
+				output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, probeName, chaos.Duration+chaos.RecoverySLO)
+				Expect(err).NotTo(HaveOccurred(), "Traffic pod did not finish")
+				fields := strings.Fields(output)
+				Expect(fields).To(HaveLen(2), "Unexpected traffic pod output %q", output)
+				succeeded, _ := strconv.Atoi(fields[0])
+				failed, _ := strconv.Atoi(fields[1])
+				AddReportEntry("Requests", fmt.Sprintf("%d succeeded, %d failed", succeeded, failed))
+				Expect(succeeded+failed).To(BeNumerically(">", 0), "Traffic pod sent no requests")
+				rate := float64(succeeded) / float64(succeeded+failed)
+				Expect(rate).To(BeNumerically(">=", minTrafficSuccess),
+					"Only %.1f%% of the requests to the Service succeeded while pods were killed", rate*100)
+			}
+		},
+		Entry("without traffic", false, SpecTimeout(30*time.Minute)),
+		Entry("while serving traffic through a Service", true, SpecTimeout(30*time.Minute)),
+	)
+})
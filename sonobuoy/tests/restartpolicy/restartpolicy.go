@@ -0,0 +1,135 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Waiting reason of a container the kubelet holds back after repeated crashes
+const crashLoopReason = "CrashLoopBackOff"
+
+// Restarts the backoff spec observes; the kubelet doubles the delay from 10s, so this takes about two minutes
+const observedRestarts = 4
+
+// Slack for the polling interval and container start-up when comparing restart gaps
+const gapTolerance = 3 * time.Second
+
+var _ = Describe("Container restart policy", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-restart-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// createPod creates a pod with the restart policy running script, with an emptyDir at /state that survives restarts
+	createPod := func(ctx context.Context, policy v1.RestartPolicy, script string) {
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c", script)
+		pod.Spec.RestartPolicy = policy
+		pod.Spec.Volumes = []v1.Volume{{Name: "state", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "state", MountPath: "/state"}}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+	}
+
+	// containerStatus returns the status of the pod's only container, or nil before the kubelet reports it
+	containerStatus := func(ctx context.Context) (*v1.ContainerStatus, error) {
+		pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil || len(pod.Status.ContainerStatuses) == 0 {
+			return nil, err
+		}
+		return &pod.Status.ContainerStatuses[0], nil
+	}
+
+	// restartCount returns how often the pod's container restarted
+	restartCount := func(ctx context.Context) (int32, error) {
+		status, err := containerStatus(ctx)
+		if err != nil || status == nil {
+			return 0, err
+		}
+		return status.RestartCount, nil
+	}
+
+	It("should not restart a failed container with Never", func(ctx SpecContext) {
+		createPod(ctx, v1.RestartPolicyNever, "exit 1")
+		pod, err := framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodFailed, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not fail")
+		Expect(pod.Status.ContainerStatuses[0].State.Terminated).To(HaveField("ExitCode", int32(1)))
+		Consistently(restartCount, 15*time.Second, 3*time.Second).Should(BeZero(), "Container was restarted despite restartPolicy Never")
+	})
+
+	It("should restart a failed container with OnFailure until it succeeds", func(ctx SpecContext) {
+		// Fails on the first run only; the marker survives the restart in the emptyDir
+		createPod(ctx, v1.RestartPolicyOnFailure, "if [ -f /state/ran ]; then exit 0; fi; touch /state/ran; exit 1")
+		pod, err := framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodSucceeded, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not succeed after a restart")
+		status := pod.Status.ContainerStatuses[0]
+		Expect(status.RestartCount).To(Equal(int32(1)), "Container was not restarted exactly once")
+		Expect(status.LastTerminationState.Terminated).To(HaveField("ExitCode", int32(1)), "Previous run did not fail")
+	})
+
+	It("should not restart a succeeded container with OnFailure", func(ctx SpecContext) {
+		createPod(ctx, v1.RestartPolicyOnFailure, "exit 0")
+		_, err := framework.WaitForPodPhase(ctx, framework.Clientset, namespace, name, v1.PodSucceeded, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not succeed")
+		Consistently(restartCount, 15*time.Second, 3*time.Second).Should(BeZero(), "Succeeded container was restarted despite restartPolicy OnFailure")
+	})
+
+	It("should restart a succeeded container with Always", func(ctx SpecContext) {
+		createPod(ctx, v1.RestartPolicyAlways, "sleep 2; exit 0")
+		framework.Eventually(restartCount, 120*time.Second, 2*time.Second).Should(BeNumerically(">=", 1), "Exited container was not restarted")
+		pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get pod")
+		Expect(pod.Status.Phase).To(Equal(v1.PodRunning), "Pod with restartPolicy Always left the Running phase")
+		Expect(pod.Status.ContainerStatuses[0].LastTerminationState.Terminated).To(HaveField("ExitCode", int32(0)))
+	})
+
+	It("should back off exponentially in CrashLoopBackOff", func(ctx SpecContext) {
+		createPod(ctx, v1.RestartPolicyAlways, "exit 1")
+
+		By("recording when each restart happens")
+		var restartedAt []time.Time
+		crashLooping := false
+		framework.Eventually(func() (int, error) {
+			status, err := containerStatus(ctx)
+			if err != nil || status == nil {
+				return 0, err
+			}
+			for int(status.RestartCount) > len(restartedAt) {
+				restartedAt = append(restartedAt, time.Now())
+			}
+			if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopReason {
+				crashLooping = true
+			}
+			return len(restartedAt), nil
+		}, 4*time.Minute, time.Second).Should(BeNumerically(">=", observedRestarts), "Crashing container was not restarted repeatedly")
+		Expect(crashLooping).To(BeTrue(), "Container was never reported in %s", crashLoopReason)
+
+		var gaps []time.Duration
+		for i := 1; i < len(restartedAt); i++ {
+			gaps = append(gaps, restartedAt[i].Sub(restartedAt[i-1]))
+		}
+		AddReportEntry("Restart gaps", fmt.Sprintf("%v", gaps))
+		for i := 1; i < len(gaps); i++ {
+			Expect(gaps[i]).To(BeNumerically(">=", gaps[i-1]-gapTolerance), "Backoff shrank between restarts: %v", gaps)
+		}
+		Expect(gaps[len(gaps)-1]).To(BeNumerically(">", gaps[0]+gapTolerance), "Backoff did not grow between restarts: %v", gaps)
+	})
+})
@@ -0,0 +1,166 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Images for the successive revisions of the Deployment
+var revisionImages = []string{"alpine:3.18", "alpine:3.19", "alpine:3.20"}
+
+var _ = Describe("Deployment Rollout Undo", func() {
+	var namespace string
+	var deploymentName string
+	// Pod templates recorded after each rollout, indexed by revision - 1
+	var templates []v1.PodTemplateSpec
+
+	// setImage rolls the Deployment out to a new image and records the resulting template
+	setImage := func(ctx context.Context, image string) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			deployment.Spec.Template.Spec.Containers[0].Image = image
+			_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update deployment image")
+
+		deployment, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		templates = append(templates, deployment.Spec.Template)
+	}
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		deploymentName = fmt.Sprintf("test-rollout-%d", time.Now().UnixNano())
+		templates = nil
+
+		deployment := framework.NewDeployment(namespace, deploymentName, revisionImages[0], 2, "sh", "-c", "sleep 3600")
+		// A rolling update surges one pod over the two replicas
+		framework.RequirePodRoom(ctx, 3, &deployment.Spec.Template.Spec)
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+		created, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		templates = append(templates, created.Spec.Template)
+
+		for _, image := range revisionImages[1:] {
+			setImage(ctx, image)
+		}
+	})
+
+	It("should roll back to the previous revision", func(ctx SpecContext) {
+		_, err := framework.RolloutUndo(ctx, framework.Clientset, namespace, deploymentName, 0)
+		Expect(err).NotTo(HaveOccurred(), "Failed to roll back deployment")
+
+		deployment, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(revisionImages[1]))
+		Expect(framework.Revision(deployment)).To(Equal(int64(len(revisionImages)+1)), "Rollback did not create a new revision")
+		Expect(framework.VerifyRolledBack(ctx, framework.Clientset, deployment, &templates[1])).To(Succeed())
+	})
+
+	It("should roll back to an explicit revision", func(ctx SpecContext) {
+		_, err := framework.RolloutUndo(ctx, framework.Clientset, namespace, deploymentName, 1)
+		Expect(err).NotTo(HaveOccurred(), "Failed to roll back deployment")
+
+		deployment, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(revisionImages[0]))
+		Expect(framework.VerifyRolledBack(ctx, framework.Clientset, deployment, &templates[0])).To(Succeed())
+	})
+
+	It("should refuse to roll back to a revision that does not exist", func(ctx SpecContext) {
+		_, err := framework.RolloutUndo(ctx, framework.Clientset, namespace, deploymentName, 42)
+		Expect(err).To(MatchError(ContainSubstring("revision 42 not found")))
+
+		deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get deployment")
+		Expect(framework.Revision(deployment)).To(Equal(int64(len(revisionImages))), "Failed rollback changed the deployment")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+})
+
+var _ = Describe("Deployment Revision History", func() {
+	var namespace string
+	var deploymentName string
+	// Old ReplicaSets the Deployment keeps besides its current one
+	const historyLimit = int32(1)
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		deploymentName = fmt.Sprintf("test-history-%d", time.Now().UnixNano())
+
+		deployment := framework.NewDeployment(namespace, deploymentName, revisionImages[0], 1, "sh", "-c", "sleep 3600")
+		limit := historyLimit
+		deployment.Spec.RevisionHistoryLimit = &limit
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create deployment")
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), deploymentName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete deployment")
+	})
+
+	It("should garbage collect old ReplicaSets beyond the revisionHistoryLimit", func(ctx SpecContext) {
+		// Each rollout changes the template, so revision n+1 follows revision n
+		rollouts := 4
+		for i := 1; i <= rollouts; i++ {
+			err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				deployment.Spec.Template.Annotations = map[string]string{"e2e/rollout": fmt.Sprint(i)}
+				_, err = framework.Clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to update deployment template")
+			deployment, err := framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, deploymentName, 180*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The current ReplicaSet plus at most historyLimit of the newest old ones survive
+			current := int64(i + 1)
+			kept := min(int64(historyLimit), current-1)
+			framework.Eventually(func() ([]int64, error) {
+				replicaSets, err := framework.ListReplicaSets(ctx, framework.Clientset, deployment)
+				if err != nil {
+					return nil, err
+				}
+				var revisions []int64
+				for j := range replicaSets {
+					revisions = append(revisions, framework.Revision(&replicaSets[j]))
+				}
+				return revisions, nil
+			}, 120*time.Second, 2*time.Second).Should(HaveLen(int(kept+1)),
+				"Old ReplicaSets were not pruned to the revisionHistoryLimit after rollout %d", i)
+
+			replicaSets, err := framework.ListReplicaSets(ctx, framework.Clientset, deployment)
+			Expect(err).NotTo(HaveOccurred(), "Failed to list ReplicaSets")
+			for j := range replicaSets {
+				Expect(framework.Revision(&replicaSets[j])).To(BeNumerically(">", current-kept-1),
+					"An older revision survived while a newer one was pruned")
+			}
+			Expect(framework.Revision(&replicaSets[len(replicaSets)-1])).To(Equal(current), "The current revision is not the newest ReplicaSet")
+		}
+	})
+})
@@ -0,0 +1,215 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// scaleClient is the /scale subresource of a typed workload client, the same calls HPA and kubectl scale make
+type scaleClient interface {
+	GetScale(ctx context.Context, name string, opts metav1.GetOptions) (*autoscalingv1.Scale, error)
+	UpdateScale(ctx context.Context, name string, scale *autoscalingv1.Scale, opts metav1.UpdateOptions) (*autoscalingv1.Scale, error)
+}
+
+// workload is a kind of scalable object the specs run against
+type workload struct {
+	// create creates a single-replica object of the kind
+	create func(ctx context.Context, namespace, name string)
+	// replicas returns the object's spec.replicas and ready replicas, read from the object itself
+	replicas func(ctx context.Context, namespace, name string) (desired, ready int32, err error)
+	// scale returns the kind's scale subresource client
+	scale func(namespace string) scaleClient
+	// cleanup deletes the object and whatever create made for it
+	cleanup func(ctx context.Context, namespace, name string)
+}
+
+// template returns a restricted pod template labeled app=<name>
+func template(name string) v1.PodTemplateSpec {
+	return framework.NewDeployment("", name, podImage, 1, "sleep", "3600").Spec.Template
+}
+
+var deployments = workload{
+	create: func(ctx context.Context, namespace, name string) {
+		_, err := framework.Clientset.AppsV1().Deployments(namespace).Create(ctx,
+			framework.NewDeployment(namespace, name, podImage, 1, "sleep", "3600"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Deployment")
+	},
+	replicas: func(ctx context.Context, namespace, name string) (int32, int32, error) {
+		deployment, err := framework.Clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, 0, err
+		}
+		return *deployment.Spec.Replicas, deployment.Status.ReadyReplicas, nil
+	},
+	scale: func(namespace string) scaleClient {
+		return framework.Clientset.AppsV1().Deployments(namespace)
+	},
+	cleanup: func(ctx context.Context, namespace, name string) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployment")
+	},
+}
+
+var replicaSets = workload{
+	create: func(ctx context.Context, namespace, name string) {
+		replicas := int32(1)
+		replicaSet := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template: template(name),
+			},
+		}
+		_, err := framework.Clientset.AppsV1().ReplicaSets(namespace).Create(ctx, replicaSet, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ReplicaSet")
+	},
+	replicas: func(ctx context.Context, namespace, name string) (int32, int32, error) {
+		replicaSet, err := framework.Clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, 0, err
+		}
+		return *replicaSet.Spec.Replicas, replicaSet.Status.ReadyReplicas, nil
+	},
+	scale: func(namespace string) scaleClient {
+		return framework.Clientset.AppsV1().ReplicaSets(namespace)
+	},
+	cleanup: func(ctx context.Context, namespace, name string) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().ReplicaSets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ReplicaSet")
+	},
+}
+
+var statefulSets = workload{
+	create: func(ctx context.Context, namespace, name string) {
+		// StatefulSets require a governing headless service
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				ClusterIP: v1.ClusterIPNone,
+				Selector:  map[string]string{"app": name},
+				Ports:     []v1.ServicePort{{Name: "placeholder", Port: 80}},
+			},
+		}
+		_, err := framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create headless service")
+
+		replicas := int32(1)
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:            &replicas,
+				ServiceName:         name,
+				PodManagementPolicy: appsv1.ParallelPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template:            template(name),
+			},
+		}
+		_, err = framework.Clientset.AppsV1().StatefulSets(namespace).Create(ctx, statefulSet, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create StatefulSet")
+	},
+	replicas: func(ctx context.Context, namespace, name string) (int32, int32, error) {
+		statefulSet, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, 0, err
+		}
+		return *statefulSet.Spec.Replicas, statefulSet.Status.ReadyReplicas, nil
+	},
+	scale: func(namespace string) scaleClient {
+		return framework.Clientset.AppsV1().StatefulSets(namespace)
+	},
+	cleanup: func(ctx context.Context, namespace, name string) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().StatefulSets(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete StatefulSet")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete headless service")
+	},
+}
+
+var _ = Describe("Scale subresource", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-scale-%d", time.Now().UnixNano())
+	})
+
+	// waitForReplicas waits until the object asks for and has replicas ready pods, and its scale reports them
+	waitForReplicas := func(ctx context.Context, kind workload, replicas int32) {
+		framework.Eventually(func(g Gomega) {
+			desired, ready, err := kind.replicas(ctx, namespace, name)
+			g.Expect(err).NotTo(HaveOccurred(), "Failed to get object")
+			g.Expect(desired).To(Equal(replicas), "spec.replicas does not follow the scale subresource")
+			g.Expect(ready).To(Equal(replicas), "Ready replicas did not converge")
+			scale, err := kind.scale(namespace).GetScale(ctx, name, metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred(), "Failed to get scale")
+			g.Expect(scale.Status.Replicas).To(Equal(replicas), "Scale status does not report the replicas")
+		}, 180*time.Second, 2*time.Second).Should(Succeed())
+	}
+
+	// updateScale sets the replicas through the scale subresource, retrying on conflicting writes
+	updateScale := func(ctx context.Context, kind workload, replicas int32) {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			scale, err := kind.scale(namespace).GetScale(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			scale.Spec.Replicas = replicas
+			updated, err := kind.scale(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+			if err == nil && updated.Spec.Replicas != replicas {
+				return fmt.Errorf("scale update returned %d replicas, want %d", updated.Spec.Replicas, replicas)
+			}
+			return err
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update scale")
+	}
+
+	DescribeTable("should scale through the scale subresource",
+		func(ctx SpecContext, kind workload) {
+			podTemplate := template(name)
+			framework.RequirePodRoom(ctx, 3, &podTemplate.Spec)
+			kind.create(ctx, namespace, name)
+			DeferCleanup(kind.cleanup, namespace, name)
+			waitForReplicas(ctx, kind, 1)
+
+			By("reading the scale subresource")
+			scale, err := kind.scale(namespace).GetScale(ctx, name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get scale")
+			Expect(scale.Name).To(Equal(name))
+			Expect(scale.Spec.Replicas).To(Equal(int32(1)), "Scale does not report spec.replicas")
+			Expect(scale.Status.Selector).To(Equal("app="+name), "Scale does not report the label selector HPA uses")
+
+			By("scaling up")
+			updateScale(ctx, kind, 3)
+			waitForReplicas(ctx, kind, 3)
+
+			By("refusing a scale update based on a stale resourceVersion")
+			scale.Spec.Replicas = 5
+			_, err = kind.scale(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+			Expect(errors.IsConflict(err)).To(BeTrue(), "Stale scale update was not rejected with a conflict: %v", err)
+
+			By("scaling down")
+			updateScale(ctx, kind, 1)
+			waitForReplicas(ctx, kind, 1)
+		},
+		Entry("Deployment", deployments),
+		Entry("ReplicaSet", replicaSets),
+		Entry("StatefulSet", statefulSets),
+	)
+})
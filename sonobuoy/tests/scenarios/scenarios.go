@@ -0,0 +1,36 @@
+package e2e
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Scenarios shipped with the suites
+//
+//go:embed builtin/*.yaml
+var builtin embed.FS
+
+var _ = framework.DescribeScenarios(mustSub(builtin, "builtin"))
+
+// Scenarios mounted into the plugin, e.g. from a ConfigMap, run next to the built-in ones. An invalid
+// run configuration is reported by the entry point before any spec runs.
+var _ = describeScenarioDir()
+
+func describeScenarioDir() bool {
+	config, err := framework.LoadRunConfig()
+	if err != nil || config.ScenarioDir == "" {
+		return false
+	}
+	return framework.DescribeScenarios(os.DirFS(config.ScenarioDir))
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
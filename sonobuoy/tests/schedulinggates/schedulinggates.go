@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Gate the spec holds its pod back with
+const schedulingGate = "e2e.sonobuoy.io/hold"
+
+// Reason of the PodScheduled condition of a pod the scheduler has not considered because of its gates
+const schedulingGatedReason = "SchedulingGated"
+
+// Pod scheduling readiness is beta and enabled by default since 1.27; older servers drop the field
+var _ = Describe("Pod scheduling gates", func() {
+	var namespace string
+	var name string
+
+	BeforeEach(func() {
+		framework.SkipUnlessVersionAtLeast("1.27")
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-schedulinggates-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// patch applies a JSON patch to the pod
+	patch := func(ctx context.Context, jsonPatch string) error {
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Patch(ctx, name, types.JSONPatchType, []byte(jsonPatch), metav1.PatchOptions{})
+		return err
+	}
+
+	It("should hold a gated pod back from scheduling until its gate is removed", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+		pod.Spec.SchedulingGates = []v1.PodSchedulingGate{{Name: schedulingGate}}
+		created, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		Expect(created.Spec.SchedulingGates).To(HaveLen(1), "API server dropped the scheduling gate")
+
+		By("checking the pod stays SchedulingGated")
+		framework.Eventually(func() (*v1.Pod, error) {
+			return framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		}, 30*time.Second, 2*time.Second).Should(HaveField("Status.Conditions", ContainElement(And(
+			HaveField("Type", v1.PodScheduled),
+			HaveField("Status", v1.ConditionFalse),
+			HaveField("Reason", schedulingGatedReason),
+		))), "Gated pod was not reported %s", schedulingGatedReason)
+		Consistently(func() (string, error) {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			return pod.Spec.NodeName, nil
+		}, 10*time.Second, 2*time.Second).Should(BeEmpty(), "Gated pod was scheduled")
+
+		By("refusing to add a gate once the pod exists")
+		err = patch(ctx, `[{"op": "add", "path": "/spec/schedulingGates/-", "value": {"name": "e2e.sonobuoy.io/late"}}]`)
+		Expect(errors.IsInvalid(err)).To(BeTrue(), "Adding a scheduling gate to an existing pod was not rejected: %v", err)
+
+		By("removing the gate")
+		err = patch(ctx, `[{"op": "remove", "path": "/spec/schedulingGates"}]`)
+		Expect(err).NotTo(HaveOccurred(), "Failed to remove the scheduling gate")
+		running, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not schedule and start once its gate was removed")
+		Expect(running.Spec.NodeName).NotTo(BeEmpty())
+	})
+})
@@ -0,0 +1,174 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Directory the kubelet resolves Localhost seccomp profiles in, under its default root directory
+const kubeletSeccompDir = "/var/lib/kubelet/seccomp"
+
+// Localhost profiles installed by the suite live in their own subdirectory of kubeletSeccompDir
+const profileDir = "e2e-sonobuoy"
+
+// Seccomp mode the kernel reports in /proc/<pid>/status for a process confined by a filter
+const seccompModeFilter = 2
+
+// denyMkdirProfile allows every syscall except creating directories, which fails with EPERM. Directory
+// creation is harmless to block and easy to observe from a shell, unlike what RuntimeDefault blocks.
+const denyMkdirProfile = `{
+  "defaultAction": "SCMP_ACT_ALLOW",
+  "syscalls": [
+    {"names": ["mkdir", "mkdirat"], "action": "SCMP_ACT_ERRNO", "errnoRet": 1}
+  ]
+}`
+
+// Prints the seccomp status of the container process, then whether it may create a directory
+const probeScript = `grep Seccomp /proc/self/status
+if mkdir /tmp/probe 2>/tmp/error; then echo mkdir-allowed; else cat /tmp/error; echo mkdir-denied; fi
+if touch /tmp/file; then echo touch-allowed; fi`
+
+// seccompStatus parses a field such as Seccomp or Seccomp_filters the probe printed from /proc/self/status
+func seccompStatus(output, field string) int {
+	GinkgoHelper()
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(line, field+":"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			Expect(err).NotTo(HaveOccurred(), "Failed to parse %s %q", field, value)
+			return n
+		}
+	}
+	Fail(fmt.Sprintf("Pod did not print its %s line:\n%s", field, output))
+	return 0
+}
+
+// Seccomp profiles are enforced by the container runtime, so the specs check the kernel's view from inside
+// the container rather than what the API server stored.
+var _ = Describe("Seccomp Profiles", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-seccomp-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// runProbe runs probeScript with the given seccomp profile and returns what it printed
+	runProbe := func(ctx context.Context, profile *v1.SeccompProfile) string {
+		GinkgoHelper()
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", probeScript)
+		pod.Spec.SecurityContext.SeccompProfile = profile
+
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		return output
+	}
+
+	It("should confine the container with the RuntimeDefault profile", func(ctx SpecContext) {
+		output := runProbe(ctx, &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault})
+
+		Expect(seccompStatus(output, "Seccomp")).To(Equal(seccompModeFilter), "Process is not confined by a seccomp filter")
+		Expect(seccompStatus(output, "Seccomp_filters")).To(BeNumerically(">=", 1), "No seccomp filter is installed")
+		// RuntimeDefault only blocks syscalls that are dangerous to the host
+		Expect(output).To(ContainSubstring("mkdir-allowed"), "RuntimeDefault blocked an ordinary syscall")
+	})
+
+	// The profile has to exist on every node before a pod can reference it, so a DaemonSet writes it to
+	// the kubelet's seccomp directory and removes it again when it is deleted. Writing to the host needs
+	// a namespace that allows hostPath volumes and root.
+	Context("with a Localhost profile", Ordered, Label(framework.LabelPrivileged), func() {
+		var profile string
+
+		BeforeAll(func(ctx SpecContext) {
+			suffix := time.Now().UnixNano()
+			profileName := fmt.Sprintf("deny-mkdir-%d.json", suffix)
+			profile = profileDir + "/" + profileName
+			installerNamespace := framework.CreateNamespace(ctx, "test-seccomp-installer", framework.PodSecurityPrivileged)
+
+			path := "/seccomp/" + profile
+			script := fmt.Sprintf(`mkdir -p /seccomp/%s && printf '%%s' "$PROFILE" > %s
+trap 'rm -f %s; exit 0' TERM
+while true; do sleep 1; done`, profileDir, path, path)
+			installer := framework.NewPod(installerNamespace, "seccomp-installer", podImage, "sh", "-c", script)
+			framework.Unrestricted(&installer.Spec)
+			installer.Spec.RestartPolicy = v1.RestartPolicyAlways
+			container := &installer.Spec.Containers[0]
+			container.Env = []v1.EnvVar{{Name: "PROFILE", Value: denyMkdirProfile}}
+			container.VolumeMounts = []v1.VolumeMount{{Name: "seccomp", MountPath: "/seccomp"}}
+			container.ReadinessProbe = &v1.Probe{
+				ProbeHandler:  v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"test", "-f", path}}},
+				PeriodSeconds: 2,
+			}
+			directoryOrCreate := v1.HostPathDirectoryOrCreate
+			installer.Spec.Volumes = []v1.Volume{{
+				Name: "seccomp",
+				VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{
+					Path: kubeletSeccompDir,
+					Type: &directoryOrCreate,
+				}},
+			}}
+
+			daemonSet := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      installer.Name,
+					Namespace: installerNamespace,
+				},
+				Spec: appsv1.DaemonSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: installer.Labels},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: installer.Labels},
+						Spec:       installer.Spec,
+					},
+				},
+			}
+			_, err := framework.Clientset.AppsV1().DaemonSets(installerNamespace).Create(ctx, daemonSet, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create profile installer DaemonSet")
+
+			framework.Eventually(func() error {
+				ds, err := framework.Clientset.AppsV1().DaemonSets(installerNamespace).Get(ctx, daemonSet.Name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if ds.Status.DesiredNumberScheduled == 0 || ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+					return fmt.Errorf("profile installed on %d of %d nodes", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+				}
+				return nil
+			}, 120*time.Second, 2*time.Second).Should(Succeed(), "Seccomp profile was not installed on every node")
+		})
+
+		It("should confine the container with the Localhost profile", func(ctx SpecContext) {
+			output := runProbe(ctx, &v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile})
+
+			Expect(seccompStatus(output, "Seccomp")).To(Equal(seccompModeFilter), "Process is not confined by a seccomp filter")
+			Expect(output).To(ContainSubstring("touch-allowed"), "Profile blocked a syscall it allows")
+		})
+
+		It("should fail the syscalls the Localhost profile blocks", func(ctx SpecContext) {
+			output := runProbe(ctx, &v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile})
+
+			Expect(output).To(ContainSubstring("mkdir-denied"), "Blocked syscall succeeded")
+			Expect(output).To(ContainSubstring("Operation not permitted"), "Blocked syscall did not fail with the profile's errno")
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package e2e
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Secret CRUD test suite with unique secret names
+var _ = Describe("Secrets CRUD Operations", func() {
+	var namespace string
+	var secretName string
+
+	BeforeEach(func(ctx SpecContext) {
+		// Define namespace and generate a unique secret name with a timestamp
+		namespace = framework.TestNamespace()
+		secretName = fmt.Sprintf("test-secret-%d", time.Now().UnixNano())
+
+		// Create a secret before each test
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("secret"),
+			},
+			Type: v1.SecretTypeOpaque,
+		}
+
+		_, err := framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create secret")
+	})
+
+	// Read the secret
+	It("should read the secret successfully", func(ctx SpecContext) {
+		secret, err := framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to read secret")
+		Expect(secret.Data["username"]).To(Equal([]byte("admin")))
+		Expect(secret.Data["password"]).To(Equal([]byte("secret")))
+	})
+
+	// Update the secret
+	It("should update the secret successfully", func(ctx SpecContext) {
+		secret, err := framework.Clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get secret for update")
+
+		// Modify the secret data
+		secret.Data["password"] = []byte("newsecret")
+		_, err = framework.Clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		// Check if the error is a StatusError and extract errstatus.message
+		if statusError, isStatus := err.(*errors.StatusError); isStatus {
+			// Fail the test and only show the relevant error message
+			Fail(fmt.Sprintf("Error: %s", statusError.ErrStatus.Message))
+		} else {
+			// If no error or unexpected error, ensure the test fails accordingly
+			Expect(err).NotTo(HaveOccurred(), "Unexpected failure during secret update")
+		}
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), secretName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete secret")
+	})
+})
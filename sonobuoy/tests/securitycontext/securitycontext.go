@@ -0,0 +1,138 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Bit of CAP_CHOWN in the capability sets of /proc/<pid>/status
+const capChown = 0
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func boolPtr(b bool) *bool { return &b }
+
+// effectiveCapabilities parses the CapEff line a pod printed from /proc/self/status
+func effectiveCapabilities(output string) uint64 {
+	GinkgoHelper()
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(line, "CapEff:"); ok {
+			caps, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+			Expect(err).NotTo(HaveOccurred(), "Failed to parse CapEff %q", value)
+			return caps
+		}
+	}
+	Fail("Pod did not print its CapEff line:\n" + output)
+	return 0
+}
+
+// The security context is only as good as what the container runtime enforces, so every spec checks
+// the effect from inside the container rather than what the API server stored.
+var _ = Describe("SecurityContext Behavior", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-securitycontext-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	// runPod creates the pod and returns what it printed once it completed
+	runPod := func(ctx context.Context, pod *v1.Pod) string {
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		return output
+	}
+
+	// withScratchVolume mounts an emptyDir at /scratch in the pod's container
+	withScratchVolume := func(pod *v1.Pod) *v1.Pod {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name:         "scratch",
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+			Name:      "scratch",
+			MountPath: "/scratch",
+		})
+		return pod
+	}
+
+	It("should run the container process as runAsUser and runAsGroup with supplementalGroups", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", "id -u; id -g; id -G")
+		pod.Spec.SecurityContext.RunAsUser = int64Ptr(1234)
+		pod.Spec.SecurityContext.RunAsGroup = int64Ptr(5678)
+		pod.Spec.SecurityContext.SupplementalGroups = []int64{4321}
+
+		lines := strings.Fields(runPod(ctx, pod))
+		Expect(len(lines)).To(BeNumerically(">=", 3), "Unexpected output: %v", lines)
+		Expect(lines[0]).To(Equal("1234"), "Process does not run as runAsUser")
+		Expect(lines[1]).To(Equal("5678"), "Process does not run as runAsGroup")
+		Expect(lines[2:]).To(ContainElements("5678", "4321"), "Process is missing its supplemental groups")
+	})
+
+	It("should give volumes and the files written to them the fsGroup", func(ctx SpecContext) {
+		script := "stat -c '%g' /scratch; touch /scratch/file; stat -c '%u %g' /scratch/file; id -G"
+		pod := withScratchVolume(framework.NewPod(namespace, podName, podImage, "sh", "-c", script))
+		pod.Spec.SecurityContext.RunAsUser = int64Ptr(1234)
+		pod.Spec.SecurityContext.FSGroup = int64Ptr(2345)
+
+		lines := strings.Split(strings.TrimSpace(runPod(ctx, pod)), "\n")
+		Expect(lines).To(HaveLen(3), "Unexpected output: %v", lines)
+		Expect(lines[0]).To(Equal("2345"), "Volume is not owned by the fsGroup")
+		Expect(lines[1]).To(Equal("1234 2345"), "Files written to the volume do not inherit the fsGroup")
+		Expect(strings.Fields(lines[2])).To(ContainElement("2345"), "Process is not a member of the fsGroup")
+	})
+
+	It("should block writes to the root filesystem with readOnlyRootFilesystem", func(ctx SpecContext) {
+		// /tmp is world-writable on the image's root filesystem, so only the read-only mount can refuse the write
+		script := `if touch /tmp/probe 2>/dev/null; then echo root-writable; else echo root-read-only; fi
+if touch /scratch/probe; then echo volume-writable; else echo volume-read-only; fi`
+		pod := withScratchVolume(framework.NewPod(namespace, podName, podImage, "sh", "-c", script))
+		pod.Spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem = boolPtr(true)
+
+		output := runPod(ctx, pod)
+		Expect(output).To(ContainSubstring("root-read-only"), "Root filesystem accepted a write")
+		Expect(output).To(ContainSubstring("volume-writable"), "Volumes must stay writable on a read-only root filesystem")
+	})
+
+	It("should remove dropped capabilities from the container process", Label(framework.LabelPrivileged), func(ctx SpecContext) {
+		// Capabilities only matter for root, which the restricted defaults rule out
+		script := "grep CapEff /proc/self/status; touch /tmp/file; if chown 1:1 /tmp/file 2>/dev/null; then echo chown-allowed; else echo chown-denied; fi"
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", script)
+		framework.Unrestricted(&pod.Spec)
+		pod.Spec.SecurityContext = &v1.PodSecurityContext{RunAsUser: int64Ptr(0)}
+		pod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{
+			Capabilities: &v1.Capabilities{Drop: []v1.Capability{"CHOWN"}},
+		}
+
+		output := runPod(ctx, pod)
+		Expect(effectiveCapabilities(output)&(1<<capChown)).To(BeZero(), "CAP_CHOWN is still in the effective set")
+		Expect(output).To(ContainSubstring("chown-denied"), "chown succeeded without CAP_CHOWN")
+	})
+
+	It("should leave no effective capabilities when all are dropped", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, podImage, "grep", "CapEff", "/proc/self/status")
+
+		Expect(effectiveCapabilities(runPod(ctx, pod))).To(BeZero(), "Process kept capabilities after dropping ALL")
+	})
+})
@@ -0,0 +1,201 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("Label and Field Selectors", func() {
+	var namespace string
+	var otherNamespace string
+	var runID string
+
+	// Every object carries the run label so cluster-wide queries stay scoped to this spec
+	tiers := map[string]map[string]string{
+		"frontend-prod": {"tier": "frontend", "environment": "prod"},
+		"frontend-dev":  {"tier": "frontend", "environment": "dev"},
+		"backend-prod":  {"tier": "backend", "environment": "prod"},
+		"cache":         {"tier": "cache"},
+		"unlabeled":     {},
+	}
+
+	// listNames returns the sorted short names of the ConfigMaps matching the selectors
+	listNames := func(ctx context.Context, ns, labelSelector string) []string {
+		selector := "e2e-run=" + runID
+		if labelSelector != "" {
+			selector += "," + labelSelector
+		}
+		list, err := framework.Clientset.CoreV1().ConfigMaps(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list ConfigMaps with selector %q", selector)
+		names := []string{}
+		for _, item := range list.Items {
+			names = append(names, item.Labels["e2e-name"])
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		runID = fmt.Sprintf("%d", time.Now().UnixNano())
+		otherNamespace = "test-selectors-" + runID
+
+		_, err := framework.Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: otherNamespace},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create second namespace")
+
+		for _, ns := range []string{namespace, otherNamespace} {
+			for name, labels := range tiers {
+				objectLabels := map[string]string{"e2e-run": runID, "e2e-name": name}
+				for key, value := range labels {
+					objectLabels[key] = value
+				}
+				configMap := &v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("test-selector-%s-%s", name, runID),
+						Namespace: ns,
+						Labels:    objectLabels,
+					},
+				}
+				_, err := framework.Clientset.CoreV1().ConfigMaps(ns).Create(ctx, configMap, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+			}
+		}
+	})
+
+	DescribeTable("should filter lists by label selector",
+		func(ctx SpecContext, labelSelector string, expected []string) {
+			Expect(listNames(ctx, namespace, labelSelector)).To(Equal(expected), "Namespaced list returned the wrong objects")
+
+			// Cluster-wide the same objects exist once per namespace
+			var clusterWide []string
+			for _, name := range expected {
+				clusterWide = append(clusterWide, name, name)
+			}
+			if clusterWide == nil {
+				clusterWide = []string{}
+			}
+			Expect(listNames(ctx, metav1.NamespaceAll, labelSelector)).To(Equal(clusterWide), "Cluster-wide list returned the wrong objects")
+		},
+		Entry("equality", "tier=frontend", []string{"frontend-dev", "frontend-prod"}),
+		Entry("inequality", "tier!=frontend", []string{"backend-prod", "cache", "unlabeled"}),
+		Entry("multiple requirements", "tier=frontend,environment=prod", []string{"frontend-prod"}),
+		Entry("set-based in", "tier in (backend,cache)", []string{"backend-prod", "cache"}),
+		Entry("set-based notin", "environment notin (prod)", []string{"cache", "frontend-dev", "unlabeled"}),
+		Entry("exists", "environment", []string{"backend-prod", "frontend-dev", "frontend-prod"}),
+		Entry("does not exist", "!tier", []string{"unlabeled"}),
+		Entry("no match", "tier=does-not-exist", []string{}),
+	)
+
+	It("should only deliver watch events for objects matching the label selector", func(ctx SpecContext) {
+		w, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector: "e2e-run=" + runID + ",tier=watched",
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to start watch")
+		defer w.Stop()
+
+		for _, tier := range []string{"ignored", "watched"} {
+			configMap := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-selector-%s-%s", tier, runID),
+					Namespace: namespace,
+					Labels:    map[string]string{"e2e-run": runID, "e2e-name": tier, "tier": tier},
+				},
+			}
+			_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		}
+
+		var event watch.Event
+		framework.Eventually(w.ResultChan(), 30*time.Second).Should(Receive(&event), "Timed out waiting for watch event")
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*v1.ConfigMap).Labels["tier"]).To(Equal("watched"), "Watch delivered a non-matching object")
+		Consistently(w.ResultChan(), 5*time.Second).ShouldNot(Receive(), "Watch delivered a non-matching object")
+	})
+
+	It("should filter pods by field selector", func(ctx SpecContext) {
+		pods := map[string]string{
+			"running":   "sleep 3600",
+			"succeeded": "exit 0",
+		}
+		for name, script := range pods {
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-selector-%s-%s", name, runID),
+					Namespace: namespace,
+					Labels:    map[string]string{"e2e-run": runID, "e2e-name": name},
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{
+						{
+							Name:    "alpine",
+							Image:   "alpine:3.20",
+							Command: []string{"sh", "-c", script},
+						},
+					},
+				},
+			}
+			framework.Restrict(&pod.Spec)
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		}
+
+		podNames := func(ns string, fieldSelector fields.Selector) []string {
+			list, err := framework.Clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
+				LabelSelector: "e2e-run=" + runID,
+				FieldSelector: fieldSelector.String(),
+			})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list pods with field selector %q", fieldSelector)
+			names := []string{}
+			for _, item := range list.Items {
+				names = append(names, item.Labels["e2e-name"])
+			}
+			sort.Strings(names)
+			return names
+		}
+
+		running := fields.OneTermEqualSelector("status.phase", string(v1.PodRunning))
+		succeeded := fields.OneTermEqualSelector("status.phase", string(v1.PodSucceeded))
+		framework.Eventually(func() []string {
+			return podNames(namespace, running)
+		}, 120*time.Second, 2*time.Second).Should(Equal([]string{"running"}), "Running pod not selected by status.phase")
+		framework.Eventually(func() []string {
+			return podNames(namespace, succeeded)
+		}, 120*time.Second, 2*time.Second).Should(Equal([]string{"succeeded"}), "Succeeded pod not selected by status.phase")
+
+		Expect(podNames(metav1.NamespaceAll, running)).To(Equal([]string{"running"}), "Cluster-wide status.phase selector returned the wrong pods")
+		Expect(podNames(namespace, fields.OneTermNotEqualSelector("status.phase", string(v1.PodRunning)))).To(Equal([]string{"succeeded"}))
+
+		byName := fields.OneTermEqualSelector("metadata.name", fmt.Sprintf("test-selector-running-%s", runID))
+		Expect(podNames(namespace, byName)).To(Equal([]string{"running"}), "metadata.name selector returned the wrong pods")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.CleanupCollection(ctx, framework.Clientset.CoreV1().Pods(namespace), metav1.ListOptions{
+			LabelSelector: "e2e-run=" + runID,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pods")
+
+		err = framework.CleanupCollection(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), metav1.ListOptions{
+			LabelSelector: "e2e-run=" + runID,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMaps")
+
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Namespaces(), otherNamespace)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete second namespace")
+	})
+})
@@ -0,0 +1,254 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var (
+	snapshotGroup          = "snapshot.storage.k8s.io"
+	volumeSnapshotGVR      = schema.GroupVersionResource{Group: snapshotGroup, Version: "v1", Resource: "volumesnapshots"}
+	volumeSnapshotClassGVR = schema.GroupVersionResource{Group: snapshotGroup, Version: "v1", Resource: "volumesnapshotclasses"}
+	referenceGrantGVR      = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1beta1", Resource: "referencegrants"}
+)
+
+// Restoring a VolumeSnapshot into another namespace must only be possible through an explicit ReferenceGrant.
+// Whatever the cluster does is recorded as a "Capability" report entry so it shows up in the results.
+var _ = Describe("Cross-namespace VolumeSnapshot Restore", func() {
+	var sourceNamespace string
+	var targetNamespace string
+	var sourcePVCName string
+	var snapshotName string
+	var storageClassName *string
+
+	BeforeEach(func(ctx SpecContext) {
+		framework.SkipUnlessResourceExists(volumeSnapshotGVR.GroupVersion().String(), volumeSnapshotGVR.Resource)
+
+		config, err := framework.LoadRunConfig()
+		Expect(err).NotTo(HaveOccurred(), "Failed to load run configuration")
+
+		// Use E2E_SNAPSHOT_CLASS if provided, otherwise fall back to the first VolumeSnapshotClass found
+		snapshotClassName := config.Storage.SnapshotClass
+		if snapshotClassName == "" {
+			classes, err := framework.DynamicClient.Resource(volumeSnapshotClassGVR).List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to list VolumeSnapshotClasses")
+			if len(classes.Items) == 0 {
+				Skip("No VolumeSnapshotClass is available")
+			}
+			snapshotClassName = classes.Items[0].GetName()
+		}
+		framework.RequireStorageClass(ctx, config.Storage.StorageClass)
+		if sc := config.Storage.StorageClass; sc != "" {
+			storageClassName = &sc
+		}
+
+		sourceNamespace = framework.TestNamespace()
+		suffix := time.Now().UnixNano()
+		targetNamespace = fmt.Sprintf("test-snapshot-restore-%d", suffix)
+		sourcePVCName = fmt.Sprintf("test-snapshot-source-%d", suffix)
+		snapshotName = fmt.Sprintf("test-snapshot-%d", suffix)
+
+		_, err = framework.Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: targetNamespace},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create target namespace")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Namespaces(), targetNamespace)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete target namespace")
+		})
+
+		// Populate a source PVC and bind it with a consumer pod
+		pvc := newPVC(sourceNamespace, sourcePVCName, storageClassName)
+		_, err = framework.Clientset.CoreV1().PersistentVolumeClaims(sourceNamespace).Create(ctx, pvc, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create source PVC")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(sourceNamespace), sourcePVCName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete source PVC")
+		})
+
+		writerName := sourcePVCName + "-writer"
+		writer := newConsumerPod(sourceNamespace, writerName, sourcePVCName, "echo snapshot-data > /mnt/test/data && sleep 3600")
+		_, err = framework.Clientset.CoreV1().Pods(sourceNamespace).Create(ctx, writer, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create writer pod")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(sourceNamespace), writerName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete writer pod")
+		})
+
+		_, err = framework.WaitForPodRunning(ctx, framework.Clientset, sourceNamespace, writerName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Writer pod did not reach running state")
+
+		// Snapshot the source PVC and wait for it to be usable
+		snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": volumeSnapshotGVR.GroupVersion().String(),
+			"kind":       "VolumeSnapshot",
+			"metadata": map[string]interface{}{
+				"name":      snapshotName,
+				"namespace": sourceNamespace,
+			},
+			"spec": map[string]interface{}{
+				"volumeSnapshotClassName": snapshotClassName,
+				"source": map[string]interface{}{
+					"persistentVolumeClaimName": sourcePVCName,
+				},
+			},
+		}}
+		_, err = framework.DynamicClient.Resource(volumeSnapshotGVR).Namespace(sourceNamespace).Create(ctx, snapshot, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create VolumeSnapshot")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Dynamic(framework.DynamicClient.Resource(volumeSnapshotGVR).Namespace(sourceNamespace)), snapshotName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete VolumeSnapshot")
+		})
+
+		framework.Eventually(func() bool {
+			snap, err := framework.DynamicClient.Resource(volumeSnapshotGVR).Namespace(sourceNamespace).Get(ctx, snapshotName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get VolumeSnapshot")
+			ready, _, _ := unstructured.NestedBool(snap.Object, "status", "readyToUse")
+			return ready
+		}, 300*time.Second, 5*time.Second).Should(BeTrue(), "VolumeSnapshot was not ready within the timeout")
+	})
+
+	It("should not restore a snapshot from another namespace without a ReferenceGrant", func(ctx SpecContext) {
+		restoreName := snapshotName + "-restore"
+		restore := newPVC(targetNamespace, restoreName, storageClassName)
+		restore.Spec.DataSourceRef = &v1.TypedObjectReference{
+			APIGroup:  &snapshotGroup,
+			Kind:      "VolumeSnapshot",
+			Name:      snapshotName,
+			Namespace: &sourceNamespace,
+		}
+
+		created, err := framework.Clientset.CoreV1().PersistentVolumeClaims(targetNamespace).Create(ctx, restore, metav1.CreateOptions{})
+		if errors.IsInvalid(err) || errors.IsForbidden(err) {
+			AddReportEntry("Capability: cross-namespace snapshot restore", "rejected at admission: "+err.Error())
+			return
+		}
+		Expect(err).NotTo(HaveOccurred(), "Failed to create restore PVC")
+
+		behavior := "CrossNamespaceVolumeDataSource enabled"
+		if created.Spec.DataSourceRef == nil || created.Spec.DataSourceRef.Namespace == nil {
+			behavior = "CrossNamespaceVolumeDataSource disabled (namespace dropped from dataSourceRef)"
+		}
+
+		// A consumer pod ensures WaitForFirstConsumer classes would provision if the restore was allowed
+		consumerName := restoreName + "-consumer"
+		consumer := newConsumerPod(targetNamespace, consumerName, restoreName, "cat /mnt/test/data && sleep 3600")
+		_, err = framework.Clientset.CoreV1().Pods(targetNamespace).Create(ctx, consumer, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create consumer pod")
+
+		Consistently(func() v1.PersistentVolumeClaimPhase {
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(targetNamespace).Get(ctx, restoreName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get restore PVC")
+			return pvc.Status.Phase
+		}, 60*time.Second, 5*time.Second).ShouldNot(Equal(v1.ClaimBound), "Snapshot was restored across namespaces without a ReferenceGrant")
+
+		if created.Spec.DataSourceRef == nil || created.Spec.DataSourceRef.Namespace == nil {
+			AddReportEntry("Capability: cross-namespace snapshot restore", behavior)
+			return
+		}
+
+		// With the feature enabled, a ReferenceGrant in the source namespace should unblock the restore
+		if _, err := framework.Clientset.Discovery().ServerResourcesForGroupVersion(referenceGrantGVR.GroupVersion().String()); err != nil {
+			AddReportEntry("Capability: cross-namespace snapshot restore", behavior+", ReferenceGrant API not installed")
+			return
+		}
+
+		grant := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": referenceGrantGVR.GroupVersion().String(),
+			"kind":       "ReferenceGrant",
+			"metadata": map[string]interface{}{
+				"name":      restoreName,
+				"namespace": sourceNamespace,
+			},
+			"spec": map[string]interface{}{
+				"from": []interface{}{
+					map[string]interface{}{"group": "", "kind": "PersistentVolumeClaim", "namespace": targetNamespace},
+				},
+				"to": []interface{}{
+					map[string]interface{}{"group": snapshotGroup, "kind": "VolumeSnapshot", "name": snapshotName},
+				},
+			},
+		}}
+		_, err = framework.DynamicClient.Resource(referenceGrantGVR).Namespace(sourceNamespace).Create(ctx, grant, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ReferenceGrant")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Dynamic(framework.DynamicClient.Resource(referenceGrantGVR).Namespace(sourceNamespace)), restoreName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ReferenceGrant")
+		})
+
+		framework.Eventually(func() v1.PersistentVolumeClaimPhase {
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(targetNamespace).Get(ctx, restoreName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get restore PVC")
+			return pvc.Status.Phase
+		}, 300*time.Second, 5*time.Second).Should(Equal(v1.ClaimBound), "Restore PVC was not bound after creating a ReferenceGrant")
+		AddReportEntry("Capability: cross-namespace snapshot restore", behavior+", allowed via ReferenceGrant")
+	})
+})
+
+// newPVC returns a 1Gi ReadWriteOnce claim, large enough to hold any provisioner's minimum snapshot size
+func newPVC(namespace, name string, storageClassName *string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: storageClassName,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	}
+}
+
+// newConsumerPod returns a pod mounting the given claim at /mnt/test and running the given shell script
+func newConsumerPod(namespace, name, claimName, script string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:    "alpine-container",
+					Image:   "alpine:3.20",
+					Command: []string{"sh", "-c", script},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "pvc-volume",
+							MountPath: "/mnt/test",
+						},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "pvc-volume",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+						},
+					},
+				},
+			},
+		},
+	}
+	framework.Restrict(&pod.Spec)
+	return pod
+}
@@ -0,0 +1,202 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	statefulSetReplicas = 3
+	// Readiness delay that makes OrderedReady sequencing observable in pod creation timestamps
+	readinessDelaySeconds = 10
+)
+
+var _ = Describe("StatefulSet Pod Management Policy", func() {
+	var namespace string
+	var statefulSetName string
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		statefulSetName = fmt.Sprintf("test-statefulset-%d", time.Now().UnixNano())
+
+		// StatefulSets require a governing headless service
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      statefulSetName,
+				Namespace: namespace,
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: v1.ClusterIPNone,
+				Selector:  map[string]string{"app": statefulSetName},
+				Ports:     []v1.ServicePort{{Name: "placeholder", Port: 80}},
+			},
+		}
+		_, err := framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create headless service")
+	})
+
+	It("should create and update pods one at a time in ordinal order with OrderedReady", func(ctx SpecContext) {
+		createStatefulSet(ctx, namespace, statefulSetName, appsv1.OrderedReadyPodManagement)
+		waitForReadyReplicas(ctx, namespace, statefulSetName, statefulSetReplicas)
+
+		pods := getOrdinalPods(ctx, namespace, statefulSetName)
+		for i := 1; i < len(pods); i++ {
+			previousReady := readyTime(pods[i-1])
+			Expect(pods[i].CreationTimestamp.Time).NotTo(BeTemporally("<", previousReady),
+				"Pod %s was created before %s became Ready", pods[i].Name, pods[i-1].Name)
+		}
+
+		rollingUpdate(ctx, namespace, statefulSetName)
+		assertReverseOrdinalUpdate(ctx, namespace, statefulSetName)
+	})
+
+	It("should launch all pods without waiting for readiness with Parallel", func(ctx SpecContext) {
+		createStatefulSet(ctx, namespace, statefulSetName, appsv1.ParallelPodManagement)
+		waitForReadyReplicas(ctx, namespace, statefulSetName, statefulSetReplicas)
+
+		pods := getOrdinalPods(ctx, namespace, statefulSetName)
+		first, last := pods[0].CreationTimestamp.Time, pods[0].CreationTimestamp.Time
+		for _, pod := range pods[1:] {
+			if pod.CreationTimestamp.Time.Before(first) {
+				first = pod.CreationTimestamp.Time
+			}
+			if pod.CreationTimestamp.Time.After(last) {
+				last = pod.CreationTimestamp.Time
+			}
+		}
+		Expect(last.Sub(first)).To(BeNumerically("<", readinessDelaySeconds*time.Second),
+			"Parallel pods were created sequentially (spread %s)", last.Sub(first))
+
+		// podManagementPolicy does not apply to updates, which remain ordered
+		rollingUpdate(ctx, namespace, statefulSetName)
+		assertReverseOrdinalUpdate(ctx, namespace, statefulSetName)
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().StatefulSets(namespace), statefulSetName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete StatefulSet")
+
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), statefulSetName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete headless service")
+	})
+})
+
+// createStatefulSet creates a StatefulSet whose pods only become Ready after readinessDelaySeconds
+func createStatefulSet(ctx context.Context, namespace, name string, policy appsv1.PodManagementPolicyType) {
+	replicas := int32(statefulSetReplicas)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            &replicas,
+			ServiceName:         name,
+			PodManagementPolicy: policy,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": name},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": name},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:    "alpine",
+							Image:   "alpine:3.20",
+							Command: []string{"sh", "-c", "sleep 3600"},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler: v1.ProbeHandler{
+									Exec: &v1.ExecAction{Command: []string{"true"}},
+								},
+								InitialDelaySeconds: readinessDelaySeconds,
+								PeriodSeconds:       2,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	framework.Restrict(&statefulSet.Spec.Template.Spec)
+	framework.RequirePodRoom(ctx, statefulSetReplicas, &statefulSet.Spec.Template.Spec)
+	_, err := framework.Clientset.AppsV1().StatefulSets(namespace).Create(ctx, statefulSet, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred(), "Failed to create StatefulSet")
+}
+
+// waitForReadyReplicas waits until the StatefulSet reports the given number of ready and updated replicas
+func waitForReadyReplicas(ctx context.Context, namespace, name string, replicas int32) {
+	framework.Eventually(func() bool {
+		sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get StatefulSet")
+		return sts.Status.ObservedGeneration == sts.Generation &&
+			sts.Status.ReadyReplicas == replicas &&
+			sts.Status.UpdatedReplicas == replicas
+	}, 300*time.Second, 2*time.Second).Should(BeTrue(), "StatefulSet was not ready within the timeout")
+}
+
+// getOrdinalPods returns the StatefulSet pods indexed by ordinal
+func getOrdinalPods(ctx context.Context, namespace, name string) []v1.Pod {
+	pods := make([]v1.Pod, statefulSetReplicas)
+	for i := range pods {
+		pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, fmt.Sprintf("%s-%d", name, i), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get pod with ordinal %d", i)
+		pods[i] = *pod
+	}
+	return pods
+}
+
+// readyTime returns when the pod last transitioned to Ready
+func readyTime(pod v1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	Fail(fmt.Sprintf("Pod %s is not Ready", pod.Name))
+	return time.Time{}
+}
+
+// rollingUpdate changes the pod template and waits for the rollout to finish
+func rollingUpdate(ctx context.Context, namespace, name string) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		sts.Spec.Template.Annotations = map[string]string{"e2e/restartedAt": time.Now().Format(time.RFC3339)}
+		_, err = framework.Clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{})
+		return err
+	})
+	Expect(err).NotTo(HaveOccurred(), "Failed to update StatefulSet template")
+
+	framework.Eventually(func() bool {
+		sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get StatefulSet")
+		return sts.Status.ObservedGeneration == sts.Generation &&
+			sts.Status.UpdateRevision == sts.Status.CurrentRevision &&
+			sts.Status.ReadyReplicas == statefulSetReplicas
+	}, 300*time.Second, 2*time.Second).Should(BeTrue(), "StatefulSet rolling update did not complete within the timeout")
+}
+
+// assertReverseOrdinalUpdate verifies the rolling update replaced pods from the highest ordinal down
+func assertReverseOrdinalUpdate(ctx context.Context, namespace, name string) {
+	pods := getOrdinalPods(ctx, namespace, name)
+	for i := len(pods) - 1; i > 0; i-- {
+		Expect(pods[i-1].CreationTimestamp.Time).NotTo(BeTemporally("<", readyTime(pods[i])),
+			"Pod %s was replaced before %s was Ready again", pods[i-1].Name, pods[i].Name)
+	}
+}
@@ -0,0 +1,243 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Name of the volumeClaimTemplate; its PVCs are named <template>-<statefulset>-<ordinal>
+const claimTemplate = "data"
+
+// Where the pods mount their PVC
+const dataMountPath = "/data"
+
+// Storage each PVC of the volumeClaimTemplate requests
+const claimSize = "1Gi"
+
+var _ = Describe("StatefulSet volumeClaimTemplates", func() {
+	var namespace string
+	var statefulSetName string
+	var storageClass string
+
+	BeforeEach(func(ctx SpecContext) {
+		storageClass = framework.RequireTestStorageClass(ctx).Name
+
+		namespace = framework.TestNamespace()
+		statefulSetName = fmt.Sprintf("test-statefulset-vct-%d", time.Now().UnixNano())
+
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      statefulSetName,
+				Namespace: namespace,
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: v1.ClusterIPNone,
+				Selector:  map[string]string{"app": statefulSetName},
+				Ports:     []v1.ServicePort{{Name: "placeholder", Port: 80}},
+			},
+		}
+		_, err := framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create headless service")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.AppsV1().StatefulSets(namespace), statefulSetName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete StatefulSet")
+
+		// PVCs created from the templates outlive the StatefulSet unless a retention policy deletes them
+		err = framework.CleanupCollection(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace),
+			metav1.ListOptions{LabelSelector: "app=" + statefulSetName})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVCs")
+
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), statefulSetName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete headless service")
+	})
+
+	It("should reattach each ordinal to its own PVC with its data when pods are recreated", func(ctx SpecContext) {
+		createStatefulSetWithClaims(ctx, namespace, statefulSetName, storageClass, nil)
+		waitForReadyReplicas(ctx, namespace, statefulSetName, statefulSetReplicas)
+
+		By("writing per-ordinal data")
+		claims := map[string]types.UID{}
+		for _, pod := range getOrdinalPods(ctx, namespace, statefulSetName) {
+			_, err := framework.ExecInPod(ctx, namespace, pod.Name, "", "sh", "-c", fmt.Sprintf("echo %s > %s/ordinal", pod.Name, dataMountPath))
+			Expect(err).NotTo(HaveOccurred(), "Failed to write data from pod %s", pod.Name)
+			claimName := claimTemplate + "-" + pod.Name
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "PVC %s was not created from the template", claimName)
+			claims[pod.Name] = pvc.UID
+		}
+
+		By("deleting every pod")
+		oldPods := map[string]types.UID{}
+		for _, pod := range getOrdinalPods(ctx, namespace, statefulSetName) {
+			oldPods[pod.Name] = pod.UID
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), pod.Name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete pod %s", pod.Name)
+		}
+		framework.Eventually(func() (int, error) {
+			recreated := 0
+			for name, uid := range oldPods {
+				pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err == nil && pod.UID != uid && pod.Status.Phase == v1.PodRunning {
+					recreated++
+				}
+			}
+			return recreated, nil
+		}, 5*time.Minute, 2*time.Second).Should(Equal(statefulSetReplicas), "Deleted pods were not recreated")
+		waitForReadyReplicas(ctx, namespace, statefulSetName, statefulSetReplicas)
+
+		By("checking every ordinal got its own PVC and data back")
+		for _, pod := range getOrdinalPods(ctx, namespace, statefulSetName) {
+			claimName := claimTemplate + "-" + pod.Name
+			Expect(pod.Spec.Volumes).To(ContainElement(HaveField("VolumeSource.PersistentVolumeClaim.ClaimName", claimName)),
+				"Pod %s is not using its own PVC", pod.Name)
+			pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claimName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get PVC %s", claimName)
+			Expect(pvc.UID).To(Equal(claims[pod.Name]), "PVC %s was recreated instead of reattached", claimName)
+
+			result, err := framework.ExecInPod(ctx, namespace, pod.Name, "", "cat", dataMountPath+"/ordinal")
+			Expect(err).NotTo(HaveOccurred(), "Failed to read data from pod %s", pod.Name)
+			Expect(strings.TrimSpace(result.Stdout)).To(Equal(pod.Name), "Pod %s did not get its own data back", pod.Name)
+		}
+	})
+
+	// persistentVolumeClaimRetentionPolicy is beta from Kubernetes 1.27, and dropped by API servers
+	// that do not enable the StatefulSetAutoDeletePVC feature
+	Context("with a PVC retention policy", func() {
+		// createWithRetention creates the StatefulSet, skipping the spec if the API server dropped the policy
+		createWithRetention := func(ctx context.Context, whenScaled, whenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType) {
+			createStatefulSetWithClaims(ctx, namespace, statefulSetName, storageClass, &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenScaled:  whenScaled,
+				WhenDeleted: whenDeleted,
+			})
+			sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, statefulSetName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to get StatefulSet")
+			if sts.Spec.PersistentVolumeClaimRetentionPolicy == nil {
+				Skip("API server does not support persistentVolumeClaimRetentionPolicy")
+			}
+			waitForReadyReplicas(ctx, namespace, statefulSetName, statefulSetReplicas)
+		}
+
+		// existingClaims returns which ordinals still have their PVC
+		existingClaims := func(ctx context.Context) ([]int, error) {
+			var ordinals []int
+			for i := 0; i < statefulSetReplicas; i++ {
+				_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx,
+					fmt.Sprintf("%s-%s-%d", claimTemplate, statefulSetName, i), metav1.GetOptions{})
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				ordinals = append(ordinals, i)
+			}
+			return ordinals, nil
+		}
+
+		It("should delete the PVCs of removed ordinals with WhenScaled Delete and keep them with WhenDeleted Retain", func(ctx SpecContext) {
+			createWithRetention(ctx, appsv1.DeletePersistentVolumeClaimRetentionPolicyType, appsv1.RetainPersistentVolumeClaimRetentionPolicyType)
+
+			scaleStatefulSet(ctx, namespace, statefulSetName, 1)
+			framework.Eventually(existingClaims, 3*time.Minute, 2*time.Second).Should(Equal([]int{0}), "PVCs of scaled-down ordinals were not deleted")
+
+			err := framework.Cleanup(ctx, framework.Clientset.AppsV1().StatefulSets(namespace), statefulSetName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete StatefulSet")
+			Consistently(existingClaims, 20*time.Second, 2*time.Second).Should(Equal([]int{0}), "PVC was deleted with the StatefulSet")
+		})
+
+		It("should keep the PVCs of removed ordinals with WhenScaled Retain and delete them all with WhenDeleted Delete", func(ctx SpecContext) {
+			createWithRetention(ctx, appsv1.RetainPersistentVolumeClaimRetentionPolicyType, appsv1.DeletePersistentVolumeClaimRetentionPolicyType)
+
+			scaleStatefulSet(ctx, namespace, statefulSetName, 1)
+			Consistently(existingClaims, 20*time.Second, 2*time.Second).Should(Equal([]int{0, 1, 2}), "PVCs of scaled-down ordinals were deleted")
+
+			err := framework.Cleanup(ctx, framework.Clientset.AppsV1().StatefulSets(namespace), statefulSetName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete StatefulSet")
+			framework.Eventually(existingClaims, 3*time.Minute, 2*time.Second).Should(BeEmpty(), "PVCs were not deleted with the StatefulSet")
+		})
+	})
+})
+
+// scaleStatefulSet sets the number of replicas and waits until the StatefulSet has that many ready pods
+func scaleStatefulSet(ctx context.Context, namespace, name string, replicas int32) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		sts.Spec.Replicas = &replicas
+		_, err = framework.Clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{})
+		return err
+	})
+	Expect(err).NotTo(HaveOccurred(), "Failed to scale StatefulSet")
+	framework.Eventually(func() (int32, error) {
+		sts, err := framework.Clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return sts.Status.Replicas, nil
+	}, 300*time.Second, 2*time.Second).Should(Equal(replicas), "StatefulSet did not scale within the timeout")
+}
+
+// createStatefulSetWithClaims creates a StatefulSet of statefulSetReplicas pods, each mounting its own PVC of
+// storageClass from a volumeClaimTemplate, with the given PVC retention policy if any
+func createStatefulSetWithClaims(ctx context.Context, namespace, name, storageClass string, retention *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy) {
+	replicas := int32(statefulSetReplicas)
+	labels := map[string]string{"app": name}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:                             &replicas,
+			ServiceName:                          name,
+			Selector:                             &metav1.LabelSelector{MatchLabels: labels},
+			PersistentVolumeClaimRetentionPolicy: retention,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         "alpine",
+						Image:        "alpine:3.20",
+						Command:      []string{"sleep", "3600"},
+						VolumeMounts: []v1.VolumeMount{{Name: claimTemplate, MountPath: dataMountPath}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: claimTemplate, Labels: labels},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					StorageClassName: &storageClass,
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(claimSize)},
+					},
+				},
+			}},
+		},
+	}
+
+	framework.Restrict(&statefulSet.Spec.Template.Spec)
+	framework.RequireClaimRoom(ctx, statefulSetReplicas, claimSize)
+	framework.RequirePodRoom(ctx, statefulSetReplicas, &statefulSet.Spec.Template.Spec)
+	_, err := framework.Clientset.AppsV1().StatefulSets(namespace).Create(ctx, statefulSet, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred(), "Failed to create StatefulSet")
+}
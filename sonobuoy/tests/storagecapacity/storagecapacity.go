@@ -0,0 +1,198 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Annotation the scheduler sets on a WaitForFirstConsumer PVC to ask for provisioning on a node
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// newClaim returns a PVC of storageClass requesting size
+func newClaim(namespace, name, storageClass string, size resource.Quantity) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: size},
+			},
+		},
+	}
+}
+
+// claimPod returns a long-running pod mounting the PVC
+func claimPod(namespace, name, pvcName string) *v1.Pod {
+	pod := framework.NewPod(namespace, name, podImage, "sleep", "3600")
+	pod.Spec.Volumes = []v1.Volume{{
+		Name: "data",
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: pvcName,
+		}},
+	}}
+	pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: "/data"}}
+	return pod
+}
+
+// capacitiesFor returns the CSIStorageCapacity objects the driver published for storageClass, in any namespace
+func capacitiesFor(ctx context.Context, storageClass string) []storagev1.CSIStorageCapacity {
+	GinkgoHelper()
+	list, err := framework.Clientset.StorageV1().CSIStorageCapacities(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	Expect(err).NotTo(HaveOccurred(), "Failed to list CSIStorageCapacity objects")
+	var capacities []storagev1.CSIStorageCapacity
+	for _, capacity := range list.Items {
+		if capacity.StorageClassName == storageClass {
+			capacities = append(capacities, capacity)
+		}
+	}
+	return capacities
+}
+
+// nodeCapacity returns the largest capacity published for a topology segment the node belongs to
+func nodeCapacity(capacities []storagev1.CSIStorageCapacity, node *v1.Node) *resource.Quantity {
+	GinkgoHelper()
+	var largest *resource.Quantity
+	for i, capacity := range capacities {
+		if capacity.Capacity == nil || capacity.NodeTopology == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(capacity.NodeTopology)
+		Expect(err).NotTo(HaveOccurred(), "CSIStorageCapacity %s has an invalid node topology", capacity.Name)
+		if selector.Matches(labels.Set(node.Labels)) && (largest == nil || capacity.Capacity.Cmp(*largest) > 0) {
+			largest = capacities[i].Capacity
+		}
+	}
+	return largest
+}
+
+// Runs against the StorageClass named by E2E_CAPACITY_STORAGE_CLASS. Capacity tracking only applies to
+// WaitForFirstConsumer classes, where the scheduler picks a node before the volume is provisioned.
+var _ = Describe("CSI storage capacity", func() {
+	var namespace string
+	var name string
+	var storageClass string
+
+	BeforeEach(func(ctx SpecContext) {
+		storageClass = framework.RequireCapacityStorageClass(ctx)
+
+		class, err := framework.Clientset.StorageV1().StorageClasses().Get(ctx, storageClass, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get StorageClass")
+		Expect(class.VolumeBindingMode).NotTo(BeNil(), "StorageClass %s has no volume binding mode", storageClass)
+		Expect(*class.VolumeBindingMode).To(Equal(storagev1.VolumeBindingWaitForFirstConsumer),
+			"E2E_CAPACITY_STORAGE_CLASS must name a WaitForFirstConsumer StorageClass")
+		driver, err := framework.Clientset.StorageV1().CSIDrivers().Get(ctx, class.Provisioner, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "StorageClass %s is not provisioned by a registered CSI driver", storageClass)
+		Expect(driver.Spec.StorageCapacity).To(HaveValue(BeTrue()), "CSI driver %s does not enable storage capacity tracking", driver.Name)
+
+		namespace = framework.TestNamespace()
+		name = fmt.Sprintf("test-storage-capacity-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+		err = framework.Cleanup(ctx, framework.Clientset.CoreV1().PersistentVolumeClaims(namespace), name)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete PVC")
+	})
+
+	It("should publish CSIStorageCapacity objects for the StorageClass", func(ctx SpecContext) {
+		var capacities []storagev1.CSIStorageCapacity
+		framework.Eventually(func() []storagev1.CSIStorageCapacity {
+			capacities = capacitiesFor(ctx, storageClass)
+			return capacities
+		}, 2*time.Minute, 5*time.Second).ShouldNot(BeEmpty(), "CSI driver published no capacity for StorageClass %s", storageClass)
+
+		available := false
+		for _, capacity := range capacities {
+			Expect(capacity.NodeTopology).NotTo(BeNil(), "CSIStorageCapacity %s/%s has no node topology", capacity.Namespace, capacity.Name)
+			if capacity.Capacity != nil && capacity.Capacity.Sign() > 0 {
+				available = true
+			}
+			AddReportEntry("CSIStorageCapacity", fmt.Sprintf("%s/%s: capacity %v for %s",
+				capacity.Namespace, capacity.Name, capacity.Capacity, metav1.FormatLabelSelector(capacity.NodeTopology)))
+		}
+		Expect(available).To(BeTrue(), "No topology segment has capacity left for StorageClass %s", storageClass)
+	})
+
+	It("should bind a WaitForFirstConsumer PVC only once its pod is scheduled to a node with capacity", func(ctx SpecContext) {
+		size := resource.MustParse("1Gi")
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, size), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create PVC")
+
+		By("checking the PVC waits for a consumer")
+		Consistently(func() (*v1.PersistentVolumeClaim, error) {
+			return framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		}, 10*time.Second, 2*time.Second).Should(And(
+			HaveField("Status.Phase", v1.ClaimPending),
+			HaveField("Annotations", Not(HaveKey(selectedNodeAnnotation))),
+		), "PVC was bound before any pod used it")
+
+		By("scheduling a pod using the PVC")
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, name, name), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+		pod, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 5*time.Minute)
+		Expect(err).NotTo(HaveOccurred(), "Pod using the PVC did not start")
+
+		pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PVC")
+		Expect(pvc.Status.Phase).To(Equal(v1.ClaimBound), "PVC was not bound once its pod was scheduled")
+		Expect(pvc.Annotations).To(HaveKeyWithValue(selectedNodeAnnotation, pod.Spec.NodeName), "PVC was provisioned for another node than its pod's")
+
+		node, err := framework.Clientset.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get node")
+		capacity := nodeCapacity(capacitiesFor(ctx, storageClass), node)
+		Expect(capacity).NotTo(BeNil(), "Pod was scheduled to node %s, for which no capacity is published", node.Name)
+		AddReportEntry("Selected node capacity", fmt.Sprintf("%s: %v", node.Name, capacity))
+	})
+
+	It("should not schedule a pod whose PVC exceeds the capacity of every node", func(ctx SpecContext) {
+		var largest *resource.Quantity
+		for _, capacity := range capacitiesFor(ctx, storageClass) {
+			if capacity.Capacity != nil && (largest == nil || capacity.Capacity.Cmp(*largest) > 0) {
+				largest = capacity.Capacity
+			}
+		}
+		if largest == nil {
+			Skip(fmt.Sprintf("No capacity is published for StorageClass %s", storageClass))
+		}
+		// Twice the largest capacity cannot fit anywhere
+		size := largest.DeepCopy()
+		size.Add(*largest)
+		_, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newClaim(namespace, name, storageClass, size), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create oversized PVC")
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, claimPod(namespace, name, name), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		framework.Eventually(func() (string, error) {
+			pod, err := framework.Clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+					return condition.Message, nil
+				}
+			}
+			return "", nil
+		}, 60*time.Second, 2*time.Second).Should(ContainSubstring("did not have enough free storage"),
+			"Scheduler did not hold back a pod whose PVC fits no node")
+		pvc, err := framework.Clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get PVC")
+		Expect(pvc.Status.Phase).To(Equal(v1.ClaimPending), "Oversized PVC was bound")
+	})
+})
@@ -0,0 +1,189 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Node directory the propagation specs mount under. It lives below /var/lib/kubelet, which the kubelet
+// requires to be a shared mount, so mounts made inside it can propagate.
+const propagationHostDir = "/var/lib/kubelet/e2e-sonobuoy-propagation"
+
+// Where pods mount propagationHostDir
+const propagationMountPath = "/mnt/propagation"
+
+func boolPtr(b bool) *bool { return &b }
+
+var _ = Describe("subPath volume mounts", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-subpath-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should mount only the selected directory of a volume", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", "ls /selected && cat /selected/file")
+		pod.Spec.Volumes = []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+		pod.Spec.InitContainers = []v1.Container{{
+			Name:         "prepare",
+			Image:        podImage,
+			Command:      []string{"sh", "-c", "mkdir /data/a /data/b && echo from-a > /data/a/file && echo from-b > /data/b/other"},
+			VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "data", MountPath: "/selected", SubPath: "a"}}
+		framework.Restrict(&pod.Spec)
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		Expect(strings.Fields(output)).To(Equal([]string{"file", "from-a"}), "subPath mount does not hold exactly the selected directory")
+	})
+
+	It("should mount a single ConfigMap key as a file without hiding the directory", func(ctx SpecContext) {
+		_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace},
+			Data:       map[string]string{"app.conf": "greeting=hello", "unused.conf": "unused"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), podName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+		})
+
+		// /etc/alpine-release comes with the image and must stay visible next to the mounted file
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c",
+			"cat /etc/app.conf && test -f /etc/alpine-release && test ! -e /etc/unused.conf && echo untouched")
+		pod.Spec.Volumes = []v1.Volume{{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: podName},
+		}}}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "config", MountPath: "/etc/app.conf", SubPath: "app.conf"}}
+		_, err = framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		Expect(strings.Fields(output)).To(Equal([]string{"greeting=hello", "untouched"}))
+	})
+
+	It("should expand environment variables in subPathExpr", func(ctx SpecContext) {
+		pod := framework.NewPod(namespace, podName, podImage, "sh", "-c", "cat /all/"+podName+"/file")
+		pod.Spec.Volumes = []v1.Volume{{Name: "logs", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}}
+		pod.Spec.InitContainers = []v1.Container{{
+			Name:    "writer",
+			Image:   podImage,
+			Command: []string{"sh", "-c", "echo written-by-$POD_NAME > /logs/file"},
+			Env: []v1.EnvVar{{
+				Name:      "POD_NAME",
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+			}},
+			VolumeMounts: []v1.VolumeMount{{Name: "logs", MountPath: "/logs", SubPathExpr: "$(POD_NAME)"}},
+		}}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "logs", MountPath: "/all"}}
+		framework.Restrict(&pod.Spec)
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		Expect(strings.TrimSpace(output)).To(Equal("written-by-"+podName), "subPathExpr did not expand to the pod name")
+	})
+})
+
+// Three pods on one node mount the same node directory, each with a different propagation mode, and mount
+// a tmpfs below it. Which tmpfs mounts each pod can see shows how mounts propagate between them and the host.
+// Mounting requires CAP_SYS_ADMIN, so the pods are privileged.
+var _ = Describe("Mount propagation", Label(framework.LabelPrivileged), func() {
+	var namespace string
+	var runDir string
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.CreateNamespace(ctx, "test-propagation", framework.PodSecurityPrivileged)
+		runDir = namespace
+	})
+
+	// propagationPod returns a privileged pod that mounts a tmpfs named after itself and keeps running
+	propagationPod := func(name string, mode v1.MountPropagationMode, nodeName string) *v1.Pod {
+		mountPoint := fmt.Sprintf("%s/%s/%s", propagationMountPath, runDir, name)
+		pod := framework.NewPod(namespace, name, podImage, "sh", "-c",
+			fmt.Sprintf("mkdir -p %[1]s && mount -t tmpfs tmpfs %[1]s && echo %[2]s > %[1]s/file && sleep 3600", mountPoint, name))
+		framework.Unrestricted(&pod.Spec)
+		pod.Spec.NodeName = nodeName
+		hostPathType := v1.HostPathDirectoryOrCreate
+		pod.Spec.Volumes = []v1.Volume{{Name: "host", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{
+			Path: propagationHostDir,
+			Type: &hostPathType,
+		}}}}
+		pod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{Privileged: boolPtr(true)}
+		pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "host", MountPath: propagationMountPath, MountPropagation: &mode}}
+		return pod
+	}
+
+	// sees reports whether the tmpfs mounted by owner is visible inside pod
+	sees := func(ctx context.Context, pod, owner string) bool {
+		_, err := framework.ExecInPod(ctx, namespace, pod, "", "cat", fmt.Sprintf("%s/%s/%s/file", propagationMountPath, runDir, owner))
+		return err == nil
+	}
+
+	It("should propagate mounts according to HostToContainer and Bidirectional", func(ctx SpecContext) {
+		By("starting the Bidirectional pod")
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, propagationPod("bidirectional", v1.MountPropagationBidirectional, ""), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create Bidirectional pod")
+		master, err := framework.WaitForPodRunning(ctx, framework.Clientset, namespace, "bidirectional", 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Bidirectional pod did not start")
+		// Unmount the tmpfs it propagated to the node before the namespace goes away
+		DeferCleanup(func(ctx SpecContext) {
+			_, err := framework.ExecInPod(ctx, namespace, "bidirectional", "", "sh", "-c",
+				fmt.Sprintf("umount %[1]s/%[2]s/bidirectional; rm -rf %[1]s/%[2]s", propagationMountPath, runDir))
+			Expect(err).NotTo(HaveOccurred(), "Failed to unmount the propagated tmpfs from the node")
+		})
+
+		By("starting the HostToContainer and None pods on the same node")
+		for name, mode := range map[string]v1.MountPropagationMode{
+			"hosttocontainer": v1.MountPropagationHostToContainer,
+			"none":            v1.MountPropagationNone,
+		} {
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, propagationPod(name, mode, master.Spec.NodeName), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create %s pod", name)
+			_, err = framework.WaitForPodRunning(ctx, framework.Clientset, namespace, name, 120*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "%s pod did not start", name)
+		}
+
+		By("checking every pod sees its own mount")
+		for _, name := range []string{"bidirectional", "hosttocontainer", "none"} {
+			framework.Eventually(sees, 60*time.Second, 2*time.Second).WithArguments(name, name).Should(BeTrue(), "%s pod does not see its own mount", name)
+		}
+
+		By("checking the Bidirectional mount reached the node and the HostToContainer pod only")
+		framework.Eventually(sees, 60*time.Second, 2*time.Second).WithArguments("hosttocontainer", "bidirectional").Should(BeTrue(),
+			"Bidirectional mount did not propagate to the HostToContainer pod")
+		Expect(sees(ctx, "none", "bidirectional")).To(BeFalse(), "Bidirectional mount propagated to the None pod")
+
+		By("checking mounts made by the other pods stay private")
+		for _, name := range []string{"bidirectional", "none"} {
+			Expect(sees(ctx, name, "hosttocontainer")).To(BeFalse(), "HostToContainer mount propagated to the %s pod", name)
+		}
+		for _, name := range []string{"bidirectional", "hosttocontainer"} {
+			Expect(sees(ctx, name, "none")).To(BeFalse(), "None mount propagated to the %s pod", name)
+		}
+	})
+})
@@ -0,0 +1,104 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const podImage = "alpine:3.20"
+
+// Reason the kubelet fails a pod with when it sets a sysctl the node does not allow
+const sysctlForbiddenReason = "SysctlForbidden"
+
+// sysctlPath returns where a sysctl is exposed under /proc/sys, e.g. net/ipv4/ip_local_port_range
+func sysctlPath(name string) string {
+	return "/proc/sys/" + strings.ReplaceAll(name, ".", "/")
+}
+
+// readSysctlsPod returns a pod printing the given sysctls, one per line, after setting them in its securityContext
+func readSysctlsPod(namespace, name string, sysctls ...v1.Sysctl) *v1.Pod {
+	var script []string
+	for _, sysctl := range sysctls {
+		script = append(script, "cat "+sysctlPath(sysctl.Name))
+	}
+	pod := framework.NewPod(namespace, name, podImage, "sh", "-c", strings.Join(script, "; "))
+	pod.Spec.SecurityContext.Sysctls = sysctls
+	return pod
+}
+
+// Safe sysctls are namespaced and isolated from other pods, so every kubelet allows them and the restricted
+// Pod Security Standard admits them. Anything else is unsafe and needs --allowed-unsafe-sysctls on the kubelet.
+var _ = Describe("Sysctls", func() {
+	var namespace string
+	var podName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		podName = fmt.Sprintf("test-sysctls-%d", time.Now().UnixNano())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Pods(namespace), podName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete pod")
+	})
+
+	It("should apply safe sysctls inside the container", func(ctx SpecContext) {
+		sysctls := []v1.Sysctl{
+			{Name: "net.ipv4.ip_local_port_range", Value: "20000 40000"},
+			{Name: "kernel.shm_rmid_forced", Value: "1"},
+		}
+		_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, readSysctlsPod(namespace, podName, sysctls...), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create pod with safe sysctls")
+
+		output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		Expect(lines).To(HaveLen(len(sysctls)), "Unexpected output: %q", output)
+		for i, sysctl := range sysctls {
+			// Multi-value sysctls are printed tab-separated
+			Expect(strings.Fields(lines[i])).To(Equal(strings.Fields(sysctl.Value)), "Sysctl %s was not applied", sysctl.Name)
+		}
+	})
+
+	// Pod Security Admission rejects unsafe sysctls from baseline up, so the spec runs in a namespace that
+	// allows them in order to reach the kubelet
+	Context("that are unsafe", Label(framework.LabelPrivileged), func() {
+		BeforeEach(func(ctx SpecContext) {
+			namespace = framework.CreateNamespace(ctx, "test-sysctls", framework.PodSecurityPrivileged)
+		})
+
+		It("should be rejected by the kubelet unless it allows them", func(ctx SpecContext) {
+			sysctl := v1.Sysctl{Name: "net.core.somaxconn", Value: "1024"}
+			_, err := framework.Clientset.CoreV1().Pods(namespace).Create(ctx, readSysctlsPod(namespace, podName, sysctl), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred(), "Failed to create pod with an unsafe sysctl")
+
+			var pod *v1.Pod
+			framework.Eventually(func() (v1.PodPhase, error) {
+				pod, err = framework.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+				if err != nil {
+					return "", err
+				}
+				return pod.Status.Phase, nil
+			}, 120*time.Second, 2*time.Second).Should(BeElementOf(v1.PodFailed, v1.PodSucceeded), "Pod never completed nor was rejected")
+
+			if pod.Status.Phase == v1.PodFailed {
+				Expect(pod.Status.Reason).To(Equal(sysctlForbiddenReason), "Pod failed for another reason: %s", pod.Status.Message)
+				return
+			}
+			// The node was configured with --allowed-unsafe-sysctls, so the sysctl must have been applied
+			AddReportEntry("Unsafe sysctl allowed", fmt.Sprintf("%s is allowed by the kubelet on node %s", sysctl.Name, pod.Spec.NodeName))
+			output, err := framework.WaitForPodOutput(ctx, framework.Clientset, namespace, podName, 120*time.Second)
+			Expect(err).NotTo(HaveOccurred(), "Pod did not complete")
+			Expect(strings.TrimSpace(output)).To(Equal(sysctl.Value), "Allowed unsafe sysctl was not applied")
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package e2e
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+// Shortest expiry the API server accepts for a TokenRequest
+const tokenExpirationSeconds = 600
+
+var _ = Describe("TokenRequest and TokenReview", func() {
+	var namespace string
+	var serviceAccountName string
+	var audience string
+	var serviceAccount *v1.ServiceAccount
+
+	// requestToken issues a bound token for the ServiceAccount through the token subresource
+	requestToken := func(ctx context.Context) *authenticationv1.TokenRequest {
+		expirationSeconds := int64(tokenExpirationSeconds)
+		tokenRequest, err := framework.Clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccountName, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				Audiences:         []string{audience},
+				ExpirationSeconds: &expirationSeconds,
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to request token")
+		Expect(tokenRequest.Status.Token).NotTo(BeEmpty(), "TokenRequest returned an empty token")
+		return tokenRequest
+	}
+
+	// reviewToken asks the API server to authenticate the token for the given audiences
+	reviewToken := func(ctx context.Context, token string, audiences ...string) authenticationv1.TokenReviewStatus {
+		review, err := framework.Clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{
+				Token:     token,
+				Audiences: audiences,
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create TokenReview")
+		return review.Status
+	}
+
+	BeforeEach(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		serviceAccountName = fmt.Sprintf("test-token-%d", time.Now().UnixNano())
+		audience = fmt.Sprintf("e2e-audience-%d", time.Now().UnixNano())
+
+		var err error
+		serviceAccount, err = framework.Clientset.CoreV1().ServiceAccounts(namespace).Create(ctx, &v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceAccountName,
+				Namespace: namespace,
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ServiceAccount")
+	})
+
+	It("should issue a token with the requested audience and expiry", func(ctx SpecContext) {
+		requested := time.Now()
+		tokenRequest := requestToken(ctx)
+		Expect(tokenRequest.Status.ExpirationTimestamp.Time).To(
+			BeTemporally("~", requested.Add(tokenExpirationSeconds*time.Second), 30*time.Second),
+			"Token expiry does not match the requested expirationSeconds")
+
+		// The token is a JWT; its claims must carry the audience and expiry
+		parts := strings.Split(tokenRequest.Status.Token, ".")
+		Expect(parts).To(HaveLen(3), "Token is not a JWT")
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		Expect(err).NotTo(HaveOccurred(), "Failed to decode token claims")
+		var claims struct {
+			Audience []string `json:"aud"`
+			Expiry   int64    `json:"exp"`
+			Subject  string   `json:"sub"`
+		}
+		Expect(json.Unmarshal(payload, &claims)).To(Succeed(), "Failed to parse token claims")
+		Expect(claims.Audience).To(ConsistOf(audience))
+		Expect(claims.Expiry).To(Equal(tokenRequest.Status.ExpirationTimestamp.Unix()))
+		Expect(claims.Subject).To(Equal(fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName)))
+	})
+
+	It("should authenticate the token as the ServiceAccount with TokenReview", func(ctx SpecContext) {
+		tokenRequest := requestToken(ctx)
+
+		status := reviewToken(ctx, tokenRequest.Status.Token, audience)
+		Expect(status.Error).To(BeEmpty())
+		Expect(status.Authenticated).To(BeTrue(), "Token was not authenticated")
+		Expect(status.Audiences).To(ConsistOf(audience))
+		Expect(status.User.Username).To(Equal(fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName)))
+		Expect(status.User.UID).To(Equal(string(serviceAccount.UID)))
+		Expect(status.User.Groups).To(ContainElements(
+			"system:serviceaccounts",
+			"system:serviceaccounts:"+namespace,
+			"system:authenticated",
+		))
+	})
+
+	It("should reject the token for a different audience", func(ctx SpecContext) {
+		tokenRequest := requestToken(ctx)
+
+		status := reviewToken(ctx, tokenRequest.Status.Token, audience+"-other")
+		Expect(status.Authenticated).To(BeFalse(), "Token was accepted for an audience it was not issued for")
+
+		// Without explicit audiences the review uses the API server's own audiences
+		status = reviewToken(ctx, tokenRequest.Status.Token)
+		Expect(status.Authenticated).To(BeFalse(), "Token was accepted by the API server audience")
+	})
+
+	It("should reject the token once the ServiceAccount is deleted", func(ctx SpecContext) {
+		tokenRequest := requestToken(ctx)
+		Expect(reviewToken(ctx, tokenRequest.Status.Token, audience).Authenticated).To(BeTrue(), "Token was not authenticated")
+
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ServiceAccounts(namespace), serviceAccountName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ServiceAccount")
+
+		// Bound tokens are invalidated with the object they are bound to
+		framework.Eventually(func() bool {
+			return reviewToken(ctx, tokenRequest.Status.Token, audience).Authenticated
+		}, 60*time.Second, 2*time.Second).Should(BeFalse(), "Token remained valid after the ServiceAccount was deleted")
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ServiceAccounts(namespace), serviceAccountName)
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ServiceAccount")
+	})
+})
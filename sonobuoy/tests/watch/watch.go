@@ -0,0 +1,152 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+var _ = Describe("Watch Semantics", func() {
+	var namespace string
+	var configMapName string
+
+	BeforeEach(func() {
+		namespace = framework.TestNamespace()
+		configMapName = fmt.Sprintf("test-watch-%d", time.Now().UnixNano())
+	})
+
+	// watchConfigMap opens a watch scoped to the spec's ConfigMap, starting at the given resourceVersion
+	watchConfigMap := func(ctx context.Context, resourceVersion string, bookmarks bool) watch.Interface {
+		w, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:       fields.OneTermEqualSelector("metadata.name", configMapName).String(),
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: bookmarks,
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to start watch")
+		DeferCleanup(w.Stop)
+		return w
+	}
+
+	// listResourceVersion returns the current resourceVersion of the ConfigMap collection
+	listResourceVersion := func(ctx context.Context) string {
+		list, err := framework.Clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", configMapName).String(),
+		})
+		Expect(err).NotTo(HaveOccurred(), "Failed to list ConfigMaps")
+		return list.ResourceVersion
+	}
+
+	// expectEvent waits for the next event on the watch and checks its type
+	expectEvent := func(w watch.Interface, eventType watch.EventType) *v1.ConfigMap {
+		var event watch.Event
+		framework.Eventually(w.ResultChan(), 30*time.Second).Should(Receive(&event), "Timed out waiting for %s event", eventType)
+		Expect(event.Type).To(Equal(eventType), "Unexpected watch event: %#v", event.Object)
+		configMap, ok := event.Object.(*v1.ConfigMap)
+		Expect(ok).To(BeTrue(), "Watch event did not carry a ConfigMap")
+		return configMap
+	}
+
+	updateConfigMap := func(ctx context.Context, value string) *v1.ConfigMap {
+		configMap, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get ConfigMap")
+		configMap.Data["config-key"] = value
+		configMap, err = framework.Clientset.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update ConfigMap")
+		return configMap
+	}
+
+	createConfigMap := func(ctx context.Context) {
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      configMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				"config-key": "config-value",
+			},
+		}
+		_, err := framework.Clientset.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ConfigMap")
+		// Specs that delete the ConfigMap themselves may fail first; Cleanup ignores it being gone
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(namespace), configMapName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+		})
+	}
+
+	deleteConfigMap := func(ctx context.Context) {
+		err := framework.Clientset.CoreV1().ConfigMaps(namespace).Delete(ctx, configMapName, metav1.DeleteOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+	}
+
+	It("should deliver ADDED, MODIFIED and DELETED events in order", func(ctx SpecContext) {
+		w := watchConfigMap(ctx, listResourceVersion(ctx), false)
+
+		createConfigMap(ctx)
+		added := expectEvent(w, watch.Added)
+		Expect(added.Data["config-key"]).To(Equal("config-value"))
+
+		updated := updateConfigMap(ctx, "updated-value")
+		modified := expectEvent(w, watch.Modified)
+		Expect(modified.Data["config-key"]).To(Equal("updated-value"))
+		Expect(modified.ResourceVersion).To(Equal(updated.ResourceVersion))
+
+		deleteConfigMap(ctx)
+		deleted := expectEvent(w, watch.Deleted)
+		Expect(deleted.UID).To(Equal(added.UID), "DELETED event refers to a different object")
+
+		Consistently(w.ResultChan(), 5*time.Second).ShouldNot(Receive(), "Unexpected extra watch events")
+	})
+
+	It("should send bookmark events when allowWatchBookmarks is set", func(ctx SpecContext) {
+		createConfigMap(ctx)
+
+		w := watchConfigMap(ctx, listResourceVersion(ctx), true)
+
+		// Bookmarks are sent periodically by the watch cache, roughly once a minute
+		var bookmark *v1.ConfigMap
+		timeout := time.After(2 * time.Minute)
+		for bookmark == nil {
+			select {
+			case event, ok := <-w.ResultChan():
+				Expect(ok).To(BeTrue(), "Watch closed before a bookmark was received")
+				if event.Type == watch.Bookmark {
+					bookmark, ok = event.Object.(*v1.ConfigMap)
+					Expect(ok).To(BeTrue(), "Bookmark did not carry a ConfigMap")
+				}
+			case <-timeout:
+				Fail("No bookmark event was received within the timeout")
+			}
+		}
+		Expect(bookmark.ResourceVersion).NotTo(BeEmpty(), "Bookmark carried no resourceVersion")
+	})
+
+	It("should resume from a resourceVersion after the watch is interrupted", func(ctx SpecContext) {
+		w := watchConfigMap(ctx, listResourceVersion(ctx), false)
+
+		createConfigMap(ctx)
+		expectEvent(w, watch.Added)
+		checkpoint := updateConfigMap(ctx, "first-update")
+		expectEvent(w, watch.Modified)
+
+		// Simulate an interruption and keep mutating while nobody is watching
+		w.Stop()
+		updateConfigMap(ctx, "missed-update")
+		deleteConfigMap(ctx)
+
+		resumed := watchConfigMap(ctx, checkpoint.ResourceVersion, false)
+		missed := expectEvent(resumed, watch.Modified)
+		Expect(missed.Data["config-key"]).To(Equal("missed-update"), "Resumed watch replayed the wrong update")
+		expectEvent(resumed, watch.Deleted)
+	})
+})
@@ -0,0 +1,257 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/farazkhawaja/sonobuoy-e2e/sonobuoy/framework"
+)
+
+const (
+	// agnhost's webhook server denies ConfigMaps holding this key and value on its /configmaps path
+	webhookImage      = "registry.k8s.io/e2e-test-images/agnhost:2.43"
+	webhookPort       = int32(8444)
+	disallowedKey     = "webhook-e2e-test"
+	disallowedValue   = "webhook-disallow"
+	webhookCertPath   = "/webhook.local.config/certificates"
+	webhookNameSuffix = ".e2e.sonobuoy.io"
+)
+
+// Admission webhooks let cluster operators enforce policy, so a broken webhook path blocks whole workloads.
+// The webhook server runs once for the container; each spec registers its own configuration, scoped by
+// namespaceSelector to a dedicated namespace so the rest of the cluster is never affected.
+var _ = Describe("Validating Admission Webhooks", Ordered, Label(framework.LabelDisruptive), func() {
+	var namespace string
+	var serviceName string
+	var targetNamespace string
+	var selectorValue string
+	var caBundle []byte
+
+	BeforeAll(func(ctx SpecContext) {
+		namespace = framework.TestNamespace()
+		suffix := time.Now().UnixNano()
+		serviceName = fmt.Sprintf("test-webhook-%d", suffix)
+		selectorValue = fmt.Sprintf("%d", suffix)
+
+		cert, err := framework.GenerateServingCertificate(time.Hour, framework.ServiceDNSNames(namespace, serviceName)...)
+		Expect(err).NotTo(HaveOccurred(), "Failed to generate webhook serving certificate")
+		caBundle = cert.CACert
+
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				v1.TLSCertKey:       cert.Cert,
+				v1.TLSPrivateKeyKey: cert.Key,
+			},
+		}
+		_, err = framework.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create webhook certificate Secret")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Secrets(namespace), serviceName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Secret")
+		})
+
+		deployment := framework.NewDeployment(namespace, serviceName, webhookImage, 1)
+		podSpec := &deployment.Spec.Template.Spec
+		podSpec.Containers[0].Args = []string{
+			"webhook",
+			"--tls-cert-file=" + webhookCertPath + "/" + v1.TLSCertKey,
+			"--tls-private-key-file=" + webhookCertPath + "/" + v1.TLSPrivateKeyKey,
+			fmt.Sprintf("--port=%d", webhookPort),
+		}
+		podSpec.Containers[0].Ports = []v1.ContainerPort{{ContainerPort: webhookPort}}
+		podSpec.Containers[0].ReadinessProbe = &v1.Probe{
+			ProbeHandler: v1.ProbeHandler{
+				HTTPGet: &v1.HTTPGetAction{
+					Path:   "/readyz",
+					Port:   intstr.FromInt32(webhookPort),
+					Scheme: v1.URISchemeHTTPS,
+				},
+			},
+			PeriodSeconds: 2,
+		}
+		podSpec.Containers[0].VolumeMounts = []v1.VolumeMount{{Name: "certs", MountPath: webhookCertPath, ReadOnly: true}}
+		podSpec.Volumes = []v1.Volume{{
+			Name:         "certs",
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: serviceName}},
+		}}
+		_, err = framework.Clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create webhook Deployment")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.AppsV1().Deployments(namespace), serviceName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Deployment")
+		})
+
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName,
+				Namespace: namespace,
+			},
+			Spec: v1.ServiceSpec{
+				Selector: deployment.Spec.Selector.MatchLabels,
+				Ports: []v1.ServicePort{{
+					Port:       443,
+					TargetPort: intstr.FromInt32(webhookPort),
+				}},
+			},
+		}
+		_, err = framework.Clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create webhook Service")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Services(namespace), serviceName)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete Service")
+		})
+
+		_, err = framework.WaitForRolloutComplete(ctx, framework.Clientset, namespace, serviceName, 180*time.Second)
+		Expect(err).NotTo(HaveOccurred(), "Webhook server did not become ready")
+
+		// Only this namespace carries the label the webhooks select on
+		targetNamespace = fmt.Sprintf("test-webhook-target-%d", suffix)
+		_, err = framework.Clientset.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   targetNamespace,
+				Labels: map[string]string{"e2e-webhook": selectorValue},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create target namespace")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.CoreV1().Namespaces(), targetNamespace)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete target namespace")
+		})
+	})
+
+	// registerWebhook creates a ValidatingWebhookConfiguration for ConfigMaps in the target namespace
+	// that calls path on the given service, and removes it when the spec ends
+	registerWebhook := func(ctx context.Context, service, path string, failurePolicy admissionregistrationv1.FailurePolicyType) string {
+		name := fmt.Sprintf("test-webhook-%d", time.Now().UnixNano())
+		sideEffects := admissionregistrationv1.SideEffectClassNone
+		port := int32(443)
+		timeout := int32(10)
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name: name + webhookNameSuffix,
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{
+						Namespace: namespace,
+						Name:      service,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Operations: []admissionregistrationv1.OperationType{
+						admissionregistrationv1.Create,
+						admissionregistrationv1.Update,
+					},
+					Rule: admissionregistrationv1.Rule{
+						APIGroups:   []string{""},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"configmaps"},
+					},
+				}},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"e2e-webhook": selectorValue},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+				TimeoutSeconds:          &timeout,
+			}},
+		}
+		_, err := framework.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(ctx, config, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to create ValidatingWebhookConfiguration")
+		DeferCleanup(func(ctx SpecContext) {
+			err := framework.Cleanup(ctx, framework.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations(), name)
+			Expect(err).NotTo(HaveOccurred(), "Failed to delete ValidatingWebhookConfiguration")
+		})
+		return name
+	}
+
+	// createConfigMap creates a ConfigMap that the webhook server allows, or denies when disallowed is set
+	createConfigMap := func(ctx context.Context, ns string, disallowed bool) error {
+		value := "webhook-allow"
+		if disallowed {
+			value = disallowedValue
+		}
+		cm := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-webhook-cm-%d", time.Now().UnixNano()),
+				Namespace: ns,
+			},
+			Data: map[string]string{disallowedKey: value},
+		}
+		_, err := framework.Clientset.CoreV1().ConfigMaps(ns).Create(ctx, cm, metav1.CreateOptions{})
+		if err == nil && ns != targetNamespace {
+			// The target namespace is deleted as a whole, anything else is cleaned up per object
+			DeferCleanup(func(ctx SpecContext) {
+				err := framework.Cleanup(ctx, framework.Clientset.CoreV1().ConfigMaps(ns), cm.Name)
+				Expect(err).NotTo(HaveOccurred(), "Failed to delete ConfigMap")
+			})
+		}
+		return err
+	}
+
+	// waitForDenial waits until the API server has picked up a new configuration and rejects a disallowed ConfigMap
+	waitForDenial := func(ctx context.Context, message string) {
+		framework.Eventually(func() error {
+			return createConfigMap(ctx, targetNamespace, true)
+		}, 120*time.Second, 2*time.Second).Should(MatchError(ContainSubstring(message)), "Webhook configuration did not take effect")
+	}
+
+	It("should reject objects the webhook denies and admit the rest", func(ctx SpecContext) {
+		name := registerWebhook(ctx, serviceName, "/configmaps", admissionregistrationv1.Fail)
+		waitForDenial(ctx, "denied the request")
+
+		err := createConfigMap(ctx, targetNamespace, true)
+		Expect(err).To(HaveOccurred(), "Disallowed ConfigMap was admitted")
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("admission webhook %q denied the request", name+webhookNameSuffix)))
+
+		err = createConfigMap(ctx, targetNamespace, false)
+		Expect(err).NotTo(HaveOccurred(), "Allowed ConfigMap was rejected")
+	})
+
+	It("should only call the webhook for namespaces matching its namespaceSelector", func(ctx SpecContext) {
+		registerWebhook(ctx, serviceName, "/configmaps", admissionregistrationv1.Fail)
+		waitForDenial(ctx, "denied the request")
+
+		err := createConfigMap(ctx, namespace, true)
+		Expect(err).NotTo(HaveOccurred(), "Webhook was called for a namespace outside its namespaceSelector")
+	})
+
+	It("should apply failurePolicy when the webhook cannot be reached", func(ctx SpecContext) {
+		// No Service of this name exists, so every call to the webhook fails
+		name := registerWebhook(ctx, serviceName+"-missing", "/configmaps", admissionregistrationv1.Fail)
+		waitForDenial(ctx, "failed calling webhook")
+
+		err := createConfigMap(ctx, targetNamespace, false)
+		Expect(err).To(HaveOccurred(), "Unreachable webhook with failurePolicy Fail admitted a ConfigMap")
+
+		// With Ignore the same unreachable webhook must no longer block requests
+		webhooks := framework.Clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+		config, err := webhooks.Get(ctx, name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to get ValidatingWebhookConfiguration")
+		ignore := admissionregistrationv1.Ignore
+		config.Webhooks[0].FailurePolicy = &ignore
+		_, err = webhooks.Update(ctx, config, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred(), "Failed to update failurePolicy")
+
+		framework.Eventually(func() error {
+			return createConfigMap(ctx, targetNamespace, true)
+		}, 120*time.Second, 2*time.Second).Should(Succeed(), "Unreachable webhook with failurePolicy Ignore blocked a ConfigMap")
+	})
+})